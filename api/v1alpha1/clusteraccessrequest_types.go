@@ -0,0 +1,123 @@
+// Copyright 2024
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// ClusterAccessRequestKind is the string representation of a ClusterAccessRequest.
+	ClusterAccessRequestKind = "ClusterAccessRequest"
+	// ClusterAccessRequestFinalizer is the finalizer revoking the granted access on deletion.
+	ClusterAccessRequestFinalizer = "k0rdent.mirantis.com/cluster-access-request"
+
+	// AccessGrantedCondition indicates the access to the cluster has been granted
+	// and the kubeconfig is available.
+	AccessGrantedCondition = "AccessGranted"
+
+	// ExpiredReason indicates the granted access has expired and has been revoked.
+	ExpiredReason = "Expired"
+)
+
+// ClusterAccessRequestSpec defines the desired state of ClusterAccessRequest
+type ClusterAccessRequestSpec struct {
+	// +kubebuilder:validation:MinLength=1
+
+	// ClusterDeployment is the name of the ClusterDeployment
+	// in the same namespace to request the access to.
+	ClusterDeployment string `json:"clusterDeployment"`
+
+	// +kubebuilder:default:=view
+	// +kubebuilder:validation:MinLength=1
+
+	// ClusterRole is the name of the ClusterRole in the cluster to grant,
+	// it must be allowed by the controller manager configuration.
+	ClusterRole string `json:"clusterRole,omitempty"`
+
+	// Namespace is the namespace in the cluster to scope the access to.
+	// If empty, the ClusterRole is granted cluster-wide, which must be
+	// allowed by the controller manager configuration.
+	Namespace string `json:"namespace,omitempty"`
+
+	// +kubebuilder:default:="1h"
+	// +kubebuilder:validation:XValidation:rule="duration(self) >= duration('10m')",message="the TTL must be at least 10m"
+
+	// TTL is the lifetime of the access, the access is revoked
+	// and the kubeconfig is removed once it expires. It must be at least 10m
+	// and must not exceed the maximum of the controller manager configuration.
+	TTL metav1.Duration `json:"ttl,omitempty"`
+}
+
+// ClusterAccessRequestStatus defines the observed state of ClusterAccessRequest
+type ClusterAccessRequestStatus struct {
+	// ExpirationTime is the time the access expires at.
+	ExpirationTime *metav1.Time `json:"expirationTime,omitempty"`
+	// KubeconfigSecretName is the name of the Secret in the same namespace
+	// holding the issued kubeconfig under the "value" key.
+	KubeconfigSecretName string `json:"kubeconfigSecretName,omitempty"`
+	// ServiceAccount is the namespaced name of the ServiceAccount
+	// created in the cluster for the access.
+	ServiceAccount string `json:"serviceAccount,omitempty"`
+	// Conditions contains details for the current state of the ClusterAccessRequest.
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// ObservedGeneration is the last observed generation.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:shortName=car
+// +kubebuilder:printcolumn:name="clusterDeployment",type="string",JSONPath=".spec.clusterDeployment",description="ClusterDeployment",priority=0
+// +kubebuilder:printcolumn:name="clusterRole",type="string",JSONPath=".spec.clusterRole",description="ClusterRole",priority=0
+// +kubebuilder:printcolumn:name="granted",type="string",JSONPath=".status.conditions[?(@.type==\"AccessGranted\")].status",description="Granted",priority=0
+// +kubebuilder:printcolumn:name="expires",type="date",JSONPath=".status.expirationTime",description="Expiration time",priority=0
+// +kubebuilder:printcolumn:name="kubeconfig",type="string",JSONPath=".status.kubeconfigSecretName",description="Kubeconfig Secret",priority=1
+
+// ClusterAccessRequest is the Schema for the clusteraccessrequests API.
+// It issues a short-lived kubeconfig scoped to the given ClusterRole
+// for the cluster of a ClusterDeployment.
+type ClusterAccessRequest struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="Spec is immutable"
+
+	Spec   ClusterAccessRequestSpec   `json:"spec,omitempty"`
+	Status ClusterAccessRequestStatus `json:"status,omitempty"`
+}
+
+func (in *ClusterAccessRequest) GetConditions() *[]metav1.Condition {
+	return &in.Status.Conditions
+}
+
+// KubeconfigSecretName returns the name of the Secret holding the issued kubeconfig.
+// The suffix differs from the one of the <cluster>-kubeconfig Secrets created by Cluster API.
+func (in *ClusterAccessRequest) KubeconfigSecretName() string {
+	return in.Name + "-access-kubeconfig"
+}
+
+// +kubebuilder:object:root=true
+
+// ClusterAccessRequestList contains a list of ClusterAccessRequest
+type ClusterAccessRequestList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterAccessRequest `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ClusterAccessRequest{}, &ClusterAccessRequestList{})
+}
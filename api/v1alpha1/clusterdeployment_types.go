@@ -35,6 +35,15 @@ const (
 	KCMManagedLabelValue = "true"
 
 	ClusterNameLabelKey = "cluster.x-k8s.io/cluster-name"
+
+	// AutoscalerMinSizeAnnotation and AutoscalerMaxSizeAnnotation are the annotations
+	// of the MachineDeployments with the size limits of the cluster-autoscaler node groups.
+	AutoscalerMinSizeAnnotation = "cluster.x-k8s.io/cluster-api-autoscaler-node-group-min-size"
+	AutoscalerMaxSizeAnnotation = "cluster.x-k8s.io/cluster-api-autoscaler-node-group-max-size"
+
+	// ClusterAutoscalerServiceName is the name of the cluster-autoscaler
+	// service of the ClusterDeployments with the autoscaler enabled.
+	ClusterAutoscalerServiceName = "cluster-autoscaler"
 )
 
 const (
@@ -46,8 +55,31 @@ const (
 	HelmChartReadyCondition = "HelmChartReady"
 	// HelmReleaseReadyCondition indicates the corresponding HelmRelease is ready and fully reconciled.
 	HelmReleaseReadyCondition = "HelmReleaseReady"
+	// TemplateDeprecatedCondition indicates the referenced Template is deprecated
+	// and points to the recommended upgrade path. It is set only for the deprecated Templates.
+	TemplateDeprecatedCondition = "TemplateDeprecated"
+	// ClusterInspectedCondition indicates the adopted cluster has been reached
+	// with the Credential's kubeconfig and its inventory has been discovered.
+	ClusterInspectedCondition = "ClusterInspected"
+	// ServiceReleasesConflictCondition indicates some of the services planned in the ServiceSpec
+	// collide with the Helm releases already installed on the adopted cluster.
+	// It is set only if there are such conflicts.
+	ServiceReleasesConflictCondition = "ServiceReleasesConflict"
 )
 
+const (
+	// DeprecatedReason indicates the referenced Template is deprecated.
+	DeprecatedReason = "Deprecated"
+	// EndOfLifeReason indicates the referenced Template has reached its end-of-life.
+	EndOfLifeReason = "EndOfLife"
+	// ConflictReason indicates the planned services conflict with the existing Helm releases.
+	ConflictReason = "Conflict"
+)
+
+// ConditionHistoryLimit is the maximum number of the transitions of each
+// condition kept in the condition history of the ClusterDeployment.
+const ConditionHistoryLimit = 10
+
 // ClusterDeploymentSpec defines the desired state of ClusterDeployment
 type ClusterDeploymentSpec struct {
 	// Config allows to provide parameters for template customization.
@@ -68,10 +100,65 @@ type ClusterDeploymentSpec struct {
 	PropagateCredentials bool `json:"propagateCredentials,omitempty"`
 	// ServiceSpec is spec related to deployment of services.
 	ServiceSpec ServiceSpec `json:"serviceSpec,omitempty"`
+	// Adoption configures the adoption of an existing cluster,
+	// it is taken into account only for the templates of the adopted clusters.
+	Adoption *AdoptionSpec `json:"adoption,omitempty"`
+	// Autoscaling configures the autoscaling of the worker machine pools of the cluster.
+	Autoscaling *AutoscalingSpec `json:"autoscaling,omitempty"`
 	// DryRun specifies whether the template should be applied after validation or only validated.
 	DryRun bool `json:"dryRun,omitempty"`
 }
 
+// AdoptionSpec defines how an existing cluster is adopted.
+type AdoptionSpec struct {
+	// ImportHelmReleases indicates whether the Helm releases found on the cluster
+	// should be taken over as services if there is a ServiceTemplate
+	// with the same chart name and version, instead of being left unmanaged.
+	// The services defined in the ServiceSpec take precedence over the imported ones.
+	ImportHelmReleases bool `json:"importHelmReleases,omitempty"`
+}
+
+// AutoscalingSpec defines the autoscaling of the worker machine pools of a cluster.
+type AutoscalingSpec struct {
+	// Pools are the size limits of the autoscaled worker machine pools.
+	// +listType=map
+	// +listMapKey=name
+	Pools []PoolAutoscaling `json:"pools,omitempty"`
+	// Autoscaler deploys cluster-autoscaler on the cluster as a service.
+	Autoscaler *AutoscalerService `json:"autoscaler,omitempty"`
+}
+
+// PoolAutoscaling defines the size limits of an autoscaled worker machine pool.
+// +kubebuilder:validation:XValidation:rule="self.minReplicas <= self.maxReplicas",message="minReplicas must not be greater than maxReplicas"
+type PoolAutoscaling struct {
+	// Name is the name of a NodePool attached to the ClusterDeployment
+	// or of a MachineDeployment rendered by the ClusterTemplate.
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+	// MinReplicas is the minimum number of the machines of the pool.
+	// +kubebuilder:validation:Minimum=0
+	MinReplicas int32 `json:"minReplicas"`
+	// MaxReplicas is the maximum number of the machines of the pool.
+	// +kubebuilder:validation:Minimum=1
+	MaxReplicas int32 `json:"maxReplicas"`
+}
+
+// AutoscalerService defines the cluster-autoscaler service of a cluster. It runs on the
+// cluster and manages the machines of the cluster on the management cluster through
+// the kubeconfig of a ServiceAccount scoped to the namespace of the ClusterDeployment.
+type AutoscalerService struct {
+	// Template is the name of the ServiceTemplate of the cluster-autoscaler
+	// chart located in the same namespace.
+	// +kubebuilder:validation:MinLength=1
+	Template string `json:"template"`
+	// Namespace is the namespace cluster-autoscaler is installed in.
+	// +kubebuilder:default:=kube-system
+	Namespace string `json:"namespace,omitempty"`
+	// Values are the additional helm values of the chart, they are deep-merged
+	// over the values configuring the clusterapi cloud provider.
+	Values string `json:"values,omitempty"`
+}
+
 // ClusterDeploymentStatus defines the observed state of ClusterDeployment
 type ClusterDeploymentStatus struct {
 	// Services contains details for the state of services.
@@ -81,15 +168,106 @@ type ClusterDeploymentStatus struct {
 	KubernetesVersion string `json:"k8sVersion,omitempty"`
 	// Conditions contains details for the current state of the ClusterDeployment.
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// ConditionHistory contains the last transitions of each of the conditions,
+	// at most ConditionHistoryLimit per condition type, from the oldest to the newest.
+	ConditionHistory []ConditionTransition `json:"conditionHistory,omitempty"`
+
+	// Inventory is the inventory of the adopted cluster discovered
+	// during the pre-flight inspection. Being set only for the adopted clusters.
+	Inventory *ClusterInventory `json:"inventory,omitempty"`
 
 	// AvailableUpgrades is the list of ClusterTemplate names to which
 	// this cluster can be upgraded. It can be an empty array, which means no upgrades are
 	// available.
 	AvailableUpgrades []string `json:"availableUpgrades,omitempty"`
+	// AppliedDefaults is the list of the ClusterDeploymentDefaults names
+	// layered under the spec.config, from the lowest to the highest priority.
+	AppliedDefaults []string `json:"appliedDefaults,omitempty"`
+	// EffectiveValuesHash is the SHA256 hash of the effective Helm values
+	// the cluster is deployed with. If any ClusterDeploymentDefaults apply, the values
+	// along with their sources are stored in the <name>-effective-values ConfigMap.
+	EffectiveValuesHash string `json:"effectiveValuesHash,omitempty"`
+	// NodePools is the status of the NodePools attached to the ClusterDeployment.
+	NodePools []NodePoolSummary `json:"nodePools,omitempty"`
+	// Autoscaling is the status of the autoscaled worker machine pools.
+	Autoscaling []PoolAutoscalingStatus `json:"autoscaling,omitempty"`
+	// ValidatedInputsHash is the SHA256 hash of the chart digest, the effective
+	// values and the Credential the Helm chart was last successfully validated with.
+	// The validation is skipped while the hash does not change.
+	ValidatedInputsHash string `json:"validatedInputsHash,omitempty"`
+	// FirstReadyTime is the time the ClusterDeployment became Ready for the first time.
+	FirstReadyTime *metav1.Time `json:"firstReadyTime,omitempty"`
 	// ObservedGeneration is the last observed generation.
 	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
 }
 
+// PoolAutoscalingStatus is the status of an autoscaled worker machine pool.
+type PoolAutoscalingStatus struct {
+	// Name is the name of the pool.
+	Name string `json:"name"`
+	// MachineDeployment is the name of the MachineDeployment of the pool,
+	// empty if it is not found.
+	MachineDeployment string `json:"machineDeployment,omitempty"`
+	// MinReplicas is the minimum number of the machines of the pool.
+	MinReplicas int32 `json:"minReplicas"`
+	// MaxReplicas is the maximum number of the machines of the pool.
+	MaxReplicas int32 `json:"maxReplicas"`
+	// Replicas is the current number of the machines of the pool.
+	Replicas int32 `json:"replicas"`
+	// DesiredReplicas is the number of the machines of the pool
+	// currently requested by cluster-autoscaler.
+	DesiredReplicas int32 `json:"desiredReplicas"`
+}
+
+// ConditionTransition is a change of the status or the reason of a condition.
+type ConditionTransition struct {
+	// Time is the time of the transition.
+	Time metav1.Time `json:"time"`
+	// Type is the type of the condition.
+	Type string `json:"type"`
+	// Status is the status of the condition after the transition.
+	Status metav1.ConditionStatus `json:"status"`
+	// Reason is the reason of the condition after the transition.
+	Reason string `json:"reason,omitempty"`
+	// Message is the message of the condition after the transition.
+	Message string `json:"message,omitempty"`
+}
+
+// ClusterInventory is the inventory discovered on an adopted cluster.
+type ClusterInventory struct {
+	// LastInspectionTime is the time of the last successful inspection.
+	LastInspectionTime metav1.Time `json:"lastInspectionTime,omitempty"`
+	// KubernetesVersion is the version of the cluster's API server.
+	KubernetesVersion string `json:"k8sVersion,omitempty"`
+	// CNI is the name of the detected CNI plugin, empty if none has been recognized.
+	CNI string `json:"cni,omitempty"`
+	// KubeletVersions is the sorted list of the distinct kubelet versions of the nodes.
+	KubeletVersions []string `json:"kubeletVersions,omitempty"`
+	// HelmReleases is the list of the deployed Helm releases found on the cluster.
+	HelmReleases []DiscoveredHelmRelease `json:"helmReleases,omitempty"`
+	// Nodes is the total number of the nodes.
+	Nodes int32 `json:"nodes,omitempty"`
+	// ReadyNodes is the number of the nodes in the Ready state.
+	ReadyNodes int32 `json:"readyNodes,omitempty"`
+}
+
+// DiscoveredHelmRelease is a Helm release found on an adopted cluster.
+type DiscoveredHelmRelease struct {
+	// Name is the name of the release.
+	Name string `json:"name"`
+	// Namespace is the namespace of the release.
+	Namespace string `json:"namespace"`
+	// Chart is the name of the chart the release is installed from.
+	Chart string `json:"chart"`
+	// Version is the version of the chart.
+	Version string `json:"version"`
+	// Status is the status of the release.
+	Status string `json:"status,omitempty"`
+	// ImportedAs is the name of the ServiceTemplate the release
+	// has been imported with as a service, empty if it is not managed.
+	ImportedAs string `json:"importedAs,omitempty"`
+}
+
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
 // +kubebuilder:resource:shortName=clusterd;cld
@@ -0,0 +1,90 @@
+// Copyright 2024
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+const (
+	// ClusterDeploymentDefaultsKind is the string representation of a ClusterDeploymentDefaults.
+	ClusterDeploymentDefaultsKind = "ClusterDeploymentDefaults"
+
+	// EffectiveValuesConfigMapSuffix is the suffix of the name of the ConfigMap
+	// holding the effective values of a ClusterDeployment.
+	EffectiveValuesConfigMapSuffix = "-effective-values"
+	// EffectiveValuesKey is the key of the effective values in the effective values ConfigMap.
+	EffectiveValuesKey = "values.yaml"
+	// EffectiveValuesSourcesKey is the key of the values sources in the effective values ConfigMap.
+	EffectiveValuesSourcesKey = "sources.yaml"
+	// ClusterDeploymentConfigSource is the source of the values set in the ClusterDeployment spec.config.
+	ClusterDeploymentConfigSource = "spec.config"
+)
+
+// ClusterDeploymentDefaultsSpec defines the desired state of ClusterDeploymentDefaults
+type ClusterDeploymentDefaultsSpec struct {
+	// Selector selects the ClusterDeployments in the same namespace the defaults apply to.
+	// If not set, the defaults apply to all of the ClusterDeployments in the namespace.
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+
+	// Config holds the default Helm values deep-merged under the spec.config
+	// of the selected ClusterDeployments.
+	Config *apiextensionsv1.JSON `json:"config,omitempty"`
+
+	// Priority defines the order in which the defaults are layered if several of them
+	// select the same ClusterDeployment, the values of the defaults with the higher priority win.
+	// The defaults with the same priority are layered in the alphabetical order of their names.
+	Priority int32 `json:"priority,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:shortName=cddefaults
+// +kubebuilder:printcolumn:name="priority",type="integer",JSONPath=".spec.priority",description="Priority",priority=0
+
+// ClusterDeploymentDefaults is the Schema for the clusterdeploymentdefaults API
+type ClusterDeploymentDefaults struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ClusterDeploymentDefaultsSpec `json:"spec,omitempty"`
+}
+
+// Matches reports whether the defaults apply to a ClusterDeployment with the given labels.
+func (in *ClusterDeploymentDefaults) Matches(clusterDeploymentLabels map[string]string) (bool, error) {
+	if in.Spec.Selector == nil {
+		return true, nil
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(in.Spec.Selector)
+	if err != nil {
+		return false, err
+	}
+	return selector.Matches(labels.Set(clusterDeploymentLabels)), nil
+}
+
+// +kubebuilder:object:root=true
+
+// ClusterDeploymentDefaultsList contains a list of ClusterDeploymentDefaults
+type ClusterDeploymentDefaultsList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterDeploymentDefaults `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ClusterDeploymentDefaults{}, &ClusterDeploymentDefaultsList{})
+}
@@ -43,6 +43,9 @@ type ClusterTemplateSpec struct {
 	// Providers represent required CAPI providers.
 	// Should be set if not present in the Helm chart metadata.
 	Providers Providers `json:"providers,omitempty"`
+	// Lifecycle describes the deprecation and end-of-life state of the ClusterTemplate.
+	// Unlike the rest of the spec, it can be changed after the creation.
+	Lifecycle *TemplateLifecycle `json:"lifecycle,omitempty"`
 }
 
 // ClusterTemplateStatus defines the observed state of ClusterTemplate
@@ -96,6 +99,11 @@ func (t *ClusterTemplate) GetSpecProviders() Providers {
 	return t.Spec.Providers
 }
 
+// GetLifecycle returns .spec.lifecycle of the Template.
+func (t *ClusterTemplate) GetLifecycle() *TemplateLifecycle {
+	return t.Spec.Lifecycle
+}
+
 // GetHelmSpec returns .spec.helm of the Template.
 func (t *ClusterTemplate) GetHelmSpec() *HelmSpec {
 	return &t.Spec.Helm
@@ -118,7 +126,7 @@ type ClusterTemplate struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
 
-	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="Spec is immutable"
+	// +kubebuilder:validation:XValidation:rule="self.helm == oldSelf.helm && (has(self.providerContracts) == has(oldSelf.providerContracts) && (!has(self.providerContracts) || self.providerContracts == oldSelf.providerContracts)) && (has(self.k8sVersion) == has(oldSelf.k8sVersion) && (!has(self.k8sVersion) || self.k8sVersion == oldSelf.k8sVersion)) && (has(self.providers) == has(oldSelf.providers) && (!has(self.providers) || self.providers == oldSelf.providers))",message="Spec is immutable except for the lifecycle"
 
 	Spec   ClusterTemplateSpec   `json:"spec,omitempty"`
 	Status ClusterTemplateStatus `json:"status,omitempty"`
@@ -33,6 +33,8 @@ func SetupIndexers(ctx context.Context, mgr ctrl.Manager) error {
 		setupClusterTemplateChainIndexer,
 		setupServiceTemplateChainIndexer,
 		setupClusterTemplateProvidersIndexer,
+		setupTemplateRegistryIndexers,
+		setupNodePoolClusterDeploymentIndexer,
 		setupMultiClusterServiceServicesIndexer,
 		setupOwnerReferenceIndexers,
 		setupManagementBackupIndexer,
@@ -189,6 +191,39 @@ func ExtractProvidersFromClusterTemplate(o client.Object) []string {
 	return ct.Status.Providers
 }
 
+// template registry
+
+// TemplateRegistryIndexKey indexer field name to extract the TemplateRegistry name reference from a Template object.
+const TemplateRegistryIndexKey = ".spec.helm.registry"
+
+func setupTemplateRegistryIndexers(ctx context.Context, mgr ctrl.Manager) error {
+	var merr error
+	for _, obj := range []client.Object{
+		&ClusterTemplate{},
+		&ServiceTemplate{},
+		&ProviderTemplate{},
+	} {
+		merr = errors.Join(merr, mgr.GetFieldIndexer().IndexField(ctx, obj, TemplateRegistryIndexKey, ExtractTemplateRegistryName))
+	}
+
+	return merr
+}
+
+// ExtractTemplateRegistryName returns the name of the TemplateRegistry referenced by a Template object.
+func ExtractTemplateRegistryName(rawObj client.Object) []string {
+	template, ok := rawObj.(interface{ GetHelmSpec() *HelmSpec })
+	if !ok {
+		return nil
+	}
+
+	registry := template.GetHelmSpec().Registry
+	if registry == "" {
+		return nil
+	}
+
+	return []string{registry}
+}
+
 // multicluster service
 
 // MultiClusterServiceTemplatesIndexKey indexer field name to extract service templates names from a MultiClusterService object.
@@ -275,3 +310,23 @@ func setupManagementBackupAutoUpgradesIndexer(ctx context.Context, mgr ctrl.Mana
 		return []string{"true"}
 	})
 }
+
+// node pool
+
+// NodePoolClusterDeploymentIndexKey indexer field name to extract the ClusterDeployment name reference from a NodePool object.
+const NodePoolClusterDeploymentIndexKey = ".spec.clusterDeployment"
+
+func setupNodePoolClusterDeploymentIndexer(ctx context.Context, mgr ctrl.Manager) error {
+	return mgr.GetFieldIndexer().IndexField(ctx, &NodePool{}, NodePoolClusterDeploymentIndexKey, ExtractClusterDeploymentNameFromNodePool)
+}
+
+// ExtractClusterDeploymentNameFromNodePool returns the referenced ClusterDeployment name
+// declared in a NodePool object.
+func ExtractClusterDeploymentNameFromNodePool(rawObj client.Object) []string {
+	nodePool, ok := rawObj.(*NodePool)
+	if !ok {
+		return nil
+	}
+
+	return []string{nodePool.Spec.ClusterDeployment}
+}
@@ -0,0 +1,128 @@
+// Copyright 2024
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// NodePoolKind is the string representation of a NodePool.
+	NodePoolKind = "NodePool"
+
+	// NodePoolLabelKey is the label of the objects rendered for a NodePool,
+	// the value is the name of the NodePool.
+	NodePoolLabelKey = "k0rdent.mirantis.com/node-pool"
+
+	// NodePoolReadyCondition indicates whether all of the machines of the NodePool are ready.
+	NodePoolReadyCondition = "NodePoolReady"
+)
+
+// NodePoolSpec defines the desired state of NodePool
+type NodePoolSpec struct {
+	// ClusterDeployment is the name of the ClusterDeployment in the same namespace
+	// the pool of the worker machines is added to.
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="clusterDeployment is immutable"
+	ClusterDeployment string `json:"clusterDeployment"`
+
+	// Replicas is the number of the machines of the pool.
+	// +kubebuilder:default=1
+	// +kubebuilder:validation:Minimum=0
+	Replicas *int32 `json:"replicas,omitempty"`
+
+	// Labels are the labels of the nodes of the pool.
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Taints are the taints of the nodes of the pool.
+	Taints []corev1.Taint `json:"taints,omitempty"`
+
+	// MachineTemplate is the provider-specific snippet deep-merged into the
+	// spec.template.spec of the infrastructure machine template of the default
+	// worker pool of the cluster, e.g. {"instanceType": "g4dn.xlarge"}
+	// for the AWSMachineTemplate, or into the spec of the infrastructure machine
+	// pool if the workers of the cluster are MachinePools, e.g. {"machineType": "n2-standard-8"}
+	// for the GCPManagedMachinePool.
+	MachineTemplate *apiextensionsv1.JSON `json:"machineTemplate,omitempty"`
+}
+
+// NodePoolStatus defines the observed state of NodePool
+type NodePoolStatus struct {
+	// MachineDeployment is the name of the MachineDeployment rendered for the pool
+	// if the workers of the cluster are MachineDeployments.
+	MachineDeployment string `json:"machineDeployment,omitempty"`
+	// MachinePool is the name of the MachinePool rendered for the pool
+	// if the workers of the cluster are MachinePools.
+	MachinePool string `json:"machinePool,omitempty"`
+	// Conditions contains details for the current state of the NodePool.
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// Replicas is the number of the machines of the pool.
+	Replicas int32 `json:"replicas,omitempty"`
+	// ReadyReplicas is the number of the ready machines of the pool.
+	ReadyReplicas int32 `json:"readyReplicas,omitempty"`
+	// ObservedGeneration is the last observed generation.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+}
+
+// NodePoolSummary is the status of a NodePool of a ClusterDeployment.
+type NodePoolSummary struct {
+	// Name is the name of the NodePool.
+	Name string `json:"name"`
+	// Replicas is the number of the machines of the pool.
+	Replicas int32 `json:"replicas,omitempty"`
+	// ReadyReplicas is the number of the ready machines of the pool.
+	ReadyReplicas int32 `json:"readyReplicas,omitempty"`
+	// Ready indicates whether all of the machines of the pool are ready.
+	Ready bool `json:"ready"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:shortName=np
+// +kubebuilder:printcolumn:name="Cluster",type=string,JSONPath=`.spec.clusterDeployment`
+// +kubebuilder:printcolumn:name="Replicas",type=integer,JSONPath=`.status.replicas`
+// +kubebuilder:printcolumn:name="Ready",type=integer,JSONPath=`.status.readyReplicas`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// NodePool is the Schema for the nodepools API.
+// It adds a pool of worker machines to a ClusterDeployment in addition
+// to the ones defined by the values of its ClusterTemplate.
+type NodePool struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   NodePoolSpec   `json:"spec,omitempty"`
+	Status NodePoolStatus `json:"status,omitempty"`
+}
+
+// GetConditions returns the conditions of the NodePool.
+func (in *NodePool) GetConditions() *[]metav1.Condition {
+	return &in.Status.Conditions
+}
+
+// +kubebuilder:object:root=true
+
+// NodePoolList contains a list of NodePool
+type NodePoolList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []NodePool `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&NodePool{}, &NodePoolList{})
+}
@@ -0,0 +1,99 @@
+// Copyright 2024
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// ProviderDefinitionKind is the string representation of a ProviderDefinition.
+const ProviderDefinitionKind = "ProviderDefinition"
+
+// ProviderDefinitionSpec defines the desired state of ProviderDefinition
+type ProviderDefinitionSpec struct {
+	// ClusterGVKs is the list of the GroupVersionKinds of the provider's
+	// infrastructure cluster resources.
+	// +kubebuilder:validation:MinItems=1
+	ClusterGVKs []ClusterGVK `json:"clusterGVKs"`
+	// ClusterIdentityKinds is the list of the kinds of the cluster identities
+	// a Credential may reference to be used with the provider.
+	// +kubebuilder:validation:MinItems=1
+	ClusterIdentityKinds []string `json:"clusterIdentityKinds"`
+	// InstallInManagement adds the provider to the providers of the Management,
+	// the ProviderTemplate of the provider must be available in the Release.
+	// The provider is removed from the Management once the flag is unset
+	// or the ProviderDefinition is deleted.
+	InstallInManagement bool `json:"installInManagement,omitempty"`
+}
+
+// ClusterGVK is the GroupVersionKind of an infrastructure cluster resource.
+type ClusterGVK struct {
+	// +kubebuilder:validation:MinLength=1
+
+	// Group is the API group of the resource.
+	Group string `json:"group"`
+
+	// +kubebuilder:validation:MinLength=1
+
+	// Version is the API version of the resource.
+	Version string `json:"version"`
+
+	// +kubebuilder:validation:MinLength=1
+
+	// Kind is the kind of the resource.
+	Kind string `json:"kind"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster,shortName=providerdef
+
+// ProviderDefinition is the Schema for the providerdefinitions API.
+// It registers a Cluster API infrastructure provider in addition to
+// the providers built into the kcm image, the name of the object is
+// the short name of the provider, e.g. "docker" for the "infrastructure-docker".
+type ProviderDefinition struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ProviderDefinitionSpec `json:"spec,omitempty"`
+}
+
+// GetClusterGVKs returns the GroupVersionKinds of the provider's cluster resources.
+func (in *ProviderDefinition) GetClusterGVKs() []schema.GroupVersionKind {
+	gvks := make([]schema.GroupVersionKind, 0, len(in.Spec.ClusterGVKs))
+	for _, gvk := range in.Spec.ClusterGVKs {
+		gvks = append(gvks, schema.GroupVersionKind{Group: gvk.Group, Version: gvk.Version, Kind: gvk.Kind})
+	}
+	return gvks
+}
+
+// GetClusterIdentityKinds returns the supported cluster identity kinds.
+func (in *ProviderDefinition) GetClusterIdentityKinds() []string {
+	return append([]string(nil), in.Spec.ClusterIdentityKinds...)
+}
+
+// +kubebuilder:object:root=true
+
+// ProviderDefinitionList contains a list of ProviderDefinition
+type ProviderDefinitionList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ProviderDefinition `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ProviderDefinition{}, &ProviderDefinitionList{})
+}
@@ -36,6 +36,9 @@ type ServiceTemplateSpec struct {
 	// Providers represent requested CAPI providers.
 	// Should be set if not present in the Helm chart metadata.
 	Providers Providers `json:"providers,omitempty"`
+	// Lifecycle describes the deprecation and end-of-life state of the ServiceTemplate.
+	// Unlike the rest of the spec, it can be changed after the creation.
+	Lifecycle *TemplateLifecycle `json:"lifecycle,omitempty"`
 }
 
 // ServiceTemplateStatus defines the observed state of ServiceTemplate
@@ -75,6 +78,11 @@ func (t *ServiceTemplate) GetSpecProviders() Providers {
 	return t.Spec.Providers
 }
 
+// GetLifecycle returns .spec.lifecycle of the Template.
+func (t *ServiceTemplate) GetLifecycle() *TemplateLifecycle {
+	return t.Spec.Lifecycle
+}
+
 // GetHelmSpec returns .spec.helm of the Template.
 func (t *ServiceTemplate) GetHelmSpec() *HelmSpec {
 	return &t.Spec.Helm
@@ -97,7 +105,7 @@ type ServiceTemplate struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
 
-	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="Spec is immutable"
+	// +kubebuilder:validation:XValidation:rule="self.helm == oldSelf.helm && (has(self.k8sConstraint) == has(oldSelf.k8sConstraint) && (!has(self.k8sConstraint) || self.k8sConstraint == oldSelf.k8sConstraint)) && (has(self.providers) == has(oldSelf.providers) && (!has(self.providers) || self.providers == oldSelf.providers))",message="Spec is immutable except for the lifecycle"
 
 	Spec   ServiceTemplateSpec   `json:"spec,omitempty"`
 	Status ServiceTemplateStatus `json:"status,omitempty"`
@@ -32,16 +32,22 @@ func (t *ServiceTemplateChain) GetSpec() *TemplateChainSpec {
 	return &t.Spec
 }
 
+func (t *ServiceTemplateChain) GetStatus() *TemplateChainStatus {
+	return &t.Status
+}
+
 // +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="valid",type="boolean",JSONPath=".status.valid",description="Valid",priority=0
+// +kubebuilder:printcolumn:name="warnings",type="string",JSONPath=".status.warnings",description="Warnings",priority=1
 
 // ServiceTemplateChain is the Schema for the servicetemplatechains API
 type ServiceTemplateChain struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
 
-	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="Spec is immutable"
-
-	Spec TemplateChainSpec `json:"spec,omitempty"`
+	Spec   TemplateChainSpec   `json:"spec,omitempty"`
+	Status TemplateChainStatus `json:"status,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -14,16 +14,116 @@
 
 package v1alpha1
 
-// TemplateChainSpec defines the observed state of TemplateChain
+// TemplateChainSpec defines the observed state of TemplateChain.
+// The chain can only be extended: the supported templates and their available upgrades
+// can be added but cannot be removed.
 type TemplateChainSpec struct {
 	// SupportedTemplates is the list of supported Templates definitions and all available upgrade sequences for it.
 	SupportedTemplates []SupportedTemplate `json:"supportedTemplates,omitempty"`
+	// UpgradeRules is the list of rules defining the available upgrades between the supported Templates
+	// based on their chart versions. The rules are resolved by the controller
+	// in addition to the explicitly listed available upgrades.
+	UpgradeRules []UpgradeRule `json:"upgradeRules,omitempty"`
+}
+
+// UpgradeRuleTarget defines which of the Templates matching an upgrade rule are available as upgrades.
+type UpgradeRuleTarget string
+
+const (
+	// UpgradeRuleTargetHighest makes only the Template with the highest matching chart version available.
+	UpgradeRuleTargetHighest UpgradeRuleTarget = "Highest"
+	// UpgradeRuleTargetAll makes all of the matching Templates available.
+	UpgradeRuleTargetAll UpgradeRuleTarget = "All"
+)
+
+// UpgradeRule defines the upgrades available for a family of the supported Templates,
+// i.e. the Templates of the same Helm chart, based on their chart versions.
+// Only the upgrades to the higher chart versions are considered.
+type UpgradeRule struct {
+	// +kubebuilder:validation:MinLength=1
+
+	// Chart is the name of the Helm chart of the Templates the rule applies to.
+	Chart string `json:"chart"`
+
+	// +kubebuilder:validation:MinLength=1
+
+	// From is the semver constraint the chart version of the Template being upgraded should satisfy, e.g. "~1.2".
+	From string `json:"from"`
+
+	// +kubebuilder:validation:MinLength=1
+
+	// To is the semver constraint the chart versions of the Templates available as upgrades should satisfy,
+	// e.g. ">=1.2 <1.4".
+	To string `json:"to"`
+
+	// +kubebuilder:validation:Enum=Highest;All
+	// +kubebuilder:default=Highest
+
+	// Target defines which of the Templates satisfying the To constraint are available as upgrades:
+	// either the one with the highest chart version only or all of them.
+	Target UpgradeRuleTarget `json:"target,omitempty"`
+}
+
+// TemplateChainStatus defines the observed state of TemplateChain
+type TemplateChainStatus struct {
+	// ResolvedUpgrades is the list of the available upgrades resolved from the upgrade rules.
+	ResolvedUpgrades []ResolvedUpgrade `json:"resolvedUpgrades,omitempty"`
+	// Graph is the computed upgrade graph of the supported Templates
+	// including both the explicit and the resolved upgrades.
+	Graph []UpgradeGraphNode `json:"graph,omitempty"`
+	// UnreachableTemplates is the list of the supported Templates disconnected from the upgrade graph,
+	// i.e. the ones that can be neither upgraded nor upgraded to.
+	UnreachableTemplates []string `json:"unreachableTemplates,omitempty"`
+	// Warnings is the list of the problems found during the upgrade graph validation.
+	Warnings []string `json:"warnings,omitempty"`
+	// ObservedGeneration is the last observed generation.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Valid indicates whether the upgrade graph passed validation or not.
+	Valid bool `json:"valid"`
+}
+
+// UpgradeGraphNode is the supported Template along with all of its available upgrades.
+type UpgradeGraphNode struct {
+	// Name is the name of the Template.
+	Name string `json:"name"`
+	// KubernetesVersion is the Kubernetes version provided by the ClusterTemplate.
+	KubernetesVersion string `json:"k8sVersion,omitempty"`
+	// Upgrades is the list of names of the Templates available as upgrades.
+	Upgrades []string `json:"upgrades,omitempty"`
+}
+
+// ResolvedUpgrade is the list of the available upgrades for the Template resolved from the upgrade rules.
+type ResolvedUpgrade struct {
+	// Name is the name of the Template.
+	Name string `json:"name"`
+	// AvailableUpgrades is the list of available upgrades for the specified Template.
+	AvailableUpgrades []AvailableUpgrade `json:"availableUpgrades,omitempty"`
+}
+
+// AvailableUpgradesFor returns the available upgrades for the Template with the given name,
+// both listed explicitly in the spec and resolved from the upgrade rules.
+func AvailableUpgradesFor(spec *TemplateChainSpec, status *TemplateChainStatus, name string) []AvailableUpgrade {
+	var upgrades []AvailableUpgrade
+	for _, supportedTemplate := range spec.SupportedTemplates {
+		if supportedTemplate.Name == name {
+			upgrades = append(upgrades, supportedTemplate.AvailableUpgrades...)
+		}
+	}
+	for _, resolved := range status.ResolvedUpgrades {
+		if resolved.Name == name {
+			upgrades = append(upgrades, resolved.AvailableUpgrades...)
+		}
+	}
+	return upgrades
 }
 
 // SupportedTemplate is the supported Template definition and all available upgrade sequences for it
 type SupportedTemplate struct {
 	// Name is the name of the Template.
 	Name string `json:"name"`
+	// Lifecycle describes the deprecation and end-of-life state of the Template within the chain.
+	// It takes precedence over the lifecycle defined in the Template itself.
+	Lifecycle *TemplateLifecycle `json:"lifecycle,omitempty"`
 	// AvailableUpgrades is the list of available upgrades for the specified Template.
 	AvailableUpgrades []AvailableUpgrade `json:"availableUpgrades,omitempty"`
 }
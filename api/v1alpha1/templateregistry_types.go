@@ -0,0 +1,87 @@
+// Copyright 2024
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// TemplateRegistryKind is the string representation of a TemplateRegistry.
+	TemplateRegistryKind = "TemplateRegistry"
+
+	// templateRegistryRepoPrefix is the prefix of the names of the HelmRepositories
+	// created for the TemplateRegistries.
+	templateRegistryRepoPrefix = "kcm-registry-"
+)
+
+// TemplateRegistrySpec defines the desired state of TemplateRegistry
+type TemplateRegistrySpec struct {
+	// URL is the URL of the registry. The oci:// scheme stands for an OCI registry,
+	// the http:// and https:// schemes stand for a Helm HTTP repository.
+	// +kubebuilder:validation:Pattern=`^(oci|https?)://.+$`
+	URL string `json:"url"`
+
+	// CredentialsSecret is the name of the Secret with the credentials to access the registry.
+	// The Secret is looked up in the namespace of each Template referencing the registry.
+	CredentialsSecret string `json:"credentialsSecret,omitempty"`
+
+	// CertSecret is the name of the Secret with the CA bundle in the "ca.crt" key
+	// to verify the registry with and optionally the client certificate and key
+	// in the "tls.crt" and "tls.key" keys to authenticate to the registry with.
+	// The Secret is looked up in the namespace of each Template referencing the registry.
+	CertSecret string `json:"certSecret,omitempty"`
+
+	// Interval is the interval at which the registry is checked for updates.
+	// Defaults to 10m.
+	Interval *metav1.Duration `json:"interval,omitempty"`
+
+	// Insecure allows connecting to a non-TLS HTTP registry.
+	Insecure bool `json:"insecure,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster,shortName=treg
+// +kubebuilder:printcolumn:name="URL",type=string,JSONPath=`.spec.url`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// TemplateRegistry is the Schema for the templateregistries API.
+// It declares a chart registry Templates can fetch their charts from
+// by referencing the registry by name in the .spec.helm.registry.
+type TemplateRegistry struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec TemplateRegistrySpec `json:"spec,omitempty"`
+}
+
+// TemplateRegistryRepoName returns the name of the HelmRepository
+// serving the charts of the TemplateRegistry with the given name.
+func TemplateRegistryRepoName(registry string) string {
+	return templateRegistryRepoPrefix + registry
+}
+
+// +kubebuilder:object:root=true
+
+// TemplateRegistryList contains a list of TemplateRegistry
+type TemplateRegistryList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []TemplateRegistry `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&TemplateRegistry{}, &TemplateRegistryList{})
+}
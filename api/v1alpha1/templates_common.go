@@ -19,10 +19,12 @@ import (
 	"fmt"
 	"slices"
 	"strings"
+	"time"
 
 	helmcontrollerv2 "github.com/fluxcd/helm-controller/api/v2"
 	sourcev1 "github.com/fluxcd/source-controller/api/v1"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	clusterapiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
 )
 
@@ -38,6 +40,7 @@ var DefaultSourceRef = sourcev1.LocalHelmChartSourceReference{
 }
 
 // +kubebuilder:validation:XValidation:rule="(has(self.chartSpec) && !has(self.chartRef)) || (!has(self.chartSpec) && has(self.chartRef))", message="either chartSpec or chartRef must be set"
+// +kubebuilder:validation:XValidation:rule="!has(self.registry) || has(self.chartSpec)", message="registry can only be set along with chartSpec"
 
 // HelmSpec references a Helm chart representing the KCM template
 type HelmSpec struct {
@@ -47,6 +50,11 @@ type HelmSpec struct {
 	// ChartRef is a reference to a source controller resource containing the
 	// Helm chart representing the template.
 	ChartRef *helmcontrollerv2.CrossNamespaceSourceReference `json:"chartRef,omitempty"`
+
+	// Registry is the name of the TemplateRegistry to fetch the chart of the ChartSpec from.
+	// The sourceRef of the ChartSpec is set to the HelmRepository of the registry
+	// in the namespace of the Template. If unset, the default registry is used.
+	Registry string `json:"registry,omitempty"`
 }
 
 func (s *HelmSpec) String() string {
@@ -65,6 +73,59 @@ func (s *HelmSpec) String() string {
 	return s.ChartSpec.Chart
 }
 
+// TemplateLifecycle describes the deprecation and end-of-life state of a Template.
+type TemplateLifecycle struct {
+	// EndOfLife is the date after which the Template can no longer be used
+	// by new ClusterDeployments or as a target of an upgrade.
+	// A Template with the EndOfLife set is considered deprecated.
+	EndOfLife *metav1.Time `json:"endOfLife,omitempty"`
+	// DeprecationMessage is a human-readable message explaining the deprecation.
+	DeprecationMessage string `json:"deprecationMessage,omitempty"`
+	// Replacement is the name of the Template recommended to be used instead.
+	Replacement string `json:"replacement,omitempty"`
+	// Deprecated marks the Template as deprecated.
+	Deprecated bool `json:"deprecated,omitempty"`
+}
+
+// IsDeprecated reports whether the Template is deprecated.
+func (l *TemplateLifecycle) IsDeprecated() bool {
+	return l != nil && (l.Deprecated || l.EndOfLife != nil)
+}
+
+// IsEndOfLife reports whether the Template has reached its end-of-life at the given time.
+func (l *TemplateLifecycle) IsEndOfLife(now time.Time) bool {
+	return l != nil && l.EndOfLife != nil && !now.Before(l.EndOfLife.Time)
+}
+
+// MergeLifecycles returns the lifecycle combining all of the given ones,
+// later lifecycles take precedence over the former ones for the non-empty fields.
+// Returns nil if none of the given lifecycles is set.
+func MergeLifecycles(lifecycles ...*TemplateLifecycle) *TemplateLifecycle {
+	var res *TemplateLifecycle
+	for _, l := range lifecycles {
+		if l == nil {
+			continue
+		}
+
+		if res == nil {
+			res = new(TemplateLifecycle)
+		}
+
+		res.Deprecated = res.Deprecated || l.Deprecated
+		if l.EndOfLife != nil && (res.EndOfLife == nil || l.EndOfLife.Before(res.EndOfLife)) {
+			res.EndOfLife = l.EndOfLife.DeepCopy()
+		}
+		if l.DeprecationMessage != "" {
+			res.DeprecationMessage = l.DeprecationMessage
+		}
+		if l.Replacement != "" {
+			res.Replacement = l.Replacement
+		}
+	}
+
+	return res
+}
+
 // TemplateStatusCommon defines the observed state of Template common for all Template types
 type TemplateStatusCommon struct {
 	// Config demonstrates available parameters for template customization,
@@ -73,6 +134,8 @@ type TemplateStatusCommon struct {
 	// ChartRef is a reference to a source controller resource containing the
 	// Helm chart representing the template.
 	ChartRef *helmcontrollerv2.CrossNamespaceSourceReference `json:"chartRef,omitempty"`
+	// ChartName represents the name of the Helm Chart associated with this template.
+	ChartName string `json:"chartName,omitempty"`
 	// ChartVersion represents the version of the Helm Chart associated with this template.
 	ChartVersion string `json:"chartVersion,omitempty"`
 	// Description contains information about the template.
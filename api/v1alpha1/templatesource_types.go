@@ -0,0 +1,118 @@
+// Copyright 2024
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// TemplateSourceKind is the string representation of a TemplateSource.
+	TemplateSourceKind = "TemplateSource"
+
+	// TemplateSourceLabelKey is the label set on the Templates created by a TemplateSource,
+	// the value is the name of the TemplateSource.
+	TemplateSourceLabelKey = "k0rdent.mirantis.com/template-source"
+)
+
+// TemplateSourceSpec defines the desired state of TemplateSource
+type TemplateSourceSpec struct {
+	// +kubebuilder:validation:Enum=ClusterTemplate;ServiceTemplate
+
+	// TemplateKind is the kind of the Templates to be created for the discovered charts.
+	TemplateKind string `json:"templateKind"`
+
+	// +kubebuilder:validation:MinLength=1
+
+	// Repository is the name of the HelmRepository in the system namespace
+	// to discover the charts in.
+	Repository string `json:"repository"`
+
+	// +kubebuilder:validation:MinLength=1
+
+	// ChartPattern is the shell pattern (as in [path.Match]) the names of the discovered charts should match.
+	// OCI repositories do not support listing of the charts,
+	// hence for the OCI repositories the pattern must be an exact chart name.
+	ChartPattern string `json:"chartPattern"`
+
+	// VersionConstraint is the semver constraint the versions of the discovered charts should satisfy,
+	// e.g. ">= 1.0.0, < 2.0.0" or "~1.2.0-0" to include the pre-releases.
+	// If not set, all of the versions are discovered.
+	VersionConstraint string `json:"versionConstraint,omitempty"`
+
+	// Chain is the name of the ClusterTemplateChain or ServiceTemplateChain (depending on the TemplateKind)
+	// in the system namespace. The discovered Templates are appended to the chain,
+	// each one as an available upgrade from the previous version of the same chart.
+	// The chain is created if it does not exist.
+	Chain string `json:"chain,omitempty"`
+
+	// Interval is the interval at which the repository is checked for new versions.
+	// Defaults to 10 minutes.
+	Interval *metav1.Duration `json:"interval,omitempty"`
+}
+
+// TemplateSourceStatus defines the observed state of TemplateSource
+type TemplateSourceStatus struct {
+	// LastSyncTime is the time of the last successful discovery.
+	LastSyncTime *metav1.Time `json:"lastSyncTime,omitempty"`
+	// Templates is the list of the Templates discovered by the TemplateSource
+	// ordered by chart name and version.
+	Templates []DiscoveredTemplate `json:"templates,omitempty"`
+	// Conditions contains details for the current state of the TemplateSource.
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// ObservedGeneration is the last observed generation.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+}
+
+// DiscoveredTemplate is the Template created for a discovered chart version.
+type DiscoveredTemplate struct {
+	// Name is the name of the Template.
+	Name string `json:"name"`
+	// Chart is the name of the discovered chart.
+	Chart string `json:"chart"`
+	// Version is the version of the discovered chart.
+	Version string `json:"version"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,shortName=tsrc
+// +kubebuilder:printcolumn:name="kind",type="string",JSONPath=".spec.templateKind",description="Template kind",priority=0
+// +kubebuilder:printcolumn:name="repository",type="string",JSONPath=".spec.repository",description="HelmRepository",priority=0
+// +kubebuilder:printcolumn:name="ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status",description="Ready",priority=0
+// +kubebuilder:printcolumn:name="lastSync",type="date",JSONPath=".status.lastSyncTime",description="Time elapsed since the last discovery",priority=0
+// +kubebuilder:printcolumn:name="status",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].message",description="Status",priority=1
+
+// TemplateSource is the Schema for the templatesources API
+type TemplateSource struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   TemplateSourceSpec   `json:"spec,omitempty"`
+	Status TemplateSourceStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// TemplateSourceList contains a list of TemplateSource
+type TemplateSourceList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []TemplateSource `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&TemplateSource{}, &TemplateSourceList{})
+}
@@ -164,6 +164,61 @@ func (in *AccessRule) DeepCopy() *AccessRule {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AdoptionSpec) DeepCopyInto(out *AdoptionSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AdoptionSpec.
+func (in *AdoptionSpec) DeepCopy() *AdoptionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(AdoptionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AutoscalerService) DeepCopyInto(out *AutoscalerService) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AutoscalerService.
+func (in *AutoscalerService) DeepCopy() *AutoscalerService {
+	if in == nil {
+		return nil
+	}
+	out := new(AutoscalerService)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AutoscalingSpec) DeepCopyInto(out *AutoscalingSpec) {
+	*out = *in
+	if in.Pools != nil {
+		in, out := &in.Pools, &out.Pools
+		*out = make([]PoolAutoscaling, len(*in))
+		copy(*out, *in)
+	}
+	if in.Autoscaler != nil {
+		in, out := &in.Autoscaler, &out.Autoscaler
+		*out = new(AutoscalerService)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AutoscalingSpec.
+func (in *AutoscalingSpec) DeepCopy() *AutoscalingSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(AutoscalingSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AvailableUpgrade) DeepCopyInto(out *AvailableUpgrade) {
 	*out = *in
@@ -179,6 +234,107 @@ func (in *AvailableUpgrade) DeepCopy() *AvailableUpgrade {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterAccessRequest) DeepCopyInto(out *ClusterAccessRequest) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterAccessRequest.
+func (in *ClusterAccessRequest) DeepCopy() *ClusterAccessRequest {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterAccessRequest)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterAccessRequest) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterAccessRequestList) DeepCopyInto(out *ClusterAccessRequestList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ClusterAccessRequest, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterAccessRequestList.
+func (in *ClusterAccessRequestList) DeepCopy() *ClusterAccessRequestList {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterAccessRequestList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterAccessRequestList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterAccessRequestSpec) DeepCopyInto(out *ClusterAccessRequestSpec) {
+	*out = *in
+	out.TTL = in.TTL
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterAccessRequestSpec.
+func (in *ClusterAccessRequestSpec) DeepCopy() *ClusterAccessRequestSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterAccessRequestSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterAccessRequestStatus) DeepCopyInto(out *ClusterAccessRequestStatus) {
+	*out = *in
+	if in.ExpirationTime != nil {
+		in, out := &in.ExpirationTime, &out.ExpirationTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterAccessRequestStatus.
+func (in *ClusterAccessRequestStatus) DeepCopy() *ClusterAccessRequestStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterAccessRequestStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClusterDeployment) DeepCopyInto(out *ClusterDeployment) {
 	*out = *in
@@ -206,6 +362,89 @@ func (in *ClusterDeployment) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterDeploymentDefaults) DeepCopyInto(out *ClusterDeploymentDefaults) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterDeploymentDefaults.
+func (in *ClusterDeploymentDefaults) DeepCopy() *ClusterDeploymentDefaults {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterDeploymentDefaults)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterDeploymentDefaults) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterDeploymentDefaultsList) DeepCopyInto(out *ClusterDeploymentDefaultsList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ClusterDeploymentDefaults, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterDeploymentDefaultsList.
+func (in *ClusterDeploymentDefaultsList) DeepCopy() *ClusterDeploymentDefaultsList {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterDeploymentDefaultsList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterDeploymentDefaultsList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterDeploymentDefaultsSpec) DeepCopyInto(out *ClusterDeploymentDefaultsSpec) {
+	*out = *in
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Config != nil {
+		in, out := &in.Config, &out.Config
+		*out = new(apiextensionsv1.JSON)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterDeploymentDefaultsSpec.
+func (in *ClusterDeploymentDefaultsSpec) DeepCopy() *ClusterDeploymentDefaultsSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterDeploymentDefaultsSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClusterDeploymentList) DeepCopyInto(out *ClusterDeploymentList) {
 	*out = *in
@@ -247,6 +486,16 @@ func (in *ClusterDeploymentSpec) DeepCopyInto(out *ClusterDeploymentSpec) {
 		(*in).DeepCopyInto(*out)
 	}
 	in.ServiceSpec.DeepCopyInto(&out.ServiceSpec)
+	if in.Adoption != nil {
+		in, out := &in.Adoption, &out.Adoption
+		*out = new(AdoptionSpec)
+		**out = **in
+	}
+	if in.Autoscaling != nil {
+		in, out := &in.Autoscaling, &out.Autoscaling
+		*out = new(AutoscalingSpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterDeploymentSpec.
@@ -276,11 +525,42 @@ func (in *ClusterDeploymentStatus) DeepCopyInto(out *ClusterDeploymentStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.ConditionHistory != nil {
+		in, out := &in.ConditionHistory, &out.ConditionHistory
+		*out = make([]ConditionTransition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Inventory != nil {
+		in, out := &in.Inventory, &out.Inventory
+		*out = new(ClusterInventory)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.AvailableUpgrades != nil {
 		in, out := &in.AvailableUpgrades, &out.AvailableUpgrades
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.AppliedDefaults != nil {
+		in, out := &in.AppliedDefaults, &out.AppliedDefaults
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.NodePools != nil {
+		in, out := &in.NodePools, &out.NodePools
+		*out = make([]NodePoolSummary, len(*in))
+		copy(*out, *in)
+	}
+	if in.Autoscaling != nil {
+		in, out := &in.Autoscaling, &out.Autoscaling
+		*out = make([]PoolAutoscalingStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.FirstReadyTime != nil {
+		in, out := &in.FirstReadyTime, &out.FirstReadyTime
+		*out = (*in).DeepCopy()
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterDeploymentStatus.
@@ -293,6 +573,47 @@ func (in *ClusterDeploymentStatus) DeepCopy() *ClusterDeploymentStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterGVK) DeepCopyInto(out *ClusterGVK) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterGVK.
+func (in *ClusterGVK) DeepCopy() *ClusterGVK {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterGVK)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterInventory) DeepCopyInto(out *ClusterInventory) {
+	*out = *in
+	in.LastInspectionTime.DeepCopyInto(&out.LastInspectionTime)
+	if in.KubeletVersions != nil {
+		in, out := &in.KubeletVersions, &out.KubeletVersions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.HelmReleases != nil {
+		in, out := &in.HelmReleases, &out.HelmReleases
+		*out = make([]DiscoveredHelmRelease, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterInventory.
+func (in *ClusterInventory) DeepCopy() *ClusterInventory {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterInventory)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClusterTemplate) DeepCopyInto(out *ClusterTemplate) {
 	*out = *in
@@ -326,6 +647,7 @@ func (in *ClusterTemplateChain) DeepCopyInto(out *ClusterTemplateChain) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterTemplateChain.
@@ -426,6 +748,11 @@ func (in *ClusterTemplateSpec) DeepCopyInto(out *ClusterTemplateSpec) {
 		*out = make(Providers, len(*in))
 		copy(*out, *in)
 	}
+	if in.Lifecycle != nil {
+		in, out := &in.Lifecycle, &out.Lifecycle
+		*out = new(TemplateLifecycle)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterTemplateSpec.
@@ -522,6 +849,22 @@ func (in *ComponentStatus) DeepCopy() *ComponentStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConditionTransition) DeepCopyInto(out *ConditionTransition) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConditionTransition.
+func (in *ConditionTransition) DeepCopy() *ConditionTransition {
+	if in == nil {
+		return nil
+	}
+	out := new(ConditionTransition)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Core) DeepCopyInto(out *Core) {
 	*out = *in
@@ -655,6 +998,36 @@ func (in *CredentialStatus) DeepCopy() *CredentialStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DiscoveredHelmRelease) DeepCopyInto(out *DiscoveredHelmRelease) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiscoveredHelmRelease.
+func (in *DiscoveredHelmRelease) DeepCopy() *DiscoveredHelmRelease {
+	if in == nil {
+		return nil
+	}
+	out := new(DiscoveredHelmRelease)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DiscoveredTemplate) DeepCopyInto(out *DiscoveredTemplate) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiscoveredTemplate.
+func (in *DiscoveredTemplate) DeepCopy() *DiscoveredTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(DiscoveredTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HelmSpec) DeepCopyInto(out *HelmSpec) {
 	*out = *in
@@ -1031,28 +1404,276 @@ func (in *NamedProviderTemplate) DeepCopyInto(out *NamedProviderTemplate) {
 	out.CoreProviderTemplate = in.CoreProviderTemplate
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamedProviderTemplate.
-func (in *NamedProviderTemplate) DeepCopy() *NamedProviderTemplate {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamedProviderTemplate.
+func (in *NamedProviderTemplate) DeepCopy() *NamedProviderTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(NamedProviderTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodePool) DeepCopyInto(out *NodePool) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodePool.
+func (in *NodePool) DeepCopy() *NodePool {
+	if in == nil {
+		return nil
+	}
+	out := new(NodePool)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NodePool) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodePoolList) DeepCopyInto(out *NodePoolList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]NodePool, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodePoolList.
+func (in *NodePoolList) DeepCopy() *NodePoolList {
+	if in == nil {
+		return nil
+	}
+	out := new(NodePoolList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NodePoolList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodePoolSpec) DeepCopyInto(out *NodePoolSpec) {
+	*out = *in
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Taints != nil {
+		in, out := &in.Taints, &out.Taints
+		*out = make([]corev1.Taint, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.MachineTemplate != nil {
+		in, out := &in.MachineTemplate, &out.MachineTemplate
+		*out = new(apiextensionsv1.JSON)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodePoolSpec.
+func (in *NodePoolSpec) DeepCopy() *NodePoolSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NodePoolSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodePoolStatus) DeepCopyInto(out *NodePoolStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodePoolStatus.
+func (in *NodePoolStatus) DeepCopy() *NodePoolStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NodePoolStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodePoolSummary) DeepCopyInto(out *NodePoolSummary) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodePoolSummary.
+func (in *NodePoolSummary) DeepCopy() *NodePoolSummary {
+	if in == nil {
+		return nil
+	}
+	out := new(NodePoolSummary)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PoolAutoscaling) DeepCopyInto(out *PoolAutoscaling) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PoolAutoscaling.
+func (in *PoolAutoscaling) DeepCopy() *PoolAutoscaling {
+	if in == nil {
+		return nil
+	}
+	out := new(PoolAutoscaling)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PoolAutoscalingStatus) DeepCopyInto(out *PoolAutoscalingStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PoolAutoscalingStatus.
+func (in *PoolAutoscalingStatus) DeepCopy() *PoolAutoscalingStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PoolAutoscalingStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Provider) DeepCopyInto(out *Provider) {
+	*out = *in
+	in.Component.DeepCopyInto(&out.Component)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Provider.
+func (in *Provider) DeepCopy() *Provider {
+	if in == nil {
+		return nil
+	}
+	out := new(Provider)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProviderDefinition) DeepCopyInto(out *ProviderDefinition) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProviderDefinition.
+func (in *ProviderDefinition) DeepCopy() *ProviderDefinition {
+	if in == nil {
+		return nil
+	}
+	out := new(ProviderDefinition)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ProviderDefinition) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProviderDefinitionList) DeepCopyInto(out *ProviderDefinitionList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ProviderDefinition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProviderDefinitionList.
+func (in *ProviderDefinitionList) DeepCopy() *ProviderDefinitionList {
 	if in == nil {
 		return nil
 	}
-	out := new(NamedProviderTemplate)
+	out := new(ProviderDefinitionList)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ProviderDefinitionList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Provider) DeepCopyInto(out *Provider) {
+func (in *ProviderDefinitionSpec) DeepCopyInto(out *ProviderDefinitionSpec) {
 	*out = *in
-	in.Component.DeepCopyInto(&out.Component)
+	if in.ClusterGVKs != nil {
+		in, out := &in.ClusterGVKs, &out.ClusterGVKs
+		*out = make([]ClusterGVK, len(*in))
+		copy(*out, *in)
+	}
+	if in.ClusterIdentityKinds != nil {
+		in, out := &in.ClusterIdentityKinds, &out.ClusterIdentityKinds
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Provider.
-func (in *Provider) DeepCopy() *Provider {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProviderDefinitionSpec.
+func (in *ProviderDefinitionSpec) DeepCopy() *ProviderDefinitionSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(Provider)
+	out := new(ProviderDefinitionSpec)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -1294,6 +1915,26 @@ func (in *ReleaseStatus) DeepCopy() *ReleaseStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResolvedUpgrade) DeepCopyInto(out *ResolvedUpgrade) {
+	*out = *in
+	if in.AvailableUpgrades != nil {
+		in, out := &in.AvailableUpgrades, &out.AvailableUpgrades
+		*out = make([]AvailableUpgrade, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResolvedUpgrade.
+func (in *ResolvedUpgrade) DeepCopy() *ResolvedUpgrade {
+	if in == nil {
+		return nil
+	}
+	out := new(ResolvedUpgrade)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Service) DeepCopyInto(out *Service) {
 	*out = *in
@@ -1408,6 +2049,7 @@ func (in *ServiceTemplateChain) DeepCopyInto(out *ServiceTemplateChain) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceTemplateChain.
@@ -1501,6 +2143,11 @@ func (in *ServiceTemplateSpec) DeepCopyInto(out *ServiceTemplateSpec) {
 		*out = make(Providers, len(*in))
 		copy(*out, *in)
 	}
+	if in.Lifecycle != nil {
+		in, out := &in.Lifecycle, &out.Lifecycle
+		*out = new(TemplateLifecycle)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceTemplateSpec.
@@ -1537,6 +2184,11 @@ func (in *ServiceTemplateStatus) DeepCopy() *ServiceTemplateStatus {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SupportedTemplate) DeepCopyInto(out *SupportedTemplate) {
 	*out = *in
+	if in.Lifecycle != nil {
+		in, out := &in.Lifecycle, &out.Lifecycle
+		*out = new(TemplateLifecycle)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.AvailableUpgrades != nil {
 		in, out := &in.AvailableUpgrades, &out.AvailableUpgrades
 		*out = make([]AvailableUpgrade, len(*in))
@@ -1589,6 +2241,11 @@ func (in *TemplateChainSpec) DeepCopyInto(out *TemplateChainSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.UpgradeRules != nil {
+		in, out := &in.UpgradeRules, &out.UpgradeRules
+		*out = make([]UpgradeRule, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TemplateChainSpec.
@@ -1601,6 +2258,252 @@ func (in *TemplateChainSpec) DeepCopy() *TemplateChainSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TemplateChainStatus) DeepCopyInto(out *TemplateChainStatus) {
+	*out = *in
+	if in.ResolvedUpgrades != nil {
+		in, out := &in.ResolvedUpgrades, &out.ResolvedUpgrades
+		*out = make([]ResolvedUpgrade, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Graph != nil {
+		in, out := &in.Graph, &out.Graph
+		*out = make([]UpgradeGraphNode, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.UnreachableTemplates != nil {
+		in, out := &in.UnreachableTemplates, &out.UnreachableTemplates
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Warnings != nil {
+		in, out := &in.Warnings, &out.Warnings
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TemplateChainStatus.
+func (in *TemplateChainStatus) DeepCopy() *TemplateChainStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TemplateChainStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TemplateLifecycle) DeepCopyInto(out *TemplateLifecycle) {
+	*out = *in
+	if in.EndOfLife != nil {
+		in, out := &in.EndOfLife, &out.EndOfLife
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TemplateLifecycle.
+func (in *TemplateLifecycle) DeepCopy() *TemplateLifecycle {
+	if in == nil {
+		return nil
+	}
+	out := new(TemplateLifecycle)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TemplateRegistry) DeepCopyInto(out *TemplateRegistry) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TemplateRegistry.
+func (in *TemplateRegistry) DeepCopy() *TemplateRegistry {
+	if in == nil {
+		return nil
+	}
+	out := new(TemplateRegistry)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *TemplateRegistry) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TemplateRegistryList) DeepCopyInto(out *TemplateRegistryList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]TemplateRegistry, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TemplateRegistryList.
+func (in *TemplateRegistryList) DeepCopy() *TemplateRegistryList {
+	if in == nil {
+		return nil
+	}
+	out := new(TemplateRegistryList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *TemplateRegistryList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TemplateRegistrySpec) DeepCopyInto(out *TemplateRegistrySpec) {
+	*out = *in
+	if in.Interval != nil {
+		in, out := &in.Interval, &out.Interval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TemplateRegistrySpec.
+func (in *TemplateRegistrySpec) DeepCopy() *TemplateRegistrySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(TemplateRegistrySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TemplateSource) DeepCopyInto(out *TemplateSource) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TemplateSource.
+func (in *TemplateSource) DeepCopy() *TemplateSource {
+	if in == nil {
+		return nil
+	}
+	out := new(TemplateSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *TemplateSource) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TemplateSourceList) DeepCopyInto(out *TemplateSourceList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]TemplateSource, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TemplateSourceList.
+func (in *TemplateSourceList) DeepCopy() *TemplateSourceList {
+	if in == nil {
+		return nil
+	}
+	out := new(TemplateSourceList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *TemplateSourceList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TemplateSourceSpec) DeepCopyInto(out *TemplateSourceSpec) {
+	*out = *in
+	if in.Interval != nil {
+		in, out := &in.Interval, &out.Interval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TemplateSourceSpec.
+func (in *TemplateSourceSpec) DeepCopy() *TemplateSourceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(TemplateSourceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TemplateSourceStatus) DeepCopyInto(out *TemplateSourceStatus) {
+	*out = *in
+	if in.LastSyncTime != nil {
+		in, out := &in.LastSyncTime, &out.LastSyncTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Templates != nil {
+		in, out := &in.Templates, &out.Templates
+		*out = make([]DiscoveredTemplate, len(*in))
+		copy(*out, *in)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TemplateSourceStatus.
+func (in *TemplateSourceStatus) DeepCopy() *TemplateSourceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TemplateSourceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TemplateStatusCommon) DeepCopyInto(out *TemplateStatusCommon) {
 	*out = *in
@@ -1641,3 +2544,38 @@ func (in *TemplateValidationStatus) DeepCopy() *TemplateValidationStatus {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UpgradeGraphNode) DeepCopyInto(out *UpgradeGraphNode) {
+	*out = *in
+	if in.Upgrades != nil {
+		in, out := &in.Upgrades, &out.Upgrades
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpgradeGraphNode.
+func (in *UpgradeGraphNode) DeepCopy() *UpgradeGraphNode {
+	if in == nil {
+		return nil
+	}
+	out := new(UpgradeGraphNode)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UpgradeRule) DeepCopyInto(out *UpgradeRule) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpgradeRule.
+func (in *UpgradeRule) DeepCopy() *UpgradeRule {
+	if in == nil {
+		return nil
+	}
+	out := new(UpgradeRule)
+	in.DeepCopyInto(out)
+	return out
+}
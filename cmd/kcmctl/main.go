@@ -12,20 +12,21 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-package projectroot
+package main
 
 import (
-	"path/filepath"
-	"runtime"
-)
+	"fmt"
+	"os"
 
-// WARNING: This path resolution is dependent on the source file's location
-// in the project structure. Moving this file to a different directory will
-// change the resolved path. When refactoring or restructuring the project,
-// ensure to update this path resolution accordingly.
-var (
-	_, b, _, _ = runtime.Caller(0)
+	ctrl "sigs.k8s.io/controller-runtime"
 
-	// Path is root folder of this project.
-	Path = filepath.Join(filepath.Dir(b), "../..")
+	"github.com/K0rdent/kcm/internal/kcmctl"
 )
+
+func main() {
+	cmd := kcmctl.NewCommand(kcmctl.Streams{In: os.Stdin, Out: os.Stdout, ErrOut: os.Stderr})
+	if err := cmd.ExecuteContext(ctrl.SetupSignalHandler()); err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+}
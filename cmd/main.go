@@ -15,6 +15,7 @@
 package main
 
 import (
+	"context"
 	"crypto/tls"
 	"flag"
 	"fmt"
@@ -26,24 +27,37 @@ import (
 	sveltosv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
 	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
 	velerov2alpha1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v2alpha1"
+	corev1 "k8s.io/api/core/v1"
 	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	apiextv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
+	clusterapiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	expv1 "sigs.k8s.io/cluster-api/exp/api/v1beta1"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/controller-runtime/pkg/metrics/filters"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	kcmv1 "github.com/K0rdent/kcm/api/v1alpha1"
 	"github.com/K0rdent/kcm/internal/build"
+	"github.com/K0rdent/kcm/internal/config"
 	"github.com/K0rdent/kcm/internal/controller"
 	"github.com/K0rdent/kcm/internal/helm"
 	"github.com/K0rdent/kcm/internal/providers"
+	"github.com/K0rdent/kcm/internal/scope"
+	"github.com/K0rdent/kcm/internal/sharding"
 	"github.com/K0rdent/kcm/internal/telemetry"
+	"github.com/K0rdent/kcm/internal/templatechain"
+	"github.com/K0rdent/kcm/internal/tracing"
 	"github.com/K0rdent/kcm/internal/utils"
 	kcmwebhook "github.com/K0rdent/kcm/internal/webhook"
 )
@@ -69,6 +83,8 @@ func init() {
 	utilruntime.Must(sourcev1.AddToScheme(scheme))
 	utilruntime.Must(hcv2.AddToScheme(scheme))
 	utilruntime.Must(sveltosv1beta1.AddToScheme(scheme))
+	utilruntime.Must(clusterapiv1beta1.AddToScheme(scheme))
+	utilruntime.Must(expv1.AddToScheme(scheme))
 	// +kubebuilder:scaffold:scheme
 }
 
@@ -81,12 +97,24 @@ func main() {
 		defaultRegistryURL        string
 		insecureRegistry          bool
 		registryCredentialsSecret string
+		registryCertSecret        string
+		chartCacheSize            string
+		configFile                string
+		watchNamespaces           string
+		watchNamespaceSelector    string
+		shards                    int
+		shardBy                   string
 		createManagement          bool
 		createAccessManagement    bool
 		createRelease             bool
 		createTemplates           bool
 		kcmTemplatesChartName     string
 		enableTelemetry           bool
+		telemetrySinks            string
+		telemetryOTLPEndpoint     string
+		telemetryFile             string
+		tracingOTLPEndpoint       string
+		tracingSamplingRatio      float64
 		enableWebhook             bool
 		webhookPort               int
 		webhookCertDir            string
@@ -95,14 +123,28 @@ func main() {
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.BoolVar(&secureMetrics, "metrics-secure", false,
-		"If set the metrics endpoint is served securely")
+		"If set the metrics endpoint and the template chain graphs are served securely to the authorized clients, the graphs are disabled otherwise")
 	flag.BoolVar(&enableHTTP2, "enable-http2", false,
 		"If set, HTTP/2 will be enabled for the metrics and webhook servers")
 	flag.StringVar(&defaultRegistryURL, "default-registry-url", "oci://ghcr.io/k0rdent/kcm/charts",
 		"The default registry to download Helm charts from, prefix with oci:// for OCI registries.")
 	flag.StringVar(&registryCredentialsSecret, "registry-creds-secret", "",
 		"Secret containing authentication credentials for the registry.")
+	flag.StringVar(&registryCertSecret, "registry-cert-secret", "",
+		"Secret containing the CA bundle (ca.crt) and optionally the client certificate and key (tls.crt, tls.key) for the registry.")
 	flag.BoolVar(&insecureRegistry, "insecure-registry", false, "Allow connecting to an HTTP registry.")
+	flag.StringVar(&chartCacheSize, "chart-cache-size", "32Mi",
+		"The memory limit of the cache of the chart archives shared by the controllers, 0 disables the cache.")
+	flag.StringVar(&configFile, "config", "",
+		"The path to the controller manager configuration file, the changes of the requeue policy, rate limiters and HelmRelease interval are applied without a restart.")
+	flag.StringVar(&watchNamespaces, "watch-namespaces", "",
+		"Comma-separated list of namespaces to restrict the cache, controllers and webhooks to, the system namespace is always watched.")
+	flag.StringVar(&watchNamespaceSelector, "watch-namespace-selector", "",
+		"Label selector of the namespaces to restrict the cache, controllers and webhooks to, the controller manager restarts when the matching namespaces change.")
+	flag.IntVar(&shards, "shards", 0,
+		"Number of shards the ClusterDeployments are distributed among the replicas by, sharding is disabled if lower than 2.")
+	flag.StringVar(&shardBy, "shard-by", string(sharding.ModeNamespace),
+		fmt.Sprintf("Assign the ClusterDeployments to the shards by %s or %s.", sharding.ModeNamespace, sharding.ModeClusterDeployment))
 	flag.BoolVar(&createManagement, "create-management", true, "Create a Management object with default configuration upon initial installation.")
 	flag.BoolVar(&createAccessManagement, "create-access-management", true,
 		"Create an AccessManagement object upon initial installation.")
@@ -111,6 +153,16 @@ func main() {
 	flag.StringVar(&kcmTemplatesChartName, "kcm-templates-chart-name", "kcm-templates",
 		"The name of the helm chart with KCM Templates.")
 	flag.BoolVar(&enableTelemetry, "enable-telemetry", true, "Collect and send telemetry data.")
+	flag.StringVar(&telemetrySinks, "telemetry-sinks", telemetry.SinkSegment,
+		"Comma-separated list of the sinks to send telemetry data to: segment, otlp, file, configmap.")
+	flag.StringVar(&telemetryOTLPEndpoint, "telemetry-otlp-endpoint", "",
+		"The OTLP/HTTP endpoint URL of the otlp telemetry sink, e.g. http://otel-collector:4318.")
+	flag.StringVar(&telemetryFile, "telemetry-file", "/var/lib/kcm/telemetry.jsonl",
+		"The path of the file the file telemetry sink appends the events to.")
+	flag.StringVar(&tracingOTLPEndpoint, "tracing-otlp-endpoint", "",
+		"The OTLP/HTTP endpoint URL to export the traces of the reconciliations to, e.g. http://otel-collector:4318. Tracing is disabled if not set.")
+	flag.Float64Var(&tracingSamplingRatio, "tracing-sampling-ratio", 1,
+		"The ratio of the sampled traces in the range [0, 1].")
 	flag.BoolVar(&enableWebhook, "enable-webhook", true, "Enable admission webhook.")
 	flag.IntVar(&webhookPort, "webhook-port", 9443, "Admission webhook port.")
 	flag.StringVar(&webhookCertDir, "webhook-cert-dir", "/tmp/k8s-webhook-server/serving-certs/",
@@ -170,6 +222,12 @@ func main() {
 			SecureServing: secureMetrics,
 			TLSOpts:       tlsOpts,
 		},
+		Cache: cache.Options{
+			ByObject: map[client.Object]cache.ByObject{
+				// only the ConfigMaps managed by KCM are read through the cache
+				&corev1.ConfigMap{}: {Label: labels.SelectorFromSet(labels.Set{kcmv1.KCMManagedLabelKey: kcmv1.KCMManagedLabelValue})},
+			},
+		},
 		HealthProbeBindAddress: probeAddr,
 		LeaderElection:         true,
 		LeaderElectionID:       "31c555b4.k0rdent.mirantis.com",
@@ -186,6 +244,11 @@ func main() {
 		// LeaderElectionReleaseOnCancel: true,
 	}
 
+	if secureMetrics {
+		// only the authenticated clients authorized to get the paths are served
+		managerOpts.Metrics.FilterProvider = filters.WithAuthenticationAndAuthorization
+	}
+
 	if enableWebhook {
 		managerOpts.WebhookServer = webhook.NewServer(webhook.Options{
 			Port:    webhookPort,
@@ -194,19 +257,94 @@ func main() {
 		})
 	}
 
-	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), managerOpts)
+	restConfig := ctrl.GetConfigOrDie()
+	ctx := ctrl.SetupSignalHandler()
+	currentNamespace := utils.CurrentNamespace()
+
+	var watchNamespacesList []string
+	for _, ns := range strings.Split(watchNamespaces, ",") {
+		if ns = strings.TrimSpace(ns); ns != "" {
+			watchNamespacesList = append(watchNamespacesList, ns)
+		}
+	}
+
+	var watchScope *scope.Scope
+	if len(watchNamespacesList) > 0 || watchNamespaceSelector != "" {
+		selector, err := labels.Parse(watchNamespaceSelector)
+		if err != nil {
+			setupLog.Error(err, "invalid watch namespace selector", "selector", watchNamespaceSelector)
+			os.Exit(1)
+		}
+		cl, err := client.New(restConfig, client.Options{Scheme: scheme})
+		if err != nil {
+			setupLog.Error(err, "unable to create client")
+			os.Exit(1)
+		}
+		watchScope, err = scope.Resolve(ctx, cl, watchNamespacesList, selector, currentNamespace)
+		if err != nil {
+			setupLog.Error(err, "unable to resolve watched namespaces")
+			os.Exit(1)
+		}
+
+		managerOpts.Cache.DefaultNamespaces = watchScope.CacheNamespaces()
+		scope.Set(watchScope)
+		setupLog.Info("Restricting to the watched namespaces", "namespaces", watchScope.Namespaces())
+	}
+
+	mgr, err := ctrl.NewManager(restConfig, managerOpts)
 	if err != nil {
 		setupLog.Error(err, "unable to start manager")
 		os.Exit(1)
 	}
 
-	ctx := ctrl.SetupSignalHandler()
+	if watchNamespaceSelector != "" {
+		if err = mgr.Add(&scope.Watcher{
+			Reader:          mgr.GetAPIReader(),
+			Scope:           watchScope,
+			Namespaces:      watchNamespacesList,
+			SystemNamespace: currentNamespace,
+		}); err != nil {
+			setupLog.Error(err, "unable to create watched namespaces watcher")
+			os.Exit(1)
+		}
+	}
+
+	shutdownTracing, err := tracing.Setup(ctx, tracing.Config{
+		Endpoint:      tracingOTLPEndpoint,
+		SamplingRatio: tracingSamplingRatio,
+	})
+	if err != nil {
+		setupLog.Error(err, "unable to setup tracing")
+		os.Exit(1)
+	}
+
 	if err = kcmv1.SetupIndexers(ctx, mgr); err != nil {
 		setupLog.Error(err, "unable to setup indexers")
 		os.Exit(1)
 	}
 
-	currentNamespace := utils.CurrentNamespace()
+	if configFile != "" {
+		cfg, err := config.Load(configFile)
+		if err != nil {
+			setupLog.Error(err, "unable to load config", "path", configFile)
+			os.Exit(1)
+		}
+		config.Set(cfg)
+
+		if err = mgr.Add(&config.Watcher{Path: configFile}); err != nil {
+			setupLog.Error(err, "unable to create config watcher")
+			os.Exit(1)
+		}
+	}
+
+	cacheSize, err := resource.ParseQuantity(chartCacheSize)
+	if err != nil {
+		setupLog.Error(err, "invalid chart cache size", "size", chartCacheSize)
+		os.Exit(1)
+	}
+	if cacheSize.Value() > 0 {
+		helm.SetChartCache(helm.NewChartCache(cacheSize.Value()))
+	}
 
 	templateReconciler := controller.TemplateReconciler{
 		Client:          mgr.GetClient(),
@@ -215,6 +353,7 @@ func main() {
 			URL:               defaultRegistryURL,
 			RepoType:          determinedRepositoryType,
 			CredentialsSecret: registryCredentialsSecret,
+			CertSecret:        registryCertSecret,
 			Insecure:          insecureRegistry,
 		},
 	}
@@ -237,7 +376,39 @@ func main() {
 		setupLog.Error(err, "unable to create controller", "controller", "ProviderTemplate")
 		os.Exit(1)
 	}
+	var shardingManager *sharding.Manager
+	if shards > 1 {
+		identity, err := os.Hostname()
+		if err != nil {
+			setupLog.Error(err, "unable to determine sharding identity")
+			os.Exit(1)
+		}
+		shardingManager, err = sharding.NewManager(mgr.GetClient(), mgr.GetAPIReader(), sharding.Config{
+			Mode:      sharding.Mode(shardBy),
+			Namespace: currentNamespace,
+			Identity:  identity,
+			Shards:    shards,
+		})
+		if err != nil {
+			setupLog.Error(err, "unable to create sharding manager")
+			os.Exit(1)
+		}
+		if err = mgr.Add(shardingManager); err != nil {
+			setupLog.Error(err, "unable to add sharding manager")
+			os.Exit(1)
+		}
+		if err = mgr.Add(&controller.ShardedClusterDeploymentStarter{
+			Manager:         mgr,
+			Sharding:        shardingManager,
+			SystemNamespace: currentNamespace,
+		}); err != nil {
+			setupLog.Error(err, "unable to add sharded ClusterDeployment controller starter")
+			os.Exit(1)
+		}
+	}
+
 	if err = (&controller.ManagementReconciler{
+		Sharding:               shardingManager,
 		SystemNamespace:        currentNamespace,
 		CreateAccessManagement: createAccessManagement,
 	}).SetupWithManager(mgr); err != nil {
@@ -282,6 +453,7 @@ func main() {
 			URL:               defaultRegistryURL,
 			RepoType:          determinedRepositoryType,
 			CredentialsSecret: registryCredentialsSecret,
+			CertSecret:        registryCertSecret,
 			Insecure:          insecureRegistry,
 		},
 	}).SetupWithManager(mgr); err != nil {
@@ -290,6 +462,18 @@ func main() {
 	}
 
 	if enableTelemetry {
+		sinks, err := telemetry.NewSinks(ctx, telemetry.SinksConfig{
+			Sinks:           strings.Split(telemetrySinks, ","),
+			OTLPEndpoint:    telemetryOTLPEndpoint,
+			FilePath:        telemetryFile,
+			SystemNamespace: currentNamespace,
+		}, mgr.GetClient())
+		if err != nil {
+			setupLog.Error(err, "unable to create telemetry sinks")
+			os.Exit(1)
+		}
+		telemetry.SetSinks(sinks...)
+
 		if err = mgr.Add(&telemetry.Tracker{
 			Client:          mgr.GetClient(),
 			SystemNamespace: currentNamespace,
@@ -299,6 +483,12 @@ func main() {
 		}
 	}
 
+	if err = (&controller.ClusterAccessRequestReconciler{
+		Client: mgr.GetClient(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ClusterAccessRequest")
+		os.Exit(1)
+	}
 	if err = (&controller.CredentialReconciler{
 		SystemNamespace: currentNamespace,
 		Client:          mgr.GetClient(),
@@ -314,8 +504,33 @@ func main() {
 		setupLog.Error(err, "unable to create controller", "controller", "ManagementBackup")
 		os.Exit(1)
 	}
+
+	if err = (&controller.TemplateSourceReconciler{
+		Client:          mgr.GetClient(),
+		SystemNamespace: currentNamespace,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "TemplateSource")
+		os.Exit(1)
+	}
+	if err = (&controller.ProviderDefinitionReconciler{
+		Client: mgr.GetClient(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ProviderDefinition")
+		os.Exit(1)
+	}
 	// +kubebuilder:scaffold:builder
 
+	// the graphs expose the chains and the templates of all of the namespaces,
+	// hence they are only served along with the authenticated and authorized metrics
+	if secureMetrics {
+		if err := mgr.AddMetricsServerExtraHandler(templatechain.GraphHandlerPath, &templatechain.GraphHandler{Client: mgr.GetClient()}); err != nil {
+			setupLog.Error(err, "unable to set up the template chain graph handler")
+			os.Exit(1)
+		}
+	} else {
+		setupLog.Info("The template chain graph handler is disabled since the metrics are not served securely")
+	}
+
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
 		setupLog.Error(err, "unable to set up health check")
 		os.Exit(1)
@@ -337,6 +552,13 @@ func main() {
 		setupLog.Error(err, "problem running manager")
 		os.Exit(1)
 	}
+
+	if err := telemetry.Close(); err != nil {
+		setupLog.Error(err, "failed to flush telemetry data")
+	}
+	if err := shutdownTracing(context.Background()); err != nil {
+		setupLog.Error(err, "failed to flush traces")
+	}
 }
 
 func setupWebhooks(mgr ctrl.Manager, currentNamespace string) error {
@@ -384,5 +606,17 @@ func setupWebhooks(mgr ctrl.Manager, currentNamespace string) error {
 		setupLog.Error(err, "unable to create webhook", "webhook", "Release")
 		return err
 	}
+	if err := (&kcmwebhook.ProviderDefinitionValidator{}).SetupWebhookWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create webhook", "webhook", "ProviderDefinition")
+		return err
+	}
+	if err := (&kcmwebhook.ClusterAccessRequestValidator{}).SetupWebhookWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create webhook", "webhook", "ClusterAccessRequest")
+		return err
+	}
+	if err := (&kcmwebhook.NodePoolValidator{}).SetupWebhookWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create webhook", "webhook", "NodePool")
+		return err
+	}
 	return nil
 }
@@ -0,0 +1,315 @@
+// Copyright 2024
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adoption
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/release"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/yaml"
+
+	kcm "github.com/K0rdent/kcm/api/v1alpha1"
+	"github.com/K0rdent/kcm/internal/helm"
+	providersloader "github.com/K0rdent/kcm/internal/providers"
+)
+
+// InternalProvider is the infrastructure provider of the adopted clusters templates.
+const InternalProvider = providersloader.InfraPrefix + "internal"
+
+// kubeconfigKeys are the keys of the Secret data the kubeconfig is looked up by.
+var kubeconfigKeys = []string{"value", "Value", "kubeconfig"}
+
+// cniDaemonSets maps the name prefixes of the well-known CNI DaemonSets to the names of the plugins.
+// The order matters since some plugins are composed of the others.
+var cniDaemonSets = []struct {
+	prefix, name string
+}{
+	{"canal", "canal"},
+	{"calico-node", "calico"},
+	{"cilium", "cilium"},
+	{"kube-flannel", "flannel"},
+	{"weave-net", "weave"},
+	{"kube-router", "kube-router"},
+	{"antrea-agent", "antrea"},
+	{"ovnkube-node", "ovn-kubernetes"},
+}
+
+// Inspection is the result of the inspection of an adopted cluster.
+type Inspection struct {
+	// values holds the user-supplied values of the releases by their namespaced names.
+	values map[string]map[string]any
+
+	Inventory kcm.ClusterInventory
+}
+
+// IsAdoptedClusterTemplate returns true if the given ClusterTemplate
+// describes an adopted cluster.
+func IsAdoptedClusterTemplate(template *kcm.ClusterTemplate) bool {
+	return slices.Contains(template.Status.Providers, InternalProvider)
+}
+
+// RESTConfigFromSecret returns the REST config built from the kubeconfig stored in the given Secret.
+func RESTConfigFromSecret(secret *corev1.Secret) (*rest.Config, error) {
+	kubeconfig, err := KubeconfigFromSecret(secret)
+	if err != nil {
+		return nil, err
+	}
+
+	return clientcmd.RESTConfigFromKubeConfig(kubeconfig)
+}
+
+// KubeconfigFromSecret returns the kubeconfig stored in the given Secret. The kubeconfig
+// is taken from the well-known keys or the only key of the Secret the same way Sveltos does.
+func KubeconfigFromSecret(secret *corev1.Secret) ([]byte, error) {
+	var kubeconfig []byte
+	for _, key := range kubeconfigKeys {
+		if v, ok := secret.Data[key]; ok {
+			kubeconfig = v
+			break
+		}
+	}
+
+	if kubeconfig == nil && len(secret.Data) == 1 {
+		for _, v := range secret.Data {
+			kubeconfig = v
+		}
+	}
+
+	if len(kubeconfig) == 0 {
+		return nil, fmt.Errorf("no kubeconfig found in the Secret %s/%s", secret.Namespace, secret.Name)
+	}
+
+	return kubeconfig, nil
+}
+
+// Inspect connects to the cluster with the given config and discovers
+// its Kubernetes version, nodes, CNI and the installed Helm releases.
+func Inspect(ctx context.Context, cfg *rest.Config) (*Inspection, error) {
+	clientset, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create a client: %w", err)
+	}
+
+	version, err := clientset.Discovery().ServerVersion()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the server version: %w", err)
+	}
+
+	nodes, err := clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+
+	daemonSets, err := clientset.AppsV1().DaemonSets(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list DaemonSets: %w", err)
+	}
+
+	releases, err := listReleases(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list Helm releases: %w", err)
+	}
+
+	inspection := &Inspection{
+		Inventory: kcm.ClusterInventory{
+			LastInspectionTime: metav1.Now(),
+			KubernetesVersion:  version.GitVersion,
+			CNI:                DetectCNI(daemonSets.Items),
+		},
+		values: make(map[string]map[string]any, len(releases)),
+	}
+	setNodes(&inspection.Inventory, nodes.Items)
+
+	for _, r := range releases {
+		if r.Chart == nil || r.Chart.Metadata == nil {
+			continue
+		}
+
+		var status string
+		if r.Info != nil {
+			status = r.Info.Status.String()
+		}
+		inspection.Inventory.HelmReleases = append(inspection.Inventory.HelmReleases, kcm.DiscoveredHelmRelease{
+			Name:      r.Name,
+			Namespace: r.Namespace,
+			Chart:     r.Chart.Metadata.Name,
+			Version:   r.Chart.Metadata.Version,
+			Status:    status,
+		})
+		inspection.values[releaseKey(r.Namespace, r.Name)] = r.Config
+	}
+
+	slices.SortFunc(inspection.Inventory.HelmReleases, func(a, b kcm.DiscoveredHelmRelease) int {
+		return cmp.Or(cmp.Compare(a.Namespace, b.Namespace), cmp.Compare(a.Name, b.Name))
+	})
+
+	return inspection, nil
+}
+
+func listReleases(cfg *rest.Config) ([]*release.Release, error) {
+	dc, err := discovery.NewDiscoveryClientForConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	mapper := restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(dc))
+
+	actionConfig := new(action.Configuration)
+	// the empty namespace makes the storage driver look up the releases in all of the namespaces
+	if err := actionConfig.Init(helm.NewMemoryRESTClientGetter(cfg, mapper), metav1.NamespaceAll, "secret", func(string, ...any) {}); err != nil {
+		return nil, err
+	}
+
+	list := action.NewList(actionConfig)
+	list.AllNamespaces = true
+	list.StateMask = action.ListDeployed | action.ListFailed | action.ListPendingInstall | action.ListPendingUpgrade | action.ListPendingRollback
+
+	return list.Run()
+}
+
+func setNodes(inventory *kcm.ClusterInventory, nodes []corev1.Node) {
+	inventory.Nodes = int32(len(nodes)) //nolint:gosec // the number of nodes never overflows int32
+	inventory.ReadyNodes = 0
+	inventory.KubeletVersions = nil
+
+	for _, node := range nodes {
+		for _, c := range node.Status.Conditions {
+			if c.Type == corev1.NodeReady && c.Status == corev1.ConditionTrue {
+				inventory.ReadyNodes++
+				break
+			}
+		}
+
+		if v := node.Status.NodeInfo.KubeletVersion; v != "" && !slices.Contains(inventory.KubeletVersions, v) {
+			inventory.KubeletVersions = append(inventory.KubeletVersions, v)
+		}
+	}
+
+	slices.Sort(inventory.KubeletVersions)
+}
+
+// DetectCNI returns the name of the CNI plugin deployed by one of the given DaemonSets,
+// or an empty string if none of the well-known plugins has been found.
+func DetectCNI(daemonSets []appsv1.DaemonSet) string {
+	for _, cni := range cniDaemonSets {
+		for _, ds := range daemonSets {
+			if strings.HasPrefix(ds.Name, cni.prefix) {
+				return cni.name
+			}
+		}
+	}
+
+	return ""
+}
+
+// FindConflicts returns the descriptions of the services planned in the given list colliding
+// with the Helm releases found on the cluster. The releases already managed as services,
+// as reported by the managed func, are not considered conflicting.
+func FindConflicts(inventory *kcm.ClusterInventory, services []kcm.Service, managed func(namespace, name string) bool) []string {
+	var conflicts []string
+	for _, svc := range services {
+		if svc.Disable {
+			continue
+		}
+
+		namespace := serviceNamespace(svc)
+		if managed != nil && managed(namespace, svc.Name) {
+			continue
+		}
+
+		for _, r := range inventory.HelmReleases {
+			if r.Name == svc.Name && r.Namespace == namespace {
+				conflicts = append(conflicts, fmt.Sprintf("service %s would take over the release %s/%s installed from the chart %s-%s",
+					svc.Name, r.Namespace, r.Name, r.Chart, r.Version))
+				break
+			}
+		}
+	}
+
+	return conflicts
+}
+
+// ImportServices returns the services to take over the deployed Helm releases of the given inspection
+// installed from the same chart name and version as one of the given valid ServiceTemplates.
+// The releases matching one of the given planned services are left to them. The imported
+// releases are marked in the inventory with the name of the ServiceTemplate.
+func (i *Inspection) ImportServices(services []kcm.Service, templates []kcm.ServiceTemplate) ([]kcm.Service, error) {
+	var (
+		imported []kcm.Service
+		errs     error
+	)
+	for idx := range i.Inventory.HelmReleases {
+		r := &i.Inventory.HelmReleases[idx]
+		r.ImportedAs = ""
+
+		if r.Status != release.StatusDeployed.String() || slices.ContainsFunc(services, func(svc kcm.Service) bool {
+			return svc.Name == r.Name && serviceNamespace(svc) == r.Namespace
+		}) {
+			continue
+		}
+
+		tplIdx := slices.IndexFunc(templates, func(t kcm.ServiceTemplate) bool {
+			return t.Status.Valid && t.Status.ChartName == r.Chart && t.Status.ChartVersion == r.Version
+		})
+		if tplIdx < 0 {
+			continue
+		}
+
+		var values string
+		if v := i.values[releaseKey(r.Namespace, r.Name)]; len(v) > 0 {
+			b, err := yaml.Marshal(v)
+			if err != nil {
+				errs = errors.Join(errs, fmt.Errorf("failed to marshal values of the release %s/%s: %w", r.Namespace, r.Name, err))
+				continue
+			}
+			values = string(b)
+		}
+
+		r.ImportedAs = templates[tplIdx].Name
+		imported = append(imported, kcm.Service{
+			Template:  templates[tplIdx].Name,
+			Name:      r.Name,
+			Namespace: r.Namespace,
+			Values:    values,
+		})
+	}
+
+	return imported, errs
+}
+
+func serviceNamespace(svc kcm.Service) string {
+	if svc.Namespace != "" {
+		return svc.Namespace
+	}
+	return svc.Name
+}
+
+func releaseKey(namespace, name string) string {
+	return namespace + "/" + name
+}
@@ -0,0 +1,210 @@
+// Copyright 2024
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adoption
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kcm "github.com/K0rdent/kcm/api/v1alpha1"
+)
+
+const testKubeconfig = `apiVersion: v1
+kind: Config
+clusters:
+- name: adopted
+  cluster:
+    server: https://adopted.example.com:6443
+contexts:
+- name: adopted
+  context:
+    cluster: adopted
+    user: admin
+current-context: adopted
+users:
+- name: admin
+  user:
+    token: secret
+`
+
+func TestRESTConfigFromSecret(t *testing.T) {
+	tests := []struct {
+		name string
+		data map[string][]byte
+		err  string
+	}{
+		{
+			name: "well-known key",
+			data: map[string][]byte{"ca.crt": []byte("ca"), "value": []byte(testKubeconfig)},
+		},
+		{
+			name: "the only key",
+			data: map[string][]byte{"admin.conf": []byte(testKubeconfig)},
+		},
+		{
+			name: "no kubeconfig",
+			data: map[string][]byte{"ca.crt": []byte("ca"), "tls.key": []byte("key")},
+			err:  "no kubeconfig found in the Secret kcm-system/adopted-kubeconfig",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			cfg, err := RESTConfigFromSecret(&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "adopted-kubeconfig", Namespace: "kcm-system"},
+				Data:       tt.data,
+			})
+			if tt.err != "" {
+				g.Expect(err).To(MatchError(tt.err))
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(cfg.Host).To(Equal("https://adopted.example.com:6443"))
+		})
+	}
+}
+
+func TestDetectCNI(t *testing.T) {
+	daemonSets := func(names ...string) []appsv1.DaemonSet {
+		result := make([]appsv1.DaemonSet, 0, len(names))
+		for _, name := range names {
+			result = append(result, appsv1.DaemonSet{ObjectMeta: metav1.ObjectMeta{Name: name}})
+		}
+		return result
+	}
+
+	tests := []struct {
+		name       string
+		daemonSets []appsv1.DaemonSet
+		expected   string
+	}{
+		{name: "calico", daemonSets: daemonSets("kube-proxy", "calico-node"), expected: "calico"},
+		{name: "canal over calico and flannel", daemonSets: daemonSets("kube-flannel-ds", "canal", "calico-node"), expected: "canal"},
+		{name: "k0s default", daemonSets: daemonSets("konnectivity-agent", "kube-router"), expected: "kube-router"},
+		{name: "unknown", daemonSets: daemonSets("kube-proxy", "node-exporter")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			NewWithT(t).Expect(DetectCNI(tt.daemonSets)).To(Equal(tt.expected))
+		})
+	}
+}
+
+func TestSetNodes(t *testing.T) {
+	g := NewWithT(t)
+
+	node := func(ready corev1.ConditionStatus, version string) corev1.Node {
+		return corev1.Node{Status: corev1.NodeStatus{
+			Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: ready}},
+			NodeInfo:   corev1.NodeSystemInfo{KubeletVersion: version},
+		}}
+	}
+
+	inventory := &kcm.ClusterInventory{}
+	setNodes(inventory, []corev1.Node{
+		node(corev1.ConditionTrue, "v1.31.2"),
+		node(corev1.ConditionFalse, "v1.30.5"),
+		node(corev1.ConditionTrue, "v1.31.2"),
+	})
+	g.Expect(inventory.Nodes).To(Equal(int32(3)))
+	g.Expect(inventory.ReadyNodes).To(Equal(int32(2)))
+	g.Expect(inventory.KubeletVersions).To(Equal([]string{"v1.30.5", "v1.31.2"}))
+}
+
+func TestFindConflicts(t *testing.T) {
+	g := NewWithT(t)
+
+	inventory := &kcm.ClusterInventory{
+		HelmReleases: []kcm.DiscoveredHelmRelease{
+			{Name: "ingress-nginx", Namespace: "ingress-nginx", Chart: "ingress-nginx", Version: "4.11.3", Status: "deployed"},
+			{Name: "cert-manager", Namespace: "cert-manager", Chart: "cert-manager", Version: "1.16.2", Status: "deployed"},
+			{Name: "kyverno", Namespace: "kyverno", Chart: "kyverno", Version: "3.2.6", Status: "deployed"},
+		},
+	}
+	services := []kcm.Service{
+		{Template: "ingress-nginx-4-11-3", Name: "ingress-nginx"},
+		{Template: "cert-manager-1-16-2", Name: "cert-manager", Namespace: "kube-system"},
+		{Template: "kyverno-3-2-6", Name: "kyverno", Disable: true},
+	}
+
+	g.Expect(FindConflicts(inventory, services, nil)).To(Equal([]string{
+		"service ingress-nginx would take over the release ingress-nginx/ingress-nginx installed from the chart ingress-nginx-4.11.3",
+	}))
+
+	managed := func(namespace, name string) bool { return namespace == "ingress-nginx" && name == "ingress-nginx" }
+	g.Expect(FindConflicts(inventory, services, managed)).To(BeEmpty())
+}
+
+func TestImportServices(t *testing.T) {
+	g := NewWithT(t)
+
+	serviceTemplate := func(name, chart, version string, valid bool) kcm.ServiceTemplate {
+		tpl := kcm.ServiceTemplate{ObjectMeta: metav1.ObjectMeta{Name: name}}
+		tpl.Status.ChartName = chart
+		tpl.Status.ChartVersion = version
+		tpl.Status.Valid = valid
+		return tpl
+	}
+
+	inspection := &Inspection{
+		Inventory: kcm.ClusterInventory{
+			HelmReleases: []kcm.DiscoveredHelmRelease{
+				{Name: "cert-manager", Namespace: "cert-manager", Chart: "cert-manager", Version: "1.16.2", Status: "deployed"},
+				{Name: "ingress-nginx", Namespace: "ingress-nginx", Chart: "ingress-nginx", Version: "4.11.3", Status: "deployed"},
+				{Name: "kyverno", Namespace: "kyverno", Chart: "kyverno", Version: "3.2.6", Status: "deployed"},
+				{Name: "velero", Namespace: "velero", Chart: "velero", Version: "8.1.0", Status: "failed"},
+				{Name: "dex", Namespace: "dex", Chart: "dex", Version: "0.19.1", Status: "deployed"},
+			},
+		},
+		values: map[string]map[string]any{
+			"cert-manager/cert-manager": {"crds": map[string]any{"enabled": true}},
+		},
+	}
+	services := []kcm.Service{
+		{Template: "ingress-nginx-4-11-3", Name: "ingress-nginx"},
+	}
+	templates := []kcm.ServiceTemplate{
+		serviceTemplate("cert-manager-1-16-2", "cert-manager", "1.16.2", true),
+		serviceTemplate("ingress-nginx-4-11-3", "ingress-nginx", "4.11.3", true),
+		serviceTemplate("kyverno-3-2-6", "kyverno", "3.2.6", false),
+		serviceTemplate("velero-8-1-0", "velero", "8.1.0", true),
+		serviceTemplate("dex-0-19-0", "dex", "0.19.0", true),
+	}
+
+	imported, err := inspection.ImportServices(services, templates)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(imported).To(Equal([]kcm.Service{
+		{Template: "cert-manager-1-16-2", Name: "cert-manager", Namespace: "cert-manager", Values: "crds:\n  enabled: true\n"},
+	}))
+
+	importedAs := make(map[string]string)
+	for _, r := range inspection.Inventory.HelmReleases {
+		importedAs[r.Name] = r.ImportedAs
+	}
+	g.Expect(importedAs).To(Equal(map[string]string{
+		"cert-manager":  "cert-manager-1-16-2",
+		"ingress-nginx": "",
+		"kyverno":       "",
+		"velero":        "",
+		"dex":           "",
+	}))
+}
@@ -0,0 +1,308 @@
+// Copyright 2024
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package airgap exports the charts of a KCM release into a bundle
+// and imports the bundle into the registry of a disconnected site.
+package airgap
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/distribution/reference"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/engine"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/yaml"
+
+	kcm "github.com/K0rdent/kcm/api/v1alpha1"
+)
+
+const (
+	// ManifestFile is the name of the file describing the content of the bundle.
+	ManifestFile = "bundle.yaml"
+	// ImagesFile is the name of the file listing the container images referenced by the charts of the bundle.
+	ImagesFile = "images.txt"
+
+	templatesDir = "files/templates/"
+)
+
+// Manifest describes the content of a bundle.
+type Manifest struct {
+	// Version is the version of the KCM release.
+	Version string `json:"version"`
+	// Charts are the charts of the bundle.
+	Charts []Chart `json:"charts"`
+	// Images are the container images referenced by the charts, they are not a part
+	// of the bundle and are to be mirrored with the tools like skopeo or crane.
+	Images []string `json:"images,omitempty"`
+	// Warnings are the issues met while collecting the images.
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// Chart is a chart of a bundle.
+type Chart struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// ExportOptions are the options of the bundle export.
+type ExportOptions struct {
+	// Source is the registry to fetch the charts from.
+	Source Registry
+	// TemplatesChart is the name of the chart with the KCM templates.
+	TemplatesChart string
+	// Version is the version of the KCM release.
+	Version string
+	// Dir is the directory to write the bundle to.
+	Dir string
+}
+
+// Export writes the bundle with the templates chart of the release and all of the charts
+// of the templates it contains. The container images the charts refer to with
+// the default values are listed in the manifest of the bundle.
+func Export(ctx context.Context, opts ExportOptions) (*Manifest, error) {
+	l := log.FromContext(ctx)
+
+	out, err := newLayout(opts.Dir)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := &Manifest{Version: opts.Version}
+	images := make(map[string]struct{})
+
+	templatesChart, err := exportChart(ctx, out, &opts.Source, opts.TemplatesChart, opts.Version)
+	if err != nil {
+		return nil, err
+	}
+	manifest.Charts = append(manifest.Charts, Chart{Name: opts.TemplatesChart, Version: opts.Version})
+
+	charts, err := templateCharts(templatesChart)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range charts {
+		l.Info("Exporting chart", "chart", c.Name, "version", c.Version)
+		ch, err := exportChart(ctx, out, &opts.Source, c.Name, c.Version)
+		if err != nil {
+			return nil, err
+		}
+		manifest.Charts = append(manifest.Charts, c)
+
+		chartImages, err := collectImages(ch)
+		if err != nil {
+			manifest.Warnings = append(manifest.Warnings, fmt.Sprintf("images of chart %s:%s are not collected: %v", c.Name, c.Version, err))
+			continue
+		}
+		for _, image := range chartImages {
+			images[image] = struct{}{}
+		}
+	}
+	manifest.Images = slices.Sorted(maps.Keys(images))
+
+	if err := out.close(); err != nil {
+		return nil, fmt.Errorf("failed to write bundle index: %w", err)
+	}
+	if err := writeManifest(opts.Dir, manifest); err != nil {
+		return nil, err
+	}
+
+	return manifest, nil
+}
+
+// Import pushes the charts of the bundle in the given directory to the OCI registry.
+func Import(ctx context.Context, dir string, target Registry) (*Manifest, error) {
+	l := log.FromContext(ctx)
+
+	manifest, err := ReadManifest(dir)
+	if err != nil {
+		return nil, err
+	}
+	in, err := openLayout(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, desc := range in.index.Manifests {
+		name, version := desc.Annotations[chartNameAnnotation], desc.Annotations[chartVersionAnnotation]
+		if name == "" || version == "" {
+			continue
+		}
+
+		l.Info("Importing chart", "chart", name, "version", version)
+		data, err := in.readChart(desc)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read chart %s:%s: %w", name, version, err)
+		}
+		if err := target.pushChart(data, name, version); err != nil {
+			return nil, err
+		}
+	}
+
+	return manifest, nil
+}
+
+// ReadManifest reads the manifest of the bundle in the given directory.
+func ReadManifest(dir string) (*Manifest, error) {
+	data, err := os.ReadFile(filepath.Join(dir, ManifestFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bundle manifest: %w", err)
+	}
+	manifest := &Manifest{}
+	if err := yaml.Unmarshal(data, manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse bundle manifest: %w", err)
+	}
+	return manifest, nil
+}
+
+func writeManifest(dir string, manifest *Manifest) error {
+	data, err := yaml.Marshal(manifest)
+	if err != nil {
+		return fmt.Errorf("failed to marshal bundle manifest: %w", err)
+	}
+
+	var images bytes.Buffer
+	for _, image := range manifest.Images {
+		images.WriteString(image + "\n")
+	}
+
+	return errors.Join(
+		os.WriteFile(filepath.Join(dir, ManifestFile), data, 0o644),
+		os.WriteFile(filepath.Join(dir, ImagesFile), images.Bytes(), 0o644),
+	)
+}
+
+// exportChart fetches the chart from the source registry and stores it in the bundle.
+func exportChart(ctx context.Context, out *layout, source *Registry, name, version string) (*chart.Chart, error) {
+	data, err := source.fetchChart(ctx, name, version)
+	if err != nil {
+		return nil, err
+	}
+	ch, err := loader.LoadArchive(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load chart %s:%s: %w", name, version, err)
+	}
+	if err := out.addChart(data, ch.Metadata); err != nil {
+		return nil, err
+	}
+	return ch, nil
+}
+
+// templateCharts returns the charts of the templates contained in the templates chart.
+func templateCharts(templatesChart *chart.Chart) ([]Chart, error) {
+	seen := make(map[Chart]struct{})
+	var charts []Chart
+	for _, f := range templatesChart.Files {
+		if !strings.HasPrefix(f.Name, templatesDir) || filepath.Ext(f.Name) != ".yaml" {
+			continue
+		}
+
+		var template struct {
+			Kind string `json:"kind"`
+			Spec struct {
+				Helm kcm.HelmSpec `json:"helm"`
+			} `json:"spec"`
+		}
+		if err := yaml.Unmarshal(f.Data, &template); err != nil {
+			return nil, fmt.Errorf("failed to parse %s of the templates chart: %w", f.Name, err)
+		}
+		switch template.Kind {
+		case kcm.ProviderTemplateKind, kcm.ClusterTemplateKind, kcm.ServiceTemplateKind:
+		default:
+			continue
+		}
+
+		chartSpec := template.Spec.Helm.ChartSpec
+		if chartSpec == nil {
+			continue
+		}
+		c := Chart{Name: chartSpec.Chart, Version: chartSpec.Version}
+		if _, ok := seen[c]; ok {
+			continue
+		}
+		seen[c] = struct{}{}
+		charts = append(charts, c)
+	}
+
+	slices.SortFunc(charts, func(a, b Chart) int {
+		return strings.Compare(a.Name+":"+a.Version, b.Name+":"+b.Version)
+	})
+	return charts, nil
+}
+
+// collectImages renders the chart with the default values and returns
+// the container images referenced by the rendered manifests.
+func collectImages(ch *chart.Chart) ([]string, error) {
+	if err := chartutil.ProcessDependenciesWithMerge(ch, ch.Values); err != nil {
+		return nil, err
+	}
+	values, err := chartutil.ToRenderValues(ch, ch.Values, chartutil.ReleaseOptions{
+		Name:      ch.Name(),
+		Namespace: "default",
+		IsInstall: true,
+	}, chartutil.DefaultCapabilities)
+	if err != nil {
+		return nil, err
+	}
+	rendered, err := engine.Render(ch, values)
+	if err != nil {
+		return nil, err
+	}
+
+	images := make(map[string]struct{})
+	for name, content := range rendered {
+		if filepath.Ext(name) != ".yaml" && filepath.Ext(name) != ".yml" {
+			continue
+		}
+		for _, doc := range strings.Split(content, "\n---") {
+			var obj any
+			if err := yaml.Unmarshal([]byte(doc), &obj); err != nil {
+				continue
+			}
+			findImages(obj, images)
+		}
+	}
+
+	return slices.Sorted(maps.Keys(images)), nil
+}
+
+// findImages adds the values of the "image" keys which are valid image references.
+func findImages(obj any, images map[string]struct{}) {
+	switch o := obj.(type) {
+	case map[string]any:
+		for k, v := range o {
+			if s, ok := v.(string); ok && k == "image" {
+				if ref, err := reference.ParseNormalizedNamed(s); err == nil {
+					images[reference.TagNameOnly(ref).String()] = struct{}{}
+				}
+				continue
+			}
+			findImages(v, images)
+		}
+	case []any:
+		for _, v := range o {
+			findImages(v, images)
+		}
+	}
+}
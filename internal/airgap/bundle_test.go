@@ -0,0 +1,138 @@
+// Copyright 2024
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package airgap
+
+import (
+	"bytes"
+	"context"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/chartutil"
+)
+
+func testRegistryURL(server *httptest.Server) Registry {
+	return Registry{URL: "oci://" + strings.TrimPrefix(server.URL, "http://") + "/charts", PlainHTTP: true}
+}
+
+func pushTestChart(t *testing.T, r Registry, ch *chart.Chart) {
+	t.Helper()
+
+	path, err := chartutil.Save(ch, t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.pushChart(data, ch.Name(), ch.Metadata.Version); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestExportImport(t *testing.T) {
+	g := NewWithT(t)
+
+	upstream := httptest.NewServer(newTestRegistry())
+	defer upstream.Close()
+	source := testRegistryURL(upstream)
+
+	pushTestChart(t, source, &chart.Chart{
+		Metadata: &chart.Metadata{APIVersion: chart.APIVersionV2, Name: "kcm-templates", Version: "0.1.0"},
+		Files: []*chart.File{
+			{Name: "files/release.yaml", Data: []byte("apiVersion: k0rdent.mirantis.com/v1alpha1\nkind: Release\n")},
+			{Name: "files/templates/ingress-nginx-4-11-0.yaml", Data: []byte(`apiVersion: k0rdent.mirantis.com/v1alpha1
+kind: ServiceTemplate
+metadata:
+  name: ingress-nginx-4-11-0
+spec:
+  helm:
+    chartSpec:
+      chart: ingress-nginx
+      version: 4.11.0
+`)},
+			{Name: "files/templates/cluster-api-0-1-0.yaml", Data: []byte(`apiVersion: k0rdent.mirantis.com/v1alpha1
+kind: ProviderTemplate
+metadata:
+  name: cluster-api-0-1-0
+spec:
+  helm:
+    chartSpec:
+      chart: cluster-api
+      version: 0.1.0
+`)},
+		},
+	})
+	pushTestChart(t, source, &chart.Chart{
+		Metadata: &chart.Metadata{APIVersion: chart.APIVersionV2, Name: "ingress-nginx", Version: "4.11.0"},
+		Raw:      []*chart.File{{Name: chartutil.ValuesfileName, Data: []byte("image: registry.k8s.io/ingress-nginx/controller:v1.11.0\n")}},
+		Templates: []*chart.File{{Name: "templates/deployment.yaml", Data: []byte(`apiVersion: apps/v1
+kind: Deployment
+spec:
+  template:
+    spec:
+      containers:
+      - name: controller
+        image: {{ .Values.image }}
+      - name: sidecar
+        image: busybox
+`)}},
+	})
+	pushTestChart(t, source, &chart.Chart{
+		Metadata:  &chart.Metadata{APIVersion: chart.APIVersionV2, Name: "cluster-api", Version: "0.1.0"},
+		Templates: []*chart.File{{Name: "templates/broken.yaml", Data: []byte(`{{ required "value is required" .Values.missing }}`)}},
+	})
+
+	dir := t.TempDir()
+	manifest, err := Export(context.Background(), ExportOptions{
+		Source:         source,
+		TemplatesChart: "kcm-templates",
+		Version:        "0.1.0",
+		Dir:            dir,
+	})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(manifest.Charts).To(Equal([]Chart{
+		{Name: "kcm-templates", Version: "0.1.0"},
+		{Name: "cluster-api", Version: "0.1.0"},
+		{Name: "ingress-nginx", Version: "4.11.0"},
+	}))
+	g.Expect(manifest.Images).To(Equal([]string{"docker.io/library/busybox:latest", "registry.k8s.io/ingress-nginx/controller:v1.11.0"}))
+	g.Expect(manifest.Warnings).To(ConsistOf(ContainSubstring("images of chart cluster-api:0.1.0 are not collected")))
+	g.Expect(filepath.Join(dir, "oci-layout")).To(BeARegularFile())
+	g.Expect(os.ReadFile(filepath.Join(dir, ImagesFile))).To(BeEquivalentTo("docker.io/library/busybox:latest\nregistry.k8s.io/ingress-nginx/controller:v1.11.0\n"))
+
+	mirror := httptest.NewServer(newTestRegistry())
+	defer mirror.Close()
+	target := testRegistryURL(mirror)
+
+	imported, err := Import(context.Background(), dir, target)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(imported).To(Equal(manifest))
+
+	for _, c := range manifest.Charts {
+		data, err := target.fetchChart(context.Background(), c.Name, c.Version)
+		g.Expect(err).NotTo(HaveOccurred())
+		ch, err := loader.LoadArchive(bytes.NewReader(data))
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(ch.Metadata.Version).To(Equal(c.Version))
+	}
+}
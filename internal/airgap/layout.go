@@ -0,0 +1,169 @@
+// Copyright 2024
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package airgap
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	godigest "github.com/opencontainers/go-digest"
+	specs "github.com/opencontainers/image-spec/specs-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/registry"
+)
+
+// The annotations of the chart manifests in the index of the bundle.
+const (
+	chartNameAnnotation    = "k0rdent.mirantis.com/chart-name"
+	chartVersionAnnotation = "k0rdent.mirantis.com/chart-version"
+)
+
+// layout is the OCI image layout directory storing the charts
+// the same way Helm stores them in OCI registries.
+type layout struct {
+	dir   string
+	index ocispec.Index
+}
+
+func newLayout(dir string) (*layout, error) {
+	if err := os.MkdirAll(filepath.Join(dir, ocispec.ImageBlobsDir, godigest.Canonical.String()), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create bundle directory %s: %w", dir, err)
+	}
+
+	return &layout{
+		dir: dir,
+		index: ocispec.Index{
+			Versioned: specs.Versioned{SchemaVersion: 2},
+			MediaType: ocispec.MediaTypeImageIndex,
+		},
+	}, nil
+}
+
+// openLayout reads the index of the OCI image layout in the given directory.
+func openLayout(dir string) (*layout, error) {
+	l := &layout{dir: dir}
+	data, err := os.ReadFile(filepath.Join(dir, ocispec.ImageIndexFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the bundle index: %w", err)
+	}
+	if err := json.Unmarshal(data, &l.index); err != nil {
+		return nil, fmt.Errorf("failed to parse the bundle index: %w", err)
+	}
+	return l, nil
+}
+
+// addChart stores the chart archive with the given metadata.
+func (l *layout) addChart(data []byte, metadata *chart.Metadata) error {
+	config, err := json.Marshal(metadata)
+	if err != nil {
+		return fmt.Errorf("failed to marshal chart %s metadata: %w", metadata.Name, err)
+	}
+	configDesc, err := l.writeBlob(registry.ConfigMediaType, config)
+	if err != nil {
+		return err
+	}
+	layerDesc, err := l.writeBlob(registry.ChartLayerMediaType, data)
+	if err != nil {
+		return err
+	}
+
+	manifest, err := json.Marshal(ocispec.Manifest{
+		Versioned: specs.Versioned{SchemaVersion: 2},
+		MediaType: ocispec.MediaTypeImageManifest,
+		Config:    configDesc,
+		Layers:    []ocispec.Descriptor{layerDesc},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal chart %s manifest: %w", metadata.Name, err)
+	}
+	manifestDesc, err := l.writeBlob(ocispec.MediaTypeImageManifest, manifest)
+	if err != nil {
+		return err
+	}
+
+	manifestDesc.Annotations = map[string]string{
+		ocispec.AnnotationRefName: metadata.Name + ":" + metadata.Version,
+		chartNameAnnotation:       metadata.Name,
+		chartVersionAnnotation:    metadata.Version,
+	}
+	l.index.Manifests = append(l.index.Manifests, manifestDesc)
+	return nil
+}
+
+// readChart returns the chart archive stored with the given manifest descriptor.
+func (l *layout) readChart(desc ocispec.Descriptor) ([]byte, error) {
+	data, err := l.readBlob(desc)
+	if err != nil {
+		return nil, err
+	}
+	manifest := ocispec.Manifest{}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest %s: %w", desc.Digest, err)
+	}
+
+	for _, layer := range manifest.Layers {
+		if layer.MediaType == registry.ChartLayerMediaType {
+			return l.readBlob(layer)
+		}
+	}
+	return nil, fmt.Errorf("manifest %s has no chart layer", desc.Digest)
+}
+
+// close writes the index and the layout marker file.
+func (l *layout) close() error {
+	marker, err := json.Marshal(ocispec.ImageLayout{Version: ocispec.ImageLayoutVersion})
+	if err != nil {
+		return err
+	}
+	index, err := json.MarshalIndent(l.index, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return errors.Join(
+		os.WriteFile(filepath.Join(l.dir, ocispec.ImageLayoutFile), marker, 0o644),
+		os.WriteFile(filepath.Join(l.dir, ocispec.ImageIndexFile), index, 0o644),
+	)
+}
+
+func (l *layout) writeBlob(mediaType string, data []byte) (ocispec.Descriptor, error) {
+	digest := godigest.FromBytes(data)
+	if err := os.WriteFile(l.blobPath(digest), data, 0o644); err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("failed to write blob %s: %w", digest, err)
+	}
+	return ocispec.Descriptor{MediaType: mediaType, Digest: digest, Size: int64(len(data))}, nil
+}
+
+func (l *layout) readBlob(desc ocispec.Descriptor) ([]byte, error) {
+	if err := desc.Digest.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid digest %s: %w", desc.Digest, err)
+	}
+	data, err := os.ReadFile(l.blobPath(desc.Digest))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read blob %s: %w", desc.Digest, err)
+	}
+	if godigest.FromBytes(data) != desc.Digest {
+		return nil, fmt.Errorf("blob %s is corrupted", desc.Digest)
+	}
+	return data, nil
+}
+
+func (l *layout) blobPath(digest godigest.Digest) string {
+	return filepath.Join(l.dir, ocispec.ImageBlobsDir, digest.Algorithm().String(), digest.Encoded())
+}
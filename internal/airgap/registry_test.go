@@ -0,0 +1,132 @@
+// Copyright 2024
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package airgap
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	godigest "github.com/opencontainers/go-digest"
+)
+
+// testRegistry is an in-memory stand-in of an OCI distribution registry
+// implementing just enough of the API to push and pull the charts.
+type testRegistry struct {
+	mu        sync.Mutex
+	blobs     map[godigest.Digest][]byte
+	manifests map[string]godigest.Digest // repository:reference -> digest
+	types     map[godigest.Digest]string
+	uploads   int
+}
+
+func newTestRegistry() *testRegistry {
+	return &testRegistry{
+		blobs:     make(map[godigest.Digest][]byte),
+		manifests: make(map[string]godigest.Digest),
+		types:     make(map[godigest.Digest]string),
+	}
+}
+
+func (r *testRegistry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	path := strings.TrimPrefix(req.URL.Path, "/v2/")
+	switch {
+	case path == "" || path == "/":
+		w.WriteHeader(http.StatusOK)
+	case strings.Contains(path, "/manifests/"):
+		repo, ref, _ := strings.Cut(path, "/manifests/")
+		r.serveManifest(w, req, repo, ref)
+	case strings.Contains(path, "/blobs/uploads/"):
+		repo, _, _ := strings.Cut(path, "/blobs/uploads/")
+		r.serveUpload(w, req, repo)
+	case strings.Contains(path, "/blobs/"):
+		_, digest, _ := strings.Cut(path, "/blobs/")
+		r.serveBlob(w, req, godigest.Digest(digest))
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (r *testRegistry) serveManifest(w http.ResponseWriter, req *http.Request, repo, ref string) {
+	switch req.Method {
+	case http.MethodPut:
+		data, _ := io.ReadAll(req.Body)
+		digest := godigest.FromBytes(data)
+		r.blobs[digest] = data
+		r.types[digest] = req.Header.Get("Content-Type")
+		r.manifests[repo+":"+ref] = digest
+		r.manifests[repo+":"+digest.String()] = digest
+		w.Header().Set("Docker-Content-Digest", digest.String())
+		w.WriteHeader(http.StatusCreated)
+	case http.MethodGet, http.MethodHead:
+		digest, ok := r.manifests[repo+":"+ref]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		data := r.blobs[digest]
+		w.Header().Set("Content-Type", r.types[digest])
+		w.Header().Set("Docker-Content-Digest", digest.String())
+		w.Header().Set("Content-Length", fmt.Sprint(len(data)))
+		w.WriteHeader(http.StatusOK)
+		if req.Method == http.MethodGet {
+			_, _ = w.Write(data)
+		}
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (r *testRegistry) serveUpload(w http.ResponseWriter, req *http.Request, repo string) {
+	switch req.Method {
+	case http.MethodPost:
+		r.uploads++
+		w.Header().Set("Location", fmt.Sprintf("/v2/%s/blobs/uploads/%d", repo, r.uploads))
+		w.WriteHeader(http.StatusAccepted)
+	case http.MethodPut:
+		data, _ := io.ReadAll(req.Body)
+		digest := godigest.Digest(req.URL.Query().Get("digest"))
+		if godigest.FromBytes(data) != digest {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		r.blobs[digest] = data
+		w.Header().Set("Location", fmt.Sprintf("/v2/%s/blobs/%s", repo, digest))
+		w.Header().Set("Docker-Content-Digest", digest.String())
+		w.WriteHeader(http.StatusCreated)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (r *testRegistry) serveBlob(w http.ResponseWriter, req *http.Request, digest godigest.Digest) {
+	data, ok := r.blobs[digest]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Docker-Content-Digest", digest.String())
+	w.Header().Set("Content-Length", fmt.Sprint(len(data)))
+	w.WriteHeader(http.StatusOK)
+	if req.Method == http.MethodGet {
+		_, _ = w.Write(data)
+	}
+}
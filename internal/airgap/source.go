@@ -0,0 +1,164 @@
+// Copyright 2024
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package airgap
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"helm.sh/helm/v3/pkg/registry"
+	"helm.sh/helm/v3/pkg/repo"
+	"sigs.k8s.io/yaml"
+
+	"github.com/K0rdent/kcm/internal/utils"
+)
+
+// Registry is a chart registry the charts are fetched from or pushed to.
+type Registry struct {
+	// URL is the URL of the registry, prefixed with oci:// for OCI registries.
+	URL      string
+	Username string
+	Password string
+	// PlainHTTP makes the OCI registry to be accessed over HTTP.
+	PlainHTTP bool
+}
+
+func (r *Registry) isOCI() (bool, error) {
+	repoType, err := utils.DetermineDefaultRepositoryType(r.URL)
+	if err != nil {
+		return false, err
+	}
+	return repoType == utils.RegistryTypeOCI, nil
+}
+
+func (r *Registry) client() (*registry.Client, error) {
+	opts := []registry.ClientOption{registry.ClientOptWriter(io.Discard)}
+	if r.PlainHTTP {
+		opts = append(opts, registry.ClientOptPlainHTTP())
+	}
+	if r.Username != "" || r.Password != "" {
+		opts = append(opts, registry.ClientOptBasicAuth(r.Username, r.Password))
+	}
+
+	c, err := registry.NewClient(opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create registry client: %w", err)
+	}
+	return c, nil
+}
+
+// chartRef returns the OCI reference of the chart with the given name and version in the registry.
+func (r *Registry) chartRef(name, version string) string {
+	return strings.TrimSuffix(strings.TrimPrefix(r.URL, "oci://"), "/") + "/" + name + ":" + version
+}
+
+// fetchChart downloads the archive of the chart with the given name and version.
+func (r *Registry) fetchChart(ctx context.Context, name, version string) ([]byte, error) {
+	oci, err := r.isOCI()
+	if err != nil {
+		return nil, err
+	}
+	if !oci {
+		return r.fetchIndexChart(ctx, name, version)
+	}
+
+	c, err := r.client()
+	if err != nil {
+		return nil, err
+	}
+	result, err := c.Pull(r.chartRef(name, version), registry.PullOptWithChart(true))
+	if err != nil {
+		return nil, fmt.Errorf("failed to pull chart %s: %w", r.chartRef(name, version), err)
+	}
+	return result.Chart.Data, nil
+}
+
+func (r *Registry) fetchIndexChart(ctx context.Context, name, version string) ([]byte, error) {
+	base, err := url.Parse(strings.TrimSuffix(r.URL, "/") + "/")
+	if err != nil {
+		return nil, fmt.Errorf("invalid registry URL %s: %w", r.URL, err)
+	}
+
+	data, err := r.get(ctx, base.JoinPath("index.yaml").String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to download the index of %s: %w", r.URL, err)
+	}
+	index := new(repo.IndexFile)
+	if err := yaml.Unmarshal(data, index); err != nil {
+		return nil, fmt.Errorf("failed to parse the index of %s: %w", r.URL, err)
+	}
+
+	entry, err := index.Get(name, version)
+	if err != nil {
+		return nil, fmt.Errorf("chart %s:%s is not found in %s: %w", name, version, r.URL, err)
+	}
+	if len(entry.URLs) == 0 {
+		return nil, fmt.Errorf("chart %s:%s in %s has no URLs", name, version, r.URL)
+	}
+	chartURL, err := base.Parse(entry.URLs[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL of the chart %s:%s: %w", name, version, err)
+	}
+
+	data, err = r.get(ctx, chartURL.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to download chart %s:%s: %w", name, version, err)
+	}
+	return data, nil
+}
+
+func (r *Registry) get(ctx context.Context, u string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	if r.Username != "" || r.Password != "" {
+		req.SetBasicAuth(r.Username, r.Password)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("request failed: %s", resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// pushChart uploads the chart archive to the OCI registry.
+func (r *Registry) pushChart(data []byte, name, version string) error {
+	oci, err := r.isOCI()
+	if err != nil {
+		return err
+	}
+	if !oci {
+		return fmt.Errorf("charts can only be pushed to OCI registries, got %s", r.URL)
+	}
+
+	c, err := r.client()
+	if err != nil {
+		return err
+	}
+	if _, err := c.Push(data, r.chartRef(name, version)); err != nil {
+		return fmt.Errorf("failed to push chart %s: %w", r.chartRef(name, version), err)
+	}
+	return nil
+}
@@ -0,0 +1,225 @@
+// Copyright 2024
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clusteraccess
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kcm "github.com/K0rdent/kcm/api/v1alpha1"
+	"github.com/K0rdent/kcm/internal/adoption"
+	"github.com/K0rdent/kcm/internal/config"
+)
+
+const (
+	// ServiceAccountNamespace is the namespace in the cluster
+	// the ServiceAccounts of the granted accesses are created in.
+	ServiceAccountNamespace = metav1.NamespaceSystem
+	// MinTTL is the minimum lifetime of the access, the API server
+	// rejects the TokenRequests with the shorter expiration.
+	MinTTL = 10 * time.Minute
+)
+
+// Access describes the access to grant in the cluster.
+type Access struct {
+	// Name is the name of the ServiceAccount and its binding.
+	Name string
+	// ClusterName is the name of the cluster in the issued kubeconfig.
+	ClusterName string
+	// ClusterRole is the ClusterRole to bind.
+	ClusterRole string
+	// Namespace scopes the binding, the ClusterRole is bound cluster-wide if empty.
+	Namespace string
+	// TTL is the requested lifetime of the issued token.
+	TTL time.Duration
+}
+
+// CheckLimits returns an error if the access exceeds the given limits.
+func CheckLimits(access Access, limits config.ClusterAccess) error {
+	var errs error
+
+	if !slices.Contains(limits.AllowedClusterRoles, access.ClusterRole) {
+		errs = errors.Join(errs, fmt.Errorf("the ClusterRole %s is not allowed, the allowed ClusterRoles are: %s",
+			access.ClusterRole, strings.Join(limits.AllowedClusterRoles, ", ")))
+	}
+	if access.Namespace == "" && !limits.AllowClusterWide {
+		errs = errors.Join(errs, errors.New("the namespace must be set, the ClusterRoles are not allowed to be granted cluster-wide"))
+	}
+	if access.TTL < MinTTL {
+		errs = errors.Join(errs, fmt.Errorf("the TTL %s is less than the minimum of %s", access.TTL, MinTTL))
+	}
+	if access.TTL > limits.MaxTTL.Duration {
+		errs = errors.Join(errs, fmt.Errorf("the TTL %s exceeds the maximum of %s", access.TTL, limits.MaxTTL.Duration))
+	}
+
+	return errs
+}
+
+// ServiceAccountName returns the name of the ServiceAccount created in the cluster
+// for the given ClusterAccessRequest.
+func ServiceAccountName(req *kcm.ClusterAccessRequest) string {
+	return "kcm-access-" + req.Namespace + "-" + req.Name
+}
+
+// ClusterRESTConfig returns the admin REST config of the cluster of the given ClusterDeployment.
+func ClusterRESTConfig(ctx context.Context, cl client.Client, cd *kcm.ClusterDeployment) (*rest.Config, error) {
+	kubeconfig, err := ClusterKubeconfig(ctx, cl, cd)
+	if err != nil {
+		return nil, err
+	}
+
+	return clientcmd.RESTConfigFromKubeConfig(kubeconfig)
+}
+
+// ClusterKubeconfig returns the admin kubeconfig of the cluster of the given ClusterDeployment.
+// The kubeconfig of an adopted cluster is taken from its Credential, the one of the other
+// clusters is taken from the <name>-kubeconfig Secret created by Cluster API.
+func ClusterKubeconfig(ctx context.Context, cl client.Client, cd *kcm.ClusterDeployment) ([]byte, error) {
+	template := &kcm.ClusterTemplate{}
+	if err := cl.Get(ctx, client.ObjectKey{Namespace: cd.Namespace, Name: cd.Spec.Template}, template); err != nil {
+		return nil, fmt.Errorf("failed to get ClusterTemplate %s/%s: %w", cd.Namespace, cd.Spec.Template, err)
+	}
+
+	secretRef := client.ObjectKey{Namespace: cd.Namespace, Name: cd.Name + "-kubeconfig"}
+	if adoption.IsAdoptedClusterTemplate(template) {
+		cred := &kcm.Credential{}
+		if err := cl.Get(ctx, client.ObjectKey{Namespace: cd.Namespace, Name: cd.Spec.Credential}, cred); err != nil {
+			return nil, fmt.Errorf("failed to get Credential %s/%s: %w", cd.Namespace, cd.Spec.Credential, err)
+		}
+		if cred.Spec.IdentityRef == nil {
+			return nil, fmt.Errorf("the Credential %s/%s does not reference the kubeconfig Secret", cred.Namespace, cred.Name)
+		}
+		secretRef = client.ObjectKey{Namespace: cmp.Or(cred.Spec.IdentityRef.Namespace, cd.Namespace), Name: cred.Spec.IdentityRef.Name}
+	}
+
+	secret := &corev1.Secret{}
+	if err := cl.Get(ctx, secretRef, secret); err != nil {
+		return nil, fmt.Errorf("failed to get the kubeconfig Secret %s: %w", secretRef, err)
+	}
+
+	return adoption.KubeconfigFromSecret(secret)
+}
+
+// Grant creates the ServiceAccount bound to the ClusterRole in the cluster and
+// returns the kubeconfig with the ServiceAccount token along with its expiration time.
+// The server of the kubeconfig is taken from the given admin config.
+func Grant(ctx context.Context, clientset kubernetes.Interface, adminConfig *rest.Config, access Access) ([]byte, time.Time, error) {
+	labels := map[string]string{kcm.KCMManagedLabelKey: kcm.KCMManagedLabelValue}
+
+	sa := &corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{Name: access.Name, Namespace: ServiceAccountNamespace, Labels: labels},
+	}
+	if _, err := clientset.CoreV1().ServiceAccounts(ServiceAccountNamespace).Create(ctx, sa, metav1.CreateOptions{}); err != nil && !apierrors.IsAlreadyExists(err) {
+		return nil, time.Time{}, fmt.Errorf("failed to create ServiceAccount %s/%s: %w", ServiceAccountNamespace, access.Name, err)
+	}
+
+	roleRef := rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: access.ClusterRole}
+	subjects := []rbacv1.Subject{{Kind: rbacv1.ServiceAccountKind, Name: access.Name, Namespace: ServiceAccountNamespace}}
+
+	var err error
+	if access.Namespace == "" {
+		_, err = clientset.RbacV1().ClusterRoleBindings().Create(ctx, &rbacv1.ClusterRoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: access.Name, Labels: labels},
+			RoleRef:    roleRef,
+			Subjects:   subjects,
+		}, metav1.CreateOptions{})
+	} else {
+		_, err = clientset.RbacV1().RoleBindings(access.Namespace).Create(ctx, &rbacv1.RoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: access.Name, Namespace: access.Namespace, Labels: labels},
+			RoleRef:    roleRef,
+			Subjects:   subjects,
+		}, metav1.CreateOptions{})
+	}
+	if err != nil && !apierrors.IsAlreadyExists(err) {
+		return nil, time.Time{}, fmt.Errorf("failed to bind the ClusterRole %s: %w", access.ClusterRole, err)
+	}
+
+	token, err := clientset.CoreV1().ServiceAccounts(ServiceAccountNamespace).CreateToken(ctx, access.Name, &authenticationv1.TokenRequest{
+		Spec: authenticationv1.TokenRequestSpec{
+			ExpirationSeconds: ptr.To(int64(access.TTL.Seconds())),
+		},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("failed to issue the token for the ServiceAccount %s/%s: %w", ServiceAccountNamespace, access.Name, err)
+	}
+
+	// the server may shorten the requested lifetime
+	expiration := token.Status.ExpirationTimestamp.Time
+	if expiration.IsZero() {
+		expiration = time.Now().Add(access.TTL)
+	}
+
+	kubeconfig, err := Kubeconfig(adminConfig, access.ClusterName, access.Name, token.Status.Token)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	return kubeconfig, expiration, nil
+}
+
+// Revoke removes the ServiceAccount and its binding created by Grant from the cluster.
+// Deleting the ServiceAccount invalidates the issued tokens.
+func Revoke(ctx context.Context, clientset kubernetes.Interface, access Access) error {
+	var errs error
+
+	if access.Namespace == "" {
+		errs = errors.Join(errs, client.IgnoreNotFound(clientset.RbacV1().ClusterRoleBindings().Delete(ctx, access.Name, metav1.DeleteOptions{})))
+	} else {
+		errs = errors.Join(errs, client.IgnoreNotFound(clientset.RbacV1().RoleBindings(access.Namespace).Delete(ctx, access.Name, metav1.DeleteOptions{})))
+	}
+
+	errs = errors.Join(errs, client.IgnoreNotFound(clientset.CoreV1().ServiceAccounts(ServiceAccountNamespace).Delete(ctx, access.Name, metav1.DeleteOptions{})))
+
+	return errs
+}
+
+// Kubeconfig returns the kubeconfig authenticating with the given token
+// against the server of the given admin config.
+func Kubeconfig(adminConfig *rest.Config, clusterName, userName, token string) ([]byte, error) {
+	kubeconfig := clientcmdapi.NewConfig()
+	kubeconfig.Clusters[clusterName] = &clientcmdapi.Cluster{
+		Server:                   adminConfig.Host,
+		CertificateAuthorityData: adminConfig.CAData,
+		InsecureSkipTLSVerify:    adminConfig.Insecure,
+		TLSServerName:            adminConfig.ServerName,
+	}
+	kubeconfig.AuthInfos[userName] = &clientcmdapi.AuthInfo{Token: token}
+	kubeconfig.Contexts[clusterName] = &clientcmdapi.Context{Cluster: clusterName, AuthInfo: userName}
+	kubeconfig.CurrentContext = clusterName
+
+	b, err := clientcmd.Write(*kubeconfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to write kubeconfig: %w", err)
+	}
+
+	return b, nil
+}
@@ -0,0 +1,169 @@
+// Copyright 2024
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clusteraccess
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/K0rdent/kcm/internal/config"
+)
+
+func TestGrantAndRevoke(t *testing.T) {
+	adminConfig := &rest.Config{
+		Host:            "https://child.example.com:6443",
+		TLSClientConfig: rest.TLSClientConfig{CAData: []byte("ca")},
+	}
+	expiration := time.Now().Add(30 * time.Minute).Truncate(time.Second)
+
+	tests := []struct {
+		name   string
+		access Access
+	}{
+		{
+			name:   "cluster-wide",
+			access: Access{Name: "kcm-access-dev-alice", ClusterName: "dev", ClusterRole: "view", TTL: time.Hour},
+		},
+		{
+			name:   "namespaced",
+			access: Access{Name: "kcm-access-dev-bob", ClusterName: "dev", ClusterRole: "edit", Namespace: "apps", TTL: time.Hour},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			ctx := context.Background()
+
+			clientset := fake.NewClientset()
+			var requestedSeconds int64
+			clientset.PrependReactor("create", "serviceaccounts", func(action k8stesting.Action) (bool, runtime.Object, error) {
+				if action.GetSubresource() != "token" {
+					return false, nil, nil
+				}
+				req := action.(k8stesting.CreateAction).GetObject().(*authenticationv1.TokenRequest)
+				requestedSeconds = *req.Spec.ExpirationSeconds
+				req.Status = authenticationv1.TokenRequestStatus{Token: "token", ExpirationTimestamp: metav1.NewTime(expiration)}
+				return true, req, nil
+			})
+
+			kubeconfig, exp, err := Grant(ctx, clientset, adminConfig, tt.access)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(exp).To(BeTemporally("==", expiration))
+			g.Expect(requestedSeconds).To(Equal(int64(3600)))
+
+			cfg, err := clientcmd.RESTConfigFromKubeConfig(kubeconfig)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(cfg.Host).To(Equal(adminConfig.Host))
+			g.Expect(cfg.CAData).To(Equal([]byte("ca")))
+			g.Expect(cfg.BearerToken).To(Equal("token"))
+
+			_, err = clientset.CoreV1().ServiceAccounts(ServiceAccountNamespace).Get(ctx, tt.access.Name, metav1.GetOptions{})
+			g.Expect(err).NotTo(HaveOccurred())
+			if tt.access.Namespace == "" {
+				crb, err := clientset.RbacV1().ClusterRoleBindings().Get(ctx, tt.access.Name, metav1.GetOptions{})
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(crb.RoleRef.Name).To(Equal(tt.access.ClusterRole))
+			} else {
+				rb, err := clientset.RbacV1().RoleBindings(tt.access.Namespace).Get(ctx, tt.access.Name, metav1.GetOptions{})
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(rb.RoleRef.Name).To(Equal(tt.access.ClusterRole))
+			}
+
+			// granting again reuses the existing objects
+			_, _, err = Grant(ctx, clientset, adminConfig, tt.access)
+			g.Expect(err).NotTo(HaveOccurred())
+
+			g.Expect(Revoke(ctx, clientset, tt.access)).To(Succeed())
+			sas, err := clientset.CoreV1().ServiceAccounts(ServiceAccountNamespace).List(ctx, metav1.ListOptions{})
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(sas.Items).To(BeEmpty())
+			crbs, err := clientset.RbacV1().ClusterRoleBindings().List(ctx, metav1.ListOptions{})
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(crbs.Items).To(BeEmpty())
+			rbs, err := clientset.RbacV1().RoleBindings(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(rbs.Items).To(BeEmpty())
+
+			// revoking again is a no-op
+			g.Expect(Revoke(ctx, clientset, tt.access)).To(Succeed())
+		})
+	}
+}
+
+func TestCheckLimits(t *testing.T) {
+	limits := config.ClusterAccess{
+		AllowedClusterRoles: []string{"view", "edit"},
+		MaxTTL:              metav1.Duration{Duration: 8 * time.Hour},
+	}
+
+	tests := []struct {
+		name             string
+		access           Access
+		allowClusterWide bool
+		err              string
+	}{
+		{
+			name:   "allowed",
+			access: Access{ClusterRole: "edit", Namespace: "apps", TTL: time.Hour},
+		},
+		{
+			name:   "not allowed ClusterRole",
+			access: Access{ClusterRole: "cluster-admin", Namespace: "apps", TTL: time.Hour},
+			err:    "the ClusterRole cluster-admin is not allowed, the allowed ClusterRoles are: view, edit",
+		},
+		{
+			name:   "cluster-wide and too long",
+			access: Access{ClusterRole: "view", TTL: 24 * time.Hour},
+			err: "the namespace must be set, the ClusterRoles are not allowed to be granted cluster-wide\n" +
+				"the TTL 24h0m0s exceeds the maximum of 8h0m0s",
+		},
+		{
+			name:   "too short",
+			access: Access{ClusterRole: "view", Namespace: "apps", TTL: 5 * time.Minute},
+			err:    "the TTL 5m0s is less than the minimum of 10m0s",
+		},
+		{
+			name:             "cluster-wide allowed",
+			access:           Access{ClusterRole: "view", TTL: time.Hour},
+			allowClusterWide: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			limits := limits
+			limits.AllowClusterWide = tt.allowClusterWide
+			err := CheckLimits(tt.access, limits)
+			if tt.err == "" {
+				g.Expect(err).NotTo(HaveOccurred())
+				return
+			}
+			g.Expect(err).To(MatchError(tt.err))
+		})
+	}
+}
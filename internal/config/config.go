@@ -0,0 +1,307 @@
+// Copyright 2024
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package config holds the configuration of the controller manager loaded
+// from the file given with the --config flag. The requeue policy, the rate
+// limiters and the HelmRelease interval are reloaded on the file change,
+// the number of the concurrent reconciles requires a restart.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"sync/atomic"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	defaultMaxConcurrentReconciles = 1
+	defaultBaseDelay               = 5 * time.Millisecond
+	defaultMaxDelay                = 1000 * time.Second
+	defaultQPS                     = 10
+	defaultBurst                   = 100
+	defaultRequeueInterval         = 10 * time.Second
+	defaultHelmReleaseInterval     = 10 * time.Minute
+	defaultClusterAccessMaxTTL     = 8 * time.Hour
+	defaultInspectionInterval      = 10 * time.Minute
+)
+
+// Config is the configuration of the controller manager.
+type Config struct {
+	// Controllers overrides the Defaults per controller, the keys are
+	// the names of the controllers, e.g. ClusterDeployment.
+	Controllers map[string]Controller `json:"controllers,omitempty"`
+	// Defaults is the configuration of the controllers not listed in the Controllers.
+	Defaults Controller `json:"defaults,omitempty"`
+	// Requeue is the policy of the periodic requeues of the objects
+	// waiting for their dependencies.
+	Requeue Requeue `json:"requeue,omitempty"`
+	// HelmReleaseInterval is the reconcile interval of the HelmReleases
+	// of the ClusterDeployments and the Management components.
+	HelmReleaseInterval metav1.Duration `json:"helmReleaseInterval,omitempty"`
+	// ClusterAccess limits the accesses granted by the ClusterAccessRequests.
+	ClusterAccess ClusterAccess `json:"clusterAccess,omitempty"`
+	// InspectionInterval is the minimum interval between the inspections of an adopted
+	// cluster unless its ClusterDeployment spec or its kubeconfig is changed.
+	InspectionInterval metav1.Duration `json:"inspectionInterval,omitempty"`
+	// ClusterAutoscaler configures the access of the cluster-autoscalers
+	// deployed on the clusters to the management cluster.
+	ClusterAutoscaler ClusterAutoscaler `json:"clusterAutoscaler,omitempty"`
+}
+
+// ClusterAutoscaler configures the access of the cluster-autoscalers
+// deployed on the clusters to the management cluster.
+type ClusterAutoscaler struct {
+	// ManagementServer is the URL of the API server of the management cluster
+	// reachable from the clusters, it is required to deploy cluster-autoscaler.
+	ManagementServer string `json:"managementServer,omitempty"`
+}
+
+// ClusterAccess limits the accesses granted by the ClusterAccessRequests.
+type ClusterAccess struct {
+	// AllowedClusterRoles are the ClusterRoles allowed to be requested.
+	AllowedClusterRoles []string `json:"allowedClusterRoles,omitempty"`
+	// MaxTTL is the maximum lifetime of the access.
+	MaxTTL metav1.Duration `json:"maxTTL,omitempty"`
+	// AllowClusterWide allows to grant the ClusterRoles cluster-wide,
+	// otherwise the namespace of the access must be set.
+	AllowClusterWide bool `json:"allowClusterWide,omitempty"`
+}
+
+// Controller is the configuration of a controller.
+type Controller struct {
+	// RateLimiter is the configuration of the workqueue rate limiter.
+	RateLimiter *RateLimiter `json:"rateLimiter,omitempty"`
+	// MaxConcurrentReconciles is the maximum number of concurrent reconciles.
+	MaxConcurrentReconciles int `json:"maxConcurrentReconciles,omitempty"`
+}
+
+// RateLimiter is the configuration of the workqueue rate limiter, the delay
+// of a requeue is the maximum of the per-item exponential backoff and the
+// overall token bucket delay.
+type RateLimiter struct {
+	// BaseDelay is the delay of the first failed reconcile of an item.
+	BaseDelay metav1.Duration `json:"baseDelay,omitempty"`
+	// MaxDelay is the maximum per-item backoff.
+	MaxDelay metav1.Duration `json:"maxDelay,omitempty"`
+	// QPS is the overall rate of the requeues.
+	QPS float64 `json:"qps,omitempty"`
+	// Burst is the overall burst of the requeues.
+	Burst int `json:"burst,omitempty"`
+}
+
+// Requeue is the policy of the periodic requeues.
+type Requeue struct {
+	// Interval is the interval of the requeues.
+	Interval metav1.Duration `json:"interval,omitempty"`
+	// JitterFactor spreads the requeues by adding up to the given
+	// fraction of the interval to it, from 0 to 1.
+	JitterFactor float64 `json:"jitterFactor,omitempty"`
+}
+
+var current atomic.Pointer[Config]
+
+func init() {
+	current.Store(DefaultConfig())
+}
+
+// DefaultConfig returns the default configuration.
+func DefaultConfig() *Config {
+	return &Config{
+		Defaults: Controller{
+			MaxConcurrentReconciles: defaultMaxConcurrentReconciles,
+			RateLimiter: &RateLimiter{
+				BaseDelay: metav1.Duration{Duration: defaultBaseDelay},
+				MaxDelay:  metav1.Duration{Duration: defaultMaxDelay},
+				QPS:       defaultQPS,
+				Burst:     defaultBurst,
+			},
+		},
+		Requeue:             Requeue{Interval: metav1.Duration{Duration: defaultRequeueInterval}},
+		HelmReleaseInterval: metav1.Duration{Duration: defaultHelmReleaseInterval},
+		ClusterAccess: ClusterAccess{
+			AllowedClusterRoles: []string{"view", "edit"},
+			MaxTTL:              metav1.Duration{Duration: defaultClusterAccessMaxTTL},
+		},
+		InspectionInterval: metav1.Duration{Duration: defaultInspectionInterval},
+	}
+}
+
+// Load reads the configuration from the given file, the omitted fields are defaulted.
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	return Parse(data)
+}
+
+// Parse parses and validates the configuration, the omitted fields are defaulted.
+func Parse(data []byte) (*Config, error) {
+	cfg := DefaultConfig()
+	if err := yaml.UnmarshalStrict(data, cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+
+	defaults := DefaultConfig().Defaults
+	cfg.Defaults = merge(cfg.Defaults, defaults)
+	for name, c := range cfg.Controllers {
+		cfg.Controllers[name] = merge(c, cfg.Defaults)
+	}
+
+	if err := cfg.validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+
+	return cfg, nil
+}
+
+func (c *Config) validate() error {
+	var errs error
+	validateController := func(name string, ctrl Controller) {
+		if ctrl.MaxConcurrentReconciles < 1 {
+			errs = errors.Join(errs, fmt.Errorf("%s: maxConcurrentReconciles must be positive", name))
+		}
+		rl := ctrl.RateLimiter
+		if rl.BaseDelay.Duration < 0 || rl.MaxDelay.Duration < rl.BaseDelay.Duration {
+			errs = errors.Join(errs, fmt.Errorf("%s: rateLimiter.maxDelay must not be less than rateLimiter.baseDelay", name))
+		}
+		if rl.QPS <= 0 || rl.Burst < 1 {
+			errs = errors.Join(errs, fmt.Errorf("%s: rateLimiter.qps and rateLimiter.burst must be positive", name))
+		}
+	}
+
+	validateController("defaults", c.Defaults)
+	for name, ctrl := range c.Controllers {
+		validateController("controllers."+name, ctrl)
+	}
+	if c.Requeue.Interval.Duration <= 0 {
+		errs = errors.Join(errs, errors.New("requeue.interval must be positive"))
+	}
+	if c.Requeue.JitterFactor < 0 || c.Requeue.JitterFactor > 1 {
+		errs = errors.Join(errs, errors.New("requeue.jitterFactor must be between 0 and 1"))
+	}
+	if c.HelmReleaseInterval.Duration <= 0 {
+		errs = errors.Join(errs, errors.New("helmReleaseInterval must be positive"))
+	}
+	if len(c.ClusterAccess.AllowedClusterRoles) == 0 {
+		errs = errors.Join(errs, errors.New("clusterAccess.allowedClusterRoles must not be empty"))
+	}
+	if c.ClusterAccess.MaxTTL.Duration <= 0 {
+		errs = errors.Join(errs, errors.New("clusterAccess.maxTTL must be positive"))
+	}
+	if c.InspectionInterval.Duration <= 0 {
+		errs = errors.Join(errs, errors.New("inspectionInterval must be positive"))
+	}
+	if server := c.ClusterAutoscaler.ManagementServer; server != "" {
+		if u, err := url.Parse(server); err != nil || u.Scheme != "https" || u.Host == "" {
+			errs = errors.Join(errs, fmt.Errorf("clusterAutoscaler.managementServer %q must be an https URL", server))
+		}
+	}
+
+	return errs
+}
+
+// merge fills the omitted fields of the controller configuration from the defaults.
+func merge(c, defaults Controller) Controller {
+	if c.MaxConcurrentReconciles == 0 {
+		c.MaxConcurrentReconciles = defaults.MaxConcurrentReconciles
+	}
+	if c.RateLimiter == nil {
+		rl := *defaults.RateLimiter
+		c.RateLimiter = &rl
+		return c
+	}
+
+	rl := *c.RateLimiter
+	if rl.BaseDelay.Duration == 0 {
+		rl.BaseDelay = defaults.RateLimiter.BaseDelay
+	}
+	if rl.MaxDelay.Duration == 0 {
+		rl.MaxDelay = defaults.RateLimiter.MaxDelay
+	}
+	if rl.QPS == 0 {
+		rl.QPS = defaults.RateLimiter.QPS
+	}
+	if rl.Burst == 0 {
+		rl.Burst = defaults.RateLimiter.Burst
+	}
+	c.RateLimiter = &rl
+	return c
+}
+
+// Set sets the current configuration.
+func Set(cfg *Config) {
+	current.Store(cfg)
+}
+
+// Current returns the current configuration.
+func Current() *Config {
+	return current.Load()
+}
+
+// Controller returns the configuration of the controller with the given name.
+func (c *Config) Controller(name string) Controller {
+	if ctrl, ok := c.Controllers[name]; ok {
+		return ctrl
+	}
+	return c.Defaults
+}
+
+// ControllerOptions returns the options of the controller with the given name.
+// The rate limiter follows the changes of the current configuration.
+func ControllerOptions(name string) controller.Options {
+	return controller.Options{
+		MaxConcurrentReconciles: Current().Controller(name).MaxConcurrentReconciles,
+		RateLimiter:             newRateLimiter(name),
+	}
+}
+
+// RequeueAfter returns the jittered interval of the periodic requeues.
+func RequeueAfter() time.Duration {
+	requeue := Current().Requeue
+	if requeue.JitterFactor == 0 {
+		return requeue.Interval.Duration
+	}
+	return wait.Jitter(requeue.Interval.Duration, requeue.JitterFactor)
+}
+
+// HelmReleaseInterval returns the reconcile interval of the HelmReleases.
+func HelmReleaseInterval() time.Duration {
+	return Current().HelmReleaseInterval.Duration
+}
+
+// ClusterAccessLimits returns the limits of the accesses granted by the ClusterAccessRequests.
+func ClusterAccessLimits() ClusterAccess {
+	return Current().ClusterAccess
+}
+
+// InspectionInterval returns the minimum interval between the inspections of an adopted cluster.
+func InspectionInterval() time.Duration {
+	return Current().InspectionInterval.Duration
+}
+
+// ManagementServer returns the URL of the API server of the management
+// cluster reachable from the clusters.
+func ManagementServer() string {
+	return Current().ClusterAutoscaler.ManagementServer
+}
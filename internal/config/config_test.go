@@ -0,0 +1,156 @@
+// Copyright 2024
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestParse(t *testing.T) {
+	g := NewWithT(t)
+
+	cfg, err := Parse([]byte(`
+defaults:
+  maxConcurrentReconciles: 2
+controllers:
+  ClusterDeployment:
+    maxConcurrentReconciles: 5
+    rateLimiter:
+      baseDelay: 1s
+requeue:
+  interval: 30s
+  jitterFactor: 0.5
+clusterAccess:
+  maxTTL: 2h
+inspectionInterval: 1h
+clusterAutoscaler:
+  managementServer: https://mgmt.example.com:6443
+`))
+	g.Expect(err).NotTo(HaveOccurred())
+
+	// the omitted fields are defaulted from the defaults of the config and the built-in ones
+	g.Expect(cfg.Controller("Management").MaxConcurrentReconciles).To(Equal(2))
+	g.Expect(cfg.Controller("Management").RateLimiter.BaseDelay.Duration).To(Equal(defaultBaseDelay))
+	cd := cfg.Controller("ClusterDeployment")
+	g.Expect(cd.MaxConcurrentReconciles).To(Equal(5))
+	g.Expect(cd.RateLimiter.BaseDelay.Duration).To(Equal(time.Second))
+	g.Expect(cd.RateLimiter.MaxDelay.Duration).To(Equal(defaultMaxDelay))
+	g.Expect(cd.RateLimiter.Burst).To(Equal(defaultBurst))
+	g.Expect(cfg.Requeue.Interval.Duration).To(Equal(30 * time.Second))
+	g.Expect(cfg.HelmReleaseInterval.Duration).To(Equal(defaultHelmReleaseInterval))
+	g.Expect(cfg.ClusterAccess.AllowedClusterRoles).To(Equal([]string{"view", "edit"}))
+	g.Expect(cfg.ClusterAccess.MaxTTL.Duration).To(Equal(2 * time.Hour))
+	g.Expect(cfg.InspectionInterval.Duration).To(Equal(time.Hour))
+	g.Expect(cfg.ClusterAutoscaler.ManagementServer).To(Equal("https://mgmt.example.com:6443"))
+
+	_, err = Parse([]byte(`unknown: true`))
+	g.Expect(err).To(MatchError(ContainSubstring("unknown field")))
+
+	_, err = Parse([]byte(`
+controllers:
+  Management:
+    rateLimiter:
+      baseDelay: 10m
+      maxDelay: 1m
+requeue:
+  jitterFactor: 2
+clusterAccess:
+  allowedClusterRoles: []
+clusterAutoscaler:
+  managementServer: mgmt.example.com
+`))
+	g.Expect(err).To(MatchError(ContainSubstring("controllers.Management: rateLimiter.maxDelay must not be less than rateLimiter.baseDelay")))
+	g.Expect(err).To(MatchError(ContainSubstring("requeue.jitterFactor must be between 0 and 1")))
+	g.Expect(err).To(MatchError(ContainSubstring("clusterAccess.allowedClusterRoles must not be empty")))
+	g.Expect(err).To(MatchError(ContainSubstring(`clusterAutoscaler.managementServer "mgmt.example.com" must be an https URL`)))
+}
+
+func TestRequeueAfter(t *testing.T) {
+	g := NewWithT(t)
+	t.Cleanup(func() { Set(DefaultConfig()) })
+
+	g.Expect(RequeueAfter()).To(Equal(defaultRequeueInterval))
+
+	cfg := DefaultConfig()
+	cfg.Requeue.JitterFactor = 0.5
+	Set(cfg)
+	for range 100 {
+		g.Expect(RequeueAfter()).To(And(
+			BeNumerically(">=", defaultRequeueInterval),
+			BeNumerically("<", defaultRequeueInterval*3/2),
+		))
+	}
+}
+
+func TestRateLimiter(t *testing.T) {
+	g := NewWithT(t)
+	t.Cleanup(func() { Set(DefaultConfig()) })
+
+	item := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "ns", Name: "name"}}
+	rl := newRateLimiter("ClusterDeployment")
+	g.Expect(rl.When(item)).To(Equal(defaultBaseDelay))
+	g.Expect(rl.When(item)).To(Equal(2 * defaultBaseDelay))
+	g.Expect(rl.NumRequeues(item)).To(Equal(2))
+
+	// the changes of the config are applied to the existing rate limiters
+	cfg, err := Parse([]byte(`
+controllers:
+  ClusterDeployment:
+    rateLimiter:
+      baseDelay: 1s
+      maxDelay: 3s
+`))
+	g.Expect(err).NotTo(HaveOccurred())
+	Set(cfg)
+	g.Expect(rl.When(item)).To(Equal(3 * time.Second))
+
+	rl.Forget(item)
+	g.Expect(rl.NumRequeues(item)).To(BeZero())
+	g.Expect(rl.When(item)).To(Equal(time.Second))
+}
+
+func TestWatcher(t *testing.T) {
+	g := NewWithT(t)
+	t.Cleanup(func() { Set(DefaultConfig()) })
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	g.Expect(os.WriteFile(path, []byte("helmReleaseInterval: 5m\n"), 0o600)).To(Succeed())
+	cfg, err := Load(path)
+	g.Expect(err).NotTo(HaveOccurred())
+	Set(cfg)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go func() {
+		_ = (&Watcher{Path: path, Interval: 10 * time.Millisecond}).Start(ctx)
+	}()
+
+	g.Consistently(HelmReleaseInterval).WithTimeout(50 * time.Millisecond).Should(Equal(5 * time.Minute))
+
+	// the invalid config is not applied
+	g.Expect(os.WriteFile(path, []byte("helmReleaseInterval: -1m\n"), 0o600)).To(Succeed())
+	g.Consistently(HelmReleaseInterval).WithTimeout(50 * time.Millisecond).Should(Equal(5 * time.Minute))
+
+	g.Expect(os.WriteFile(path, []byte("helmReleaseInterval: 1m\n"), 0o600)).To(Succeed())
+	g.Eventually(HelmReleaseInterval).Should(Equal(time.Minute))
+}
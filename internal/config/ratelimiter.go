@@ -0,0 +1,87 @@
+// Copyright 2024
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// rateLimiter is the maximum of the per-item exponential backoff and the overall
+// token bucket rate limiters as the default one of the controller-runtime,
+// with the settings read from the current configuration on every requeue.
+type rateLimiter struct {
+	bucket   *rate.Limiter
+	failures map[reconcile.Request]int
+	name     string
+	mu       sync.Mutex
+}
+
+var _ workqueue.TypedRateLimiter[reconcile.Request] = (*rateLimiter)(nil)
+
+func newRateLimiter(name string) *rateLimiter {
+	settings := Current().Controller(name).RateLimiter
+	return &rateLimiter{
+		name:     name,
+		failures: make(map[reconcile.Request]int),
+		bucket:   rate.NewLimiter(rate.Limit(settings.QPS), settings.Burst),
+	}
+}
+
+// When implements the workqueue.TypedRateLimiter interface.
+func (r *rateLimiter) When(item reconcile.Request) time.Duration {
+	settings := Current().Controller(r.name).RateLimiter
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	if r.bucket.Limit() != rate.Limit(settings.QPS) {
+		r.bucket.SetLimitAt(now, rate.Limit(settings.QPS))
+	}
+	if r.bucket.Burst() != settings.Burst {
+		r.bucket.SetBurstAt(now, settings.Burst)
+	}
+
+	exp := r.failures[item]
+	r.failures[item]++
+
+	backoff := float64(settings.BaseDelay.Nanoseconds()) * math.Pow(2, float64(exp))
+	delay := settings.MaxDelay.Duration
+	if backoff < float64(delay) {
+		delay = time.Duration(backoff)
+	}
+
+	return max(delay, r.bucket.ReserveN(now, 1).DelayFrom(now))
+}
+
+// Forget implements the workqueue.TypedRateLimiter interface.
+func (r *rateLimiter) Forget(item reconcile.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.failures, item)
+}
+
+// NumRequeues implements the workqueue.TypedRateLimiter interface.
+func (r *rateLimiter) NumRequeues(item reconcile.Request) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.failures[item]
+}
@@ -0,0 +1,105 @@
+// Copyright 2024
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const defaultWatchInterval = 10 * time.Second
+
+// Watcher reloads the configuration when the file changes, e.g. when the
+// mounted ConfigMap is updated.
+type Watcher struct {
+	// Path is the path of the configuration file.
+	Path string
+	// Interval is the interval the file is checked at.
+	Interval time.Duration
+
+	last []byte
+}
+
+// NeedLeaderElection implements the manager.LeaderElectionRunnable interface,
+// the configuration is reloaded on every replica.
+func (*Watcher) NeedLeaderElection() bool {
+	return false
+}
+
+// Start implements the manager.Runnable interface.
+func (w *Watcher) Start(ctx context.Context) error {
+	interval := w.Interval
+	if interval == 0 {
+		interval = defaultWatchInterval
+	}
+
+	// the file has been loaded on start
+	w.last, _ = os.ReadFile(w.Path)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			w.reload(ctx)
+		}
+	}
+}
+
+func (w *Watcher) reload(ctx context.Context) {
+	l := log.FromContext(ctx).WithName("config-watcher").WithValues("path", w.Path)
+
+	data, err := os.ReadFile(w.Path)
+	if err != nil {
+		l.Error(err, "failed to read config file")
+		return
+	}
+	if bytes.Equal(data, w.last) {
+		return
+	}
+	w.last = data
+
+	cfg, err := Parse(data)
+	if err != nil {
+		l.Error(err, "failed to reload config, keeping the current one")
+		return
+	}
+
+	previous := Current()
+	Set(cfg)
+	l.Info("Reloaded config")
+
+	if previous.Defaults.MaxConcurrentReconciles != cfg.Defaults.MaxConcurrentReconciles || !sameConcurrency(previous.Controllers, cfg.Controllers) {
+		l.Info("The number of concurrent reconciles has been changed, restart the controller manager to apply it")
+	}
+}
+
+func sameConcurrency(a, b map[string]Controller) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for name, c := range a {
+		if other, ok := b[name]; !ok || other.MaxConcurrentReconciles != c.MaxConcurrentReconciles {
+			return false
+		}
+	}
+	return true
+}
@@ -24,10 +24,14 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	kcm "github.com/K0rdent/kcm/api/v1alpha1"
+	"github.com/K0rdent/kcm/internal/config"
+	"github.com/K0rdent/kcm/internal/scope"
+	"github.com/K0rdent/kcm/internal/tracing"
 	"github.com/K0rdent/kcm/internal/utils"
 )
 
@@ -36,6 +40,8 @@ type AccessManagementReconciler struct {
 	client.Client
 	Config          *rest.Config
 	SystemNamespace string
+
+	recorder record.EventRecorder
 }
 
 func (r *AccessManagementReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
@@ -69,6 +75,7 @@ func (r *AccessManagementReconciler) Reconcile(ctx context.Context, req ctrl.Req
 		return ctrl.Result{}, err
 	}
 
+	previousErr := accessMgmt.Status.Error
 	defer func() {
 		statusErr := ""
 		if err != nil {
@@ -76,7 +83,13 @@ func (r *AccessManagementReconciler) Reconcile(ctx context.Context, req ctrl.Req
 		}
 		accessMgmt.Status.Error = statusErr
 		accessMgmt.Status.ObservedGeneration = accessMgmt.Generation
-		err = errors.Join(err, r.updateStatus(ctx, accessMgmt))
+		if updateErr := r.updateStatus(ctx, accessMgmt); updateErr != nil {
+			err = errors.Join(err, updateErr)
+			return
+		}
+		if statusErr != "" && statusErr != previousErr {
+			r.recorder.Event(accessMgmt, corev1.EventTypeWarning, EventReasonAccessRulesFailed, statusErr)
+		}
 	}()
 
 	systemCtChains, managedCtChains, err := r.getCurrentTemplateChains(ctx, kcm.ClusterTemplateChainKind)
@@ -240,9 +253,16 @@ func (r *AccessManagementReconciler) getCredentials(ctx context.Context) (map[st
 	return systemCredentials, managedCredentials, nil
 }
 
+// getTargetNamespaces returns the target namespaces in the watched scope.
 func getTargetNamespaces(ctx context.Context, cl client.Client, targetNamespaces kcm.TargetNamespaces) ([]string, error) {
 	if len(targetNamespaces.List) > 0 {
-		return targetNamespaces.List, nil
+		result := make([]string, 0, len(targetNamespaces.List))
+		for _, ns := range targetNamespaces.List {
+			if scope.Contains(ns) {
+				result = append(result, ns)
+			}
+		}
+		return result, nil
 	}
 	var selector labels.Selector
 	var err error
@@ -270,9 +290,11 @@ func getTargetNamespaces(ctx context.Context, cl client.Client, targetNamespaces
 		return nil, err
 	}
 
-	result := make([]string, len(namespaces.Items))
-	for i, ns := range namespaces.Items {
-		result[i] = ns.Name
+	result := make([]string, 0, len(namespaces.Items))
+	for _, ns := range namespaces.Items {
+		if scope.Contains(ns.Name) {
+			result = append(result, ns.Name)
+		}
 	}
 
 	return result, nil
@@ -351,7 +373,10 @@ func (r *AccessManagementReconciler) updateStatus(ctx context.Context, accessMgm
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *AccessManagementReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.recorder = mgr.GetEventRecorderFor("accessmanagement-controller")
+
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&kcm.AccessManagement{}).
-		Complete(r)
+		WithOptions(config.ControllerOptions("AccessManagement")).
+		Complete(tracing.Reconciler("AccessManagement", r))
 }
@@ -23,6 +23,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	crclient "sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
@@ -224,6 +225,7 @@ var _ = Describe("Template Management Controller", func() {
 			controllerReconciler := &AccessManagementReconciler{
 				Client:          k8sClient,
 				SystemNamespace: systemNamespace.Name,
+				recorder:        &record.FakeRecorder{},
 			}
 			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{
 				NamespacedName: types.NamespacedName{Name: amName},
@@ -0,0 +1,281 @@
+// Copyright 2024
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	kcm "github.com/K0rdent/kcm/api/v1alpha1"
+	"github.com/K0rdent/kcm/internal/clusteraccess"
+	"github.com/K0rdent/kcm/internal/config"
+	"github.com/K0rdent/kcm/internal/tracing"
+)
+
+// ClusterAccessRequestReconciler reconciles a ClusterAccessRequest object
+type ClusterAccessRequestReconciler struct {
+	client.Client
+	recorder record.EventRecorder
+}
+
+func (r *ClusterAccessRequestReconciler) Reconcile(ctx context.Context, req ctrl.Request) (_ ctrl.Result, err error) {
+	l := ctrl.LoggerFrom(ctx)
+	l.Info("Reconciling ClusterAccessRequest")
+
+	accessReq := &kcm.ClusterAccessRequest{}
+	if err := r.Get(ctx, req.NamespacedName, accessReq); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	if !accessReq.DeletionTimestamp.IsZero() {
+		l.Info("Revoking the access of the deleted ClusterAccessRequest")
+		return ctrl.Result{}, r.delete(ctx, accessReq)
+	}
+
+	if controllerutil.AddFinalizer(accessReq, kcm.ClusterAccessRequestFinalizer) {
+		if err := r.Update(ctx, accessReq); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to update ClusterAccessRequest %s/%s: %w", accessReq.Namespace, accessReq.Name, err)
+		}
+		return ctrl.Result{}, nil
+	}
+
+	defer func() {
+		accessReq.Status.ObservedGeneration = accessReq.Generation
+		err = errors.Join(err, r.Status().Update(ctx, accessReq))
+	}()
+
+	granted := apimeta.FindStatusCondition(accessReq.Status.Conditions, kcm.AccessGrantedCondition)
+	if granted != nil && granted.Reason == kcm.ExpiredReason {
+		return ctrl.Result{}, nil
+	}
+
+	if accessReq.Status.ExpirationTime != nil {
+		if until := time.Until(accessReq.Status.ExpirationTime.Time); until > 0 {
+			return ctrl.Result{RequeueAfter: until}, nil
+		}
+
+		l.Info("Revoking the expired access")
+		if err := r.revoke(ctx, accessReq); err != nil {
+			return ctrl.Result{}, err
+		}
+
+		apimeta.SetStatusCondition(accessReq.GetConditions(), metav1.Condition{
+			Type:    kcm.AccessGrantedCondition,
+			Status:  metav1.ConditionFalse,
+			Reason:  kcm.ExpiredReason,
+			Message: "The access has expired and has been revoked",
+		})
+		r.recordEvent(ctx, accessReq, EventReasonAccessExpired, "The access of the ClusterAccessRequest %s has expired and has been revoked", accessReq.Name)
+
+		return ctrl.Result{}, nil
+	}
+
+	// the limits are enforced by the webhook as well, the controller guards against the requests
+	// created with the webhook disabled or before the limits were tightened
+	if err := clusteraccess.CheckLimits(accessFor(accessReq), config.ClusterAccessLimits()); err != nil {
+		apimeta.SetStatusCondition(accessReq.GetConditions(), metav1.Condition{
+			Type:    kcm.AccessGrantedCondition,
+			Status:  metav1.ConditionFalse,
+			Reason:  kcm.FailedReason,
+			Message: "The access exceeds the limits: " + err.Error(),
+		})
+		return ctrl.Result{}, nil
+	}
+
+	expiration, err := r.grant(ctx, accessReq)
+	if err != nil {
+		apimeta.SetStatusCondition(accessReq.GetConditions(), metav1.Condition{
+			Type:    kcm.AccessGrantedCondition,
+			Status:  metav1.ConditionFalse,
+			Reason:  kcm.FailedReason,
+			Message: err.Error(),
+		})
+		return ctrl.Result{}, err
+	}
+
+	accessReq.Status.ExpirationTime = &metav1.Time{Time: expiration}
+	apimeta.SetStatusCondition(accessReq.GetConditions(), metav1.Condition{
+		Type:    kcm.AccessGrantedCondition,
+		Status:  metav1.ConditionTrue,
+		Reason:  kcm.SucceededReason,
+		Message: "The kubeconfig is stored in the Secret " + accessReq.Status.KubeconfigSecretName,
+	})
+	r.recordEvent(ctx, accessReq, EventReasonAccessGranted, "Granted the ClusterRole %s%s to the ClusterAccessRequest %s until %s",
+		accessReq.Spec.ClusterRole, namespaceSuffix(accessReq.Spec.Namespace), accessReq.Name, expiration.UTC().Format(time.RFC3339))
+
+	return ctrl.Result{RequeueAfter: time.Until(expiration)}, nil
+}
+
+// grant creates the ServiceAccount in the cluster and stores the issued kubeconfig in the Secret.
+func (r *ClusterAccessRequestReconciler) grant(ctx context.Context, accessReq *kcm.ClusterAccessRequest) (time.Time, error) {
+	cd := &kcm.ClusterDeployment{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: accessReq.Namespace, Name: accessReq.Spec.ClusterDeployment}, cd); err != nil {
+		return time.Time{}, fmt.Errorf("failed to get ClusterDeployment %s/%s: %w", accessReq.Namespace, accessReq.Spec.ClusterDeployment, err)
+	}
+
+	cfg, err := clusteraccess.ClusterRESTConfig(ctx, r.Client, cd)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	clientset, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to create a client for the cluster: %w", err)
+	}
+
+	access := accessFor(accessReq)
+	// set in advance so the partially created objects are cleaned up as well
+	accessReq.Status.ServiceAccount = clusteraccess.ServiceAccountNamespace + "/" + access.Name
+	kubeconfig, expiration, err := clusteraccess.Grant(ctx, clientset, cfg, access)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: accessReq.KubeconfigSecretName(), Namespace: accessReq.Namespace},
+	}
+	if _, err := ctrl.CreateOrUpdate(ctx, r.Client, secret, func() error {
+		if secret.ResourceVersion != "" && !metav1.IsControlledBy(secret, accessReq) {
+			return fmt.Errorf("the Secret %s/%s already exists and is not controlled by the ClusterAccessRequest", secret.Namespace, secret.Name)
+		}
+		if secret.Labels == nil {
+			secret.Labels = make(map[string]string)
+		}
+		secret.Labels[kcm.KCMManagedLabelKey] = kcm.KCMManagedLabelValue
+		secret.Data = map[string][]byte{"value": kubeconfig}
+		return controllerutil.SetControllerReference(accessReq, secret, r.Scheme())
+	}); err != nil {
+		return time.Time{}, fmt.Errorf("failed to store the kubeconfig in the Secret %s/%s: %w", secret.Namespace, secret.Name, err)
+	}
+
+	accessReq.Status.KubeconfigSecretName = secret.Name
+
+	return expiration, nil
+}
+
+// revoke removes the ServiceAccount from the cluster and the Secret with the issued kubeconfig.
+// The access is considered revoked if the ClusterDeployment or its cluster no longer exist.
+func (r *ClusterAccessRequestReconciler) revoke(ctx context.Context, accessReq *kcm.ClusterAccessRequest) error {
+	if accessReq.Status.KubeconfigSecretName != "" {
+		if err := r.Delete(ctx, &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: accessReq.Status.KubeconfigSecretName, Namespace: accessReq.Namespace},
+		}); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("failed to delete the kubeconfig Secret: %w", err)
+		}
+		accessReq.Status.KubeconfigSecretName = ""
+	}
+
+	if accessReq.Status.ServiceAccount == "" {
+		return nil
+	}
+
+	cd := &kcm.ClusterDeployment{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: accessReq.Namespace, Name: accessReq.Spec.ClusterDeployment}, cd); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+
+	cfg, err := clusteraccess.ClusterRESTConfig(ctx, r.Client, cd)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	clientset, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to create a client for the cluster: %w", err)
+	}
+
+	if err := clusteraccess.Revoke(ctx, clientset, accessFor(accessReq)); err != nil {
+		return fmt.Errorf("failed to revoke the access: %w", err)
+	}
+
+	accessReq.Status.ServiceAccount = ""
+	return nil
+}
+
+func (r *ClusterAccessRequestReconciler) delete(ctx context.Context, accessReq *kcm.ClusterAccessRequest) error {
+	if !controllerutil.ContainsFinalizer(accessReq, kcm.ClusterAccessRequestFinalizer) {
+		return nil
+	}
+
+	if accessReq.Status.ServiceAccount != "" {
+		if err := r.revoke(ctx, accessReq); err != nil {
+			return err
+		}
+		r.recordEvent(ctx, accessReq, EventReasonAccessRevoked, "The access of the deleted ClusterAccessRequest %s has been revoked", accessReq.Name)
+	}
+
+	if controllerutil.RemoveFinalizer(accessReq, kcm.ClusterAccessRequestFinalizer) {
+		if err := r.Update(ctx, accessReq); err != nil {
+			return fmt.Errorf("failed to remove finalizer from ClusterAccessRequest %s/%s: %w", accessReq.Namespace, accessReq.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// recordEvent records the audit Event on the ClusterDeployment the access is requested to.
+func (r *ClusterAccessRequestReconciler) recordEvent(ctx context.Context, accessReq *kcm.ClusterAccessRequest, reason, messageFmt string, args ...any) {
+	cd := &kcm.ClusterDeployment{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: accessReq.Namespace, Name: accessReq.Spec.ClusterDeployment}, cd); err != nil {
+		ctrl.LoggerFrom(ctx).Error(err, "failed to get ClusterDeployment to record the Event", "reason", reason)
+		return
+	}
+
+	r.recorder.Eventf(cd, corev1.EventTypeNormal, reason, messageFmt, args...)
+}
+
+func accessFor(accessReq *kcm.ClusterAccessRequest) clusteraccess.Access {
+	return clusteraccess.Access{
+		Name:        clusteraccess.ServiceAccountName(accessReq),
+		ClusterName: accessReq.Spec.ClusterDeployment,
+		ClusterRole: accessReq.Spec.ClusterRole,
+		Namespace:   accessReq.Spec.Namespace,
+		TTL:         accessReq.Spec.TTL.Duration,
+	}
+}
+
+func namespaceSuffix(namespace string) string {
+	if namespace == "" {
+		return ""
+	}
+	return " in the namespace " + namespace
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ClusterAccessRequestReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.recorder = mgr.GetEventRecorderFor("clusteraccessrequest-controller")
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&kcm.ClusterAccessRequest{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		WithOptions(config.ControllerOptions("ClusterAccessRequest")).
+		Complete(tracing.Reconciler("ClusterAccessRequest", r))
+}
@@ -0,0 +1,391 @@
+// Copyright 2024
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+
+	hcv2 "github.com/fluxcd/helm-controller/api/v2"
+	"github.com/fluxcd/pkg/apis/kustomize"
+	sveltosv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+	"k8s.io/utils/ptr"
+	clusterapiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/yaml"
+
+	kcm "github.com/K0rdent/kcm/api/v1alpha1"
+	"github.com/K0rdent/kcm/internal/clusteraccess"
+	"github.com/K0rdent/kcm/internal/config"
+	"github.com/K0rdent/kcm/internal/utils"
+)
+
+// reconcileAutoscaling sets the cluster-autoscaler size limits on the MachineDeployments of the autoscaled pools,
+// removes them from the MachineDeployments of the pools which are not autoscaled anymore and reports the replicas
+// of the pools. It requests a requeue while the number of the machines of any of the pools is being changed.
+func (r *ClusterDeploymentReconciler) reconcileAutoscaling(ctx context.Context, mc *kcm.ClusterDeployment) (requeue bool, _ error) {
+	var pools []kcm.PoolAutoscaling
+	if mc.Spec.Autoscaling != nil {
+		pools = mc.Spec.Autoscaling.Pools
+	}
+	if len(pools) == 0 && len(mc.Status.Autoscaling) == 0 {
+		return false, nil
+	}
+
+	mds := &clusterapiv1beta1.MachineDeploymentList{}
+	if err := r.Client.List(ctx, mds,
+		client.InNamespace(mc.Namespace),
+		client.MatchingLabels{kcm.ClusterNameLabelKey: mc.Name},
+	); err != nil {
+		return false, fmt.Errorf("failed to list MachineDeployments of ClusterDeployment %s/%s: %w", mc.Namespace, mc.Name, err)
+	}
+
+	var errs error
+	statuses := make([]kcm.PoolAutoscalingStatus, 0, len(pools))
+	for _, md := range mds.Items {
+		poolName := md.Name
+		if nodePoolName, ok := md.Labels[kcm.NodePoolLabelKey]; ok {
+			poolName = nodePoolName
+		}
+
+		idx := slices.IndexFunc(pools, func(pool kcm.PoolAutoscaling) bool { return pool.Name == poolName })
+		wasAutoscaled := slices.ContainsFunc(mc.Status.Autoscaling, func(status kcm.PoolAutoscalingStatus) bool {
+			return status.MachineDeployment == md.Name
+		})
+		if idx < 0 && !wasAutoscaled {
+			// the size limits set by others are left intact
+			continue
+		}
+
+		original := md.DeepCopy()
+		if idx < 0 {
+			delete(md.Annotations, kcm.AutoscalerMinSizeAnnotation)
+			delete(md.Annotations, kcm.AutoscalerMaxSizeAnnotation)
+		} else {
+			if md.Annotations == nil {
+				md.Annotations = make(map[string]string)
+			}
+			md.Annotations[kcm.AutoscalerMinSizeAnnotation] = strconv.Itoa(int(pools[idx].MinReplicas))
+			md.Annotations[kcm.AutoscalerMaxSizeAnnotation] = strconv.Itoa(int(pools[idx].MaxReplicas))
+		}
+
+		if len(md.Annotations) != len(original.Annotations) ||
+			md.Annotations[kcm.AutoscalerMinSizeAnnotation] != original.Annotations[kcm.AutoscalerMinSizeAnnotation] ||
+			md.Annotations[kcm.AutoscalerMaxSizeAnnotation] != original.Annotations[kcm.AutoscalerMaxSizeAnnotation] {
+			ctrl.LoggerFrom(ctx).Info("Updating autoscaling size limits", "machineDeployment", md.Name, "autoscaled", idx >= 0)
+			if err := r.Client.Patch(ctx, &md, client.MergeFrom(original)); err != nil {
+				errs = errors.Join(errs, fmt.Errorf("failed to patch MachineDeployment %s/%s: %w", md.Namespace, md.Name, err))
+				continue
+			}
+		}
+
+		if idx < 0 {
+			continue
+		}
+
+		desired := ptr.Deref(md.Spec.Replicas, 0)
+		if md.Status.Replicas != desired {
+			requeue = true
+		}
+		statuses = append(statuses, kcm.PoolAutoscalingStatus{
+			Name:              poolName,
+			MachineDeployment: md.Name,
+			MinReplicas:       pools[idx].MinReplicas,
+			MaxReplicas:       pools[idx].MaxReplicas,
+			Replicas:          md.Status.Replicas,
+			DesiredReplicas:   desired,
+		})
+	}
+
+	for _, pool := range pools {
+		if !slices.ContainsFunc(statuses, func(status kcm.PoolAutoscalingStatus) bool { return status.Name == pool.Name }) {
+			statuses = append(statuses, kcm.PoolAutoscalingStatus{
+				Name:        pool.Name,
+				MinReplicas: pool.MinReplicas,
+				MaxReplicas: pool.MaxReplicas,
+			})
+		}
+	}
+
+	slices.SortFunc(statuses, func(a, b kcm.PoolAutoscalingStatus) int { return cmp.Compare(a.Name, b.Name) })
+	mc.Status.Autoscaling = statuses
+
+	return requeue, errs
+}
+
+// machineDeploymentReplicasPatch removes the replicas from the rendered MachineDeployment.
+const machineDeploymentReplicasPatch = `apiVersion: cluster.x-k8s.io/v1beta1
+kind: MachineDeployment
+metadata:
+  name: %s
+spec:
+  replicas: null
+`
+
+// autoscalingPostRenderers returns the post-renderers of the HelmRelease of the ClusterDeployment removing
+// the replicas from the MachineDeployments of the autoscaled pools rendered by the chart, so that the upgrades
+// of the release do not reset the replicas set by cluster-autoscaler. Cluster API keeps the current replicas
+// of a MachineDeployment with the size limits set when the replicas are omitted.
+func autoscalingPostRenderers(mc *kcm.ClusterDeployment) []hcv2.PostRenderer {
+	if mc.Spec.Autoscaling == nil || len(mc.Spec.Autoscaling.Pools) == 0 {
+		return nil
+	}
+
+	patches := make([]kustomize.Patch, 0, len(mc.Spec.Autoscaling.Pools))
+	for _, pool := range mc.Spec.Autoscaling.Pools {
+		patches = append(patches, kustomize.Patch{
+			Patch: fmt.Sprintf(machineDeploymentReplicasPatch, pool.Name),
+			Target: &kustomize.Selector{
+				Group: clusterapiv1beta1.GroupVersion.Group,
+				Kind:  "MachineDeployment",
+				Name:  pool.Name,
+			},
+		})
+	}
+
+	return []hcv2.PostRenderer{{Kustomize: &hcv2.Kustomize{Patches: patches}}}
+}
+
+const (
+	// autoscalerKubeconfigSecretName is the name of the Secret on the cluster
+	// with the kubeconfig of cluster-autoscaler for the management cluster.
+	autoscalerKubeconfigSecretName = "cluster-autoscaler-management-kubeconfig"
+	// autoscalerKubeconfigPath is the path the chart mounts the kubeconfig at,
+	// the key of the Secret is the base name of the path.
+	autoscalerKubeconfigPath = "/etc/kubernetes/mgmt-kubeconfig"
+	autoscalerKubeconfigKey  = "mgmt-kubeconfig"
+)
+
+// autoscalerRules are the permissions of cluster-autoscaler in the namespace of the ClusterDeployment
+// on the management cluster required by the clusterapi cloud provider.
+var autoscalerRules = []rbacv1.PolicyRule{
+	{
+		APIGroups: []string{clusterapiv1beta1.GroupVersion.Group},
+		Resources: []string{"machinedeployments", "machinepools", "machinesets", "machines"},
+		Verbs:     []string{"get", "list", "watch", "update", "patch"},
+	},
+	{
+		APIGroups: []string{clusterapiv1beta1.GroupVersion.Group},
+		Resources: []string{"machinedeployments/scale", "machinepools/scale"},
+		Verbs:     []string{"get", "update", "patch"},
+	},
+	{
+		// the machine templates of the pools scaled from zero
+		APIGroups: []string{"infrastructure.cluster.x-k8s.io"},
+		Resources: []string{
+			"awsmachinetemplates",
+			"azuremachinetemplates",
+			"dockermachinetemplates",
+			"gcpmachinetemplates",
+			"openstackmachinetemplates",
+			"vspheremachinetemplates",
+		},
+		Verbs: []string{"get", "list", "watch"},
+	},
+}
+
+// autoscalerService returns the cluster-autoscaler service of the
+// ClusterDeployment configured with the clusterapi cloud provider.
+func autoscalerService(mc *kcm.ClusterDeployment, autoscaler *kcm.AutoscalerService) (kcm.Service, error) {
+	values := map[string]any{
+		"cloudProvider": "clusterapi",
+		// cluster-autoscaler runs on the cluster and manages the machines on the management cluster
+		"clusterAPIMode":             "incluster-kubeconfig",
+		"clusterAPIKubeconfigSecret": autoscalerKubeconfigSecretName,
+		"clusterAPICloudConfigPath":  autoscalerKubeconfigPath,
+		"autoDiscovery": map[string]any{
+			"clusterName": mc.Name,
+			"namespace":   mc.Namespace,
+		},
+	}
+
+	var extra map[string]any
+	if err := yaml.Unmarshal([]byte(autoscaler.Values), &extra); err != nil {
+		return kcm.Service{}, fmt.Errorf("failed to parse the values of the cluster-autoscaler: %w", err)
+	}
+	merged, _ := utils.MergeValues(utils.ValuesLayer{Values: values}, utils.ValuesLayer{Values: extra})
+
+	raw, err := yaml.Marshal(merged)
+	if err != nil {
+		return kcm.Service{}, fmt.Errorf("failed to marshal the values of the cluster-autoscaler: %w", err)
+	}
+
+	return kcm.Service{
+		Name:      kcm.ClusterAutoscalerServiceName,
+		Namespace: autoscaler.Namespace,
+		Template:  autoscaler.Template,
+		Values:    string(raw),
+	}, nil
+}
+
+// autoscalerAccessName returns the name of the ServiceAccount of the cluster-autoscaler
+// of the ClusterDeployment on the management cluster, its Role and its RoleBinding.
+func autoscalerAccessName(mc *kcm.ClusterDeployment) string {
+	return mc.Name + "-" + kcm.ClusterAutoscalerServiceName
+}
+
+// autoscalerAccessObjects returns the objects granting cluster-autoscaler the access to the
+// management cluster in the order of their creation: the ServiceAccount, its Role, its RoleBinding,
+// its token Secret and the Secret with the manifest of the kubeconfig Secret deployed on the cluster.
+func autoscalerAccessObjects(mc *kcm.ClusterDeployment) (sa *corev1.ServiceAccount, role *rbacv1.Role, binding *rbacv1.RoleBinding, token, kubeconfig *corev1.Secret) {
+	name := autoscalerAccessName(mc)
+	meta := func(name string) metav1.ObjectMeta {
+		return metav1.ObjectMeta{Name: name, Namespace: mc.Namespace}
+	}
+
+	return &corev1.ServiceAccount{ObjectMeta: meta(name)},
+		&rbacv1.Role{ObjectMeta: meta(name)},
+		&rbacv1.RoleBinding{ObjectMeta: meta(name)},
+		&corev1.Secret{ObjectMeta: meta(name + "-token")},
+		&corev1.Secret{ObjectMeta: meta(name + "-kubeconfig")}
+}
+
+// reconcileAutoscalerAccess grants the cluster-autoscaler of the ClusterDeployment the access to the Cluster API
+// objects in the namespace of the ClusterDeployment with a ServiceAccount on the management cluster and returns
+// the policy references of the Profile deploying its kubeconfig to the cluster. The access is revoked once the
+// autoscaler is not set. The objects are controlled by the ClusterDeployment, so they are removed along with it.
+func (r *ClusterDeploymentReconciler) reconcileAutoscalerAccess(ctx context.Context, mc *kcm.ClusterDeployment) ([]sveltosv1beta1.PolicyRef, error) {
+	if mc.Spec.Autoscaling == nil || mc.Spec.Autoscaling.Autoscaler == nil {
+		return nil, r.revokeAutoscalerAccess(ctx, mc)
+	}
+	autoscaler := mc.Spec.Autoscaling.Autoscaler
+
+	server := config.ManagementServer()
+	if server == "" {
+		return nil, errors.New("the API server of the management cluster reachable from the clusters is not configured, " +
+			"set the clusterAutoscaler.managementServer of the controller config to deploy cluster-autoscaler")
+	}
+
+	sa, role, binding, token, kubeconfigSecret := autoscalerAccessObjects(mc)
+	if err := r.ensureAutoscalerAccessObject(ctx, mc, sa, func() {}); err != nil {
+		return nil, err
+	}
+	if err := r.ensureAutoscalerAccessObject(ctx, mc, role, func() {
+		role.Rules = autoscalerRules
+	}); err != nil {
+		return nil, err
+	}
+	if err := r.ensureAutoscalerAccessObject(ctx, mc, binding, func() {
+		binding.RoleRef = rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "Role", Name: role.Name}
+		binding.Subjects = []rbacv1.Subject{{Kind: rbacv1.ServiceAccountKind, Name: sa.Name, Namespace: sa.Namespace}}
+	}); err != nil {
+		return nil, err
+	}
+	if err := r.ensureAutoscalerAccessObject(ctx, mc, token, func() {
+		token.Type = corev1.SecretTypeServiceAccountToken
+		metav1.SetMetaDataAnnotation(&token.ObjectMeta, corev1.ServiceAccountNameKey, sa.Name)
+	}); err != nil {
+		return nil, err
+	}
+	if len(token.Data[corev1.ServiceAccountTokenKey]) == 0 {
+		return nil, fmt.Errorf("the token of the ServiceAccount %s/%s of the cluster-autoscaler is not issued yet", sa.Namespace, sa.Name)
+	}
+
+	kubeconfig, err := clusteraccess.Kubeconfig(&rest.Config{
+		Host:            server,
+		TLSClientConfig: rest.TLSClientConfig{CAData: token.Data[corev1.ServiceAccountRootCAKey]},
+	}, "management", sa.Name, string(token.Data[corev1.ServiceAccountTokenKey]))
+	if err != nil {
+		return nil, err
+	}
+	manifest, err := yaml.Marshal(&corev1.Secret{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Secret"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      autoscalerKubeconfigSecretName,
+			Namespace: cmp.Or(autoscaler.Namespace, kcm.ClusterAutoscalerServiceName),
+		},
+		Data: map[string][]byte{autoscalerKubeconfigKey: kubeconfig},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal the kubeconfig Secret of the cluster-autoscaler: %w", err)
+	}
+
+	if err := r.ensureAutoscalerAccessObject(ctx, mc, kubeconfigSecret, func() {
+		kubeconfigSecret.Type = libsveltosv1beta1.ClusterProfileSecretType
+		kubeconfigSecret.Data = map[string][]byte{"kubeconfig-secret.yaml": manifest}
+	}); err != nil {
+		return nil, err
+	}
+
+	return []sveltosv1beta1.PolicyRef{
+		{
+			Kind:           "Secret",
+			Namespace:      kubeconfigSecret.Namespace,
+			Name:           kubeconfigSecret.Name,
+			DeploymentType: sveltosv1beta1.DeploymentTypeRemote,
+		},
+	}, nil
+}
+
+// ensureAutoscalerAccessObject creates or updates the given object controlled by the ClusterDeployment
+// with the given mutation. It fails if the object exists and is not controlled by the ClusterDeployment.
+func (r *ClusterDeploymentReconciler) ensureAutoscalerAccessObject(ctx context.Context, mc *kcm.ClusterDeployment, obj client.Object, mutate func()) error {
+	gvk, err := r.Client.GroupVersionKindFor(obj)
+	if err != nil {
+		return err
+	}
+
+	if _, err := controllerutil.CreateOrUpdate(ctx, r.Client, obj, func() error {
+		if obj.GetResourceVersion() != "" && !metav1.IsControlledBy(obj, mc) {
+			return fmt.Errorf("the %s %s/%s of the cluster-autoscaler already exists and is not controlled by the ClusterDeployment",
+				gvk.Kind, obj.GetNamespace(), obj.GetName())
+		}
+		mutate()
+		labels := obj.GetLabels()
+		if labels == nil {
+			labels = make(map[string]string)
+		}
+		labels[kcm.KCMManagedLabelKey] = kcm.KCMManagedLabelValue
+		obj.SetLabels(labels)
+		return controllerutil.SetControllerReference(mc, obj, r.Client.Scheme())
+	}); err != nil {
+		return fmt.Errorf("failed to reconcile %s %s/%s of the cluster-autoscaler: %w", gvk.Kind, obj.GetNamespace(), obj.GetName(), err)
+	}
+
+	return nil
+}
+
+// revokeAutoscalerAccess removes the objects granting cluster-autoscaler the access to the management cluster
+// controlled by the ClusterDeployment. Deleting the ServiceAccount invalidates its token.
+func (r *ClusterDeploymentReconciler) revokeAutoscalerAccess(ctx context.Context, mc *kcm.ClusterDeployment) error {
+	sa, role, binding, token, kubeconfigSecret := autoscalerAccessObjects(mc)
+
+	var errs error
+	for _, obj := range []client.Object{kubeconfigSecret, token, binding, role, sa} {
+		if err := r.Client.Get(ctx, client.ObjectKeyFromObject(obj), obj); err != nil {
+			errs = errors.Join(errs, client.IgnoreNotFound(err))
+			continue
+		}
+		if !metav1.IsControlledBy(obj, mc) {
+			continue
+		}
+
+		ctrl.LoggerFrom(ctx).Info("Revoking the access of the cluster-autoscaler", "object", client.ObjectKeyFromObject(obj))
+		errs = errors.Join(errs, client.IgnoreNotFound(r.Client.Delete(ctx, obj)))
+	}
+
+	return errs
+}
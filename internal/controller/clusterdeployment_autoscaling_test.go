@@ -0,0 +1,319 @@
+// Copyright 2024
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	sveltosv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/utils/ptr"
+	clusterapiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/kustomize/api/krusty"
+	kustypes "sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/kustomize/kyaml/resid"
+	"sigs.k8s.io/yaml"
+
+	kcm "github.com/K0rdent/kcm/api/v1alpha1"
+	"github.com/K0rdent/kcm/internal/config"
+)
+
+var _ = Describe("ClusterDeployment autoscaling", func() {
+	const (
+		namespace             = "default"
+		clusterDeploymentName = "test-cd"
+	)
+
+	var (
+		ctx               context.Context
+		fakeClient        client.Client
+		reconciler        *ClusterDeploymentReconciler
+		clusterDeployment *kcm.ClusterDeployment
+	)
+
+	newMachineDeployment := func(name string, replicas, currentReplicas int32, labels, annotations map[string]string) *clusterapiv1beta1.MachineDeployment {
+		md := &clusterapiv1beta1.MachineDeployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Namespace:   namespace,
+				Labels:      map[string]string{kcm.ClusterNameLabelKey: clusterDeploymentName},
+				Annotations: annotations,
+			},
+			Spec:   clusterapiv1beta1.MachineDeploymentSpec{ClusterName: clusterDeploymentName, Replicas: ptr.To(replicas)},
+			Status: clusterapiv1beta1.MachineDeploymentStatus{Replicas: currentReplicas},
+		}
+		for k, v := range labels {
+			md.Labels[k] = v
+		}
+		return md
+	}
+
+	getAnnotations := func(name string) map[string]string {
+		md := &clusterapiv1beta1.MachineDeployment{}
+		Expect(fakeClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, md)).To(Succeed())
+		return md.Annotations
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		clusterDeployment = &kcm.ClusterDeployment{
+			ObjectMeta: metav1.ObjectMeta{Name: clusterDeploymentName, Namespace: namespace},
+			Spec: kcm.ClusterDeploymentSpec{
+				Autoscaling: &kcm.AutoscalingSpec{
+					Pools: []kcm.PoolAutoscaling{
+						{Name: clusterDeploymentName + "-md", MinReplicas: 1, MaxReplicas: 3},
+						{Name: "gpu", MinReplicas: 0, MaxReplicas: 5},
+						{Name: "missing", MinReplicas: 1, MaxReplicas: 2},
+					},
+				},
+			},
+		}
+
+		fakeClient = fake.NewClientBuilder().
+			WithScheme(scheme.Scheme).
+			WithObjects(
+				newMachineDeployment(clusterDeploymentName+"-md", 2, 2, nil, nil),
+				newMachineDeployment(clusterDeploymentName+"-pool-gpu", 3, 1, map[string]string{kcm.NodePoolLabelKey: "gpu"}, nil),
+				newMachineDeployment(clusterDeploymentName+"-manual", 1, 1, nil, map[string]string{
+					kcm.AutoscalerMinSizeAnnotation: "1",
+					kcm.AutoscalerMaxSizeAnnotation: "10",
+				}),
+			).
+			Build()
+		reconciler = &ClusterDeploymentReconciler{Client: fakeClient}
+	})
+
+	It("should set the size limits and report the replicas of the autoscaled pools", func() {
+		requeue, err := reconciler.reconcileAutoscaling(ctx, clusterDeployment)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(requeue).To(BeTrue(), "the gpu pool is being scaled")
+
+		Expect(getAnnotations(clusterDeploymentName + "-md")).To(Equal(map[string]string{
+			kcm.AutoscalerMinSizeAnnotation: "1",
+			kcm.AutoscalerMaxSizeAnnotation: "3",
+		}))
+		Expect(getAnnotations(clusterDeploymentName + "-pool-gpu")).To(Equal(map[string]string{
+			kcm.AutoscalerMinSizeAnnotation: "0",
+			kcm.AutoscalerMaxSizeAnnotation: "5",
+		}))
+
+		Expect(clusterDeployment.Status.Autoscaling).To(Equal([]kcm.PoolAutoscalingStatus{
+			{Name: "gpu", MachineDeployment: clusterDeploymentName + "-pool-gpu", MinReplicas: 0, MaxReplicas: 5, Replicas: 1, DesiredReplicas: 3},
+			{Name: "missing", MinReplicas: 1, MaxReplicas: 2},
+			{Name: clusterDeploymentName + "-md", MachineDeployment: clusterDeploymentName + "-md", MinReplicas: 1, MaxReplicas: 3, Replicas: 2, DesiredReplicas: 2},
+		}))
+	})
+
+	It("should remove the size limits of the pools which are not autoscaled anymore", func() {
+		_, err := reconciler.reconcileAutoscaling(ctx, clusterDeployment)
+		Expect(err).NotTo(HaveOccurred())
+
+		clusterDeployment.Spec.Autoscaling.Pools = clusterDeployment.Spec.Autoscaling.Pools[:1]
+		requeue, err := reconciler.reconcileAutoscaling(ctx, clusterDeployment)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(requeue).To(BeFalse())
+
+		Expect(getAnnotations(clusterDeploymentName + "-pool-gpu")).To(BeEmpty())
+		Expect(getAnnotations(clusterDeploymentName + "-md")).To(HaveKeyWithValue(kcm.AutoscalerMaxSizeAnnotation, "3"))
+		Expect(getAnnotations(clusterDeploymentName+"-manual")).To(HaveKeyWithValue(kcm.AutoscalerMaxSizeAnnotation, "10"),
+			"the size limits not set by kcm should be left intact")
+		Expect(clusterDeployment.Status.Autoscaling).To(HaveLen(1))
+	})
+
+	It("should remove the replicas of the autoscaled pools from the rendered MachineDeployments", func() {
+		renderers := autoscalingPostRenderers(clusterDeployment)
+		Expect(renderers).To(HaveLen(1))
+		Expect(renderers[0].Kustomize).NotTo(BeNil())
+
+		kustomization := kustypes.Kustomization{Resources: []string{"manifests.yaml"}}
+		for _, patch := range renderers[0].Kustomize.Patches {
+			kustomization.Patches = append(kustomization.Patches, kustypes.Patch{
+				Patch: patch.Patch,
+				Target: &kustypes.Selector{ResId: resid.ResId{
+					Gvk:  resid.Gvk{Group: patch.Target.Group, Version: patch.Target.Version, Kind: patch.Target.Kind},
+					Name: patch.Target.Name,
+				}},
+			})
+		}
+
+		var manifests []byte
+		for _, md := range []*clusterapiv1beta1.MachineDeployment{
+			newMachineDeployment(clusterDeploymentName+"-md", 2, 0, nil, nil),
+			newMachineDeployment(clusterDeploymentName+"-manual", 1, 0, nil, nil),
+		} {
+			md.SetGroupVersionKind(clusterapiv1beta1.GroupVersion.WithKind("MachineDeployment"))
+			raw, err := yaml.Marshal(md)
+			Expect(err).NotTo(HaveOccurred())
+			manifests = append(append(manifests, raw...), []byte("---\n")...)
+		}
+		rawKustomization, err := yaml.Marshal(kustomization)
+		Expect(err).NotTo(HaveOccurred())
+
+		fs := filesys.MakeFsInMemory()
+		Expect(fs.WriteFile("/manifests.yaml", manifests)).To(Succeed())
+		Expect(fs.WriteFile("/kustomization.yaml", rawKustomization)).To(Succeed())
+		resources, err := krusty.MakeKustomizer(krusty.MakeDefaultOptions()).Run(fs, "/")
+		Expect(err).NotTo(HaveOccurred())
+
+		replicas := make(map[string]any)
+		for _, res := range resources.Resources() {
+			obj, err := res.Map()
+			Expect(err).NotTo(HaveOccurred())
+			spec, ok := obj["spec"].(map[string]any)
+			Expect(ok).To(BeTrue())
+			replicas[res.GetName()] = spec["replicas"]
+		}
+		Expect(replicas).To(Equal(map[string]any{
+			clusterDeploymentName + "-md":     nil,
+			clusterDeploymentName + "-manual": 1,
+		}), "the replicas of the pools which are not autoscaled should be kept")
+
+		clusterDeployment.Spec.Autoscaling = nil
+		Expect(autoscalingPostRenderers(clusterDeployment)).To(BeEmpty())
+	})
+
+	It("should render the cluster-autoscaler service with the clusterapi cloud provider", func() {
+		svc, err := autoscalerService(clusterDeployment, &kcm.AutoscalerService{
+			Template:  "cluster-autoscaler-9-46-6",
+			Namespace: "kube-system",
+			Values:    "extraArgs:\n  scale-down-delay-after-add: 5m\nautoDiscovery:\n  labels:\n  - pool: gpu\n",
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(svc.Name).To(Equal(kcm.ClusterAutoscalerServiceName))
+		Expect(svc.Namespace).To(Equal("kube-system"))
+		Expect(svc.Template).To(Equal("cluster-autoscaler-9-46-6"))
+
+		var values map[string]any
+		Expect(yaml.Unmarshal([]byte(svc.Values), &values)).To(Succeed())
+		Expect(values).To(Equal(map[string]any{
+			"cloudProvider":              "clusterapi",
+			"clusterAPIMode":             "incluster-kubeconfig",
+			"clusterAPIKubeconfigSecret": "cluster-autoscaler-management-kubeconfig",
+			"clusterAPICloudConfigPath":  "/etc/kubernetes/mgmt-kubeconfig",
+			"extraArgs":                  map[string]any{"scale-down-delay-after-add": "5m"},
+			"autoDiscovery": map[string]any{
+				"clusterName": clusterDeploymentName,
+				"namespace":   namespace,
+				"labels":      []any{map[string]any{"pool": "gpu"}},
+			},
+		}))
+	})
+
+	Context("the access of cluster-autoscaler to the management cluster", func() {
+		const accessName = clusterDeploymentName + "-cluster-autoscaler"
+
+		BeforeEach(func() {
+			cfg := config.DefaultConfig()
+			cfg.ClusterAutoscaler.ManagementServer = "https://mgmt.example.com:6443"
+			config.Set(cfg)
+			DeferCleanup(func() { config.Set(config.DefaultConfig()) })
+
+			clusterDeployment.UID = "cd-uid"
+			clusterDeployment.Spec.Autoscaling.Autoscaler = &kcm.AutoscalerService{Template: "cluster-autoscaler-9-46-6", Namespace: "kube-system"}
+		})
+
+		issueToken := func() {
+			token := &corev1.Secret{}
+			Expect(fakeClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: accessName + "-token"}, token)).To(Succeed())
+			token.Data = map[string][]byte{
+				corev1.ServiceAccountTokenKey:  []byte("sa-token"),
+				corev1.ServiceAccountRootCAKey: []byte("ca"),
+			}
+			Expect(fakeClient.Update(ctx, token)).To(Succeed())
+		}
+
+		It("should deploy the kubeconfig of a scoped ServiceAccount to the cluster and revoke it once disabled", func() {
+			_, err := reconciler.reconcileAutoscalerAccess(ctx, clusterDeployment)
+			Expect(err).To(MatchError(ContainSubstring("is not issued yet")))
+
+			sa := &corev1.ServiceAccount{}
+			Expect(fakeClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: accessName}, sa)).To(Succeed())
+			Expect(metav1.IsControlledBy(sa, clusterDeployment)).To(BeTrue())
+			role := &rbacv1.Role{}
+			Expect(fakeClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: accessName}, role)).To(Succeed())
+			Expect(metav1.IsControlledBy(role, clusterDeployment)).To(BeTrue())
+			Expect(role.Rules).To(Equal(autoscalerRules))
+			binding := &rbacv1.RoleBinding{}
+			Expect(fakeClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: accessName}, binding)).To(Succeed())
+			Expect(metav1.IsControlledBy(binding, clusterDeployment)).To(BeTrue())
+			Expect(binding.RoleRef.Name).To(Equal(accessName))
+			Expect(binding.Subjects).To(ConsistOf(rbacv1.Subject{Kind: rbacv1.ServiceAccountKind, Name: accessName, Namespace: namespace}))
+
+			issueToken()
+			refs, err := reconciler.reconcileAutoscalerAccess(ctx, clusterDeployment)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(refs).To(Equal([]sveltosv1beta1.PolicyRef{{
+				Kind:           "Secret",
+				Namespace:      namespace,
+				Name:           accessName + "-kubeconfig",
+				DeploymentType: sveltosv1beta1.DeploymentTypeRemote,
+			}}))
+
+			policy := &corev1.Secret{}
+			Expect(fakeClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: accessName + "-kubeconfig"}, policy)).To(Succeed())
+			Expect(metav1.IsControlledBy(policy, clusterDeployment)).To(BeTrue())
+			Expect(policy.Type).To(Equal(libsveltosv1beta1.ClusterProfileSecretType))
+
+			deployed := &corev1.Secret{}
+			Expect(yaml.Unmarshal(policy.Data["kubeconfig-secret.yaml"], deployed)).To(Succeed())
+			Expect(deployed.Namespace).To(Equal("kube-system"))
+			Expect(deployed.Name).To(Equal("cluster-autoscaler-management-kubeconfig"))
+			kubeconfig, err := clientcmd.Load(deployed.Data["mgmt-kubeconfig"])
+			Expect(err).NotTo(HaveOccurred())
+			Expect(kubeconfig.Clusters["management"].Server).To(Equal("https://mgmt.example.com:6443"))
+			Expect(kubeconfig.Clusters["management"].CertificateAuthorityData).To(Equal([]byte("ca")))
+			Expect(kubeconfig.AuthInfos[accessName].Token).To(Equal("sa-token"))
+
+			clusterDeployment.Spec.Autoscaling.Autoscaler = nil
+			refs, err = reconciler.reconcileAutoscalerAccess(ctx, clusterDeployment)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(refs).To(BeEmpty())
+			for _, obj := range []client.Object{sa, role, binding, policy} {
+				Expect(apierrors.IsNotFound(fakeClient.Get(ctx, client.ObjectKeyFromObject(obj), obj))).To(BeTrue())
+			}
+		})
+
+		It("should not take over the objects not controlled by the ClusterDeployment", func() {
+			foreign := &rbacv1.Role{ObjectMeta: metav1.ObjectMeta{Name: accessName, Namespace: namespace}}
+			Expect(fakeClient.Create(ctx, foreign)).To(Succeed())
+
+			_, err := reconciler.reconcileAutoscalerAccess(ctx, clusterDeployment)
+			Expect(err).To(MatchError(ContainSubstring("the Role default/" + accessName + " of the cluster-autoscaler already exists and is not controlled by the ClusterDeployment")))
+
+			clusterDeployment.Spec.Autoscaling.Autoscaler = nil
+			Expect(reconciler.revokeAutoscalerAccess(ctx, clusterDeployment)).To(Succeed())
+			Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(foreign), foreign)).To(Succeed(), "the foreign Role should be left intact")
+		})
+
+		It("should fail without the API server of the management cluster", func() {
+			config.Set(config.DefaultConfig())
+
+			_, err := reconciler.reconcileAutoscalerAccess(ctx, clusterDeployment)
+			Expect(err).To(MatchError(ContainSubstring("clusterAutoscaler.managementServer")))
+		})
+	})
+})
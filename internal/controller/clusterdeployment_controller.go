@@ -15,11 +15,15 @@
 package controller
 
 import (
+	"cmp"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"slices"
 	"strings"
+	"sync"
 	"time"
 
 	hcv2 "github.com/fluxcd/helm-controller/api/v2"
@@ -35,8 +39,12 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -44,20 +52,23 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+	"sigs.k8s.io/yaml"
 
 	kcm "github.com/K0rdent/kcm/api/v1alpha1"
+	"github.com/K0rdent/kcm/internal/adoption"
+	"github.com/K0rdent/kcm/internal/config"
 	"github.com/K0rdent/kcm/internal/helm"
+	"github.com/K0rdent/kcm/internal/metrics"
 	providersloader "github.com/K0rdent/kcm/internal/providers"
+	"github.com/K0rdent/kcm/internal/sharding"
 	"github.com/K0rdent/kcm/internal/sveltos"
 	"github.com/K0rdent/kcm/internal/telemetry"
+	"github.com/K0rdent/kcm/internal/tracing"
 	"github.com/K0rdent/kcm/internal/utils"
 	"github.com/K0rdent/kcm/internal/utils/status"
 )
 
-const (
-	DefaultRequeueInterval = 10 * time.Second
-)
-
 var ErrClusterNotFound = errors.New("cluster is not found")
 
 type helmActor interface {
@@ -70,9 +81,15 @@ type helmActor interface {
 type ClusterDeploymentReconciler struct {
 	Client client.Client
 	helmActor
-	Config          *rest.Config
-	DynamicClient   *dynamic.DynamicClient
+	Config        *rest.Config
+	DynamicClient *dynamic.DynamicClient
+	// Sharding restricts the reconciliation to the ClusterDeployments of the
+	// shards owned by the replica, the controller runs on every replica if set.
+	Sharding        *sharding.Manager
 	SystemNamespace string
+
+	recorder    record.EventRecorder
+	inspections inspectionCache
 }
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
@@ -92,6 +109,11 @@ func (r *ClusterDeploymentReconciler) Reconcile(ctx context.Context, req ctrl.Re
 		return ctrl.Result{}, err
 	}
 
+	if r.Sharding != nil && !r.Sharding.Owns(clusterDeployment) {
+		l.V(1).Info("ClusterDeployment belongs to a shard of another replica, skipping")
+		return ctrl.Result{}, nil
+	}
+
 	if !clusterDeployment.DeletionTimestamp.IsZero() {
 		l.Info("Deleting ClusterDeployment")
 		return r.Delete(ctx, clusterDeployment)
@@ -153,7 +175,7 @@ func (r *ClusterDeploymentReconciler) setStatusFromChildObjects(ctx context.Cont
 	return !allConditionsComplete, nil
 }
 
-func (r *ClusterDeploymentReconciler) reconcileUpdate(ctx context.Context, mc *kcm.ClusterDeployment) (_ ctrl.Result, err error) {
+func (r *ClusterDeploymentReconciler) reconcileUpdate(ctx context.Context, mc *kcm.ClusterDeployment) (res ctrl.Result, err error) {
 	l := ctrl.LoggerFrom(ctx)
 
 	if controllerutil.AddFinalizer(mc, kcm.ClusterDeploymentFinalizer) {
@@ -170,6 +192,7 @@ func (r *ClusterDeploymentReconciler) reconcileUpdate(ctx context.Context, mc *k
 		return ctrl.Result{}, err
 	}
 
+	previousConditions := slices.Clone(mc.Status.Conditions)
 	if len(mc.Status.Conditions) == 0 {
 		mc.InitConditions()
 	}
@@ -177,7 +200,12 @@ func (r *ClusterDeploymentReconciler) reconcileUpdate(ctx context.Context, mc *k
 	clusterTpl := &kcm.ClusterTemplate{}
 
 	defer func() {
-		err = errors.Join(err, r.updateStatus(ctx, mc, clusterTpl))
+		endOfLifeIn, statusErr := r.updateStatus(ctx, mc, clusterTpl, previousConditions)
+		err = errors.Join(err, statusErr)
+		// the reason of the TemplateDeprecated condition changes once the end-of-life is reached
+		if err == nil && endOfLifeIn > 0 && !res.Requeue && (res.RequeueAfter == 0 || endOfLifeIn < res.RequeueAfter) {
+			res.RequeueAfter = endOfLifeIn
+		}
 	}()
 
 	if err = r.Client.Get(ctx, client.ObjectKey{Name: mc.Spec.Template, Namespace: mc.Namespace}, clusterTpl); err != nil {
@@ -196,9 +224,22 @@ func (r *ClusterDeploymentReconciler) reconcileUpdate(ctx context.Context, mc *k
 	}
 
 	clusterRes, clusterErr := r.updateCluster(ctx, mc, clusterTpl)
-	servicesRes, servicesErr := r.updateServices(ctx, mc)
+	autoscalingRequeue, autoscalingErr := r.reconcileAutoscaling(ctx, mc)
+	inspection, inspectionErr := r.inspectAdoptedCluster(ctx, mc, clusterTpl)
+
+	var (
+		servicesRes ctrl.Result
+		servicesErr error
+	)
+	if inspectionErr != nil && mc.Spec.Adoption != nil && mc.Spec.Adoption.ImportHelmReleases {
+		// the imported services are unknown, reconciling the Profile
+		// without them would uninstall the previously imported releases
+		l.Info("Skipping services reconciliation since the adopted cluster inspection failed")
+	} else {
+		servicesRes, servicesErr = r.updateServices(ctx, mc, inspection)
+	}
 
-	if err = errors.Join(clusterErr, servicesErr); err != nil {
+	if err = errors.Join(clusterErr, autoscalingErr, inspectionErr, servicesErr); err != nil {
 		return ctrl.Result{}, err
 	}
 	if !clusterRes.IsZero() {
@@ -207,11 +248,17 @@ func (r *ClusterDeploymentReconciler) reconcileUpdate(ctx context.Context, mc *k
 	if !servicesRes.IsZero() {
 		return servicesRes, nil
 	}
+	if autoscalingRequeue {
+		return ctrl.Result{RequeueAfter: config.RequeueAfter()}, nil
+	}
 
 	return ctrl.Result{}, nil
 }
 
-func (r *ClusterDeploymentReconciler) updateCluster(ctx context.Context, mc *kcm.ClusterDeployment, clusterTpl *kcm.ClusterTemplate) (ctrl.Result, error) {
+func (r *ClusterDeploymentReconciler) updateCluster(ctx context.Context, mc *kcm.ClusterDeployment, clusterTpl *kcm.ClusterTemplate) (_ ctrl.Result, err error) {
+	ctx, span := tracing.Start(ctx, "ClusterDeploymentReconciler.updateCluster")
+	defer func() { tracing.End(span, err) }()
+
 	l := ctrl.LoggerFrom(ctx)
 
 	if clusterTpl == nil {
@@ -248,33 +295,32 @@ func (r *ClusterDeploymentReconciler) updateCluster(ctx context.Context, mc *kcm
 		})
 		return ctrl.Result{}, err
 	}
-	l.Info("Downloading Helm chart")
-	hcChart, err := r.DownloadChartFromArtifact(ctx, source.GetArtifact())
-	if err != nil {
+
+	if err := r.applyDefaults(ctx, mc); err != nil {
 		apimeta.SetStatusCondition(mc.GetConditions(), metav1.Condition{
 			Type:    kcm.HelmChartReadyCondition,
 			Status:  metav1.ConditionFalse,
 			Reason:  kcm.FailedReason,
-			Message: fmt.Sprintf("failed to download helm chart: %s", err),
+			Message: fmt.Sprintf("failed to apply ClusterDeploymentDefaults: %s", err),
 		})
 		return ctrl.Result{}, err
 	}
 
-	l.Info("Initializing Helm client")
-	actionConfig, err := r.InitializeConfiguration(mc, l.Info)
-	if err != nil {
-		return ctrl.Result{}, err
-	}
-
-	l.Info("Validating Helm chart with provided values")
-	if err = r.EnsureReleaseWithValues(ctx, actionConfig, hcChart, mc); err != nil {
-		apimeta.SetStatusCondition(mc.GetConditions(), metav1.Condition{
-			Type:    kcm.HelmChartReadyCondition,
-			Status:  metav1.ConditionFalse,
-			Reason:  kcm.FailedReason,
-			Message: fmt.Sprintf("failed to validate template with provided configuration: %s", err),
-		})
-		return ctrl.Result{}, err
+	inputsHash := validatedInputsHash(source.GetArtifact(), mc)
+	if inputsHash != "" && inputsHash == mc.Status.ValidatedInputsHash && apimeta.IsStatusConditionTrue(mc.Status.Conditions, kcm.HelmChartReadyCondition) {
+		l.V(1).Info("Skipping Helm chart validation, the chart and the values have not changed")
+	} else {
+		mc.Status.ValidatedInputsHash = ""
+		if err := r.validateHelmChart(ctx, mc, source.GetArtifact()); err != nil {
+			apimeta.SetStatusCondition(mc.GetConditions(), metav1.Condition{
+				Type:    kcm.HelmChartReadyCondition,
+				Status:  metav1.ConditionFalse,
+				Reason:  kcm.FailedReason,
+				Message: err.Error(),
+			})
+			return ctrl.Result{}, err
+		}
+		mc.Status.ValidatedInputsHash = inputsHash
 	}
 
 	apimeta.SetStatusCondition(mc.GetConditions(), metav1.Condition{
@@ -306,15 +352,15 @@ func (r *ClusterDeploymentReconciler) updateCluster(ctx context.Context, mc *kcm
 			Reason:  kcm.FailedReason,
 			Message: "Credential is not in Ready state",
 		})
+	} else {
+		apimeta.SetStatusCondition(mc.GetConditions(), metav1.Condition{
+			Type:    kcm.CredentialReadyCondition,
+			Status:  metav1.ConditionTrue,
+			Reason:  kcm.SucceededReason,
+			Message: "Credential is Ready",
+		})
 	}
 
-	apimeta.SetStatusCondition(mc.GetConditions(), metav1.Condition{
-		Type:    kcm.CredentialReadyCondition,
-		Status:  metav1.ConditionTrue,
-		Reason:  kcm.SucceededReason,
-		Message: "Credential is Ready",
-	})
-
 	if mc.Spec.DryRun {
 		return ctrl.Result{}, nil
 	}
@@ -340,12 +386,17 @@ func (r *ClusterDeploymentReconciler) updateCluster(ctx context.Context, mc *kcm
 			Name:       mc.Name,
 			UID:        mc.UID,
 		},
-		ChartRef: clusterTpl.Status.ChartRef,
+		ChartRef:      clusterTpl.Status.ChartRef,
+		PostRenderers: autoscalingPostRenderers(mc),
 	}
 	if clusterTpl.Spec.Helm.ChartSpec != nil {
 		hrReconcileOpts.ReconcileInterval = &clusterTpl.Spec.Helm.ChartSpec.Interval.Duration
 	}
 
+	if err := r.recordUpgrade(ctx, mc, clusterTpl); err != nil {
+		return ctrl.Result{}, err
+	}
+
 	hr, _, err := helm.ReconcileHelmRelease(ctx, r.Client, mc.Name, mc.Namespace, hrReconcileOpts)
 	if err != nil {
 		apimeta.SetStatusCondition(mc.GetConditions(), metav1.Condition{
@@ -370,23 +421,349 @@ func (r *ClusterDeploymentReconciler) updateCluster(ctx context.Context, mc *kcm
 	requeue, err := r.aggregateCapoConditions(ctx, mc)
 	if err != nil {
 		if requeue {
-			return ctrl.Result{RequeueAfter: DefaultRequeueInterval}, err
+			return ctrl.Result{RequeueAfter: config.RequeueAfter()}, err
 		}
 
 		return ctrl.Result{}, err
 	}
 
 	if requeue {
-		return ctrl.Result{RequeueAfter: DefaultRequeueInterval}, nil
+		return ctrl.Result{RequeueAfter: config.RequeueAfter()}, nil
 	}
 
 	if !fluxconditions.IsReady(hr) {
-		return ctrl.Result{RequeueAfter: DefaultRequeueInterval}, nil
+		return ctrl.Result{RequeueAfter: config.RequeueAfter()}, nil
 	}
 
 	return ctrl.Result{}, nil
 }
 
+// recordUpgrade records the UpgradeStarted Event if the existing HelmRelease
+// of the given ClusterDeployment refers to a chart other than the one of the given ClusterTemplate.
+func (r *ClusterDeploymentReconciler) recordUpgrade(ctx context.Context, mc *kcm.ClusterDeployment, clusterTpl *kcm.ClusterTemplate) error {
+	hr := &hcv2.HelmRelease{}
+	if err := r.Client.Get(ctx, client.ObjectKeyFromObject(mc), hr); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+
+	if hr.Spec.ChartRef == nil || clusterTpl.Status.ChartRef == nil || hr.Spec.ChartRef.Name == clusterTpl.Status.ChartRef.Name {
+		return nil
+	}
+
+	r.recorder.Eventf(mc, corev1.EventTypeNormal, EventReasonUpgradeStarted, "Upgrading the cluster from the chart %s to the chart %s of the ClusterTemplate %s",
+		hr.Spec.ChartRef.Name, clusterTpl.Status.ChartRef.Name, clusterTpl.Name)
+
+	mgmt := &kcm.Management{}
+	if err := r.Client.Get(ctx, client.ObjectKey{Name: kcm.ManagementName}, mgmt); err != nil {
+		return fmt.Errorf("failed to get Management: %w", err)
+	}
+	if err := telemetry.TrackClusterDeploymentUpgrade(string(mgmt.UID), string(mc.UID), hr.Spec.ChartRef.Name, clusterTpl.Name); err != nil {
+		ctrl.LoggerFrom(ctx).Error(err, "Failed to track ClusterDeployment upgrade")
+	}
+
+	return nil
+}
+
+// inspectionCache keeps the last inspections of the adopted clusters keyed by the UIDs of their
+// ClusterDeployments, the values of the imported Helm releases are not stored in the status.
+type inspectionCache struct {
+	mu      sync.Mutex
+	entries map[types.UID]cachedInspection
+}
+
+type cachedInspection struct {
+	inspection *adoption.Inspection
+	// key identifies the ClusterDeployment spec and the kubeconfig the cluster was inspected with
+	key string
+}
+
+// get returns the inspection of the cluster made with the given key within the given interval.
+func (c *inspectionCache) get(uid types.UID, key string, interval time.Duration) (*adoption.Inspection, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cached, ok := c.entries[uid]
+	if !ok || cached.key != key || time.Since(cached.inspection.Inventory.LastInspectionTime.Time) >= interval {
+		return nil, false
+	}
+	return cached.inspection, true
+}
+
+func (c *inspectionCache) set(uid types.UID, key string, inspection *adoption.Inspection) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.entries == nil {
+		c.entries = make(map[types.UID]cachedInspection)
+	}
+	c.entries[uid] = cachedInspection{inspection: inspection, key: key}
+}
+
+func (c *inspectionCache) delete(uid types.UID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.entries, uid)
+}
+
+// inspectAdoptedCluster connects to the adopted cluster with the kubeconfig referenced by the Credential
+// and sets the discovered inventory, the Kubernetes version and the conflicts of the planned services
+// with the installed Helm releases on the given ClusterDeployment. It does nothing for the other clusters.
+// The cluster is inspected again once the ClusterDeployment spec, the Credential or the kubeconfig Secret
+// is changed, otherwise the last inspection is reused within the configured inspection interval.
+func (r *ClusterDeploymentReconciler) inspectAdoptedCluster(ctx context.Context, mc *kcm.ClusterDeployment, clusterTpl *kcm.ClusterTemplate) (_ *adoption.Inspection, err error) {
+	if !adoption.IsAdoptedClusterTemplate(clusterTpl) {
+		r.inspections.delete(mc.UID)
+		mc.Status.Inventory = nil
+		apimeta.RemoveStatusCondition(mc.GetConditions(), kcm.ClusterInspectedCondition)
+		apimeta.RemoveStatusCondition(mc.GetConditions(), kcm.ServiceReleasesConflictCondition)
+		return nil, nil
+	}
+
+	l := ctrl.LoggerFrom(ctx)
+
+	defer func() {
+		if err != nil {
+			apimeta.SetStatusCondition(mc.GetConditions(), metav1.Condition{
+				Type:    kcm.ClusterInspectedCondition,
+				Status:  metav1.ConditionFalse,
+				Reason:  kcm.FailedReason,
+				Message: err.Error(),
+			})
+		}
+	}()
+
+	cred := &kcm.Credential{}
+	if err := r.Client.Get(ctx, client.ObjectKey{Name: mc.Spec.Credential, Namespace: mc.Namespace}, cred); err != nil {
+		return nil, fmt.Errorf("failed to get Credential: %w", err)
+	}
+
+	if cred.Spec.IdentityRef == nil {
+		return nil, fmt.Errorf("the Credential %s/%s does not reference the kubeconfig Secret", cred.Namespace, cred.Name)
+	}
+
+	secret := &corev1.Secret{}
+	secretRef := client.ObjectKey{Name: cred.Spec.IdentityRef.Name, Namespace: cmp.Or(cred.Spec.IdentityRef.Namespace, mc.Namespace)}
+	if err := r.Client.Get(ctx, secretRef, secret); err != nil {
+		return nil, fmt.Errorf("failed to get the kubeconfig Secret %s: %w", secretRef, err)
+	}
+
+	key := fmt.Sprintf("%d/%s/%s", mc.Generation, cred.ResourceVersion, secret.ResourceVersion)
+	inspection, ok := r.inspections.get(mc.UID, key, config.InspectionInterval())
+	if !ok {
+		l.Info("Inspecting the adopted cluster")
+
+		cfg, err := adoption.RESTConfigFromSecret(secret)
+		if err != nil {
+			return nil, err
+		}
+
+		if inspection, err = adoption.Inspect(ctx, cfg); err != nil {
+			return nil, err
+		}
+		r.inspections.set(mc.UID, key, inspection)
+	}
+
+	// the imported releases are marked in the same inventory later on
+	mc.Status.Inventory = &inspection.Inventory
+	mc.Status.KubernetesVersion = inspection.Inventory.KubernetesVersion
+
+	apimeta.SetStatusCondition(mc.GetConditions(), metav1.Condition{
+		Type:   kcm.ClusterInspectedCondition,
+		Status: metav1.ConditionTrue,
+		Reason: kcm.SucceededReason,
+		Message: fmt.Sprintf("Found %d/%d Ready nodes and %d Helm releases",
+			inspection.Inventory.ReadyNodes, inspection.Inventory.Nodes, len(inspection.Inventory.HelmReleases)),
+	})
+
+	managed := func(namespace, name string) bool {
+		for _, svcStatus := range mc.Status.Services {
+			if apimeta.FindStatusCondition(svcStatus.Conditions, sveltos.HelmReleaseReadyConditionType(namespace, name)) != nil {
+				return true
+			}
+		}
+		return false
+	}
+
+	conflicts := adoption.FindConflicts(&inspection.Inventory, mc.Spec.ServiceSpec.Services, managed)
+	if len(conflicts) == 0 {
+		apimeta.RemoveStatusCondition(mc.GetConditions(), kcm.ServiceReleasesConflictCondition)
+		return inspection, nil
+	}
+
+	l.Info("Some of the services conflict with the existing Helm releases", "conflicts", conflicts)
+	// the condition is a warning only, hence it does not affect the readiness
+	apimeta.SetStatusCondition(mc.GetConditions(), metav1.Condition{
+		Type:    kcm.ServiceReleasesConflictCondition,
+		Status:  metav1.ConditionTrue,
+		Reason:  kcm.ConflictReason,
+		Message: strings.Join(conflicts, "; "),
+	})
+
+	return inspection, nil
+}
+
+// validateHelmChart downloads the chart of the artifact and validates it
+// with the values of the ClusterDeployment with a client-only dry-run install.
+func (r *ClusterDeploymentReconciler) validateHelmChart(ctx context.Context, mc *kcm.ClusterDeployment, artifact *sourcev1.Artifact) error {
+	l := ctrl.LoggerFrom(ctx)
+
+	l.Info("Downloading Helm chart")
+	hcChart, err := r.DownloadChartFromArtifact(ctx, artifact)
+	if err != nil {
+		return fmt.Errorf("failed to download helm chart: %w", err)
+	}
+
+	l.Info("Initializing Helm client")
+	actionConfig, err := r.InitializeConfiguration(mc, l.Info)
+	if err != nil {
+		return fmt.Errorf("failed to initialize helm client: %w", err)
+	}
+
+	l.Info("Validating Helm chart with provided values")
+	if err := r.EnsureReleaseWithValues(ctx, actionConfig, hcChart, mc); err != nil {
+		return fmt.Errorf("failed to validate template with provided configuration: %w", err)
+	}
+
+	return nil
+}
+
+// validatedInputsHash returns the hash of the inputs of the Helm chart validation:
+// the digest of the chart artifact, the effective values and the Credential.
+// It returns an empty string if the artifact digest is unknown.
+func validatedInputsHash(artifact *sourcev1.Artifact, mc *kcm.ClusterDeployment) string {
+	if artifact == nil || artifact.Digest == "" {
+		return ""
+	}
+
+	hash := sha256.New()
+	for _, input := range []string{artifact.Digest, mc.Status.EffectiveValuesHash, mc.Spec.Credential} {
+		hash.Write([]byte(input))
+		hash.Write([]byte{0})
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// applyDefaults deep-merges the ClusterDeploymentDefaults selecting the given ClusterDeployment
+// under its spec.config and, if any of them apply, records the effective values along with their sources
+// in the <name>-effective-values ConfigMap. The merged values are set on the given object only,
+// the ClusterDeployment spec is never updated.
+func (r *ClusterDeploymentReconciler) applyDefaults(ctx context.Context, mc *kcm.ClusterDeployment) error {
+	defaultsList := &kcm.ClusterDeploymentDefaultsList{}
+	if err := r.Client.List(ctx, defaultsList, client.InNamespace(mc.Namespace)); err != nil {
+		return fmt.Errorf("failed to list ClusterDeploymentDefaults: %w", err)
+	}
+
+	var matched []kcm.ClusterDeploymentDefaults
+	for _, defaults := range defaultsList.Items {
+		ok, err := defaults.Matches(mc.Labels)
+		if err != nil {
+			return fmt.Errorf("invalid selector of the ClusterDeploymentDefaults %s: %w", defaults.Name, err)
+		}
+		if ok {
+			matched = append(matched, defaults)
+		}
+	}
+	slices.SortFunc(matched, func(a, b kcm.ClusterDeploymentDefaults) int {
+		return cmp.Or(cmp.Compare(a.Spec.Priority, b.Spec.Priority), cmp.Compare(a.Name, b.Name))
+	})
+
+	layers := make([]utils.ValuesLayer, 0, len(matched)+1)
+	appliedDefaults := make([]string, 0, len(matched))
+	for _, defaults := range matched {
+		var values map[string]any
+		if defaults.Spec.Config != nil {
+			if err := yaml.Unmarshal(defaults.Spec.Config.Raw, &values); err != nil {
+				return fmt.Errorf("failed to unmarshal the config of the ClusterDeploymentDefaults %s: %w", defaults.Name, err)
+			}
+		}
+		layers = append(layers, utils.ValuesLayer{Source: kcm.ClusterDeploymentDefaultsKind + "/" + defaults.Name, Values: values})
+		appliedDefaults = append(appliedDefaults, defaults.Name)
+	}
+
+	config, err := mc.HelmValues()
+	if err != nil {
+		return err
+	}
+	layers = append(layers, utils.ValuesLayer{Source: kcm.ClusterDeploymentConfigSource, Values: config})
+
+	values, sources := utils.MergeValues(layers...)
+	if len(matched) > 0 {
+		if err := mc.SetHelmValues(values); err != nil {
+			return err
+		}
+	}
+
+	valuesYAML, err := yaml.Marshal(values)
+	if err != nil {
+		return fmt.Errorf("failed to marshal the effective values: %w", err)
+	}
+	sourcesYAML, err := yaml.Marshal(sources)
+	if err != nil {
+		return fmt.Errorf("failed to marshal the effective values sources: %w", err)
+	}
+
+	if err := r.recordEffectiveValues(ctx, mc, len(matched) > 0, valuesYAML, sourcesYAML); err != nil {
+		return err
+	}
+
+	hash := sha256.Sum256(valuesYAML)
+	mc.Status.EffectiveValuesHash = hex.EncodeToString(hash[:])
+	mc.Status.AppliedDefaults = appliedDefaults
+	if len(appliedDefaults) == 0 {
+		mc.Status.AppliedDefaults = nil
+	}
+
+	return nil
+}
+
+// recordEffectiveValues creates or updates the <name>-effective-values ConfigMap if any
+// ClusterDeploymentDefaults apply to the given ClusterDeployment, otherwise the ConfigMap is deleted.
+// The ConfigMap is labeled as managed by KCM since only such ConfigMaps are cached.
+func (r *ClusterDeploymentReconciler) recordEffectiveValues(ctx context.Context, mc *kcm.ClusterDeployment, defaultsApplied bool, valuesYAML, sourcesYAML []byte) error {
+	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+		Name:      mc.Name + kcm.EffectiveValuesConfigMapSuffix,
+		Namespace: mc.Namespace,
+	}}
+
+	if !defaultsApplied {
+		if err := r.Client.Delete(ctx, cm); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("failed to delete the effective values ConfigMap %s: %w", client.ObjectKeyFromObject(cm), err)
+		}
+		return nil
+	}
+
+	mutate := func() error {
+		if cm.Labels == nil {
+			cm.Labels = make(map[string]string)
+		}
+		cm.Labels[kcm.KCMManagedLabelKey] = kcm.KCMManagedLabelValue
+		cm.OwnerReferences = []metav1.OwnerReference{{
+			APIVersion: kcm.GroupVersion.String(),
+			Kind:       kcm.ClusterDeploymentKind,
+			Name:       mc.Name,
+			UID:        mc.UID,
+		}}
+		cm.Data = map[string]string{
+			kcm.EffectiveValuesKey:        string(valuesYAML),
+			kcm.EffectiveValuesSourcesKey: string(sourcesYAML),
+		}
+		return nil
+	}
+
+	_, err := ctrl.CreateOrUpdate(ctx, r.Client, cm, mutate)
+	if apierrors.IsAlreadyExists(err) {
+		// the ConfigMap recorded before the label was introduced is invisible to the cache, adopt it
+		_ = mutate()
+		err = r.Client.Patch(ctx, cm, client.Merge)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to record the effective values: %w", err)
+	}
+
+	return nil
+}
+
 func (r *ClusterDeploymentReconciler) aggregateCapoConditions(ctx context.Context, clusterDeployment *kcm.ClusterDeployment) (requeue bool, _ error) {
 	type objectToCheck struct {
 		gvr        schema.GroupVersionResource
@@ -464,8 +841,12 @@ func getProjectPolicyRefs(mc *kcm.ClusterDeployment, cred *kcm.Credential) []sve
 	}
 }
 
-// updateServices reconciles services provided in ClusterDeployment.Spec.Services.
-func (r *ClusterDeploymentReconciler) updateServices(ctx context.Context, mc *kcm.ClusterDeployment) (_ ctrl.Result, err error) {
+// updateServices reconciles services provided in ClusterDeployment.Spec.Services
+// along with the Helm releases imported from the inspected adopted cluster if requested.
+func (r *ClusterDeploymentReconciler) updateServices(ctx context.Context, mc *kcm.ClusterDeployment, inspection *adoption.Inspection) (_ ctrl.Result, err error) {
+	ctx, span := tracing.Start(ctx, "ClusterDeploymentReconciler.updateServices")
+	defer func() { tracing.End(span, err) }()
+
 	l := ctrl.LoggerFrom(ctx)
 	l.Info("Reconciling Services")
 
@@ -502,7 +883,33 @@ func (r *ClusterDeploymentReconciler) updateServices(ctx context.Context, mc *kc
 		err = errors.Join(err, servicesErr)
 	}()
 
-	opts, err := sveltos.GetHelmChartOpts(ctx, r.Client, mc.Namespace, mc.Spec.ServiceSpec.Services)
+	services := mc.Spec.ServiceSpec.Services
+	if inspection != nil && mc.Spec.Adoption != nil && mc.Spec.Adoption.ImportHelmReleases {
+		templates := &kcm.ServiceTemplateList{}
+		if err = r.Client.List(ctx, templates, client.InNamespace(mc.Namespace)); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to list ServiceTemplates: %w", err)
+		}
+
+		imported, err := inspection.ImportServices(services, templates.Items)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		services = append(slices.Clone(services), imported...)
+	}
+
+	if mc.Spec.Autoscaling != nil && mc.Spec.Autoscaling.Autoscaler != nil {
+		autoscaler, err := autoscalerService(mc, mc.Spec.Autoscaling.Autoscaler)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		services = append(slices.Clone(services), autoscaler)
+	}
+	autoscalerPolicyRefs, err := r.reconcileAutoscalerAccess(ctx, mc)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	opts, err := sveltos.GetHelmChartOpts(ctx, r.Client, mc.Namespace, services)
 	if err != nil {
 		return ctrl.Result{}, err
 	}
@@ -537,7 +944,7 @@ func (r *ClusterDeploymentReconciler) updateServices(ctx context.Context, mc *kc
 			TemplateResourceRefs: append(
 				getProjectTemplateResourceRefs(mc, cred), mc.Spec.ServiceSpec.TemplateResourceRefs...,
 			),
-			PolicyRefs:      getProjectPolicyRefs(mc, cred),
+			PolicyRefs:      append(getProjectPolicyRefs(mc, cred), autoscalerPolicyRefs...),
 			SyncMode:        mc.Spec.ServiceSpec.SyncMode,
 			DriftIgnore:     mc.Spec.ServiceSpec.DriftIgnore,
 			DriftExclusions: mc.Spec.ServiceSpec.DriftExclusions,
@@ -558,30 +965,62 @@ func (r *ClusterDeploymentReconciler) updateServices(ctx context.Context, mc *kc
 	}
 
 	var servicesStatus []kcm.ServiceStatus
+	previousServicesStatus := slices.Clone(mc.Status.Services)
 	servicesStatus, servicesErr = updateServicesStatus(ctx, r.Client, profileRef, profile.Status.MatchingClusterRefs, mc.Status.Services)
 	if servicesErr != nil {
 		return ctrl.Result{}, nil
 	}
+	recordServiceEvents(r.recorder, mc, previousServicesStatus, servicesStatus)
 	mc.Status.Services = servicesStatus
 	l.Info("Successfully updated status of services")
 
 	return ctrl.Result{}, nil
 }
 
-// updateStatus updates the status for the ClusterDeployment object.
-func (r *ClusterDeploymentReconciler) updateStatus(ctx context.Context, clusterDeployment *kcm.ClusterDeployment, template *kcm.ClusterTemplate) error {
+// updateStatus updates the status for the ClusterDeployment object, the transitions of the conditions
+// since the given previous ones are appended to the condition history. It returns the time left until
+// the end-of-life of the template, zero if the end-of-life is not set or has already been reached.
+func (r *ClusterDeploymentReconciler) updateStatus(ctx context.Context, clusterDeployment *kcm.ClusterDeployment, template *kcm.ClusterTemplate, previousConditions []metav1.Condition) (time.Duration, error) {
+	wasReady := apimeta.IsStatusConditionTrue(clusterDeployment.Status.Conditions, kcm.ReadyCondition)
+
 	clusterDeployment.Status.ObservedGeneration = clusterDeployment.Generation
 	clusterDeployment.Status.Conditions = updateStatusConditions(clusterDeployment.Status.Conditions, "ClusterDeployment is ready")
 
 	if err := r.setAvailableUpgrades(ctx, clusterDeployment, template); err != nil {
-		return errors.New("failed to set available upgrades")
+		return 0, errors.New("failed to set available upgrades")
+	}
+
+	endOfLifeIn, err := r.setTemplateDeprecation(ctx, clusterDeployment, template)
+	if err != nil {
+		return 0, fmt.Errorf("failed to set template deprecation: %w", err)
+	}
+
+	if err := r.setNodePools(ctx, clusterDeployment); err != nil {
+		return 0, fmt.Errorf("failed to set node pools: %w", err)
+	}
+
+	// the provisioning duration is observed only once the ClusterDeployment becomes Ready
+	// for the first time, the time is persisted so the restarts of the controller do not count
+	firstReady := false
+	if clusterDeployment.Status.FirstReadyTime == nil {
+		if ready := apimeta.FindStatusCondition(clusterDeployment.Status.Conditions, kcm.ReadyCondition); ready != nil && ready.Status == metav1.ConditionTrue {
+			clusterDeployment.Status.FirstReadyTime = ready.LastTransitionTime.DeepCopy()
+			firstReady = !wasReady
+		}
 	}
 
+	transitions := status.ConditionTransitions(previousConditions, clusterDeployment.Status.Conditions, metav1.Now())
+	clusterDeployment.Status.ConditionHistory = status.AppendConditionHistory(clusterDeployment.Status.ConditionHistory, transitions, kcm.ConditionHistoryLimit)
+
 	if err := r.Client.Status().Update(ctx, clusterDeployment); err != nil {
-		return fmt.Errorf("failed to update status for clusterDeployment %s/%s: %w", clusterDeployment.Namespace, clusterDeployment.Name, err)
+		return 0, fmt.Errorf("failed to update status for clusterDeployment %s/%s: %w", clusterDeployment.Namespace, clusterDeployment.Name, err)
 	}
 
-	return nil
+	recordConditionEvents(r.recorder, clusterDeployment, transitions)
+
+	metrics.TrackClusterDeployment(clusterDeployment, template.Status.Providers, firstReady)
+
+	return endOfLifeIn, nil
 }
 
 func (r *ClusterDeploymentReconciler) getSource(ctx context.Context, ref *hcv2.CrossNamespaceSourceReference) (sourcev1.Source, error) {
@@ -596,7 +1035,10 @@ func (r *ClusterDeploymentReconciler) getSource(ctx context.Context, ref *hcv2.C
 	return &hc, nil
 }
 
-func (r *ClusterDeploymentReconciler) Delete(ctx context.Context, clusterDeployment *kcm.ClusterDeployment) (ctrl.Result, error) {
+func (r *ClusterDeploymentReconciler) Delete(ctx context.Context, clusterDeployment *kcm.ClusterDeployment) (_ ctrl.Result, err error) {
+	ctx, span := tracing.Start(ctx, "ClusterDeploymentReconciler.Delete")
+	defer func() { tracing.End(span, err) }()
+
 	l := ctrl.LoggerFrom(ctx)
 
 	hr := &hcv2.HelmRelease{}
@@ -612,10 +1054,19 @@ func (r *ClusterDeploymentReconciler) Delete(ctx context.Context, clusterDeploym
 				return ctrl.Result{}, fmt.Errorf("failed to update clusterDeployment %s/%s: %w", clusterDeployment.Namespace, clusterDeployment.Name, err)
 			}
 		}
+		metrics.DeleteClusterDeployment(clusterDeployment.Namespace, clusterDeployment.Name)
+		metrics.DeleteServices(clusterDeployment.Namespace, clusterDeployment.Name)
+		r.inspections.delete(clusterDeployment.UID)
+		r.trackDelete(ctx, clusterDeployment)
 		l.Info("ClusterDeployment deleted")
 		return ctrl.Result{}, nil
 	}
 
+	// cluster-autoscaler must not scale the machines of the cluster being deleted
+	if err := r.revokeAutoscalerAccess(ctx, clusterDeployment); err != nil {
+		return ctrl.Result{}, err
+	}
+
 	if err := helm.DeleteHelmRelease(ctx, r.Client, clusterDeployment.Name, clusterDeployment.Namespace); err != nil {
 		return ctrl.Result{}, err
 	}
@@ -634,7 +1085,24 @@ func (r *ClusterDeploymentReconciler) Delete(ctx context.Context, clusterDeploym
 	}
 
 	l.Info("HelmRelease still exists, retrying")
-	return ctrl.Result{RequeueAfter: DefaultRequeueInterval}, nil
+	return ctrl.Result{RequeueAfter: config.RequeueAfter()}, nil
+}
+
+// trackDelete tracks the deletion of the ClusterDeployment unless the whole Management is being removed.
+func (r *ClusterDeploymentReconciler) trackDelete(ctx context.Context, clusterDeployment *kcm.ClusterDeployment) {
+	l := ctrl.LoggerFrom(ctx)
+
+	mgmt := &kcm.Management{}
+	if err := r.Client.Get(ctx, client.ObjectKey{Name: kcm.ManagementName}, mgmt); err != nil {
+		if !apierrors.IsNotFound(err) {
+			l.Error(err, "Failed to get Management object")
+		}
+		return
+	}
+
+	if err := telemetry.TrackClusterDeploymentDelete(string(mgmt.UID), string(clusterDeployment.UID), clusterDeployment.Spec.Template); err != nil {
+		l.Error(err, "Failed to track ClusterDeployment deletion")
+	}
 }
 
 func (r *ClusterDeploymentReconciler) releaseCluster(ctx context.Context, namespace, name, templateName string) error {
@@ -648,6 +1116,12 @@ func (r *ClusterDeploymentReconciler) releaseCluster(ctx context.Context, namesp
 		Version: "v1beta1",
 		Kind:    "Machine",
 	}
+	// the workers of the managed clusters, e.g. GKE, are the MachinePools without the Machines
+	gvkMachinePool := schema.GroupVersionKind{
+		Group:   "cluster.x-k8s.io",
+		Version: "v1beta1",
+		Kind:    "MachinePool",
+	}
 
 	// Associate the provider with it's GVK
 	for _, provider := range providers {
@@ -668,6 +1142,16 @@ func (r *ClusterDeploymentReconciler) releaseCluster(ctx context.Context, namesp
 		if err != nil {
 			continue
 		}
+		if !found {
+			found, err = r.objectsAvailable(ctx, namespace, cluster.Name, gvkMachinePool)
+			if apimeta.IsNoMatchError(err) {
+				// the MachinePools are disabled in Cluster API
+				found, err = false, nil
+			}
+			if err != nil {
+				continue
+			}
+		}
 
 		if !found {
 			return r.removeClusterFinalizer(ctx, cluster)
@@ -760,31 +1244,141 @@ func (r *ClusterDeploymentReconciler) setAvailableUpgrades(ctx context.Context,
 
 	availableUpgradesMap := make(map[string]kcm.AvailableUpgrade)
 	for _, chain := range chains.Items {
-		for _, supportedTemplate := range chain.Spec.SupportedTemplates {
-			if supportedTemplate.Name == template.Name {
-				for _, availableUpgrade := range supportedTemplate.AvailableUpgrades {
-					availableUpgradesMap[availableUpgrade.Name] = availableUpgrade
-				}
-			}
+		for _, availableUpgrade := range kcm.AvailableUpgradesFor(&chain.Spec, &chain.Status, template.Name) {
+			availableUpgradesMap[availableUpgrade.Name] = availableUpgrade
 		}
 	}
 	availableUpgrades := make([]string, 0, len(availableUpgradesMap))
 	for _, availableUpgrade := range availableUpgradesMap {
 		availableUpgrades = append(availableUpgrades, availableUpgrade.Name)
 	}
+	slices.Sort(availableUpgrades)
 
 	clusterDeployment.Status.AvailableUpgrades = availableUpgrades
 	return nil
 }
 
+// setTemplateDeprecation sets the TemplateDeprecated condition pointing to the recommended
+// upgrade path if the given ClusterTemplate is deprecated, otherwise removes the condition.
+// It returns the time left until the end-of-life of the template if it has not been reached yet.
+func (r *ClusterDeploymentReconciler) setTemplateDeprecation(ctx context.Context, clusterDeployment *kcm.ClusterDeployment, template *kcm.ClusterTemplate) (endOfLifeIn time.Duration, _ error) {
+	if template == nil || template.Name == "" {
+		return 0, nil
+	}
+
+	lifecycle, err := utils.GetTemplateLifecycle(ctx, r.Client, template)
+	if err != nil {
+		return 0, err
+	}
+
+	if !lifecycle.IsDeprecated() {
+		apimeta.RemoveStatusCondition(clusterDeployment.GetConditions(), kcm.TemplateDeprecatedCondition)
+		return 0, nil
+	}
+
+	now := time.Now()
+	reason := kcm.DeprecatedReason
+	if lifecycle.IsEndOfLife(now) {
+		reason = kcm.EndOfLifeReason
+	} else if lifecycle.EndOfLife != nil {
+		endOfLifeIn = lifecycle.EndOfLife.Sub(now)
+	}
+
+	upgrades := slices.Clone(clusterDeployment.Status.AvailableUpgrades)
+	slices.Sort(upgrades)
+
+	msg := utils.TemplateLifecycleMessage(kcm.ClusterTemplateKind, template.Name, lifecycle, now)
+	switch {
+	case lifecycle.Replacement != "" && slices.Contains(upgrades, lifecycle.Replacement):
+		msg += ", which is available as an upgrade"
+	case len(upgrades) > 0:
+		msg += ". Available upgrades: " + strings.Join(upgrades, ", ")
+	default:
+		msg += ". No upgrades are available"
+	}
+
+	apimeta.SetStatusCondition(clusterDeployment.GetConditions(), metav1.Condition{
+		Type:    kcm.TemplateDeprecatedCondition,
+		Status:  metav1.ConditionTrue,
+		Reason:  reason,
+		Message: msg,
+	})
+
+	return endOfLifeIn, nil
+}
+
+// setNodePools sets the status of the NodePools attached to the given ClusterDeployment.
+func (r *ClusterDeploymentReconciler) setNodePools(ctx context.Context, clusterDeployment *kcm.ClusterDeployment) error {
+	nodePools := &kcm.NodePoolList{}
+	if err := r.Client.List(ctx, nodePools,
+		client.InNamespace(clusterDeployment.Namespace),
+		client.MatchingFields{kcm.NodePoolClusterDeploymentIndexKey: clusterDeployment.Name},
+	); err != nil {
+		return err
+	}
+
+	summaries := make([]kcm.NodePoolSummary, 0, len(nodePools.Items))
+	for _, nodePool := range nodePools.Items {
+		summaries = append(summaries, kcm.NodePoolSummary{
+			Name:          nodePool.Name,
+			Replicas:      nodePool.Status.Replicas,
+			ReadyReplicas: nodePool.Status.ReadyReplicas,
+			Ready:         apimeta.IsStatusConditionTrue(nodePool.Status.Conditions, kcm.NodePoolReadyCondition),
+		})
+	}
+	slices.SortFunc(summaries, func(a, b kcm.NodePoolSummary) int { return cmp.Compare(a.Name, b.Name) })
+
+	clusterDeployment.Status.NodePools = summaries
+	return nil
+}
+
+// enqueueClusterDeploymentsForChains enqueues the ClusterDeployments
+// referencing any of the templates managed by the given chains.
+func (r *ClusterDeploymentReconciler) enqueueClusterDeploymentsForChains(ctx context.Context, q workqueue.TypedRateLimitingInterface[ctrl.Request], objs ...client.Object) {
+	var templates []string
+	namespace := ""
+	for _, o := range objs {
+		chain, ok := o.(*kcm.ClusterTemplateChain)
+		if !ok {
+			continue
+		}
+		namespace = chain.Namespace
+		templates = append(templates, getTemplateNamesManagedByChain(chain)...)
+	}
+	slices.Sort(templates)
+
+	for _, template := range slices.Compact(templates) {
+		clusterDeployments := &kcm.ClusterDeploymentList{}
+		if err := r.Client.List(ctx, clusterDeployments,
+			client.InNamespace(namespace),
+			client.MatchingFields{kcm.ClusterDeploymentTemplateIndexKey: template},
+		); err != nil {
+			ctrl.LoggerFrom(ctx).Error(err, "failed to list ClusterDeployments", "template", template)
+			continue
+		}
+		for _, cluster := range clusterDeployments.Items {
+			q.Add(ctrl.Request{NamespacedName: client.ObjectKeyFromObject(&cluster)})
+		}
+	}
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *ClusterDeploymentReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	r.Client = mgr.GetClient()
 	r.Config = mgr.GetConfig()
+	r.recorder = mgr.GetEventRecorderFor("clusterdeployment-controller")
 
 	r.helmActor = helm.NewActor(r.Config, r.Client.RESTMapper())
 
-	return ctrl.NewControllerManagedBy(mgr).
+	opts := config.ControllerOptions("ClusterDeployment")
+	managedBy := ctrl.NewControllerManagedBy(mgr)
+	if r.Sharding != nil {
+		opts.NeedLeaderElection = ptr.To(false)
+		managedBy = managedBy.WatchesRawSource(source.Channel(r.Sharding.Events(), &handler.EnqueueRequestForObject{}))
+	}
+
+	return managedBy.
+		WithOptions(opts).
 		For(&kcm.ClusterDeployment{}).
 		Watches(&hcv2.HelmRelease{},
 			handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, o client.Object) []ctrl.Request {
@@ -796,38 +1390,57 @@ func (r *ClusterDeploymentReconciler) SetupWithManager(mgr ctrl.Manager) error {
 				return []ctrl.Request{{NamespacedName: clusterDeploymentRef}}
 			}),
 		).
-		Watches(&kcm.ClusterTemplateChain{},
+		Watches(&kcm.ClusterTemplateChain{}, handler.Funcs{
+			// the lifecycles and the upgrades defined by the chain are reflected in the
+			// status of the ClusterDeployments, including the ones of the removed templates
+			CreateFunc: func(ctx context.Context, e event.CreateEvent, q workqueue.TypedRateLimitingInterface[ctrl.Request]) {
+				r.enqueueClusterDeploymentsForChains(ctx, q, e.Object)
+			},
+			UpdateFunc: func(ctx context.Context, e event.UpdateEvent, q workqueue.TypedRateLimitingInterface[ctrl.Request]) {
+				r.enqueueClusterDeploymentsForChains(ctx, q, e.ObjectOld, e.ObjectNew)
+			},
+			DeleteFunc: func(ctx context.Context, e event.DeleteEvent, q workqueue.TypedRateLimitingInterface[ctrl.Request]) {
+				r.enqueueClusterDeploymentsForChains(ctx, q, e.Object)
+			},
+		}).
+		Watches(&kcm.ClusterDeploymentDefaults{},
 			handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, o client.Object) []ctrl.Request {
-				chain, ok := o.(*kcm.ClusterTemplateChain)
-				if !ok {
-					return nil
+				// enqueue all of the ClusterDeployments in the namespace to handle the deselected ones as well
+				clusterDeployments := &kcm.ClusterDeploymentList{}
+				if err := r.Client.List(ctx, clusterDeployments, client.InNamespace(o.GetNamespace())); err != nil {
+					return []ctrl.Request{}
 				}
 
-				var req []ctrl.Request
-				for _, template := range getTemplateNamesManagedByChain(chain) {
-					clusterDeployments := &kcm.ClusterDeploymentList{}
-					err := r.Client.List(ctx, clusterDeployments,
-						client.InNamespace(chain.Namespace),
-						client.MatchingFields{kcm.ClusterDeploymentTemplateIndexKey: template})
-					if err != nil {
-						return []ctrl.Request{}
-					}
-					for _, cluster := range clusterDeployments.Items {
-						req = append(req, ctrl.Request{
-							NamespacedName: client.ObjectKey{
-								Namespace: cluster.Namespace,
-								Name:      cluster.Name,
-							},
-						})
-					}
+				req := make([]ctrl.Request, 0, len(clusterDeployments.Items))
+				for _, cluster := range clusterDeployments.Items {
+					req = append(req, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(&cluster)})
 				}
+
 				return req
 			}),
 			builder.WithPredicates(predicate.Funcs{
-				UpdateFunc:  func(event.UpdateEvent) bool { return false },
 				GenericFunc: func(event.GenericEvent) bool { return false },
 			}),
 		).
+		Watches(&kcm.ClusterTemplate{},
+			handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, o client.Object) []ctrl.Request {
+				clusterDeployments := &kcm.ClusterDeploymentList{}
+				err := r.Client.List(ctx, clusterDeployments,
+					client.InNamespace(o.GetNamespace()),
+					client.MatchingFields{kcm.ClusterDeploymentTemplateIndexKey: o.GetName()})
+				if err != nil {
+					return []ctrl.Request{}
+				}
+
+				req := make([]ctrl.Request, 0, len(clusterDeployments.Items))
+				for _, cluster := range clusterDeployments.Items {
+					req = append(req, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(&cluster)})
+				}
+
+				return req
+			}),
+			builder.WithPredicates(predicate.GenerationChangedPredicate{}),
+		).
 		Watches(&sveltosv1beta1.ClusterSummary{},
 			handler.EnqueueRequestsFromMapFunc(requeueSveltosProfileForClusterSummary),
 			builder.WithPredicates(predicate.Funcs{
@@ -858,5 +1471,18 @@ func (r *ClusterDeploymentReconciler) SetupWithManager(mgr ctrl.Manager) error {
 				return req
 			}),
 		).
-		Complete(r)
+		Watches(&kcm.NodePool{},
+			handler.EnqueueRequestsFromMapFunc(func(_ context.Context, o client.Object) []ctrl.Request {
+				nodePool, ok := o.(*kcm.NodePool)
+				if !ok {
+					return nil
+				}
+
+				return []ctrl.Request{{NamespacedName: client.ObjectKey{Namespace: nodePool.Namespace, Name: nodePool.Spec.ClusterDeployment}}}
+			}),
+			builder.WithPredicates(predicate.Funcs{
+				GenericFunc: func(event.GenericEvent) bool { return false },
+			}),
+		).
+		Complete(tracing.Reconciler("ClusterDeployment", r))
 }
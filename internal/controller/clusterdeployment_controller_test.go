@@ -16,6 +16,7 @@ package controller
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	hcv2 "github.com/fluxcd/helm-controller/api/v2"
@@ -27,21 +28,33 @@ import (
 	"helm.sh/helm/v3/pkg/chart"
 	corev1 "k8s.io/api/core/v1"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
 	clusterapiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	. "sigs.k8s.io/controller-runtime/pkg/envtest/komega"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	kcm "github.com/K0rdent/kcm/api/v1alpha1"
+	"github.com/K0rdent/kcm/internal/adoption"
+	"github.com/K0rdent/kcm/internal/config"
 )
 
-type fakeHelmActor struct{}
+type fakeHelmActor struct {
+	downloads int
+}
 
-func (*fakeHelmActor) DownloadChartFromArtifact(_ context.Context, _ *sourcev1.Artifact) (*chart.Chart, error) {
+func (f *fakeHelmActor) DownloadChartFromArtifact(_ context.Context, _ *sourcev1.Artifact) (*chart.Chart, error) {
+	f.downloads++
 	return &chart.Chart{
 		Metadata: &chart.Metadata{
 			APIVersion: "v2",
@@ -258,6 +271,7 @@ var _ = Describe("ClusterDeployment Controller", func() {
 				Client:    mgrClient,
 				helmActor: &fakeHelmActor{},
 				Config:    &rest.Config{},
+				recorder:  &record.FakeRecorder{},
 			}
 
 			By("creating ClusterDeployment resource", func() {
@@ -357,11 +371,13 @@ var _ = Describe("ClusterDeployment Controller", func() {
 		})
 
 		It("should reconcile ClusterDeployment with AWS credentials", func() {
+			helmActor := &fakeHelmActor{}
 			controllerReconciler := &ClusterDeploymentReconciler{
 				Client:        mgrClient,
-				helmActor:     &fakeHelmActor{},
+				helmActor:     helmActor,
 				Config:        &rest.Config{},
 				DynamicClient: dynamicClient,
+				recorder:      &record.FakeRecorder{},
 			}
 
 			By("creating ClusterDeployment resource", func() {
@@ -403,6 +419,7 @@ var _ = Describe("ClusterDeployment Controller", func() {
 				clusterTemplateHelmChart.Status.URL = helmChartURL
 				clusterTemplateHelmChart.Status.Artifact = &sourcev1.Artifact{
 					URL:            helmChartURL,
+					Digest:         "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
 					LastUpdateTime: metav1.Now(),
 				}
 				Expect(k8sClient.Status().Update(ctx, &clusterTemplateHelmChart)).To(Succeed())
@@ -575,6 +592,20 @@ var _ = Describe("ClusterDeployment Controller", func() {
 						))))
 				}).Should(Succeed())
 			})
+
+			By("skipping the Helm chart validation when the inputs have not changed", func() {
+				Expect(Get(&clusterDeployment)()).To(Succeed())
+				validatedInputsHash := clusterDeployment.Status.ValidatedInputsHash
+				Expect(validatedInputsHash).NotTo(BeEmpty())
+
+				helmActor.downloads = 0
+				_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
+					NamespacedName: client.ObjectKeyFromObject(&clusterDeployment),
+				})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(helmActor.downloads).To(BeZero())
+				Expect(Object(&clusterDeployment)()).Should(HaveField("Status.ValidatedInputsHash", validatedInputsHash))
+			})
 		})
 
 		// TODO (#852 brongineer): Add tests for ClusterDeployment reconciliation with other providers' credentials
@@ -588,3 +619,215 @@ var _ = Describe("ClusterDeployment Controller", func() {
 		})
 	})
 })
+
+var _ = Describe("ClusterDeployment cluster release", func() {
+	const (
+		namespace             = "default"
+		clusterDeploymentName = "test-gke"
+	)
+
+	It("should keep the cluster until its MachinePools are deleted", func() {
+		ctx := context.Background()
+
+		infraCluster := &unstructured.Unstructured{}
+		infraCluster.SetAPIVersion("infrastructure.cluster.x-k8s.io/v1beta1")
+		infraCluster.SetKind("GCPManagedCluster")
+		infraCluster.SetNamespace(namespace)
+		infraCluster.SetName(clusterDeploymentName)
+		infraCluster.SetLabels(map[string]string{kcm.FluxHelmChartNameKey: clusterDeploymentName})
+		infraCluster.SetFinalizers([]string{kcm.BlockingFinalizer})
+
+		machinePool := &unstructured.Unstructured{}
+		machinePool.SetAPIVersion("cluster.x-k8s.io/v1beta1")
+		machinePool.SetKind("MachinePool")
+		machinePool.SetNamespace(namespace)
+		machinePool.SetName(clusterDeploymentName + "-mp")
+		machinePool.SetLabels(map[string]string{kcm.ClusterNameLabelKey: clusterDeploymentName})
+
+		// the infrastructure clusters and the MachinePools are not in the scheme
+		testScheme := runtime.NewScheme()
+		Expect(kcm.AddToScheme(testScheme)).To(Succeed())
+		Expect(clusterapiv1beta1.AddToScheme(testScheme)).To(Succeed())
+		for _, gvk := range []schema.GroupVersionKind{
+			{Group: "infrastructure.cluster.x-k8s.io", Version: "v1beta1", Kind: "GCPCluster"},
+			infraCluster.GroupVersionKind(),
+			machinePool.GroupVersionKind(),
+		} {
+			testScheme.AddKnownTypeWithName(gvk, &unstructured.Unstructured{})
+			testScheme.AddKnownTypeWithName(gvk.GroupVersion().WithKind(gvk.Kind+"List"), &unstructured.UnstructuredList{})
+		}
+
+		fakeClient := fake.NewClientBuilder().
+			WithScheme(testScheme).
+			WithObjects(
+				&kcm.ClusterTemplate{
+					ObjectMeta: metav1.ObjectMeta{Name: "gcp-gke-0-1-0", Namespace: namespace},
+					Status: kcm.ClusterTemplateStatus{
+						Providers: kcm.Providers{"infrastructure-gcp"},
+					},
+				},
+				infraCluster,
+				machinePool,
+			).
+			Build()
+		reconciler := &ClusterDeploymentReconciler{Client: fakeClient}
+
+		finalizers := func() []string {
+			Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(infraCluster), infraCluster)).To(Succeed())
+			return infraCluster.GetFinalizers()
+		}
+
+		Expect(reconciler.releaseCluster(ctx, namespace, clusterDeploymentName, "gcp-gke-0-1-0")).To(Succeed())
+		Expect(finalizers()).To(ContainElement(kcm.BlockingFinalizer))
+
+		Expect(fakeClient.Delete(ctx, machinePool)).To(Succeed())
+		Expect(reconciler.releaseCluster(ctx, namespace, clusterDeploymentName, "gcp-gke-0-1-0")).To(Succeed())
+		Expect(finalizers()).NotTo(ContainElement(kcm.BlockingFinalizer))
+	})
+})
+
+var _ = Describe("ClusterDeployment adopted cluster inspection", func() {
+	const (
+		namespace             = "default"
+		clusterDeploymentName = "adopted"
+	)
+
+	It("should reuse the last inspection until the spec is changed or the interval elapses", func() {
+		ctx := context.Background()
+
+		clusterTemplate := &kcm.ClusterTemplate{
+			ObjectMeta: metav1.ObjectMeta{Name: "adopted-cluster-0-1-0", Namespace: namespace},
+			Status:     kcm.ClusterTemplateStatus{Providers: kcm.Providers{"infrastructure-internal"}},
+		}
+		cred := &kcm.Credential{
+			ObjectMeta: metav1.ObjectMeta{Name: "adopted-cred", Namespace: namespace},
+			Spec:       kcm.CredentialSpec{IdentityRef: &corev1.ObjectReference{Name: "adopted-kubeconfig"}},
+		}
+		// the cluster is unreachable, so any new inspection fails
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "adopted-kubeconfig", Namespace: namespace},
+			Data: map[string][]byte{"value": []byte(`apiVersion: v1
+kind: Config
+clusters:
+- name: adopted
+  cluster:
+    server: https://127.0.0.1:1
+contexts:
+- name: adopted
+  context:
+    cluster: adopted
+current-context: adopted
+`)},
+		}
+		clusterDeployment := &kcm.ClusterDeployment{
+			ObjectMeta: metav1.ObjectMeta{Name: clusterDeploymentName, Namespace: namespace, UID: "cd-uid", Generation: 1},
+			Spec:       kcm.ClusterDeploymentSpec{Template: clusterTemplate.Name, Credential: cred.Name},
+		}
+
+		fakeClient := fake.NewClientBuilder().
+			WithScheme(scheme.Scheme).
+			WithObjects(clusterTemplate, cred, secret).
+			Build()
+		reconciler := &ClusterDeploymentReconciler{Client: fakeClient}
+
+		Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(cred), cred)).To(Succeed())
+		Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(secret), secret)).To(Succeed())
+		key := fmt.Sprintf("%d/%s/%s", clusterDeployment.Generation, cred.ResourceVersion, secret.ResourceVersion)
+		cached := &adoption.Inspection{Inventory: kcm.ClusterInventory{
+			LastInspectionTime: metav1.Now(),
+			KubernetesVersion:  "v1.31.1",
+			Nodes:              3,
+			ReadyNodes:         3,
+		}}
+		reconciler.inspections.set(clusterDeployment.UID, key, cached)
+
+		inspection, err := reconciler.inspectAdoptedCluster(ctx, clusterDeployment, clusterTemplate)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(inspection).To(BeIdenticalTo(cached))
+		Expect(clusterDeployment.Status.KubernetesVersion).To(Equal("v1.31.1"))
+		Expect(meta.IsStatusConditionTrue(clusterDeployment.Status.Conditions, kcm.ClusterInspectedCondition)).To(BeTrue())
+
+		By("re-inspecting the cluster once the spec is changed")
+		clusterDeployment.Generation++
+		_, err = reconciler.inspectAdoptedCluster(ctx, clusterDeployment, clusterTemplate)
+		Expect(err).To(HaveOccurred())
+		Expect(meta.IsStatusConditionFalse(clusterDeployment.Status.Conditions, kcm.ClusterInspectedCondition)).To(BeTrue())
+
+		By("re-inspecting the cluster once the interval elapses")
+		clusterDeployment.Generation--
+		cached.Inventory.LastInspectionTime = metav1.NewTime(time.Now().Add(-config.InspectionInterval()))
+		_, err = reconciler.inspectAdoptedCluster(ctx, clusterDeployment, clusterTemplate)
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("ClusterDeployment template deprecation", func() {
+	It("should return the time left until the end-of-life of the template", func() {
+		ctx := context.Background()
+
+		template := &kcm.ClusterTemplate{
+			ObjectMeta: metav1.ObjectMeta{Name: "aws-standalone-cp-0-0-1", Namespace: "default"},
+			Spec: kcm.ClusterTemplateSpec{
+				Lifecycle: &kcm.TemplateLifecycle{EndOfLife: &metav1.Time{Time: time.Now().Add(time.Hour)}},
+			},
+		}
+		clusterDeployment := &kcm.ClusterDeployment{ObjectMeta: metav1.ObjectMeta{Name: "test-cd", Namespace: "default"}}
+
+		fakeClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(template).Build()
+		reconciler := &ClusterDeploymentReconciler{Client: fakeClient}
+
+		endOfLifeIn, err := reconciler.setTemplateDeprecation(ctx, clusterDeployment, template)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(endOfLifeIn).To(BeNumerically("~", time.Hour, time.Minute))
+		Expect(meta.FindStatusCondition(clusterDeployment.Status.Conditions, kcm.TemplateDeprecatedCondition)).To(HaveField("Reason", kcm.DeprecatedReason))
+
+		By("reporting the end-of-life once it is reached")
+		template.Spec.Lifecycle.EndOfLife = &metav1.Time{Time: time.Now().Add(-time.Minute)}
+		endOfLifeIn, err = reconciler.setTemplateDeprecation(ctx, clusterDeployment, template)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(endOfLifeIn).To(BeZero())
+		Expect(meta.FindStatusCondition(clusterDeployment.Status.Conditions, kcm.TemplateDeprecatedCondition)).To(HaveField("Reason", kcm.EndOfLifeReason))
+	})
+})
+
+var _ = Describe("ClusterDeployment effective values", func() {
+	It("should record the effective values only if any defaults apply", func() {
+		ctx := context.Background()
+
+		clusterDeployment := &kcm.ClusterDeployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-cd", Namespace: "default", Labels: map[string]string{"env": "dev"}},
+			Spec:       kcm.ClusterDeploymentSpec{Config: &apiextensionsv1.JSON{Raw: []byte(`{"workersNumber":2}`)}},
+		}
+		defaults := &kcm.ClusterDeploymentDefaults{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-defaults", Namespace: "default"},
+			Spec: kcm.ClusterDeploymentDefaultsSpec{
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"env": "prod"}},
+				Config:   &apiextensionsv1.JSON{Raw: []byte(`{"controlPlaneNumber":3}`)},
+			},
+		}
+
+		fakeClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(defaults).Build()
+		reconciler := &ClusterDeploymentReconciler{Client: fakeClient}
+		key := client.ObjectKey{Namespace: "default", Name: clusterDeployment.Name + kcm.EffectiveValuesConfigMapSuffix}
+
+		Expect(reconciler.applyDefaults(ctx, clusterDeployment)).To(Succeed())
+		Expect(clusterDeployment.Status.AppliedDefaults).To(BeEmpty())
+		Expect(clusterDeployment.Status.EffectiveValuesHash).NotTo(BeEmpty())
+		Expect(apierrors.IsNotFound(fakeClient.Get(ctx, key, &corev1.ConfigMap{}))).To(BeTrue())
+
+		By("recording the effective values once the defaults apply")
+		clusterDeployment.Labels["env"] = "prod"
+		Expect(reconciler.applyDefaults(ctx, clusterDeployment)).To(Succeed())
+		Expect(clusterDeployment.Status.AppliedDefaults).To(Equal([]string{"test-defaults"}))
+		cm := &corev1.ConfigMap{}
+		Expect(fakeClient.Get(ctx, key, cm)).To(Succeed())
+		Expect(cm.Labels).To(HaveKeyWithValue(kcm.KCMManagedLabelKey, kcm.KCMManagedLabelValue))
+		Expect(cm.Data).To(HaveKeyWithValue(kcm.EffectiveValuesKey, "controlPlaneNumber: 3\nworkersNumber: 2\n"))
+
+		By("deleting the effective values once the defaults no longer apply")
+		clusterDeployment.Labels["env"] = "dev"
+		Expect(reconciler.applyDefaults(ctx, clusterDeployment)).To(Succeed())
+		Expect(clusterDeployment.Status.AppliedDefaults).To(BeEmpty())
+		Expect(apierrors.IsNotFound(fakeClient.Get(ctx, key, &corev1.ConfigMap{}))).To(BeTrue())
+	})
+})
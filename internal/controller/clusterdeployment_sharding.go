@@ -0,0 +1,77 @@
+// Copyright 2024
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	kcm "github.com/K0rdent/kcm/api/v1alpha1"
+	"github.com/K0rdent/kcm/internal/config"
+	"github.com/K0rdent/kcm/internal/sharding"
+)
+
+// ShardedClusterDeploymentStarter sets up the sharded ClusterDeployment controller
+// on every replica once the Sveltos provider is installed. Without the sharding
+// the controller is set up by the leader in the ManagementReconciler.
+type ShardedClusterDeploymentStarter struct {
+	Manager         manager.Manager
+	Sharding        *sharding.Manager
+	SystemNamespace string
+}
+
+// NeedLeaderElection implements the manager.LeaderElectionRunnable interface.
+func (*ShardedClusterDeploymentStarter) NeedLeaderElection() bool {
+	return false
+}
+
+// Start implements the manager.Runnable interface.
+func (s *ShardedClusterDeploymentStarter) Start(ctx context.Context) error {
+	l := log.FromContext(ctx).WithName("sharded-clusterdeployment-starter")
+
+	if err := wait.PollUntilContextCancel(ctx, config.Current().Requeue.Interval.Duration, true, func(ctx context.Context) (bool, error) {
+		management := new(kcm.Management)
+		if err := s.Manager.GetClient().Get(ctx, client.ObjectKey{Name: kcm.ManagementName}, management); err != nil {
+			l.V(1).Info("Waiting for the Management object", "error", err.Error())
+			return false, nil
+		}
+		return management.Status.Components[kcm.ProviderSveltosName].Success, nil
+	}); err != nil {
+		// the manager is stopping
+		return nil
+	}
+
+	dc, err := dynamic.NewForConfig(s.Manager.GetConfig())
+	if err != nil {
+		return fmt.Errorf("failed to create dynamic client: %w", err)
+	}
+
+	l.Info(fmt.Sprintf("Provider %s has been successfully installed, so setting up sharded controller for ClusterDeployment", kcm.ProviderSveltosName))
+	if err := (&ClusterDeploymentReconciler{
+		DynamicClient:   dc,
+		Sharding:        s.Sharding,
+		SystemNamespace: s.SystemNamespace,
+	}).SetupWithManager(s.Manager); err != nil {
+		return fmt.Errorf("failed to setup controller for ClusterDeployment: %w", err)
+	}
+
+	return nil
+}
@@ -20,14 +20,19 @@ import (
 	"fmt"
 	"time"
 
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	apimeta "k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	kcm "github.com/K0rdent/kcm/api/v1alpha1"
+	"github.com/K0rdent/kcm/internal/config"
+	"github.com/K0rdent/kcm/internal/metrics"
+	"github.com/K0rdent/kcm/internal/tracing"
 	"github.com/K0rdent/kcm/internal/utils"
 )
 
@@ -36,6 +41,8 @@ type CredentialReconciler struct {
 	client.Client
 	SystemNamespace string
 	syncPeriod      time.Duration
+
+	recorder record.EventRecorder
 }
 
 func (r *CredentialReconciler) Reconcile(ctx context.Context, req ctrl.Request) (_ ctrl.Result, err error) {
@@ -53,6 +60,9 @@ func (r *CredentialReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 
 	cred := &kcm.Credential{}
 	if err := r.Client.Get(ctx, req.NamespacedName, cred); err != nil {
+		if apierrors.IsNotFound(err) {
+			metrics.DeleteCredential(req.Namespace, req.Name)
+		}
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 
@@ -84,6 +94,9 @@ func (r *CredentialReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 				cred.Spec.IdentityRef.Kind, cred.Spec.IdentityRef.Namespace, cred.Spec.IdentityRef.Name)
 		}
 
+		if !apimeta.IsStatusConditionFalse(cred.Status.Conditions, kcm.CredentialReadyCondition) {
+			r.recorder.Event(cred, corev1.EventTypeWarning, EventReasonCredentialNotReady, errMsg)
+		}
 		apimeta.SetStatusCondition(cred.GetConditions(), metav1.Condition{
 			Type:    kcm.CredentialReadyCondition,
 			Status:  metav1.ConditionFalse,
@@ -117,14 +130,18 @@ func (r *CredentialReconciler) updateStatus(ctx context.Context, cred *kcm.Crede
 		return fmt.Errorf("failed to update Credential %s/%s status: %w", cred.Namespace, cred.Name, err)
 	}
 
+	metrics.TrackCredential(cred.Namespace, cred.Name, cred.Status.Ready)
+
 	return nil
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *CredentialReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	r.syncPeriod = 15 * time.Minute
+	r.recorder = mgr.GetEventRecorderFor("credential-controller")
 
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&kcm.Credential{}).
-		Complete(r)
+		WithOptions(config.ControllerOptions("Credential")).
+		Complete(tracing.Reconciler("Credential", r))
 }
@@ -0,0 +1,109 @@
+// Copyright 2024
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"cmp"
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+
+	kcm "github.com/K0rdent/kcm/api/v1alpha1"
+)
+
+// Reasons of the Events recorded by the controllers.
+const (
+	// EventReasonTemplateInvalid is recorded when a template or the template
+	// referenced by an object turns invalid.
+	EventReasonTemplateInvalid = "TemplateInvalid"
+	// EventReasonHelmReleaseFailed is recorded when a HelmRelease fails to be reconciled or installed.
+	EventReasonHelmReleaseFailed = "HelmReleaseFailed"
+	// EventReasonCredentialNotReady is recorded when a Credential or the Credential
+	// referenced by an object turns not ready.
+	EventReasonCredentialNotReady = "CredentialNotReady"
+	// EventReasonServiceFailed is recorded when a service fails to be deployed on a cluster.
+	EventReasonServiceFailed = "ServiceFailed"
+	// EventReasonUpgradeStarted is recorded when an object starts being upgraded to another template or release.
+	EventReasonUpgradeStarted = "UpgradeStarted"
+
+	// EventReasonTemplatesCreationFailed is recorded when the templates of a Release fail to be created.
+	EventReasonTemplatesCreationFailed = "TemplatesCreationFailed"
+	// EventReasonTemplateChainInvalid is recorded when the upgrade graph of a template chain turns invalid.
+	EventReasonTemplateChainInvalid = "TemplateChainInvalid"
+	// EventReasonAccessRulesFailed is recorded when the access rules of the AccessManagement fail to be applied.
+	EventReasonAccessRulesFailed = "AccessRulesFailed"
+	// EventReasonBackupCompleted is recorded when a backup of a ManagementBackup completes successfully.
+	EventReasonBackupCompleted = "BackupCompleted"
+	// EventReasonBackupFailed is recorded when a backup of a ManagementBackup fails or cannot be created.
+	EventReasonBackupFailed = "BackupFailed"
+	// EventReasonProviderAdded is recorded when the provider of a ProviderDefinition is added to the Management.
+	EventReasonProviderAdded = "ProviderAdded"
+	// EventReasonProviderRemoved is recorded when the provider of a ProviderDefinition is removed from the Management.
+	EventReasonProviderRemoved = "ProviderRemoved"
+
+	// EventReasonAccessGranted is recorded when the access requested by a ClusterAccessRequest is granted.
+	EventReasonAccessGranted = "AccessGranted"
+	// EventReasonAccessExpired is recorded when the access granted to a ClusterAccessRequest expires.
+	EventReasonAccessExpired = "AccessExpired"
+	// EventReasonAccessRevoked is recorded when the access of a deleted ClusterAccessRequest is revoked.
+	EventReasonAccessRevoked = "AccessRevoked"
+)
+
+// failedConditionEventReasons maps the condition types to the reasons
+// of the Events recorded once the conditions turn False.
+var failedConditionEventReasons = map[string]string{
+	kcm.TemplateReadyCondition:    EventReasonTemplateInvalid,
+	kcm.HelmReleaseReadyCondition: EventReasonHelmReleaseFailed,
+	kcm.CredentialReadyCondition:  EventReasonCredentialNotReady,
+	kcm.TemplatesValidCondition:   EventReasonTemplateInvalid,
+	kcm.TemplatesCreatedCondition: EventReasonTemplatesCreationFailed,
+}
+
+// recordConditionEvents records the Warning Events for the given transitions
+// of the conditions of the object to the False status.
+func recordConditionEvents(recorder record.EventRecorder, obj runtime.Object, transitions []kcm.ConditionTransition) {
+	for _, t := range transitions {
+		reason, ok := failedConditionEventReasons[t.Type]
+		if !ok || t.Status != metav1.ConditionFalse {
+			continue
+		}
+		recorder.Event(obj, corev1.EventTypeWarning, reason, cmp.Or(t.Message, t.Type+" is False"))
+	}
+}
+
+// recordServiceEvents records the Warning Events for the services
+// of the object which have failed since the previous services status.
+func recordServiceEvents(recorder record.EventRecorder, obj runtime.Object, previous, current []kcm.ServiceStatus) {
+	for _, svcStatus := range current {
+		var prevConditions []metav1.Condition
+		if idx := slices.IndexFunc(previous, func(s kcm.ServiceStatus) bool {
+			return s.ClusterName == svcStatus.ClusterName && s.ClusterNamespace == svcStatus.ClusterNamespace
+		}); idx >= 0 {
+			prevConditions = previous[idx].Conditions
+		}
+
+		for _, c := range svcStatus.Conditions {
+			if c.Status != metav1.ConditionFalse || apimeta.IsStatusConditionFalse(prevConditions, c.Type) {
+				continue
+			}
+			recorder.Eventf(obj, corev1.EventTypeWarning, EventReasonServiceFailed, "Failed to deploy services on the cluster %s/%s: %s",
+				svcStatus.ClusterNamespace, svcStatus.ClusterName, cmp.Or(c.Message, c.Type))
+		}
+	}
+}
@@ -15,17 +15,26 @@
 package controller
 
 import (
+	"cmp"
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	kcmv1alpha1 "github.com/K0rdent/kcm/api/v1alpha1"
+	"github.com/K0rdent/kcm/internal/config"
 	"github.com/K0rdent/kcm/internal/controller/backup"
+	"github.com/K0rdent/kcm/internal/metrics"
+	"github.com/K0rdent/kcm/internal/tracing"
 )
 
 // ManagementBackupReconciler reconciles a ManagementBackup object
@@ -33,6 +42,7 @@ type ManagementBackupReconciler struct {
 	client.Client
 
 	internal *backup.Reconciler
+	recorder record.EventRecorder
 
 	SystemNamespace string
 }
@@ -51,17 +61,47 @@ func (r *ManagementBackupReconciler) Reconcile(ctx context.Context, req ctrl.Req
 
 	mgmtBackup := new(kcmv1alpha1.ManagementBackup)
 	if err := r.Client.Get(ctx, req.NamespacedName, mgmtBackup); err != nil {
+		if apierrors.IsNotFound(err) {
+			metrics.DeleteBackup(req.Name)
+		}
 		l.Error(err, "unable to fetch ManagementBackup")
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 
+	previousStatus := mgmtBackup.Status.DeepCopy()
 	res, err := r.internal.ReconcileBackup(ctx, mgmtBackup)
 	if err != nil {
 		l.Error(err, "failed to reconcile managementbackups")
 	}
+	r.recordBackupEvents(mgmtBackup, previousStatus)
 	return res, err
 }
 
+// recordBackupEvents records the Events for the error of the ManagementBackup
+// and the outcome of its last backup which have changed since the given previous status.
+func (r *ManagementBackupReconciler) recordBackupEvents(mgmtBackup *kcmv1alpha1.ManagementBackup, previous *kcmv1alpha1.ManagementBackupStatus) {
+	if mgmtBackup.Status.Error != "" && mgmtBackup.Status.Error != previous.Error {
+		r.recorder.Event(mgmtBackup, corev1.EventTypeWarning, EventReasonBackupFailed, mgmtBackup.Status.Error)
+	}
+
+	last := mgmtBackup.Status.LastBackup
+	if last == nil || (previous.LastBackup != nil && previous.LastBackup.Phase == last.Phase && previous.LastBackupName == mgmtBackup.Status.LastBackupName) {
+		return
+	}
+
+	backupName := cmp.Or(mgmtBackup.Status.LastBackupName, mgmtBackup.Name)
+	switch last.Phase {
+	case velerov1.BackupPhaseCompleted:
+		r.recorder.Eventf(mgmtBackup, corev1.EventTypeNormal, EventReasonBackupCompleted, "Backup %s has completed", backupName)
+	case velerov1.BackupPhaseFailed, velerov1.BackupPhasePartiallyFailed, velerov1.BackupPhaseFailedValidation:
+		msg := fmt.Sprintf("Backup %s has finished in the %s phase", backupName, last.Phase)
+		if reason := cmp.Or(last.FailureReason, strings.Join(last.ValidationErrors, "; ")); reason != "" {
+			msg += ": " + reason
+		}
+		r.recorder.Event(mgmtBackup, corev1.EventTypeWarning, EventReasonBackupFailed, msg)
+	}
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *ManagementBackupReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	const scheduleSyncTime = 1 * time.Minute
@@ -74,10 +114,12 @@ func (r *ManagementBackupReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	}
 
 	r.internal = backup.NewReconciler(r.Client, mgr.GetScheme(), r.SystemNamespace)
+	r.recorder = mgr.GetEventRecorderFor("managementbackup-controller")
 
 	return ctrl.NewControllerManagedBy(mgr).
 		Named("mgmtbackup_controller").
 		For(&kcmv1alpha1.ManagementBackup{}).
 		WatchesRawSource(source.Channel(runner.GetEventChannel(), &handler.EnqueueRequestForObject{})).
-		Complete(r)
+		WithOptions(config.ControllerOptions("ManagementBackup")).
+		Complete(tracing.Reconciler("ManagementBackup", r))
 }
@@ -17,9 +17,11 @@ package controller
 import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 
 	hmcmirantiscomv1alpha1 "github.com/K0rdent/kcm/api/v1alpha1"
 )
@@ -60,7 +62,8 @@ var _ = Describe("Backup Controller", func() {
 		It("should successfully reconcile the resource", func() {
 			By("Reconciling the created resource")
 			controllerReconciler := &ManagementBackupReconciler{
-				Client: k8sClient,
+				Client:   k8sClient,
+				recorder: &record.FakeRecorder{},
 			}
 			_ = controllerReconciler
 
@@ -71,3 +74,30 @@ var _ = Describe("Backup Controller", func() {
 		})
 	})
 })
+
+var _ = Describe("Backup Controller events", func() {
+	It("should record the outcome of the last backup once", func() {
+		recorder := record.NewFakeRecorder(10)
+		reconciler := &ManagementBackupReconciler{recorder: recorder}
+
+		mgmtBackup := &hmcmirantiscomv1alpha1.ManagementBackup{ObjectMeta: metav1.ObjectMeta{Name: "daily"}}
+		mgmtBackup.Status.LastBackupName = "daily-20260101000000"
+		mgmtBackup.Status.LastBackup = &velerov1.BackupStatus{Phase: velerov1.BackupPhaseInProgress}
+		previous := mgmtBackup.Status.DeepCopy()
+
+		mgmtBackup.Status.LastBackup = &velerov1.BackupStatus{Phase: velerov1.BackupPhaseFailed, FailureReason: "no storage location"}
+		reconciler.recordBackupEvents(mgmtBackup, previous)
+		Expect(recorder.Events).To(Receive(Equal("Warning " + EventReasonBackupFailed +
+			" Backup daily-20260101000000 has finished in the Failed phase: no storage location")))
+
+		reconciler.recordBackupEvents(mgmtBackup, mgmtBackup.Status.DeepCopy())
+		Expect(recorder.Events).NotTo(Receive())
+
+		By("recording the completion of the next backup")
+		previous = mgmtBackup.Status.DeepCopy()
+		mgmtBackup.Status.LastBackupName = "daily-20260102000000"
+		mgmtBackup.Status.LastBackup = &velerov1.BackupStatus{Phase: velerov1.BackupPhaseCompleted}
+		reconciler.recordBackupEvents(mgmtBackup, previous)
+		Expect(recorder.Events).To(Receive(Equal("Normal " + EventReasonBackupCompleted + " Backup daily-20260102000000 has completed")))
+	})
+})
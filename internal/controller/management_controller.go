@@ -28,8 +28,10 @@ import (
 	fluxmeta "github.com/fluxcd/pkg/apis/meta"
 	fluxconditions "github.com/fluxcd/pkg/runtime/conditions"
 	sourcev1 "github.com/fluxcd/source-controller/api/v1"
+	"go.opentelemetry.io/otel/attribute"
 	"helm.sh/helm/v3/pkg/chartutil"
 	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
@@ -40,6 +42,7 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
@@ -47,11 +50,18 @@ import (
 
 	kcm "github.com/K0rdent/kcm/api/v1alpha1"
 	"github.com/K0rdent/kcm/internal/certmanager"
+	"github.com/K0rdent/kcm/internal/config"
 	"github.com/K0rdent/kcm/internal/helm"
+	"github.com/K0rdent/kcm/internal/metrics"
+	"github.com/K0rdent/kcm/internal/sharding"
+	"github.com/K0rdent/kcm/internal/tracing"
 	"github.com/K0rdent/kcm/internal/utils"
 	"github.com/K0rdent/kcm/internal/utils/status"
 )
 
+// errHelmReleaseFailed is returned if the HelmRelease of a component has failed to be installed or upgraded.
+var errHelmReleaseFailed = errors.New("HelmRelease has failed")
+
 // ManagementReconciler reconciles a Management object
 type ManagementReconciler struct {
 	Client                             client.Client
@@ -59,9 +69,13 @@ type ManagementReconciler struct {
 	Scheme                             *runtime.Scheme
 	Config                             *rest.Config
 	DynamicClient                      *dynamic.DynamicClient
+	Sharding                           *sharding.Manager
 	SystemNamespace                    string
 	CreateAccessManagement             bool
 	sveltosDependentControllersStarted bool
+	capiDependentControllersStarted    bool
+
+	recorder record.EventRecorder
 }
 
 func (r *ManagementReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -197,6 +211,9 @@ func (r *ManagementReconciler) Update(ctx context.Context, management *kcm.Manag
 			errMsg := fmt.Sprintf("Failed to reconcile HelmRelease %s/%s: %s", r.SystemNamespace, component.helmReleaseName, err)
 			updateComponentsStatus(statusAccumulator, component, nil, errMsg)
 			errs = errors.Join(errs, errors.New(errMsg))
+			if management.Status.Components[component.helmReleaseName].Error != errMsg {
+				r.recorder.Event(management, corev1.EventTypeWarning, EventReasonHelmReleaseFailed, errMsg)
+			}
 
 			continue
 		}
@@ -205,6 +222,9 @@ func (r *ManagementReconciler) Update(ctx context.Context, management *kcm.Manag
 			l.Info("Provider is not yet ready", "template", component.Template, "err", err)
 			requeue = true
 			updateComponentsStatus(statusAccumulator, component, nil, err.Error())
+			if errors.Is(err, errHelmReleaseFailed) && management.Status.Components[component.helmReleaseName].Error != err.Error() {
+				r.recorder.Event(management, corev1.EventTypeWarning, EventReasonHelmReleaseFailed, err.Error())
+			}
 			continue
 		}
 
@@ -215,6 +235,10 @@ func (r *ManagementReconciler) Update(ctx context.Context, management *kcm.Manag
 	management.Status.CAPIContracts = statusAccumulator.compatibilityContracts
 	management.Status.Components = statusAccumulator.components
 	management.Status.ObservedGeneration = management.Generation
+	if management.Status.Release != "" && management.Status.Release != management.Spec.Release {
+		r.recorder.Eventf(management, corev1.EventTypeNormal, EventReasonUpgradeStarted, "Upgrading the components from the Release %s to the Release %s",
+			management.Status.Release, management.Spec.Release)
+	}
 	management.Status.Release = management.Spec.Release
 
 	shouldRequeue, err := r.startDependentControllers(ctx, management)
@@ -225,7 +249,16 @@ func (r *ManagementReconciler) Update(ctx context.Context, management *kcm.Manag
 		requeue = true
 	}
 
+	shouldRequeue, err = r.startCAPIDependentControllers(ctx, management)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if shouldRequeue {
+		requeue = true
+	}
+
 	setReadyCondition(management)
+	metrics.TrackManagementComponents(management.Status.Components)
 
 	if err := r.Client.Status().Update(ctx, management); err != nil {
 		errs = errors.Join(errs, fmt.Errorf("failed to update status for Management %s: %w", management.Name, err))
@@ -236,7 +269,7 @@ func (r *ManagementReconciler) Update(ctx context.Context, management *kcm.Manag
 		return ctrl.Result{}, errs
 	}
 	if requeue {
-		return ctrl.Result{RequeueAfter: DefaultRequeueInterval}, nil
+		return ctrl.Result{RequeueAfter: config.RequeueAfter()}, nil
 	}
 
 	return ctrl.Result{}, nil
@@ -245,6 +278,9 @@ func (r *ManagementReconciler) Update(ctx context.Context, management *kcm.Manag
 // startDependentControllers starts controllers that cannot be started
 // at process startup because of some dependency like CRDs being present.
 func (r *ManagementReconciler) startDependentControllers(ctx context.Context, management *kcm.Management) (requue bool, err error) {
+	ctx, span := tracing.Start(ctx, "ManagementReconciler.startDependentControllers")
+	defer func() { tracing.End(span, err) }()
+
 	l := ctrl.LoggerFrom(ctx)
 
 	if r.sveltosDependentControllersStarted {
@@ -259,14 +295,17 @@ func (r *ManagementReconciler) startDependentControllers(ctx context.Context, ma
 
 	currentNamespace := utils.CurrentNamespace()
 
-	l.Info(fmt.Sprintf("Provider %s has been successfully installed, so setting up controller for ClusterDeployment", kcm.ProviderSveltosName))
-	if err = (&ClusterDeploymentReconciler{
-		DynamicClient:   r.DynamicClient,
-		SystemNamespace: currentNamespace,
-	}).SetupWithManager(r.Manager); err != nil {
-		return false, fmt.Errorf("failed to setup controller for ClusterDeployment: %w", err)
+	// the sharded ClusterDeployment controller is set up on every replica by the ShardedClusterDeploymentStarter
+	if r.Sharding == nil {
+		l.Info(fmt.Sprintf("Provider %s has been successfully installed, so setting up controller for ClusterDeployment", kcm.ProviderSveltosName))
+		if err = (&ClusterDeploymentReconciler{
+			DynamicClient:   r.DynamicClient,
+			SystemNamespace: currentNamespace,
+		}).SetupWithManager(r.Manager); err != nil {
+			return false, fmt.Errorf("failed to setup controller for ClusterDeployment: %w", err)
+		}
+		l.Info("Setup for ClusterDeployment controller successful")
 	}
-	l.Info("Setup for ClusterDeployment controller successful")
 
 	l.Info(fmt.Sprintf("Provider %s has been successfully installed, so setting up controller for MultiClusterService", kcm.ProviderSveltosName))
 	if err = (&MultiClusterServiceReconciler{
@@ -280,7 +319,38 @@ func (r *ManagementReconciler) startDependentControllers(ctx context.Context, ma
 	return false, nil
 }
 
-func (r *ManagementReconciler) cleanupRemovedComponents(ctx context.Context, management *kcm.Management) error {
+// startCAPIDependentControllers starts controllers that cannot be started
+// at process startup because the Cluster API CRDs are not present yet.
+func (r *ManagementReconciler) startCAPIDependentControllers(ctx context.Context, management *kcm.Management) (requeue bool, err error) {
+	ctx, span := tracing.Start(ctx, "ManagementReconciler.startCAPIDependentControllers")
+	defer func() { tracing.End(span, err) }()
+
+	l := ctrl.LoggerFrom(ctx)
+
+	if r.capiDependentControllersStarted {
+		// Only need to start controllers once.
+		return false, nil
+	}
+
+	if !management.Status.Components[kcm.CoreCAPIName].Success {
+		l.Info(fmt.Sprintf("Waiting for %s provider to be ready to setup contollers dependent on it", kcm.CoreCAPIName))
+		return true, nil
+	}
+
+	l.Info(fmt.Sprintf("Provider %s has been successfully installed, so setting up controller for NodePool", kcm.CoreCAPIName))
+	if err = new(NodePoolReconciler).SetupWithManager(r.Manager); err != nil {
+		return false, fmt.Errorf("failed to setup controller for NodePool: %w", err)
+	}
+	l.Info("Setup for NodePool controller successful")
+
+	r.capiDependentControllersStarted = true
+	return false, nil
+}
+
+func (r *ManagementReconciler) cleanupRemovedComponents(ctx context.Context, management *kcm.Management) (err error) {
+	ctx, span := tracing.Start(ctx, "ManagementReconciler.cleanupRemovedComponents")
+	defer func() { tracing.End(span, err) }()
+
 	var (
 		errs error
 		l    = ctrl.LoggerFrom(ctx)
@@ -321,7 +391,10 @@ func (r *ManagementReconciler) cleanupRemovedComponents(ctx context.Context, man
 	return errs
 }
 
-func (r *ManagementReconciler) ensureAccessManagement(ctx context.Context, mgmt *kcm.Management) error {
+func (r *ManagementReconciler) ensureAccessManagement(ctx context.Context, mgmt *kcm.Management) (err error) {
+	ctx, span := tracing.Start(ctx, "ManagementReconciler.ensureAccessManagement")
+	defer func() { tracing.End(span, err) }()
+
 	l := ctrl.LoggerFrom(ctx)
 	if !r.CreateAccessManagement {
 		return nil
@@ -340,7 +413,7 @@ func (r *ManagementReconciler) ensureAccessManagement(ctx context.Context, mgmt
 			},
 		},
 	}
-	err := r.Client.Get(ctx, client.ObjectKey{
+	err = r.Client.Get(ctx, client.ObjectKey{
 		Name: kcm.AccessManagementName,
 	}, amObj)
 	if err == nil {
@@ -361,10 +434,13 @@ func (r *ManagementReconciler) ensureAccessManagement(ctx context.Context, mgmt
 // checkProviderStatus checks the status of a provider associated with a given
 // ProviderTemplate name. Since there's no way to determine resource Kind from
 // the given template iterate over all possible provider types.
-func (r *ManagementReconciler) checkProviderStatus(ctx context.Context, component component) error {
+func (r *ManagementReconciler) checkProviderStatus(ctx context.Context, component component) (err error) {
+	ctx, span := tracing.Start(ctx, "ManagementReconciler.checkProviderStatus", attribute.String("kcm.component.name", component.helmReleaseName))
+	defer func() { tracing.End(span, err) }()
+
 	helmReleaseName := component.helmReleaseName
 	hr := &fluxv2.HelmRelease{}
-	err := r.Client.Get(ctx, types.NamespacedName{Namespace: r.SystemNamespace, Name: helmReleaseName}, hr)
+	err = r.Client.Get(ctx, types.NamespacedName{Namespace: r.SystemNamespace, Name: helmReleaseName}, hr)
 	if err != nil {
 		return fmt.Errorf("failed to check provider status: %w", err)
 	}
@@ -373,6 +449,9 @@ func (r *ManagementReconciler) checkProviderStatus(ctx context.Context, componen
 	if hrReadyCondition == nil || hrReadyCondition.ObservedGeneration != hr.Generation {
 		return fmt.Errorf("HelmRelease %s/%s Ready condition is not updated yet", r.SystemNamespace, helmReleaseName)
 	}
+	if fluxconditions.IsFalse(hr, fluxmeta.ReadyCondition) {
+		return fmt.Errorf("%w: %s/%s: %s", errHelmReleaseFailed, r.SystemNamespace, helmReleaseName, hrReadyCondition.Message)
+	}
 	if !fluxconditions.IsReady(hr) {
 		return fmt.Errorf("HelmRelease %s/%s is not yet ready: %s", r.SystemNamespace, helmReleaseName, hrReadyCondition.Message)
 	}
@@ -391,6 +470,7 @@ func (r *ManagementReconciler) checkProviderStatus(ctx context.Context, componen
 		"infrastructureproviders",
 		"controlplaneproviders",
 		"bootstrapproviders",
+		"ipamproviders",
 	} {
 		gvr := schema.GroupVersionResource{
 			Group:    "operator.cluster.x-k8s.io",
@@ -430,22 +510,25 @@ func (r *ManagementReconciler) checkProviderStatus(ctx context.Context, componen
 	return errs
 }
 
-func (r *ManagementReconciler) Delete(ctx context.Context, management *kcm.Management) (ctrl.Result, error) {
+func (r *ManagementReconciler) Delete(ctx context.Context, management *kcm.Management) (_ ctrl.Result, err error) {
+	ctx, span := tracing.Start(ctx, "ManagementReconciler.Delete")
+	defer func() { tracing.End(span, err) }()
+
 	l := ctrl.LoggerFrom(ctx)
 	listOpts := &client.ListOptions{
 		LabelSelector: labels.SelectorFromSet(map[string]string{kcm.KCMManagedLabelKey: kcm.KCMManagedLabelValue}),
 	}
 	requeue, err := r.removeHelmReleases(ctx, kcm.CoreKCMName, listOpts)
 	if err != nil || requeue {
-		return ctrl.Result{RequeueAfter: DefaultRequeueInterval}, err
+		return ctrl.Result{RequeueAfter: config.RequeueAfter()}, err
 	}
 	requeue, err = r.removeHelmCharts(ctx, listOpts)
 	if err != nil || requeue {
-		return ctrl.Result{RequeueAfter: DefaultRequeueInterval}, err
+		return ctrl.Result{RequeueAfter: config.RequeueAfter()}, err
 	}
 	requeue, err = r.removeHelmRepositories(ctx, listOpts)
 	if err != nil || requeue {
-		return ctrl.Result{RequeueAfter: DefaultRequeueInterval}, err
+		return ctrl.Result{RequeueAfter: config.RequeueAfter()}, err
 	}
 
 	// Removing finalizer in the end of cleanup
@@ -597,7 +680,10 @@ func getWrappedComponents(ctx context.Context, cl client.Client, mgmt *kcm.Manag
 
 // enableAdditionalComponents enables the admission controller and cluster api operator
 // once the cert manager is ready
-func (r *ManagementReconciler) enableAdditionalComponents(ctx context.Context, mgmt *kcm.Management) error {
+func (r *ManagementReconciler) enableAdditionalComponents(ctx context.Context, mgmt *kcm.Management) (err error) {
+	ctx, span := tracing.Start(ctx, "ManagementReconciler.enableAdditionalComponents")
+	defer func() { tracing.End(span, err) }()
+
 	l := ctrl.LoggerFrom(ctx)
 
 	kcmComponent := &mgmt.Spec.Core.KCM
@@ -671,7 +757,10 @@ func (r *ManagementReconciler) enableAdditionalComponents(ctx context.Context, m
 	return nil
 }
 
-func (r *ManagementReconciler) ensureUpgradeBackup(ctx context.Context, mgmt *kcm.Management) (requeue bool, _ error) {
+func (r *ManagementReconciler) ensureUpgradeBackup(ctx context.Context, mgmt *kcm.Management) (requeue bool, err error) {
+	ctx, span := tracing.Start(ctx, "ManagementReconciler.ensureUpgradeBackup")
+	defer func() { tracing.End(span, err) }()
+
 	if mgmt.Status.Release == "" {
 		return false, nil
 	}
@@ -817,6 +906,7 @@ func (r *ManagementReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	}
 
 	r.Manager = mgr
+	r.recorder = mgr.GetEventRecorderFor("management-controller")
 	r.Client = mgr.GetClient()
 	r.Scheme = mgr.GetScheme()
 	r.Config = mgr.GetConfig()
@@ -824,5 +914,6 @@ func (r *ManagementReconciler) SetupWithManager(mgr ctrl.Manager) error {
 
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&kcm.Management{}).
-		Complete(r)
+		WithOptions(config.ControllerOptions("Management")).
+		Complete(tracing.Reconciler("Management", r))
 }
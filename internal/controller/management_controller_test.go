@@ -29,6 +29,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	capioperator "sigs.k8s.io/cluster-api-operator/api/v1alpha2"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -78,8 +79,9 @@ var _ = Describe("Management Controller", func() {
 			// NOTE: this node just checks that the finalizer has been set
 			By("Reconciling the created resource")
 			controllerReconciler := &ManagementReconciler{
-				Client: k8sClient,
-				Scheme: k8sClient.Scheme(),
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				recorder: &record.FakeRecorder{},
 			}
 
 			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
@@ -281,6 +283,10 @@ var _ = Describe("Management Controller", func() {
 				Scheme:          k8sClient.Scheme(),
 				DynamicClient:   dynamicClient,
 				SystemNamespace: utils.DefaultSystemNamespace,
+				recorder:        &record.FakeRecorder{},
+
+				// the test reconciler runs without a manager
+				capiDependentControllersStarted: true,
 			}
 
 			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
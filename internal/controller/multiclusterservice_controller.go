@@ -27,6 +27,7 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	apimeta "k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -36,7 +37,10 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 
 	kcm "github.com/K0rdent/kcm/api/v1alpha1"
+	"github.com/K0rdent/kcm/internal/config"
+	"github.com/K0rdent/kcm/internal/metrics"
 	"github.com/K0rdent/kcm/internal/sveltos"
+	"github.com/K0rdent/kcm/internal/tracing"
 	"github.com/K0rdent/kcm/internal/utils"
 )
 
@@ -44,6 +48,8 @@ import (
 type MultiClusterServiceReconciler struct {
 	Client          client.Client
 	SystemNamespace string
+
+	recorder record.EventRecorder
 }
 
 // Reconcile reconciles a MultiClusterService object.
@@ -168,10 +174,12 @@ func (r *MultiClusterServiceReconciler) reconcileUpdate(ctx context.Context, mcs
 	}
 
 	var servicesStatus []kcm.ServiceStatus
+	previousServicesStatus := slices.Clone(mcs.Status.Services)
 	servicesStatus, servicesErr = updateServicesStatus(ctx, r.Client, profileRef, profile.Status.MatchingClusterRefs, mcs.Status.Services)
 	if servicesErr != nil {
 		return ctrl.Result{}, nil
 	}
+	recordServiceEvents(r.recorder, mcs, previousServicesStatus, servicesStatus)
 	mcs.Status.Services = servicesStatus
 
 	return ctrl.Result{}, nil
@@ -270,6 +278,8 @@ func updateServicesStatus(ctx context.Context, c client.Client, profileRef clien
 		servicesStatus[idx].Conditions = conditions
 	}
 
+	metrics.TrackServices(profileRef.Namespace, profileRef.Name, servicesStatus)
+
 	return servicesStatus, nil
 }
 
@@ -283,6 +293,7 @@ func (r *MultiClusterServiceReconciler) reconcileDelete(ctx context.Context, mcs
 			return ctrl.Result{}, fmt.Errorf("failed to remove finalizer %s from MultiClusterService %s: %w", kcm.MultiClusterServiceFinalizer, mcsvc.Name, err)
 		}
 	}
+	metrics.DeleteServices("", mcsvc.Name)
 
 	return ctrl.Result{}, nil
 }
@@ -321,6 +332,7 @@ func requeueSveltosProfileForClusterSummary(ctx context.Context, obj client.Obje
 // SetupWithManager sets up the controller with the Manager.
 func (r *MultiClusterServiceReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	r.Client = mgr.GetClient()
+	r.recorder = mgr.GetEventRecorderFor("multiclusterservice-controller")
 
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&kcm.MultiClusterService{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
@@ -331,5 +343,6 @@ func (r *MultiClusterServiceReconciler) SetupWithManager(mgr ctrl.Manager) error
 				GenericFunc: func(event.GenericEvent) bool { return false },
 			}),
 		).
-		Complete(r)
+		WithOptions(config.ControllerOptions("MultiClusterService")).
+		Complete(tracing.Reconciler("MultiClusterService", r))
 }
@@ -0,0 +1,616 @@
+// Copyright 2024
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/utils/ptr"
+	clusterapiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	expv1 "sigs.k8s.io/cluster-api/exp/api/v1beta1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+
+	kcm "github.com/K0rdent/kcm/api/v1alpha1"
+	"github.com/K0rdent/kcm/internal/config"
+	"github.com/K0rdent/kcm/internal/nodepool"
+	"github.com/K0rdent/kcm/internal/tracing"
+	"github.com/K0rdent/kcm/internal/utils"
+)
+
+// NodePoolReconciler reconciles a NodePool object
+type NodePoolReconciler struct {
+	Client client.Client
+}
+
+func (r *NodePoolReconciler) Reconcile(ctx context.Context, req ctrl.Request) (_ ctrl.Result, err error) {
+	l := ctrl.LoggerFrom(ctx)
+	l.Info("Reconciling NodePool")
+
+	nodePool := &kcm.NodePool{}
+	if err := r.Client.Get(ctx, req.NamespacedName, nodePool); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	if !nodePool.DeletionTimestamp.IsZero() {
+		// the rendered objects are owned by the NodePool and garbage collected along with it
+		l.Info("NodePool is being deleted, skipping")
+		return ctrl.Result{}, nil
+	}
+
+	defer func() {
+		err = errors.Join(err, r.updateStatus(ctx, nodePool))
+	}()
+
+	clusterDeployment := &kcm.ClusterDeployment{}
+	if err := r.Client.Get(ctx, client.ObjectKey{Namespace: nodePool.Namespace, Name: nodePool.Spec.ClusterDeployment}, clusterDeployment); err != nil {
+		errMsg := fmt.Sprintf("failed to get ClusterDeployment: %s", err)
+		if apierrors.IsNotFound(err) {
+			// the NodePool is enqueued once the ClusterDeployment is created
+			errMsg, err = fmt.Sprintf("ClusterDeployment %s is not found", nodePool.Spec.ClusterDeployment), nil
+		}
+		setNodePoolCondition(nodePool, metav1.ConditionFalse, kcm.FailedReason, errMsg)
+		return ctrl.Result{}, err
+	}
+
+	// the NodePool is removed along with its ClusterDeployment
+	if !slices.ContainsFunc(nodePool.OwnerReferences, func(ref metav1.OwnerReference) bool { return ref.UID == clusterDeployment.UID }) {
+		if err := controllerutil.SetOwnerReference(clusterDeployment, nodePool, r.Client.Scheme()); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to set owner reference on NodePool %s: %w", req, err)
+		}
+		if err := r.Client.Update(ctx, nodePool); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to update NodePool %s: %w", req, err)
+		}
+	}
+
+	// the NodePool is enqueued on changes of the ClusterDeployment and of its worker MachineDeployments
+	// and MachinePools, so neither the unsupported clusters nor the ones being rendered are requeued
+	if err := nodepool.CheckSupported(ctx, r.Client, nodePool, clusterDeployment); err != nil {
+		if errors.Is(err, nodepool.ErrUnsupportedCluster) {
+			setNodePoolCondition(nodePool, metav1.ConditionFalse, kcm.FailedReason, err.Error())
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	desired, err := r.reconcileWorkers(ctx, nodePool, clusterDeployment)
+	if err != nil {
+		if errors.Is(err, nodepool.ErrNoDefaultWorkerPool) {
+			setNodePoolCondition(nodePool, metav1.ConditionFalse, kcm.ProgressingReason, err.Error())
+			return ctrl.Result{}, nil
+		}
+		setNodePoolCondition(nodePool, metav1.ConditionFalse, kcm.FailedReason, err.Error())
+		if errors.Is(err, nodepool.ErrUnsupportedCluster) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	if nodePool.Status.ReadyReplicas == desired && nodePool.Status.Replicas == desired {
+		setNodePoolCondition(nodePool, metav1.ConditionTrue, kcm.SucceededReason, "NodePool is ready")
+		return ctrl.Result{}, nil
+	}
+
+	setNodePoolCondition(nodePool, metav1.ConditionFalse, kcm.ProgressingReason,
+		fmt.Sprintf("%d/%d machines are ready", nodePool.Status.ReadyReplicas, desired))
+	return ctrl.Result{}, nil
+}
+
+// reconcileWorkers renders the NodePool as a MachinePool if the workers of the cluster are MachinePools,
+// e.g. the ones of GKE or AKS clusters, and as a MachineDeployment otherwise. It sets the status of the
+// NodePool from the rendered object and returns the desired number of its machines.
+func (r *NodePoolReconciler) reconcileWorkers(ctx context.Context, nodePool *kcm.NodePool, clusterDeployment *kcm.ClusterDeployment) (int32, error) {
+	basePool, err := nodepool.DefaultMachinePool(ctx, r.Client, clusterDeployment)
+	if err == nil {
+		mp, err := r.reconcileMachinePool(ctx, nodePool, basePool)
+		if err != nil {
+			return 0, err
+		}
+
+		nodePool.Status.MachinePool = mp.Name
+		nodePool.Status.Replicas = mp.Status.Replicas
+		nodePool.Status.ReadyReplicas = mp.Status.ReadyReplicas
+		return ptr.Deref(mp.Spec.Replicas, 0), nil
+	}
+	if !errors.Is(err, nodepool.ErrNoDefaultWorkerPool) {
+		return 0, err
+	}
+
+	base, err := nodepool.DefaultMachineDeployment(ctx, r.Client, clusterDeployment)
+	if err != nil {
+		return 0, err
+	}
+
+	md, err := r.reconcileMachineDeployment(ctx, nodePool, base)
+	if err != nil {
+		return 0, err
+	}
+
+	nodePool.Status.MachineDeployment = md.Name
+	nodePool.Status.Replicas = md.Status.Replicas
+	nodePool.Status.ReadyReplicas = md.Status.ReadyReplicas
+	return ptr.Deref(md.Spec.Replicas, 0), nil
+}
+
+func setNodePoolCondition(nodePool *kcm.NodePool, status metav1.ConditionStatus, reason, message string) {
+	apimeta.SetStatusCondition(nodePool.GetConditions(), metav1.Condition{
+		Type:               kcm.NodePoolReadyCondition,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: nodePool.Generation,
+	})
+}
+
+func (r *NodePoolReconciler) updateStatus(ctx context.Context, nodePool *kcm.NodePool) error {
+	nodePool.Status.ObservedGeneration = nodePool.Generation
+	if err := r.Client.Status().Update(ctx, nodePool); err != nil {
+		return fmt.Errorf("failed to update status for NodePool %s/%s: %w", nodePool.Namespace, nodePool.Name, err)
+	}
+	return nil
+}
+
+// reconcileMachineDeployment ensures the templates and the MachineDeployment of the NodePool.
+func (r *NodePoolReconciler) reconcileMachineDeployment(ctx context.Context, nodePool *kcm.NodePool, base *clusterapiv1beta1.MachineDeployment) (*clusterapiv1beta1.MachineDeployment, error) {
+	clusterName := base.Spec.ClusterName
+	name := nodePoolObjectName(nodePool)
+
+	infraRef, err := r.ensureInfrastructureTemplate(ctx, nodePool, clusterName, base.Spec.Template.Spec.InfrastructureRef)
+	if err != nil {
+		return nil, err
+	}
+
+	bootstrapRef, err := r.ensureBootstrapTemplate(ctx, nodePool, clusterName, base.Spec.Template.Spec.Bootstrap.ConfigRef)
+	if err != nil {
+		return nil, err
+	}
+
+	md := &clusterapiv1beta1.MachineDeployment{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: nodePool.Namespace}}
+	if _, err := controllerutil.CreateOrUpdate(ctx, r.Client, md, func() error {
+		poolLabels := map[string]string{
+			clusterapiv1beta1.ClusterNameLabel: clusterName,
+			kcm.NodePoolLabelKey:               nodePool.Name,
+		}
+
+		if md.Labels == nil {
+			md.Labels = make(map[string]string)
+		}
+		for k, v := range poolLabels {
+			md.Labels[k] = v
+		}
+
+		md.Spec.ClusterName = clusterName
+		// the replicas of the autoscaled pools are managed by cluster-autoscaler
+		if _, autoscaled := md.Annotations[kcm.AutoscalerMinSizeAnnotation]; !autoscaled || md.Spec.Replicas == nil {
+			md.Spec.Replicas = nodePool.Spec.Replicas
+		}
+		md.Spec.Selector = metav1.LabelSelector{MatchLabels: poolLabels}
+
+		if md.Spec.Template.Labels == nil {
+			md.Spec.Template.Labels = make(map[string]string)
+		}
+		for k, v := range poolLabels {
+			md.Spec.Template.Labels[k] = v
+		}
+
+		md.Spec.Template.Spec.ClusterName = clusterName
+		md.Spec.Template.Spec.Version = base.Spec.Template.Spec.Version
+		md.Spec.Template.Spec.Bootstrap.ConfigRef = bootstrapRef
+		md.Spec.Template.Spec.InfrastructureRef = *infraRef
+
+		return controllerutil.SetControllerReference(nodePool, md, r.Client.Scheme())
+	}); err != nil {
+		return nil, fmt.Errorf("failed to reconcile MachineDeployment %s/%s: %w", md.Namespace, md.Name, err)
+	}
+
+	if err := r.cleanupTemplates(ctx, nodePool, md, infraRef, bootstrapRef); err != nil {
+		return nil, err
+	}
+
+	return md, nil
+}
+
+// reconcileMachinePool ensures the infrastructure machine pool, the bootstrap config and the MachinePool of the NodePool.
+func (r *NodePoolReconciler) reconcileMachinePool(ctx context.Context, nodePool *kcm.NodePool, base *expv1.MachinePool) (*expv1.MachinePool, error) {
+	clusterName := base.Spec.ClusterName
+	name := nodePoolObjectName(nodePool)
+
+	mergeMachineTemplate, err := machineTemplateMerger(nodePool)
+	if err != nil {
+		return nil, err
+	}
+
+	infraRef, err := r.ensureMachinePoolObject(ctx, nodePool, clusterName, base.Spec.Template.Spec.InfrastructureRef, mergeMachineTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	// the workers of the managed clusters are bootstrapped by the cloud, so the MachinePools have no bootstrap config
+	bootstrap := clusterapiv1beta1.Bootstrap{DataSecretName: base.Spec.Template.Spec.Bootstrap.DataSecretName}
+	if baseRef := base.Spec.Template.Spec.Bootstrap.ConfigRef; baseRef != nil {
+		bootstrap.DataSecretName = nil
+		bootstrap.ConfigRef, err = r.ensureMachinePoolObject(ctx, nodePool, clusterName, *baseRef,
+			nodeArgsAppender(nodePool, baseRef.Kind, nodepool.K0sWorkerConfigKind))
+		if err != nil {
+			return nil, err
+		}
+	} else if len(nodeArgs(nodePool)) > 0 {
+		return nil, fmt.Errorf("%w: node labels and taints are not supported for the MachinePools without a bootstrap config, set them in the machineTemplate instead", nodepool.ErrUnsupportedCluster)
+	}
+
+	mp := &expv1.MachinePool{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: nodePool.Namespace}}
+	if _, err := controllerutil.CreateOrUpdate(ctx, r.Client, mp, func() error {
+		if mp.Labels == nil {
+			mp.Labels = make(map[string]string)
+		}
+		mp.Labels[clusterapiv1beta1.ClusterNameLabel] = clusterName
+		mp.Labels[kcm.NodePoolLabelKey] = nodePool.Name
+
+		mp.Spec.ClusterName = clusterName
+		// the replicas of the autoscaled pools are managed by cluster-autoscaler
+		if _, autoscaled := mp.Annotations[kcm.AutoscalerMinSizeAnnotation]; !autoscaled || mp.Spec.Replicas == nil {
+			mp.Spec.Replicas = nodePool.Spec.Replicas
+		}
+		mp.Spec.FailureDomains = base.Spec.FailureDomains
+
+		if mp.Spec.Template.Labels == nil {
+			mp.Spec.Template.Labels = make(map[string]string)
+		}
+		mp.Spec.Template.Labels[clusterapiv1beta1.ClusterNameLabel] = clusterName
+		mp.Spec.Template.Labels[kcm.NodePoolLabelKey] = nodePool.Name
+
+		mp.Spec.Template.Spec.ClusterName = clusterName
+		mp.Spec.Template.Spec.Version = base.Spec.Template.Spec.Version
+		mp.Spec.Template.Spec.Bootstrap = bootstrap
+		mp.Spec.Template.Spec.InfrastructureRef = *infraRef
+
+		return controllerutil.SetControllerReference(nodePool, mp, r.Client.Scheme())
+	}); err != nil {
+		return nil, fmt.Errorf("failed to reconcile MachinePool %s/%s: %w", mp.Namespace, mp.Name, err)
+	}
+
+	return mp, nil
+}
+
+// machinePoolIgnoredFields are the fields of the spec of the infrastructure machine pools which are not copied
+// to the ones of the NodePools: the provider IDs of the instances and the names of the pools in the cloud,
+// the latter are defaulted from the name of the object by the providers.
+var machinePoolIgnoredFields = []string{"providerIDList", "name", "nodePoolName", "eksNodegroupName"}
+
+// ensureMachinePoolObject creates or updates the copy of the given infrastructure machine pool or
+// bootstrap config of the default worker MachinePool with its spec mutated. Unlike the machine templates
+// of the MachineDeployments, the objects of the MachinePools are updated in place by the providers.
+func (r *NodePoolReconciler) ensureMachinePoolObject(ctx context.Context, nodePool *kcm.NodePool, clusterName string, baseRef corev1.ObjectReference, mutate func(map[string]any) (map[string]any, error)) (*corev1.ObjectReference, error) {
+	base := new(unstructured.Unstructured)
+	base.SetAPIVersion(baseRef.APIVersion)
+	base.SetKind(baseRef.Kind)
+	if err := r.Client.Get(ctx, client.ObjectKey{Namespace: nodePool.Namespace, Name: baseRef.Name}, base); err != nil {
+		return nil, fmt.Errorf("failed to get %s %s/%s: %w", baseRef.Kind, nodePool.Namespace, baseRef.Name, err)
+	}
+
+	spec, _, err := unstructured.NestedMap(base.Object, "spec")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the spec of %s %s/%s: %w", baseRef.Kind, nodePool.Namespace, baseRef.Name, err)
+	}
+	if spec == nil {
+		spec = make(map[string]any)
+	}
+	for _, field := range machinePoolIgnoredFields {
+		delete(spec, field)
+	}
+
+	if spec, err = mutate(spec); err != nil {
+		return nil, err
+	}
+
+	obj := new(unstructured.Unstructured)
+	obj.SetAPIVersion(baseRef.APIVersion)
+	obj.SetKind(baseRef.Kind)
+	obj.SetNamespace(nodePool.Namespace)
+	obj.SetName(nodePoolObjectName(nodePool))
+	if _, err := controllerutil.CreateOrUpdate(ctx, r.Client, obj, func() error {
+		labels := obj.GetLabels()
+		if labels == nil {
+			labels = make(map[string]string)
+		}
+		labels[clusterapiv1beta1.ClusterNameLabel] = clusterName
+		labels[kcm.NodePoolLabelKey] = nodePool.Name
+		obj.SetLabels(labels)
+
+		// the fields defaulted by the providers are kept
+		existing, _, err := unstructured.NestedMap(obj.Object, "spec")
+		if err != nil {
+			return fmt.Errorf("failed to get the spec of %s: %w", baseRef.Kind, err)
+		}
+		merged, _ := utils.MergeValues(utils.ValuesLayer{Values: existing}, utils.ValuesLayer{Values: spec})
+		if err := unstructured.SetNestedMap(obj.Object, merged, "spec"); err != nil {
+			return fmt.Errorf("failed to set the spec of %s: %w", baseRef.Kind, err)
+		}
+
+		return controllerutil.SetControllerReference(nodePool, obj, r.Client.Scheme())
+	}); err != nil {
+		return nil, fmt.Errorf("failed to reconcile %s %s/%s: %w", baseRef.Kind, obj.GetNamespace(), obj.GetName(), err)
+	}
+
+	return &corev1.ObjectReference{
+		APIVersion: baseRef.APIVersion,
+		Kind:       baseRef.Kind,
+		Namespace:  nodePool.Namespace,
+		Name:       obj.GetName(),
+	}, nil
+}
+
+// ensureInfrastructureTemplate creates the infrastructure machine template of the NodePool
+// from the one of the default worker pool with the NodePool machine template merged into it.
+func (r *NodePoolReconciler) ensureInfrastructureTemplate(ctx context.Context, nodePool *kcm.NodePool, clusterName string, baseRef corev1.ObjectReference) (*corev1.ObjectReference, error) {
+	mergeMachineTemplate, err := machineTemplateMerger(nodePool)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.ensureTemplate(ctx, nodePool, clusterName, baseRef, mergeMachineTemplate)
+}
+
+// ensureBootstrapTemplate creates the bootstrap config template of the NodePool
+// from the one of the default worker pool with the node labels and taints set.
+func (r *NodePoolReconciler) ensureBootstrapTemplate(ctx context.Context, nodePool *kcm.NodePool, clusterName string, baseRef *corev1.ObjectReference) (*corev1.ObjectReference, error) {
+	if baseRef == nil {
+		return nil, errors.New("default worker MachineDeployment of the cluster has no bootstrap config template")
+	}
+
+	return r.ensureTemplate(ctx, nodePool, clusterName, *baseRef,
+		nodeArgsAppender(nodePool, baseRef.Kind, nodepool.K0sWorkerConfigTemplateKind))
+}
+
+// machineTemplateMerger returns the mutation deep-merging the machine template of the NodePool into the spec.
+func machineTemplateMerger(nodePool *kcm.NodePool) (func(map[string]any) (map[string]any, error), error) {
+	var overrides map[string]any
+	if nodePool.Spec.MachineTemplate != nil {
+		if err := json.Unmarshal(nodePool.Spec.MachineTemplate.Raw, &overrides); err != nil {
+			return nil, fmt.Errorf("failed to parse the machine template of NodePool %s/%s: %w", nodePool.Namespace, nodePool.Name, err)
+		}
+	}
+
+	return func(spec map[string]any) (map[string]any, error) {
+		merged, _ := utils.MergeValues(utils.ValuesLayer{Values: spec}, utils.ValuesLayer{Values: overrides})
+		return merged, nil
+	}, nil
+}
+
+// nodeArgsAppender returns the mutation appending the node labels and taints of the NodePool to the args
+// of the spec of the bootstrap config or template of the given kind, which must be the given k0s one.
+func nodeArgsAppender(nodePool *kcm.NodePool, kind, k0sKind string) func(map[string]any) (map[string]any, error) {
+	return func(spec map[string]any) (map[string]any, error) {
+		args := nodeArgs(nodePool)
+		if len(args) == 0 {
+			return spec, nil
+		}
+		if kind != k0sKind {
+			return nil, fmt.Errorf("%w: node labels and taints are not supported for the bootstrap config of kind %s", nodepool.ErrUnsupportedCluster, kind)
+		}
+
+		existing, _, err := unstructured.NestedStringSlice(spec, "args")
+		if err != nil {
+			return nil, fmt.Errorf("failed to get the args of %s: %w", kind, err)
+		}
+		if err := unstructured.SetNestedStringSlice(spec, append(existing, args...), "args"); err != nil {
+			return nil, fmt.Errorf("failed to set the args of %s: %w", kind, err)
+		}
+		return spec, nil
+	}
+}
+
+// ensureTemplate creates a copy of the given template with its spec.template.spec mutated,
+// the name of the copy contains the hash of its spec so the MachineDeployment rolls out on changes.
+func (r *NodePoolReconciler) ensureTemplate(ctx context.Context, nodePool *kcm.NodePool, clusterName string, baseRef corev1.ObjectReference, mutate func(map[string]any) (map[string]any, error)) (*corev1.ObjectReference, error) {
+	base := new(unstructured.Unstructured)
+	base.SetAPIVersion(baseRef.APIVersion)
+	base.SetKind(baseRef.Kind)
+	if err := r.Client.Get(ctx, client.ObjectKey{Namespace: nodePool.Namespace, Name: baseRef.Name}, base); err != nil {
+		return nil, fmt.Errorf("failed to get %s %s/%s: %w", baseRef.Kind, nodePool.Namespace, baseRef.Name, err)
+	}
+
+	spec, _, err := unstructured.NestedMap(base.Object, "spec", "template", "spec")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the spec of %s %s/%s: %w", baseRef.Kind, nodePool.Namespace, baseRef.Name, err)
+	}
+	if spec == nil {
+		spec = make(map[string]any)
+	}
+
+	if spec, err = mutate(spec); err != nil {
+		return nil, err
+	}
+
+	raw, err := json.Marshal(spec)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal the spec of %s: %w", baseRef.Kind, err)
+	}
+	sum := sha256.Sum256(raw)
+
+	tpl := new(unstructured.Unstructured)
+	tpl.SetAPIVersion(baseRef.APIVersion)
+	tpl.SetKind(baseRef.Kind)
+	tpl.SetNamespace(nodePool.Namespace)
+	tpl.SetName(nodePoolObjectName(nodePool) + "-" + hex.EncodeToString(sum[:])[:8])
+	tpl.SetLabels(map[string]string{
+		clusterapiv1beta1.ClusterNameLabel: clusterName,
+		kcm.NodePoolLabelKey:               nodePool.Name,
+	})
+	if err := unstructured.SetNestedMap(tpl.Object, spec, "spec", "template", "spec"); err != nil {
+		return nil, fmt.Errorf("failed to set the spec of %s: %w", baseRef.Kind, err)
+	}
+	if err := controllerutil.SetControllerReference(nodePool, tpl, r.Client.Scheme()); err != nil {
+		return nil, fmt.Errorf("failed to set owner reference on %s: %w", baseRef.Kind, err)
+	}
+
+	if err := r.Client.Create(ctx, tpl); client.IgnoreAlreadyExists(err) != nil {
+		return nil, fmt.Errorf("failed to create %s %s/%s: %w", baseRef.Kind, tpl.GetNamespace(), tpl.GetName(), err)
+	}
+
+	return &corev1.ObjectReference{
+		APIVersion: baseRef.APIVersion,
+		Kind:       baseRef.Kind,
+		Namespace:  nodePool.Namespace,
+		Name:       tpl.GetName(),
+	}, nil
+}
+
+// cleanupTemplates removes the templates of the NodePool which are neither current
+// nor referenced by any of the MachineSets of the MachineDeployment still rolling out.
+func (r *NodePoolReconciler) cleanupTemplates(ctx context.Context, nodePool *kcm.NodePool, md *clusterapiv1beta1.MachineDeployment, current ...*corev1.ObjectReference) error {
+	machineSets := &clusterapiv1beta1.MachineSetList{}
+	if err := r.Client.List(ctx, machineSets,
+		client.InNamespace(md.Namespace),
+		client.MatchingLabels{clusterapiv1beta1.MachineDeploymentNameLabel: md.Name},
+	); err != nil {
+		return fmt.Errorf("failed to list MachineSets of MachineDeployment %s/%s: %w", md.Namespace, md.Name, err)
+	}
+
+	inUse := make(map[schema.GroupVersionKind][]string)
+	for _, ms := range machineSets.Items {
+		ref := ms.Spec.Template.Spec.InfrastructureRef
+		inUse[ref.GroupVersionKind()] = append(inUse[ref.GroupVersionKind()], ref.Name)
+		if ref := ms.Spec.Template.Spec.Bootstrap.ConfigRef; ref != nil {
+			inUse[ref.GroupVersionKind()] = append(inUse[ref.GroupVersionKind()], ref.Name)
+		}
+	}
+
+	var errs error
+	for _, ref := range current {
+		gvk := ref.GroupVersionKind()
+
+		templates := new(unstructured.UnstructuredList)
+		templates.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+		if err := r.Client.List(ctx, templates,
+			client.InNamespace(nodePool.Namespace),
+			client.MatchingLabels{kcm.NodePoolLabelKey: nodePool.Name},
+		); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("failed to list %s of NodePool %s/%s: %w", gvk.Kind, nodePool.Namespace, nodePool.Name, err))
+			continue
+		}
+
+		for _, tpl := range templates.Items {
+			if tpl.GetName() == ref.Name || slices.Contains(inUse[gvk], tpl.GetName()) || !metav1.IsControlledBy(&tpl, nodePool) {
+				continue
+			}
+
+			ctrl.LoggerFrom(ctx).Info("Removing stale NodePool template", "kind", gvk.Kind, "name", tpl.GetName())
+			if err := r.Client.Delete(ctx, &tpl); client.IgnoreNotFound(err) != nil {
+				errs = errors.Join(errs, fmt.Errorf("failed to delete %s %s/%s: %w", gvk.Kind, tpl.GetNamespace(), tpl.GetName(), err))
+			}
+		}
+	}
+
+	return errs
+}
+
+// nodePoolObjectName returns the name of the MachineDeployment or the MachinePool of the NodePool,
+// it is also the prefix of the names of the machine templates of the NodePool.
+func nodePoolObjectName(nodePool *kcm.NodePool) string {
+	return nodePool.Spec.ClusterDeployment + "-pool-" + nodePool.Name
+}
+
+// nodeArgs returns the k0s worker arguments setting the labels and taints of the nodes of the NodePool.
+func nodeArgs(nodePool *kcm.NodePool) []string {
+	var args []string
+
+	if len(nodePool.Spec.Labels) > 0 {
+		labels := make([]string, 0, len(nodePool.Spec.Labels))
+		for k, v := range nodePool.Spec.Labels {
+			labels = append(labels, k+"="+v)
+		}
+		slices.Sort(labels)
+		args = append(args, "--labels="+strings.Join(labels, ","))
+	}
+
+	if len(nodePool.Spec.Taints) > 0 {
+		taints := make([]string, 0, len(nodePool.Spec.Taints))
+		for _, taint := range nodePool.Spec.Taints {
+			taints = append(taints, taint.ToString())
+		}
+		args = append(args, "--taints="+strings.Join(taints, ","))
+	}
+
+	return args
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *NodePoolReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.Client = mgr.GetClient()
+
+	nodePoolsOfClusterDeployment := func(ctx context.Context, namespace, name string) []ctrl.Request {
+		nodePools := &kcm.NodePoolList{}
+		if err := r.Client.List(ctx, nodePools,
+			client.InNamespace(namespace),
+			client.MatchingFields{kcm.NodePoolClusterDeploymentIndexKey: name},
+		); err != nil {
+			return nil
+		}
+
+		req := make([]ctrl.Request, 0, len(nodePools.Items))
+		for _, nodePool := range nodePools.Items {
+			req = append(req, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(&nodePool)})
+		}
+		return req
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&kcm.NodePool{}).
+		Owns(&clusterapiv1beta1.MachineDeployment{}).
+		Owns(&expv1.MachinePool{}).
+		Watches(&kcm.ClusterDeployment{},
+			handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, o client.Object) []ctrl.Request {
+				return nodePoolsOfClusterDeployment(ctx, o.GetNamespace(), o.GetName())
+			}),
+		).
+		Watches(&clusterapiv1beta1.MachineDeployment{},
+			handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, o client.Object) []ctrl.Request {
+				// the default worker MachineDeployment is the base of the NodePools of the cluster
+				clusterDeploymentName, ok := o.GetLabels()[kcm.FluxHelmChartNameKey]
+				if !ok {
+					return nil
+				}
+				return nodePoolsOfClusterDeployment(ctx, o.GetNamespace(), clusterDeploymentName)
+			}),
+		).
+		Watches(&expv1.MachinePool{},
+			handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, o client.Object) []ctrl.Request {
+				// the default worker MachinePool is the base of the NodePools of the managed clusters
+				clusterDeploymentName, ok := o.GetLabels()[kcm.FluxHelmChartNameKey]
+				if !ok {
+					return nil
+				}
+				return nodePoolsOfClusterDeployment(ctx, o.GetNamespace(), clusterDeploymentName)
+			}),
+		).
+		WithOptions(config.ControllerOptions("NodePool")).
+		Complete(tracing.Reconciler("NodePool", r))
+}
@@ -0,0 +1,346 @@
+// Copyright 2024
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+	clusterapiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	expv1 "sigs.k8s.io/cluster-api/exp/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	kcm "github.com/K0rdent/kcm/api/v1alpha1"
+)
+
+var _ = Describe("NodePool Controller", func() {
+	Context("When reconciling a resource", func() {
+		const (
+			namespace             = "default"
+			clusterDeploymentName = "test-cd"
+			nodePoolName          = "gpu"
+		)
+
+		var (
+			ctx        context.Context
+			fakeClient client.Client
+			reconciler *NodePoolReconciler
+			nodePool   *kcm.NodePool
+		)
+
+		newTemplate := func(apiVersion, kind, name string, spec map[string]any) *unstructured.Unstructured {
+			tpl := new(unstructured.Unstructured)
+			tpl.SetAPIVersion(apiVersion)
+			tpl.SetKind(kind)
+			tpl.SetNamespace(namespace)
+			tpl.SetName(name)
+			Expect(unstructured.SetNestedMap(tpl.Object, spec, "spec", "template", "spec")).To(Succeed())
+			return tpl
+		}
+
+		getTemplate := func(ref corev1.ObjectReference) map[string]any {
+			tpl := new(unstructured.Unstructured)
+			tpl.SetAPIVersion(ref.APIVersion)
+			tpl.SetKind(ref.Kind)
+			Expect(fakeClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: ref.Name}, tpl)).To(Succeed())
+			Expect(metav1.IsControlledBy(tpl, nodePool)).To(BeTrue())
+
+			spec, _, err := unstructured.NestedMap(tpl.Object, "spec", "template", "spec")
+			Expect(err).NotTo(HaveOccurred())
+			return spec
+		}
+
+		reconcileNodePool := func() {
+			_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(nodePool)})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(nodePool), nodePool)).To(Succeed())
+		}
+
+		BeforeEach(func() {
+			ctx = context.Background()
+
+			clusterDeployment := &kcm.ClusterDeployment{
+				ObjectMeta: metav1.ObjectMeta{Name: clusterDeploymentName, Namespace: namespace, UID: "cd-uid"},
+				Spec:       kcm.ClusterDeploymentSpec{Template: "test-template"},
+			}
+
+			base := &clusterapiv1beta1.MachineDeployment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      clusterDeploymentName + "-md",
+					Namespace: namespace,
+					Labels:    map[string]string{kcm.FluxHelmChartNameKey: clusterDeploymentName},
+				},
+				Spec: clusterapiv1beta1.MachineDeploymentSpec{
+					ClusterName: clusterDeploymentName,
+					Template: clusterapiv1beta1.MachineTemplateSpec{
+						Spec: clusterapiv1beta1.MachineSpec{
+							ClusterName: clusterDeploymentName,
+							Version:     ptr.To("v1.31.1+k0s.1"),
+							Bootstrap: clusterapiv1beta1.Bootstrap{
+								ConfigRef: &corev1.ObjectReference{
+									APIVersion: "bootstrap.cluster.x-k8s.io/v1beta1",
+									Kind:       "K0sWorkerConfigTemplate",
+									Name:       clusterDeploymentName + "-machine-config",
+								},
+							},
+							InfrastructureRef: corev1.ObjectReference{
+								APIVersion: "infrastructure.cluster.x-k8s.io/v1beta2",
+								Kind:       "AWSMachineTemplate",
+								Name:       clusterDeploymentName + "-mt",
+							},
+						},
+					},
+				},
+			}
+
+			nodePool = &kcm.NodePool{
+				ObjectMeta: metav1.ObjectMeta{Name: nodePoolName, Namespace: namespace, UID: "np-uid"},
+				Spec: kcm.NodePoolSpec{
+					ClusterDeployment: clusterDeploymentName,
+					Replicas:          ptr.To[int32](2),
+					Labels:            map[string]string{"gpu": "true"},
+					Taints:            []corev1.Taint{{Key: "nvidia.com/gpu", Value: "true", Effect: corev1.TaintEffectNoSchedule}},
+					MachineTemplate:   &apiextensionsv1.JSON{Raw: []byte(`{"instanceType":"g4dn.xlarge"}`)},
+				},
+			}
+
+			clusterTemplate := &kcm.ClusterTemplate{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-template", Namespace: namespace},
+				Status: kcm.ClusterTemplateStatus{
+					Providers: kcm.Providers{"infrastructure-aws", "control-plane-k0sproject-k0smotron", "bootstrap-k0sproject-k0smotron"},
+				},
+			}
+
+			fakeClient = fake.NewClientBuilder().
+				WithScheme(scheme.Scheme).
+				WithStatusSubresource(&kcm.NodePool{}).
+				WithObjects(clusterDeployment, clusterTemplate, base, nodePool,
+					newTemplate("infrastructure.cluster.x-k8s.io/v1beta2", "AWSMachineTemplate", clusterDeploymentName+"-mt",
+						map[string]any{"instanceType": "t3.small", "iamInstanceProfile": "nodes"}),
+					newTemplate("bootstrap.cluster.x-k8s.io/v1beta1", "K0sWorkerConfigTemplate", clusterDeploymentName+"-machine-config",
+						map[string]any{"version": "v1.31.1+k0s.1", "args": []any{"--debug"}}),
+				).
+				Build()
+			reconciler = &NodePoolReconciler{Client: fakeClient}
+		})
+
+		It("should render the MachineDeployment of the pool from the default worker templates", func() {
+			reconcileNodePool()
+
+			Expect(nodePool.OwnerReferences).To(ContainElement(HaveField("UID", BeEquivalentTo("cd-uid"))))
+			Expect(nodePool.Status.MachineDeployment).To(Equal(clusterDeploymentName + "-pool-" + nodePoolName))
+
+			cond := apimeta.FindStatusCondition(nodePool.Status.Conditions, kcm.NodePoolReadyCondition)
+			Expect(cond).NotTo(BeNil())
+			Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+			Expect(cond.Message).To(Equal("0/2 machines are ready"))
+
+			md := &clusterapiv1beta1.MachineDeployment{}
+			Expect(fakeClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: nodePool.Status.MachineDeployment}, md)).To(Succeed())
+			Expect(metav1.IsControlledBy(md, nodePool)).To(BeTrue())
+			Expect(md.Spec.Replicas).To(HaveValue(BeEquivalentTo(2)))
+			Expect(md.Spec.Template.Labels).To(HaveKeyWithValue(kcm.NodePoolLabelKey, nodePoolName))
+			Expect(md.Spec.Template.Spec.Version).To(HaveValue(Equal("v1.31.1+k0s.1")))
+
+			Expect(getTemplate(md.Spec.Template.Spec.InfrastructureRef)).To(Equal(map[string]any{
+				"instanceType":       "g4dn.xlarge",
+				"iamInstanceProfile": "nodes",
+			}))
+			Expect(getTemplate(*md.Spec.Template.Spec.Bootstrap.ConfigRef)).To(HaveKeyWithValue("args", ConsistOf(
+				"--debug",
+				"--labels=gpu=true",
+				"--taints=nvidia.com/gpu=true:NoSchedule",
+			)))
+		})
+
+		It("should roll out a new machine template on changes and remove the stale one", func() {
+			reconcileNodePool()
+
+			md := &clusterapiv1beta1.MachineDeployment{}
+			Expect(fakeClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: nodePool.Status.MachineDeployment}, md)).To(Succeed())
+			previousRef := md.Spec.Template.Spec.InfrastructureRef
+
+			nodePool.Spec.MachineTemplate = &apiextensionsv1.JSON{Raw: []byte(`{"instanceType":"p3.2xlarge"}`)}
+			Expect(fakeClient.Update(ctx, nodePool)).To(Succeed())
+			reconcileNodePool()
+
+			Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(md), md)).To(Succeed())
+			Expect(md.Spec.Template.Spec.InfrastructureRef.Name).NotTo(Equal(previousRef.Name))
+			Expect(getTemplate(md.Spec.Template.Spec.InfrastructureRef)).To(HaveKeyWithValue("instanceType", "p3.2xlarge"))
+
+			stale := new(unstructured.Unstructured)
+			stale.SetAPIVersion(previousRef.APIVersion)
+			stale.SetKind(previousRef.Kind)
+			Expect(fakeClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: previousRef.Name}, stale)).NotTo(Succeed())
+		})
+
+		newMachinePool := func(bootstrap clusterapiv1beta1.Bootstrap, infraKind string) *expv1.MachinePool {
+			return &expv1.MachinePool{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      clusterDeploymentName + "-mp",
+					Namespace: namespace,
+					Labels:    map[string]string{kcm.FluxHelmChartNameKey: clusterDeploymentName},
+				},
+				Spec: expv1.MachinePoolSpec{
+					ClusterName:    clusterDeploymentName,
+					FailureDomains: []string{"us-east-2a"},
+					Template: clusterapiv1beta1.MachineTemplateSpec{
+						Spec: clusterapiv1beta1.MachineSpec{
+							ClusterName: clusterDeploymentName,
+							Version:     ptr.To("v1.31.1"),
+							Bootstrap:   bootstrap,
+							InfrastructureRef: corev1.ObjectReference{
+								APIVersion: "infrastructure.cluster.x-k8s.io/v1beta2",
+								Kind:       infraKind,
+								Name:       clusterDeploymentName + "-mp",
+							},
+						},
+					},
+				},
+			}
+		}
+
+		newObject := func(apiVersion, kind, name string, spec map[string]any) *unstructured.Unstructured {
+			obj := new(unstructured.Unstructured)
+			obj.SetAPIVersion(apiVersion)
+			obj.SetKind(kind)
+			obj.SetNamespace(namespace)
+			obj.SetName(name)
+			Expect(unstructured.SetNestedMap(obj.Object, spec, "spec")).To(Succeed())
+			return obj
+		}
+
+		getObjectSpec := func(ref corev1.ObjectReference) map[string]any {
+			obj := new(unstructured.Unstructured)
+			obj.SetAPIVersion(ref.APIVersion)
+			obj.SetKind(ref.Kind)
+			Expect(fakeClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: ref.Name}, obj)).To(Succeed())
+			Expect(metav1.IsControlledBy(obj, nodePool)).To(BeTrue())
+
+			spec, _, err := unstructured.NestedMap(obj.Object, "spec")
+			Expect(err).NotTo(HaveOccurred())
+			return spec
+		}
+
+		It("should render the MachinePool of the pool if the workers of the cluster are MachinePools", func() {
+			Expect(fakeClient.Create(ctx, newMachinePool(clusterapiv1beta1.Bootstrap{
+				ConfigRef: &corev1.ObjectReference{
+					APIVersion: "bootstrap.cluster.x-k8s.io/v1beta1",
+					Kind:       "K0sWorkerConfig",
+					Name:       clusterDeploymentName + "-mp-config",
+				},
+			}, "AWSMachinePool"))).To(Succeed())
+			Expect(fakeClient.Create(ctx, newObject("infrastructure.cluster.x-k8s.io/v1beta2", "AWSMachinePool", clusterDeploymentName+"-mp",
+				map[string]any{"awsLaunchTemplate": map[string]any{"instanceType": "t3.small"}, "providerIDList": []any{"aws:///us-east-2a/i-1"}}))).To(Succeed())
+			Expect(fakeClient.Create(ctx, newObject("bootstrap.cluster.x-k8s.io/v1beta1", "K0sWorkerConfig", clusterDeploymentName+"-mp-config",
+				map[string]any{"version": "v1.31.1+k0s.1", "args": []any{"--debug"}}))).To(Succeed())
+
+			nodePool.Spec.MachineTemplate = &apiextensionsv1.JSON{Raw: []byte(`{"awsLaunchTemplate":{"instanceType":"g4dn.xlarge"}}`)}
+			Expect(fakeClient.Update(ctx, nodePool)).To(Succeed())
+			reconcileNodePool()
+
+			Expect(nodePool.Status.MachineDeployment).To(BeEmpty())
+			Expect(nodePool.Status.MachinePool).To(Equal(clusterDeploymentName + "-pool-" + nodePoolName))
+
+			cond := apimeta.FindStatusCondition(nodePool.Status.Conditions, kcm.NodePoolReadyCondition)
+			Expect(cond).NotTo(BeNil())
+			Expect(cond.Message).To(Equal("0/2 machines are ready"))
+
+			mp := &expv1.MachinePool{}
+			Expect(fakeClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: nodePool.Status.MachinePool}, mp)).To(Succeed())
+			Expect(metav1.IsControlledBy(mp, nodePool)).To(BeTrue())
+			Expect(mp.Labels).To(HaveKeyWithValue(kcm.NodePoolLabelKey, nodePoolName))
+			Expect(mp.Spec.Replicas).To(HaveValue(BeEquivalentTo(2)))
+			Expect(mp.Spec.FailureDomains).To(Equal([]string{"us-east-2a"}))
+			Expect(mp.Spec.Template.Spec.Version).To(HaveValue(Equal("v1.31.1")))
+
+			Expect(getObjectSpec(mp.Spec.Template.Spec.InfrastructureRef)).To(Equal(map[string]any{
+				"awsLaunchTemplate": map[string]any{"instanceType": "g4dn.xlarge"},
+			}), "the provider IDs of the default pool must not be copied")
+			Expect(getObjectSpec(*mp.Spec.Template.Spec.Bootstrap.ConfigRef)).To(HaveKeyWithValue("args", ConsistOf(
+				"--debug",
+				"--labels=gpu=true",
+				"--taints=nvidia.com/gpu=true:NoSchedule",
+			)))
+
+			By("Scaling the pool")
+			nodePool.Spec.Replicas = ptr.To[int32](3)
+			Expect(fakeClient.Update(ctx, nodePool)).To(Succeed())
+			reconcileNodePool()
+
+			Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(mp), mp)).To(Succeed())
+			Expect(mp.Spec.Replicas).To(HaveValue(BeEquivalentTo(3)))
+		})
+
+		It("should fail without requeue if the node labels are set for a MachinePool without a bootstrap config", func() {
+			Expect(fakeClient.Create(ctx, newMachinePool(clusterapiv1beta1.Bootstrap{DataSecretName: ptr.To("")}, "GCPManagedMachinePool"))).To(Succeed())
+			Expect(fakeClient.Create(ctx, newObject("infrastructure.cluster.x-k8s.io/v1beta2", "GCPManagedMachinePool", clusterDeploymentName+"-mp",
+				map[string]any{"nodePoolName": "default", "machineType": "e2-medium"}))).To(Succeed())
+
+			res, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(nodePool)})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res.IsZero()).To(BeTrue())
+			Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(nodePool), nodePool)).To(Succeed())
+
+			cond := apimeta.FindStatusCondition(nodePool.Status.Conditions, kcm.NodePoolReadyCondition)
+			Expect(cond).NotTo(BeNil())
+			Expect(cond.Reason).To(Equal(kcm.FailedReason))
+			Expect(cond.Message).To(ContainSubstring("set them in the machineTemplate instead"))
+			Expect(nodePool.Status.MachinePool).To(BeEmpty())
+
+			By("Rendering the pool without the node labels and taints")
+			nodePool.Spec.Labels, nodePool.Spec.Taints = nil, nil
+			nodePool.Spec.MachineTemplate = &apiextensionsv1.JSON{Raw: []byte(`{"machineType":"n2-standard-8","kubernetesLabels":{"gpu":"true"}}`)}
+			Expect(fakeClient.Update(ctx, nodePool)).To(Succeed())
+			reconcileNodePool()
+
+			mp := &expv1.MachinePool{}
+			Expect(fakeClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: nodePool.Status.MachinePool}, mp)).To(Succeed())
+			Expect(mp.Spec.Template.Spec.Bootstrap.ConfigRef).To(BeNil())
+			Expect(mp.Spec.Template.Spec.Bootstrap.DataSecretName).To(HaveValue(BeEmpty()))
+			Expect(getObjectSpec(mp.Spec.Template.Spec.InfrastructureRef)).To(Equal(map[string]any{
+				"machineType":      "n2-standard-8",
+				"kubernetesLabels": map[string]any{"gpu": "true"},
+			}), "the name of the default pool in the cloud must not be copied")
+		})
+
+		It("should fail without requeue if the node labels are set for a non-k0s bootstrap template", func() {
+			base := &clusterapiv1beta1.MachineDeployment{}
+			Expect(fakeClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: clusterDeploymentName + "-md"}, base)).To(Succeed())
+			base.Spec.Template.Spec.Bootstrap.ConfigRef.Kind = "EKSConfigTemplate"
+			Expect(fakeClient.Update(ctx, base)).To(Succeed())
+
+			res, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(nodePool)})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res.IsZero()).To(BeTrue())
+			Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(nodePool), nodePool)).To(Succeed())
+
+			cond := apimeta.FindStatusCondition(nodePool.Status.Conditions, kcm.NodePoolReadyCondition)
+			Expect(cond).NotTo(BeNil())
+			Expect(cond.Reason).To(Equal(kcm.FailedReason))
+			Expect(cond.Message).To(ContainSubstring("node labels and taints are only supported"))
+		})
+	})
+})
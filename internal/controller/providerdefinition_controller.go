@@ -0,0 +1,158 @@
+// Copyright 2024
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	kcm "github.com/K0rdent/kcm/api/v1alpha1"
+	"github.com/K0rdent/kcm/internal/config"
+	"github.com/K0rdent/kcm/internal/providers"
+	"github.com/K0rdent/kcm/internal/tracing"
+)
+
+var _ providers.ProviderModule = (*kcm.ProviderDefinition)(nil)
+
+// ProviderDefinitionReconciler registers the providers defined
+// by the ProviderDefinition objects in the providers registry
+// and installs the opted-in ones in the Management.
+type ProviderDefinitionReconciler struct {
+	client.Client
+
+	recorder record.EventRecorder
+}
+
+func (r *ProviderDefinitionReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	l := ctrl.LoggerFrom(ctx)
+	l.Info("Reconciling ProviderDefinition")
+
+	definition := &kcm.ProviderDefinition{}
+	if err := r.Get(ctx, req.NamespacedName, definition); err != nil {
+		if client.IgnoreNotFound(err) != nil {
+			return ctrl.Result{}, err
+		}
+		// no finalizer is needed, every replica unregisters the provider
+		// once the ProviderDefinition is gone
+		l.Info("ProviderDefinition not found, unregistering the provider")
+		providers.Unregister(req.Name)
+		return ctrl.Result{}, r.updateManagementProviders(ctx, req.Name, false)
+	}
+
+	if err := providers.RegisterOrUpdate(definition.DeepCopy()); err != nil {
+		if errors.Is(err, providers.ErrBuiltinProvider) {
+			// the webhook rejects such definitions, nothing to retry here
+			l.Error(err, "ProviderDefinition conflicts with the built-in provider")
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{}, r.updateManagementProviders(ctx, definition.Name, definition.Spec.InstallInManagement)
+}
+
+// updateManagementProviders adds the provider registered at runtime to the providers
+// of the Management or removes it from them. The built-in providers are left intact.
+func (r *ProviderDefinitionReconciler) updateManagementProviders(ctx context.Context, shortName string, install bool) error {
+	if providers.IsBuiltin(shortName) {
+		return nil
+	}
+
+	mgmt := &kcm.Management{}
+	if err := r.Get(ctx, client.ObjectKey{Name: kcm.ManagementName}, mgmt); err != nil {
+		// the provider is added on the creation of the Management
+		return client.IgnoreNotFound(err)
+	}
+	if !mgmt.DeletionTimestamp.IsZero() {
+		return nil
+	}
+
+	name := providers.ProviderPrefix + shortName
+	idx := slices.IndexFunc(mgmt.Spec.Providers, func(p kcm.Provider) bool { return p.Name == name })
+	if install == (idx >= 0) {
+		return nil
+	}
+
+	original := mgmt.DeepCopy()
+	if install {
+		mgmt.Spec.Providers = append(mgmt.Spec.Providers, kcm.Provider{Name: name})
+	} else {
+		mgmt.Spec.Providers = slices.Delete(mgmt.Spec.Providers, idx, idx+1)
+	}
+
+	ctrl.LoggerFrom(ctx).Info("Updating the providers of the Management", "provider", name, "install", install)
+	// every replica runs the controller, hence the lock
+	if err := r.Patch(ctx, mgmt, client.MergeFromWithOptions(original, client.MergeFromWithOptimisticLock{})); err != nil {
+		return fmt.Errorf("failed to update the providers of the Management: %w", err)
+	}
+
+	// only the replica which has patched the Management records the Event
+	if install {
+		r.recorder.Eventf(mgmt, corev1.EventTypeNormal, EventReasonProviderAdded, "Added the provider %s of the ProviderDefinition %s", name, shortName)
+	} else {
+		r.recorder.Eventf(mgmt, corev1.EventTypeNormal, EventReasonProviderRemoved, "Removed the provider %s of the ProviderDefinition %s", name, shortName)
+	}
+
+	return nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ProviderDefinitionReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	opts := config.ControllerOptions("ProviderDefinition")
+	// The registry is in-memory, hence every replica, including the ones
+	// serving only the webhooks, has to keep its own registry up to date.
+	opts.NeedLeaderElection = ptr.To(false)
+	r.recorder = mgr.GetEventRecorderFor("providerdefinition-controller")
+
+	return ctrl.NewControllerManagedBy(mgr).
+		WithOptions(opts).
+		For(&kcm.ProviderDefinition{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(&kcm.Management{},
+			handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, _ client.Object) []ctrl.Request {
+				definitions := &kcm.ProviderDefinitionList{}
+				if err := r.List(ctx, definitions); err != nil {
+					ctrl.LoggerFrom(ctx).Error(err, "failed to list ProviderDefinitions")
+					return nil
+				}
+
+				var requests []ctrl.Request
+				for _, definition := range definitions.Items {
+					if definition.Spec.InstallInManagement {
+						requests = append(requests, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(&definition)})
+					}
+				}
+				return requests
+			}),
+			builder.WithPredicates(predicate.Funcs{
+				// the opted-in providers are added to the newly created Management
+				UpdateFunc:  func(event.UpdateEvent) bool { return false },
+				DeleteFunc:  func(event.DeleteEvent) bool { return false },
+				GenericFunc: func(event.GenericEvent) bool { return false },
+			}),
+		).
+		Complete(tracing.Reconciler("ProviderDefinition", r))
+}
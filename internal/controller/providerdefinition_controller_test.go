@@ -0,0 +1,108 @@
+// Copyright 2024
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	kcm "github.com/K0rdent/kcm/api/v1alpha1"
+	"github.com/K0rdent/kcm/internal/providers"
+)
+
+var _ = Describe("ProviderDefinition Controller", func() {
+	const providerName = "inhouse"
+
+	var (
+		ctx        context.Context
+		fakeClient client.Client
+		recorder   *record.FakeRecorder
+		reconciler *ProviderDefinitionReconciler
+		definition *kcm.ProviderDefinition
+	)
+
+	reconcileDefinition := func() {
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKey{Name: providerName}})
+		Expect(err).NotTo(HaveOccurred())
+	}
+
+	managementProviders := func() []kcm.Provider {
+		mgmt := &kcm.Management{}
+		Expect(fakeClient.Get(ctx, client.ObjectKey{Name: kcm.ManagementName}, mgmt)).To(Succeed())
+		return mgmt.Spec.Providers
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		definition = &kcm.ProviderDefinition{
+			ObjectMeta: metav1.ObjectMeta{Name: providerName},
+			Spec: kcm.ProviderDefinitionSpec{
+				ClusterGVKs:          []kcm.ClusterGVK{{Group: "infrastructure.cluster.x-k8s.io", Version: "v1beta1", Kind: "InHouseCluster"}},
+				ClusterIdentityKinds: []string{"Secret"},
+				InstallInManagement:  true,
+			},
+		}
+
+		fakeClient = fake.NewClientBuilder().
+			WithScheme(scheme.Scheme).
+			WithObjects(definition, &kcm.Management{
+				ObjectMeta: metav1.ObjectMeta{Name: kcm.ManagementName},
+				Spec:       kcm.ManagementSpec{Providers: providers.ListBuiltin()},
+			}).
+			Build()
+		recorder = record.NewFakeRecorder(10)
+		reconciler = &ProviderDefinitionReconciler{Client: fakeClient, recorder: recorder}
+	})
+
+	AfterEach(func() {
+		providers.Unregister(providerName)
+	})
+
+	It("should install the opted-in provider in the Management and remove it on deletion", func() {
+		reconcileDefinition()
+		Expect(providers.GetClusterGVKs(providerName)).NotTo(BeEmpty())
+		Expect(managementProviders()).To(ContainElement(kcm.Provider{Name: providers.ProviderPrefix + providerName}))
+		Expect(recorder.Events).To(Receive(HavePrefix("Normal " + EventReasonProviderAdded)))
+
+		By("recording the Event only once the Management is changed")
+		reconcileDefinition()
+		Expect(recorder.Events).NotTo(Receive())
+
+		Expect(fakeClient.Delete(ctx, definition)).To(Succeed())
+		reconcileDefinition()
+		Expect(providers.GetClusterGVKs(providerName)).To(BeEmpty())
+		Expect(managementProviders()).To(Equal(providers.ListBuiltin()))
+		Expect(recorder.Events).To(Receive(HavePrefix("Normal " + EventReasonProviderRemoved)))
+	})
+
+	It("should remove the provider from the Management once it is opted out", func() {
+		reconcileDefinition()
+
+		definition.Spec.InstallInManagement = false
+		Expect(fakeClient.Update(ctx, definition)).To(Succeed())
+		reconcileDefinition()
+		Expect(providers.GetClusterGVKs(providerName)).NotTo(BeEmpty())
+		Expect(managementProviders()).NotTo(ContainElement(kcm.Provider{Name: providers.ProviderPrefix + providerName}))
+	})
+})
@@ -19,6 +19,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"slices"
 	"strings"
 
 	hcv2 "github.com/fluxcd/helm-controller/api/v2"
@@ -35,6 +36,7 @@ import (
 	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -46,9 +48,12 @@ import (
 
 	kcm "github.com/K0rdent/kcm/api/v1alpha1"
 	"github.com/K0rdent/kcm/internal/build"
+	"github.com/K0rdent/kcm/internal/config"
 	"github.com/K0rdent/kcm/internal/helm"
 	"github.com/K0rdent/kcm/internal/providers"
+	"github.com/K0rdent/kcm/internal/tracing"
 	"github.com/K0rdent/kcm/internal/utils"
+	"github.com/K0rdent/kcm/internal/utils/status"
 )
 
 // ReleaseReconciler reconciles a Template object
@@ -65,6 +70,8 @@ type ReleaseReconciler struct {
 	CreateManagement bool
 	CreateRelease    bool
 	CreateTemplates  bool
+
+	recorder record.EventRecorder
 }
 
 func (r *ReleaseReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
@@ -101,6 +108,7 @@ func (r *ReleaseReconciler) Reconcile(ctx context.Context, req ctrl.Request) (re
 			return ctrl.Result{}, err
 		}
 
+		previousConditions := slices.Clone(release.Status.Conditions)
 		defer func() {
 			release.Status.ObservedGeneration = release.Generation
 			for _, condition := range release.Status.Conditions {
@@ -108,7 +116,11 @@ func (r *ReleaseReconciler) Reconcile(ctx context.Context, req ctrl.Request) (re
 					release.Status.Ready = false
 				}
 			}
-			err = errors.Join(err, r.Status().Update(ctx, release))
+			if statusErr := r.Status().Update(ctx, release); statusErr != nil {
+				err = errors.Join(err, statusErr)
+				return
+			}
+			recordConditionEvents(r.recorder, release, status.ConditionTransitions(previousConditions, release.Status.Conditions, metav1.Now()))
 		}()
 	}
 
@@ -218,7 +230,8 @@ func (r *ReleaseReconciler) ensureManagement(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
-	mgmtObj.Spec.Providers = providers.List()
+	// the providers registered at runtime are added by their ProviderDefinitions
+	mgmtObj.Spec.Providers = providers.ListBuiltin()
 
 	getter := helm.NewMemoryRESTClientGetter(r.Config, r.RESTMapper())
 	actionConfig := new(action.Configuration)
@@ -370,12 +383,15 @@ func (r *ReleaseReconciler) getCurrentReleaseName(ctx context.Context) (string,
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *ReleaseReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.recorder = mgr.GetEventRecorderFor("release-controller")
+
 	c, err := ctrl.NewControllerManagedBy(mgr).
 		For(&kcm.Release{}, builder.WithPredicates(predicate.Funcs{
 			DeleteFunc:  func(event.DeleteEvent) bool { return false },
 			GenericFunc: func(event.GenericEvent) bool { return false },
 		})).
-		Build(r)
+		WithOptions(config.ControllerOptions("Release")).
+		Build(tracing.Reconciler("Release", r))
 	if err != nil {
 		return err
 	}
@@ -26,9 +26,13 @@ import (
 	helmcontrollerv2 "github.com/fluxcd/helm-controller/api/v2"
 	sourcev1 "github.com/fluxcd/source-controller/api/v1"
 	"helm.sh/helm/v3/pkg/chart"
+	corev1 "k8s.io/api/core/v1"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -37,7 +41,10 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 
 	kcm "github.com/K0rdent/kcm/api/v1alpha1"
+	"github.com/K0rdent/kcm/internal/config"
 	"github.com/K0rdent/kcm/internal/helm"
+	"github.com/K0rdent/kcm/internal/metrics"
+	"github.com/K0rdent/kcm/internal/tracing"
 	"github.com/K0rdent/kcm/internal/utils"
 )
 
@@ -51,6 +58,8 @@ type TemplateReconciler struct {
 
 	SystemNamespace       string
 	DefaultRegistryConfig helm.DefaultRegistryConfig
+
+	recorder record.EventRecorder
 }
 
 type ClusterTemplateReconciler struct {
@@ -73,6 +82,7 @@ func (r *ClusterTemplateReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 	if err := r.Get(ctx, req.NamespacedName, clusterTemplate); err != nil {
 		if apierrors.IsNotFound(err) {
 			l.Info("ClusterTemplate not found, ignoring since object must be deleted")
+			metrics.DeleteTemplate(kcm.ClusterTemplateKind, req.Namespace, req.Name)
 			return ctrl.Result{}, nil
 		}
 
@@ -124,6 +134,7 @@ func (r *ServiceTemplateReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 	if err := r.Get(ctx, req.NamespacedName, serviceTemplate); err != nil {
 		if apierrors.IsNotFound(err) {
 			l.Info("ServiceTemplate not found, ignoring since object must be deleted")
+			metrics.DeleteTemplate(kcm.ServiceTemplateKind, req.Namespace, req.Name)
 			return ctrl.Result{}, nil
 		}
 		l.Error(err, "Failed to get ServiceTemplate")
@@ -157,6 +168,7 @@ func (r *ProviderTemplateReconciler) Reconcile(ctx context.Context, req ctrl.Req
 	if err := r.Get(ctx, req.NamespacedName, providerTemplate); err != nil {
 		if apierrors.IsNotFound(err) {
 			l.Info("ProviderTemplate not found, ignoring since object must be deleted")
+			metrics.DeleteTemplate(kcm.ProviderTemplateKind, req.Namespace, req.Name)
 			return ctrl.Result{}, nil
 		}
 
@@ -235,7 +247,13 @@ func (r *TemplateReconciler) ReconcileTemplate(ctx context.Context, template tem
 			l.Error(err, "invalid helm chart reference")
 			return ctrl.Result{}, err
 		}
-		if template.GetNamespace() == r.SystemNamespace || !templateManagedByKCM(template) {
+		if helmSpec.Registry != "" {
+			if err := r.reconcileRegistryHelmRepository(ctx, template); err != nil {
+				l.Error(err, "Failed to reconcile HelmRepository of the TemplateRegistry", "registry", helmSpec.Registry)
+				_ = r.updateStatus(ctx, template, err.Error())
+				return ctrl.Result{}, err
+			}
+		} else if template.GetNamespace() == r.SystemNamespace || !templateManagedByKCM(template) {
 			namespace := template.GetNamespace()
 			if namespace == "" {
 				namespace = r.SystemNamespace
@@ -264,6 +282,7 @@ func (r *TemplateReconciler) ReconcileTemplate(ctx context.Context, template tem
 		Name:      hcChart.Name,
 		Namespace: hcChart.Namespace,
 	}
+	status.ChartName = hcChart.Spec.Chart
 	status.ChartVersion = hcChart.Spec.Version
 
 	if reportStatus, err := helm.ShouldReportStatusOnArtifactReadiness(hcChart); err != nil {
@@ -333,6 +352,7 @@ func fillStatusWithProviders(template templateCommon, helmChart *chart.Chart) er
 
 func (r *TemplateReconciler) updateStatus(ctx context.Context, template templateCommon, validationError string) error {
 	status := template.GetCommonStatus()
+	previousValidationError := status.ValidationError
 	status.ObservedGeneration = template.GetGeneration()
 	status.ValidationError = validationError
 	status.Valid = validationError == ""
@@ -340,9 +360,28 @@ func (r *TemplateReconciler) updateStatus(ctx context.Context, template template
 	if err != nil {
 		return fmt.Errorf("failed to update status for template %s/%s: %w", template.GetNamespace(), template.GetName(), err)
 	}
+
+	if !status.Valid && validationError != previousValidationError {
+		r.recorder.Event(template, corev1.EventTypeWarning, EventReasonTemplateInvalid, validationError)
+	}
+
+	metrics.TrackTemplate(templateKind(template), template.GetNamespace(), template.GetName(), status.Valid)
 	return nil
 }
 
+func templateKind(template templateCommon) string {
+	switch template.(type) {
+	case *kcm.ClusterTemplate:
+		return kcm.ClusterTemplateKind
+	case *kcm.ServiceTemplate:
+		return kcm.ServiceTemplateKind
+	case *kcm.ProviderTemplate:
+		return kcm.ProviderTemplateKind
+	default:
+		return ""
+	}
+}
+
 func (r *TemplateReconciler) reconcileHelmChart(ctx context.Context, template templateCommon) (*sourcev1.HelmChart, error) {
 	namespace := template.GetNamespace()
 	if namespace == "" {
@@ -365,12 +404,113 @@ func (r *TemplateReconciler) reconcileHelmChart(ctx context.Context, template te
 		utils.AddOwnerReference(helmChart, template)
 
 		helmChart.Spec = *helmSpec.ChartSpec
+		if helmSpec.Registry != "" {
+			helmChart.Spec.SourceRef = sourcev1.LocalHelmChartSourceReference{
+				Kind: sourcev1.HelmRepositoryKind,
+				Name: kcm.TemplateRegistryRepoName(helmSpec.Registry),
+			}
+		}
 		return nil
 	})
 
 	return helmChart, err
 }
 
+// reconcileRegistryHelmRepository ensures the HelmRepository of the TemplateRegistry
+// referenced by the template exists in the namespace of the template.
+func (r *TemplateReconciler) reconcileRegistryHelmRepository(ctx context.Context, template templateCommon) error {
+	registryName := template.GetHelmSpec().Registry
+	registry := &kcm.TemplateRegistry{}
+	if err := r.Get(ctx, client.ObjectKey{Name: registryName}, registry); err != nil {
+		if apierrors.IsNotFound(err) {
+			return fmt.Errorf("TemplateRegistry %s is not found", registryName)
+		}
+		return fmt.Errorf("failed to get TemplateRegistry %s: %w", registryName, err)
+	}
+
+	spec, err := helm.TemplateRegistryHelmRepositorySpec(registry)
+	if err != nil {
+		return err
+	}
+
+	namespace := template.GetNamespace()
+	if namespace == "" {
+		namespace = r.SystemNamespace
+	}
+	return helm.ReconcileHelmRepository(ctx, r.Client, kcm.TemplateRegistryRepoName(registryName), namespace, spec)
+}
+
+// enqueueTemplatesForRegistry returns the handler enqueueing the templates
+// of the given list type referencing the changed TemplateRegistry.
+func (r *TemplateReconciler) enqueueTemplatesForRegistry(list client.ObjectList) handler.EventHandler {
+	return handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, o client.Object) []ctrl.Request {
+		templates, ok := list.DeepCopyObject().(client.ObjectList)
+		if !ok {
+			return nil
+		}
+		if err := r.List(ctx, templates, client.MatchingFields{kcm.TemplateRegistryIndexKey: o.GetName()}); err != nil {
+			ctrl.LoggerFrom(ctx).Error(err, "failed to list templates referencing the TemplateRegistry", "registry", o.GetName())
+			return nil
+		}
+
+		var requests []ctrl.Request
+		_ = apimeta.EachListItem(templates, func(obj runtime.Object) error {
+			if template, ok := obj.(client.Object); ok {
+				requests = append(requests, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(template)})
+			}
+			return nil
+		})
+
+		return requests
+	})
+}
+
+// enqueueTemplatesForCertSecret returns the handler enqueueing the templates of the
+// given list type in the namespace of the changed Secret if the Secret is referenced by
+// the certSecretRef of any of the HelmRepositories managed by KCM in the namespace.
+func (r *TemplateReconciler) enqueueTemplatesForCertSecret(list client.ObjectList) handler.EventHandler {
+	return handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, o client.Object) []ctrl.Request {
+		repos := &sourcev1.HelmRepositoryList{}
+		if err := r.List(ctx, repos, client.InNamespace(o.GetNamespace()), client.MatchingLabels{kcm.KCMManagedLabelKey: kcm.KCMManagedLabelValue}); err != nil {
+			ctrl.LoggerFrom(ctx).Error(err, "failed to list HelmRepositories", "namespace", o.GetNamespace())
+			return nil
+		}
+		if !slices.ContainsFunc(repos.Items, func(repo sourcev1.HelmRepository) bool {
+			return repo.Spec.CertSecretRef != nil && repo.Spec.CertSecretRef.Name == o.GetName()
+		}) {
+			return nil
+		}
+
+		templates, ok := list.DeepCopyObject().(client.ObjectList)
+		if !ok {
+			return nil
+		}
+		if err := r.List(ctx, templates); err != nil {
+			ctrl.LoggerFrom(ctx).Error(err, "failed to list templates referencing the TLS Secret", "secret", client.ObjectKeyFromObject(o))
+			return nil
+		}
+
+		var requests []ctrl.Request
+		_ = apimeta.EachListItem(templates, func(obj runtime.Object) error {
+			template, ok := obj.(client.Object)
+			if !ok {
+				return nil
+			}
+			// the cluster-scoped templates are sourced from the system namespace
+			namespace := template.GetNamespace()
+			if namespace == "" {
+				namespace = r.SystemNamespace
+			}
+			if namespace == o.GetNamespace() {
+				requests = append(requests, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(template)})
+			}
+			return nil
+		})
+
+		return requests
+	})
+}
+
 func (r *TemplateReconciler) getHelmChartFromChartRef(ctx context.Context, chartRef *helmcontrollerv2.CrossNamespaceSourceReference) (*sourcev1.HelmChart, error) {
 	if chartRef.Kind != sourcev1.HelmChartKind {
 		return nil, fmt.Errorf("invalid chartRef.Kind: %s. Only HelmChart kind is supported", chartRef.Kind)
@@ -459,20 +599,36 @@ func (r *ClusterTemplateReconciler) validateCompatibilityAttrs(ctx context.Conte
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *ClusterTemplateReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.recorder = mgr.GetEventRecorderFor("clustertemplate-controller")
+
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&kcm.ClusterTemplate{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
-		Complete(r)
+		Watches(&kcm.TemplateRegistry{}, r.enqueueTemplatesForRegistry(&kcm.ClusterTemplateList{}),
+			builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(&corev1.Secret{}, r.enqueueTemplatesForCertSecret(&kcm.ClusterTemplateList{}),
+			builder.WithPredicates(predicate.ResourceVersionChangedPredicate{})).
+		WithOptions(config.ControllerOptions("ClusterTemplate")).
+		Complete(tracing.Reconciler("ClusterTemplate", r))
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *ServiceTemplateReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.recorder = mgr.GetEventRecorderFor("servicetemplate-controller")
+
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&kcm.ServiceTemplate{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
-		Complete(r)
+		Watches(&kcm.TemplateRegistry{}, r.enqueueTemplatesForRegistry(&kcm.ServiceTemplateList{}),
+			builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(&corev1.Secret{}, r.enqueueTemplatesForCertSecret(&kcm.ServiceTemplateList{}),
+			builder.WithPredicates(predicate.ResourceVersionChangedPredicate{})).
+		WithOptions(config.ControllerOptions("ServiceTemplate")).
+		Complete(tracing.Reconciler("ServiceTemplate", r))
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *ProviderTemplateReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.recorder = mgr.GetEventRecorderFor("providertemplate-controller")
+
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&kcm.ProviderTemplate{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
 		Watches(&kcm.Release{},
@@ -498,5 +654,10 @@ func (r *ProviderTemplateReconciler) SetupWithManager(mgr ctrl.Manager) error {
 				DeleteFunc:  func(event.DeleteEvent) bool { return false },
 			}),
 		).
-		Complete(r)
+		Watches(&kcm.TemplateRegistry{}, r.enqueueTemplatesForRegistry(&kcm.ProviderTemplateList{}),
+			builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(&corev1.Secret{}, r.enqueueTemplatesForCertSecret(&kcm.ProviderTemplateList{}),
+			builder.WithPredicates(predicate.ResourceVersionChangedPredicate{})).
+		WithOptions(config.ControllerOptions("ProviderTemplate")).
+		Complete(tracing.Reconciler("ProviderTemplate", r))
 }
@@ -20,13 +20,16 @@ import (
 	"time"
 
 	helmcontrollerv2 "github.com/fluxcd/helm-controller/api/v2"
+	"github.com/fluxcd/pkg/apis/meta"
 	sourcev1 "github.com/fluxcd/source-controller/api/v1"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"helm.sh/helm/v3/pkg/chart"
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
@@ -179,6 +182,7 @@ var _ = Describe("Template Controller", func() {
 			templateReconciler := TemplateReconciler{
 				Client:                mgrClient,
 				downloadHelmChartFunc: fakeDownloadHelmChartFunc,
+				recorder:              &record.FakeRecorder{},
 			}
 			By("Reconciling the ClusterTemplate resource")
 			clusterTemplateReconciler := &ClusterTemplateReconciler{TemplateReconciler: templateReconciler}
@@ -294,6 +298,7 @@ var _ = Describe("Template Controller", func() {
 			clusterTemplateReconciler := &ClusterTemplateReconciler{TemplateReconciler: TemplateReconciler{
 				Client:                k8sClient,
 				downloadHelmChartFunc: fakeDownloadHelmChartFunc,
+				recorder:              &record.FakeRecorder{},
 			}}
 			_, err := clusterTemplateReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{
 				Name:      clusterTemplateName,
@@ -318,5 +323,74 @@ var _ = Describe("Template Controller", func() {
 				return apierrors.IsNotFound(k8sClient.Get(ctx, client.ObjectKeyFromObject(clusterTemplate), &kcmv1.ClusterTemplate{}))
 			}).WithTimeout(timeout).WithPolling(interval).Should(BeTrue())
 		})
+
+		It("should reconcile the HelmRepository of the referenced TemplateRegistry", func() {
+			const (
+				registryName        = "private-registry"
+				serviceTemplateName = "service-template-registry"
+			)
+
+			registry := &kcmv1.TemplateRegistry{
+				ObjectMeta: metav1.ObjectMeta{Name: registryName},
+				Spec: kcmv1.TemplateRegistrySpec{
+					URL:               "oci://registry.example.com/charts",
+					CredentialsSecret: "registry-creds",
+					CertSecret:        "registry-ca",
+				},
+			}
+			Expect(k8sClient.Create(ctx, registry)).To(Succeed())
+
+			certSecret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "registry-ca", Namespace: metav1.NamespaceDefault},
+			}
+			Expect(k8sClient.Create(ctx, certSecret)).To(Succeed())
+
+			serviceTemplate := &kcmv1.ServiceTemplate{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      serviceTemplateName,
+					Namespace: metav1.NamespaceDefault,
+				},
+				Spec: kcmv1.ServiceTemplateSpec{
+					Helm: kcmv1.HelmSpec{
+						Registry: registryName,
+						ChartSpec: &sourcev1.HelmChartSpec{
+							Chart:     "ingress-nginx",
+							Version:   "4.11.0",
+							SourceRef: kcmv1.DefaultSourceRef,
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, serviceTemplate)).To(Succeed())
+
+			By("Reconciling the ServiceTemplate referencing the TemplateRegistry")
+			serviceTemplateReconciler := &ServiceTemplateReconciler{TemplateReconciler: TemplateReconciler{
+				Client:   mgrClient,
+				recorder: &record.FakeRecorder{},
+			}}
+			// the artifact of the HelmChart is never ready in the test environment
+			_, _ = serviceTemplateReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(serviceTemplate)})
+
+			By("Having the HelmRepository of the TemplateRegistry in the namespace of the template")
+			repo := &sourcev1.HelmRepository{}
+			Expect(k8sClient.Get(ctx, client.ObjectKey{Namespace: metav1.NamespaceDefault, Name: kcmv1.TemplateRegistryRepoName(registryName)}, repo)).To(Succeed())
+			Expect(repo.Spec.Type).To(Equal("oci"))
+			Expect(repo.Spec.URL).To(Equal(registry.Spec.URL))
+			Expect(repo.Spec.SecretRef.Name).To(Equal("registry-creds"))
+			Expect(repo.Spec.CertSecretRef.Name).To(Equal("registry-ca"))
+			Expect(repo.Annotations).To(HaveKeyWithValue(meta.ReconcileRequestAnnotation, certSecret.ResourceVersion),
+				"the rotation of the TLS Secret must trigger the reconciliation of the HelmRepository")
+
+			By("Having the HelmChart sourced from the HelmRepository of the TemplateRegistry")
+			chart := &sourcev1.HelmChart{}
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(serviceTemplate), chart)).To(Succeed())
+			Expect(chart.Spec.SourceRef.Name).To(Equal(kcmv1.TemplateRegistryRepoName(registryName)))
+
+			By("Removing the created objects")
+			Expect(k8sClient.Delete(ctx, serviceTemplate)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, repo)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, registry)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, certSecret)).To(Succeed())
+		})
 	})
 })
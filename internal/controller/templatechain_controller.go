@@ -18,14 +18,25 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 
+	"github.com/Masterminds/semver/v3"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 
 	kcm "github.com/K0rdent/kcm/api/v1alpha1"
+	"github.com/K0rdent/kcm/internal/config"
+	"github.com/K0rdent/kcm/internal/templatechain"
+	"github.com/K0rdent/kcm/internal/tracing"
 	"github.com/K0rdent/kcm/internal/utils"
 )
 
@@ -35,6 +46,7 @@ type TemplateChainReconciler struct {
 	SystemNamespace string
 
 	templateKind string
+	recorder     record.EventRecorder
 }
 
 type ClusterTemplateChainReconciler struct {
@@ -49,6 +61,7 @@ type ServiceTemplateChainReconciler struct {
 type templateChain interface {
 	client.Object
 	GetSpec() *kcm.TemplateChainSpec
+	GetStatus() *kcm.TemplateChainStatus
 }
 
 func (r *ClusterTemplateChainReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -106,6 +119,11 @@ func (r *TemplateChainReconciler) ReconcileTemplateChain(ctx context.Context, te
 		return ctrl.Result{}, err
 	}
 
+	if err := r.updateStatus(ctx, templateChain, management); err != nil {
+		l.Error(err, "failed to update the chain status")
+		return ctrl.Result{}, err
+	}
+
 	if templateChain.GetNamespace() == r.SystemNamespace ||
 		templateChain.GetLabels()[kcm.KCMManagedLabelKey] != kcm.KCMManagedLabelValue {
 		return ctrl.Result{}, nil
@@ -136,7 +154,10 @@ func (r *TemplateChainReconciler) ReconcileTemplateChain(ctx context.Context, te
 			continue
 		}
 
-		var target client.Object
+		var (
+			target       client.Object
+			setLifecycle func()
+		)
 		switch r.templateKind {
 		case kcm.ClusterTemplateKind:
 			clusterTemplate, ok := source.(*kcm.ClusterTemplate)
@@ -145,7 +166,9 @@ func (r *TemplateChainReconciler) ReconcileTemplateChain(ctx context.Context, te
 			}
 			spec := clusterTemplate.Spec
 			spec.Helm = kcm.HelmSpec{ChartRef: clusterTemplate.Status.ChartRef}
-			target = &kcm.ClusterTemplate{ObjectMeta: meta, Spec: spec}
+			targetTemplate := &kcm.ClusterTemplate{ObjectMeta: meta, Spec: spec}
+			setLifecycle = func() { targetTemplate.Spec.Lifecycle = clusterTemplate.Spec.Lifecycle.DeepCopy() }
+			target = targetTemplate
 		case kcm.ServiceTemplateKind:
 			serviceTemplate, ok := source.(*kcm.ServiceTemplate)
 			if !ok {
@@ -153,13 +176,17 @@ func (r *TemplateChainReconciler) ReconcileTemplateChain(ctx context.Context, te
 			}
 			spec := serviceTemplate.Spec
 			spec.Helm = kcm.HelmSpec{ChartRef: serviceTemplate.Status.ChartRef}
-			target = &kcm.ServiceTemplate{ObjectMeta: meta, Spec: spec}
+			targetTemplate := &kcm.ServiceTemplate{ObjectMeta: meta, Spec: spec}
+			setLifecycle = func() { targetTemplate.Spec.Lifecycle = serviceTemplate.Spec.Lifecycle.DeepCopy() }
+			target = targetTemplate
 		default:
 			return ctrl.Result{}, fmt.Errorf("invalid Template kind. Supported kinds are %s and %s", kcm.ClusterTemplateKind, kcm.ServiceTemplateKind)
 		}
 
 		operation, err := ctrl.CreateOrUpdate(ctx, r.Client, target, func() error {
 			utils.AddOwnerReference(target, templateChain)
+			// the lifecycle is the only mutable part of the spec, keep it in sync with the source
+			setLifecycle()
 			return nil
 		})
 		if err != nil {
@@ -171,13 +198,104 @@ func (r *TemplateChainReconciler) ReconcileTemplateChain(ctx context.Context, te
 			l.Info(r.templateKind+" was successfully created", "template namespace", templateChain.GetNamespace(), "template name", supportedTemplate.Name)
 		}
 		if operation == controllerutil.OperationResultUpdated {
-			l.Info("Successfully updated OwnerReference on "+r.templateKind, "template namespace", templateChain.GetNamespace(), "template name", supportedTemplate.Name)
+			l.Info("Successfully updated "+r.templateKind, "template namespace", templateChain.GetNamespace(), "template name", supportedTemplate.Name)
 		}
 	}
 
 	return ctrl.Result{}, errs
 }
 
+// updateStatus resolves the upgrade rules of the chain against the chart versions
+// of the supported Templates from the chain namespace, validates the resulting
+// upgrade graph and updates the chain status.
+func (r *TemplateChainReconciler) updateStatus(ctx context.Context, templateChain templateChain, management *kcm.Management) error {
+	templates, err := r.getTemplates(ctx, &client.ListOptions{Namespace: templateChain.GetNamespace()})
+	if err != nil {
+		return fmt.Errorf("failed to get templates: %w", err)
+	}
+
+	var resolved []kcm.ResolvedUpgrade
+	if len(templateChain.GetSpec().UpgradeRules) > 0 {
+		versions := make([]utils.TemplateChartVersion, 0, len(templateChain.GetSpec().SupportedTemplates))
+		for _, supportedTemplate := range templateChain.GetSpec().SupportedTemplates {
+			template, ok := templates[supportedTemplate.Name]
+			if !ok {
+				continue
+			}
+
+			// the Templates not yet reconciled are picked up as soon as their status is updated
+			status := template.GetCommonStatus()
+			if status.ChartName == "" || status.ChartVersion == "" {
+				continue
+			}
+			version, err := semver.NewVersion(status.ChartVersion)
+			if err != nil {
+				continue
+			}
+
+			versions = append(versions, utils.TemplateChartVersion{Name: template.GetName(), Chart: status.ChartName, Version: version})
+		}
+
+		resolved, err = utils.ResolveUpgradeRules(templateChain.GetSpec().UpgradeRules, versions)
+		if err != nil {
+			return err
+		}
+	}
+
+	newStatus := &kcm.TemplateChainStatus{
+		ResolvedUpgrades:   resolved,
+		ObservedGeneration: templateChain.GetGeneration(),
+	}
+
+	graph := templatechain.Compute(templateChain.GetSpec(), newStatus, templateInfos(templates), &management.Status)
+	newStatus.Graph = graph.Graph
+	newStatus.UnreachableTemplates = graph.UnreachableTemplates
+	newStatus.Warnings = graph.Warnings
+	newStatus.Valid = graph.Valid()
+
+	status := templateChain.GetStatus()
+	if equality.Semantic.DeepEqual(status, newStatus) {
+		return nil
+	}
+
+	// the chain never observed before has not been valid yet either
+	wasValid := status.Valid || status.ObservedGeneration == 0
+	*status = *newStatus
+	if err := r.Status().Update(ctx, templateChain); err != nil {
+		return err
+	}
+
+	if wasValid && !newStatus.Valid {
+		r.recorder.Eventf(templateChain, corev1.EventTypeWarning, EventReasonTemplateChainInvalid, "The upgrade graph is invalid: %s",
+			strings.Join(newStatus.Warnings, "; "))
+	}
+
+	return nil
+}
+
+// templateInfos converts the given Templates to the attributes the upgrade graph is validated against.
+func templateInfos(templates map[string]templateCommon) map[string]templatechain.TemplateInfo {
+	infos := make(map[string]templatechain.TemplateInfo, len(templates))
+	for name, template := range templates {
+		status := template.GetCommonStatus()
+		info := templatechain.TemplateInfo{
+			Name:            name,
+			Valid:           status.Valid,
+			ValidationError: status.ValidationError,
+		}
+		switch t := template.(type) {
+		case *kcm.ClusterTemplate:
+			info.KubernetesVersion = t.Status.KubernetesVersion
+			info.Providers = t.Status.Providers
+			info.ProviderContracts = t.Status.ProviderContracts
+		case *kcm.ServiceTemplate:
+			info.Providers = t.Status.Providers
+		}
+		infos[name] = info
+	}
+	return infos
+}
+
 func (r *TemplateChainReconciler) getTemplates(ctx context.Context, opts *client.ListOptions) (map[string]templateCommon, error) {
 	templates := make(map[string]templateCommon)
 
@@ -214,20 +332,56 @@ func getTemplateNamesManagedByChain(chain templateChain) []string {
 	return result
 }
 
+// enqueueChainsForTemplate returns the map function enqueueing the chains supporting the given Template,
+// so that the upgrade rules are resolved and the upgrade graph is validated once the Template status is known.
+func (r *TemplateChainReconciler) enqueueChainsForTemplate(chains client.ObjectList) handler.MapFunc {
+	return func(ctx context.Context, o client.Object) []ctrl.Request {
+		list, ok := chains.DeepCopyObject().(client.ObjectList)
+		if !ok {
+			return nil
+		}
+		if err := r.List(ctx, list,
+			client.InNamespace(o.GetNamespace()),
+			client.MatchingFields{kcm.TemplateChainSupportedTemplatesIndexKey: o.GetName()},
+		); err != nil {
+			return nil
+		}
+
+		var requests []ctrl.Request
+		_ = apimeta.EachListItem(list, func(obj runtime.Object) error {
+			if chain, ok := obj.(templateChain); ok {
+				requests = append(requests, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(chain)})
+			}
+			return nil
+		})
+		return requests
+	}
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *ClusterTemplateChainReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	r.templateKind = kcm.ClusterTemplateKind
+	r.recorder = mgr.GetEventRecorderFor("clustertemplatechain-controller")
 
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&kcm.ClusterTemplateChain{}).
-		Complete(r)
+		Watches(&kcm.ClusterTemplate{},
+			handler.EnqueueRequestsFromMapFunc(r.enqueueChainsForTemplate(&kcm.ClusterTemplateChainList{})),
+		).
+		WithOptions(config.ControllerOptions("ClusterTemplateChain")).
+		Complete(tracing.Reconciler("ClusterTemplateChain", r))
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *ServiceTemplateChainReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	r.templateKind = kcm.ServiceTemplateKind
+	r.recorder = mgr.GetEventRecorderFor("servicetemplatechain-controller")
 
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&kcm.ServiceTemplateChain{}).
-		Complete(r)
+		Watches(&kcm.ServiceTemplate{},
+			handler.EnqueueRequestsFromMapFunc(r.enqueueChainsForTemplate(&kcm.ServiceTemplateChainList{})),
+		).
+		WithOptions(config.ControllerOptions("ServiceTemplateChain")).
+		Complete(tracing.Reconciler("ServiceTemplateChain", r))
 }
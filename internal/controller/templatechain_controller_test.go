@@ -27,6 +27,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	crclient "sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
@@ -235,6 +236,7 @@ var _ = Describe("Template Chain Controller", func() {
 			templateChainReconciler := TemplateChainReconciler{
 				Client:          mgrClient,
 				SystemNamespace: utils.DefaultSystemNamespace,
+				recorder:        &record.FakeRecorder{},
 			}
 
 			for _, chain := range ctChainNames {
@@ -294,6 +296,7 @@ var _ = Describe("Template Chain Controller", func() {
 			templateChainReconciler := TemplateChainReconciler{
 				Client:          mgrClient,
 				SystemNamespace: utils.DefaultSystemNamespace,
+				recorder:        &record.FakeRecorder{},
 			}
 			By("Reconciling the ClusterTemplateChain resources")
 			for _, chain := range ctChainNames {
@@ -0,0 +1,298 @@
+// Copyright 2024
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	sourcev1 "github.com/fluxcd/source-controller/api/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	kcm "github.com/K0rdent/kcm/api/v1alpha1"
+	"github.com/K0rdent/kcm/internal/config"
+	"github.com/K0rdent/kcm/internal/helm"
+	"github.com/K0rdent/kcm/internal/tracing"
+	"github.com/K0rdent/kcm/internal/utils"
+)
+
+// TemplateSourceReconciler reconciles a TemplateSource object
+type TemplateSourceReconciler struct {
+	client.Client
+
+	listChartVersionsFunc func(ctx context.Context, cl client.Client, repository *sourcev1.HelmRepository, pattern, constraint string) ([]helm.ChartVersion, error)
+
+	SystemNamespace string
+}
+
+func (r *TemplateSourceReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
+	l := ctrl.LoggerFrom(ctx)
+	l.Info("Reconciling TemplateSource")
+
+	templateSource := new(kcm.TemplateSource)
+	if err := r.Get(ctx, req.NamespacedName, templateSource); err != nil {
+		if apierrors.IsNotFound(err) {
+			l.Info("TemplateSource not found, ignoring since object must be deleted")
+			return ctrl.Result{}, nil
+		}
+		l.Error(err, "Failed to get TemplateSource")
+		return ctrl.Result{}, err
+	}
+
+	management := &kcm.Management{}
+	if err := r.Get(ctx, client.ObjectKey{Name: kcm.ManagementName}, management); client.IgnoreNotFound(err) != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to get Management: %w", err)
+	}
+	if !management.DeletionTimestamp.IsZero() {
+		l.Info("Management is being deleted, skipping TemplateSource reconciliation")
+		return ctrl.Result{}, nil
+	}
+
+	if updated, err := utils.AddKCMComponentLabel(ctx, r.Client, templateSource); updated || err != nil {
+		if err != nil {
+			l.Error(err, "adding component label")
+		}
+		return ctrl.Result{Requeue: true}, err // generation has not changed, need explicit requeue
+	}
+
+	defer func() {
+		templateSource.Status.ObservedGeneration = templateSource.Generation
+		err = errors.Join(err, r.Status().Update(ctx, templateSource))
+	}()
+
+	interval := helm.DefaultReconcileInterval
+	if templateSource.Spec.Interval != nil {
+		interval = templateSource.Spec.Interval.Duration
+	}
+
+	err = r.discoverTemplates(ctx, templateSource)
+	updateTemplateSourceReadyCondition(templateSource, err)
+	if err != nil {
+		l.Error(err, "failed to discover templates")
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{RequeueAfter: interval}, nil
+}
+
+// discoverTemplates creates the Templates for the chart versions available in the repository
+// and appends them to the chain if the latter is specified.
+func (r *TemplateSourceReconciler) discoverTemplates(ctx context.Context, templateSource *kcm.TemplateSource) error {
+	repository := new(sourcev1.HelmRepository)
+	if err := r.Get(ctx, client.ObjectKey{Namespace: r.SystemNamespace, Name: templateSource.Spec.Repository}, repository); err != nil {
+		return fmt.Errorf("failed to get HelmRepository %s/%s: %w", r.SystemNamespace, templateSource.Spec.Repository, err)
+	}
+
+	listChartVersions := r.listChartVersionsFunc
+	if listChartVersions == nil {
+		listChartVersions = helm.ListChartVersions
+	}
+	versions, err := listChartVersions(ctx, r.Client, repository, templateSource.Spec.ChartPattern, templateSource.Spec.VersionConstraint)
+	if err != nil {
+		return fmt.Errorf("failed to list chart versions: %w", err)
+	}
+
+	discovered := make([]kcm.DiscoveredTemplate, 0, len(versions))
+	var errs error
+	for _, version := range versions {
+		template := kcm.DiscoveredTemplate{
+			Name:    templateNameFromChartVersion(version),
+			Chart:   version.Name,
+			Version: version.Version.Original(),
+		}
+		if err := r.createTemplate(ctx, templateSource, template); err != nil {
+			errs = errors.Join(errs, err)
+			continue
+		}
+		discovered = append(discovered, template)
+	}
+
+	if templateSource.Spec.Chain != "" {
+		if err := r.updateChain(ctx, templateSource, discovered); err != nil {
+			errs = errors.Join(errs, err)
+		}
+	}
+
+	templateSource.Status.Templates = discovered
+	if errs == nil {
+		templateSource.Status.LastSyncTime = &metav1.Time{Time: time.Now()}
+	}
+
+	return errs
+}
+
+func (r *TemplateSourceReconciler) createTemplate(ctx context.Context, templateSource *kcm.TemplateSource, template kcm.DiscoveredTemplate) error {
+	meta := metav1.ObjectMeta{
+		Name:      template.Name,
+		Namespace: r.SystemNamespace,
+		Labels: map[string]string{
+			kcm.TemplateSourceLabelKey: templateSource.Name,
+		},
+	}
+	helmSpec := kcm.HelmSpec{
+		ChartSpec: &sourcev1.HelmChartSpec{
+			Chart:   template.Chart,
+			Version: template.Version,
+			SourceRef: sourcev1.LocalHelmChartSourceReference{
+				Kind: sourcev1.HelmRepositoryKind,
+				Name: templateSource.Spec.Repository,
+			},
+			Interval: metav1.Duration{Duration: helm.DefaultReconcileInterval},
+		},
+	}
+
+	var target client.Object
+	switch templateSource.Spec.TemplateKind {
+	case kcm.ClusterTemplateKind:
+		target = &kcm.ClusterTemplate{ObjectMeta: meta, Spec: kcm.ClusterTemplateSpec{Helm: helmSpec}}
+	case kcm.ServiceTemplateKind:
+		target = &kcm.ServiceTemplate{ObjectMeta: meta, Spec: kcm.ServiceTemplateSpec{Helm: helmSpec}}
+	default:
+		return fmt.Errorf("invalid Template kind. Supported kinds are %s and %s", kcm.ClusterTemplateKind, kcm.ServiceTemplateKind)
+	}
+
+	// the already existing Templates, including the ones not created by the TemplateSource, are left intact
+	if err := r.Create(ctx, target); err != nil {
+		if apierrors.IsAlreadyExists(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to create %s %s/%s: %w", templateSource.Spec.TemplateKind, r.SystemNamespace, template.Name, err)
+	}
+
+	ctrl.LoggerFrom(ctx).Info(templateSource.Spec.TemplateKind+" was successfully created", "template namespace", r.SystemNamespace, "template name", template.Name)
+	return nil
+}
+
+func (r *TemplateSourceReconciler) updateChain(ctx context.Context, templateSource *kcm.TemplateSource, discovered []kcm.DiscoveredTemplate) error {
+	var chain templateChain
+	meta := metav1.ObjectMeta{Name: templateSource.Spec.Chain, Namespace: r.SystemNamespace}
+	switch templateSource.Spec.TemplateKind {
+	case kcm.ClusterTemplateKind:
+		chain = &kcm.ClusterTemplateChain{ObjectMeta: meta}
+	case kcm.ServiceTemplateKind:
+		chain = &kcm.ServiceTemplateChain{ObjectMeta: meta}
+	default:
+		return fmt.Errorf("invalid Template kind. Supported kinds are %s and %s", kcm.ClusterTemplateKind, kcm.ServiceTemplateKind)
+	}
+
+	operation, err := ctrl.CreateOrUpdate(ctx, r.Client, chain, func() error {
+		appendToTemplateChain(chain.GetSpec(), discovered)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update chain %s/%s: %w", r.SystemNamespace, templateSource.Spec.Chain, err)
+	}
+
+	if operation == controllerutil.OperationResultCreated || operation == controllerutil.OperationResultUpdated {
+		ctrl.LoggerFrom(ctx).Info(fmt.Sprintf("Successfully %s chain %s/%s", operation, r.SystemNamespace, templateSource.Spec.Chain))
+	}
+	return nil
+}
+
+// appendToTemplateChain adds the given Templates sorted by chart name and version to the chain spec
+// if not yet present, making each one available as an upgrade from the previous version of the same chart.
+func appendToTemplateChain(spec *kcm.TemplateChainSpec, templates []kcm.DiscoveredTemplate) {
+	indexOf := func(name string) int {
+		for i, supportedTemplate := range spec.SupportedTemplates {
+			if supportedTemplate.Name == name {
+				return i
+			}
+		}
+		return -1
+	}
+
+	for i, template := range templates {
+		if indexOf(template.Name) < 0 {
+			spec.SupportedTemplates = append(spec.SupportedTemplates, kcm.SupportedTemplate{Name: template.Name})
+		}
+
+		if i == 0 || templates[i-1].Chart != template.Chart {
+			continue
+		}
+
+		previous := &spec.SupportedTemplates[indexOf(templates[i-1].Name)]
+		upgrade := kcm.AvailableUpgrade{Name: template.Name}
+		if !slices.Contains(previous.AvailableUpgrades, upgrade) {
+			previous.AvailableUpgrades = append(previous.AvailableUpgrades, upgrade)
+		}
+	}
+}
+
+// templateNameFromChartVersion returns the name of the Template for the given chart version,
+// e.g. the "aws-standalone-cp-0-1-0" for the "aws-standalone-cp" chart of the "0.1.0" version.
+func templateNameFromChartVersion(version helm.ChartVersion) string {
+	return strings.ToLower(version.Name + "-" + strings.NewReplacer(".", "-", "+", "-").Replace(strings.TrimPrefix(version.Version.Original(), "v")))
+}
+
+func updateTemplateSourceReadyCondition(templateSource *kcm.TemplateSource, err error) {
+	condition := metav1.Condition{
+		Type:               kcm.ReadyCondition,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: templateSource.Generation,
+		Reason:             kcm.SucceededReason,
+		Message:            fmt.Sprintf("%d templates discovered", len(templateSource.Status.Templates)),
+	}
+	if err != nil {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = kcm.FailedReason
+		condition.Message = err.Error()
+	}
+	apimeta.SetStatusCondition(&templateSource.Status.Conditions, condition)
+}
+
+// enqueueTemplateSourcesForRepository enqueues the TemplateSources discovering charts in the given HelmRepository,
+// so that the new versions are discovered as soon as the repository index is updated.
+func (r *TemplateSourceReconciler) enqueueTemplateSourcesForRepository(ctx context.Context, o client.Object) []ctrl.Request {
+	if o.GetNamespace() != r.SystemNamespace {
+		return nil
+	}
+
+	templateSources := new(kcm.TemplateSourceList)
+	if err := r.List(ctx, templateSources); err != nil {
+		return nil
+	}
+
+	var requests []ctrl.Request
+	for _, templateSource := range templateSources.Items {
+		if templateSource.Spec.Repository == o.GetName() {
+			requests = append(requests, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(&templateSource)})
+		}
+	}
+	return requests
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *TemplateSourceReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&kcm.TemplateSource{}).
+		Watches(&sourcev1.HelmRepository{},
+			handler.EnqueueRequestsFromMapFunc(r.enqueueTemplateSourcesForRepository),
+			builder.WithPredicates(predicate.ResourceVersionChangedPredicate{}),
+		).
+		WithOptions(config.ControllerOptions("TemplateSource")).
+		Complete(tracing.Reconciler("TemplateSource", r))
+}
@@ -0,0 +1,159 @@
+// Copyright 2024
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+
+	"github.com/Masterminds/semver/v3"
+	sourcev1 "github.com/fluxcd/source-controller/api/v1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	kcmv1 "github.com/K0rdent/kcm/api/v1alpha1"
+	"github.com/K0rdent/kcm/internal/helm"
+	"github.com/K0rdent/kcm/internal/utils"
+)
+
+var _ = Describe("TemplateSource Controller", func() {
+	Context("When reconciling a resource", func() {
+		const (
+			templateSourceName = "test-template-source"
+			repositoryName     = "test-repository"
+			chainName          = "test-discovered-chain"
+			chartName          = "test-discovered-cp"
+		)
+
+		ctx := context.Background()
+
+		templateSourceKey := types.NamespacedName{Name: templateSourceName}
+		chainKey := types.NamespacedName{Namespace: utils.DefaultSystemNamespace, Name: chainName}
+
+		var versions []string
+		listChartVersions := func(context.Context, crclient.Client, *sourcev1.HelmRepository, string, string) ([]helm.ChartVersion, error) {
+			result := make([]helm.ChartVersion, 0, len(versions))
+			for _, v := range versions {
+				result = append(result, helm.ChartVersion{Name: chartName, Version: semver.MustParse(v)})
+			}
+			return result, nil
+		}
+
+		BeforeEach(func() {
+			By("creating the system namespace")
+			if err := k8sClient.Get(ctx, types.NamespacedName{Name: utils.DefaultSystemNamespace}, &corev1.Namespace{}); errors.IsNotFound(err) {
+				Expect(k8sClient.Create(ctx, &corev1.Namespace{
+					ObjectMeta: metav1.ObjectMeta{Name: utils.DefaultSystemNamespace},
+				})).To(Succeed())
+			}
+
+			By("creating the HelmRepository")
+			repository := &sourcev1.HelmRepository{
+				ObjectMeta: metav1.ObjectMeta{Name: repositoryName, Namespace: utils.DefaultSystemNamespace},
+				Spec:       sourcev1.HelmRepositorySpec{URL: "https://charts.example.com"},
+			}
+			Expect(crclient.IgnoreAlreadyExists(k8sClient.Create(ctx, repository))).To(Succeed())
+
+			By("creating the TemplateSource")
+			templateSource := &kcmv1.TemplateSource{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: templateSourceName,
+					Labels: map[string]string{
+						kcmv1.GenericComponentNameLabel: kcmv1.GenericComponentLabelValueKCM,
+					},
+				},
+				Spec: kcmv1.TemplateSourceSpec{
+					TemplateKind: kcmv1.ClusterTemplateKind,
+					Repository:   repositoryName,
+					ChartPattern: chartName,
+					Chain:        chainName,
+				},
+			}
+			Expect(crclient.IgnoreAlreadyExists(k8sClient.Create(ctx, templateSource))).To(Succeed())
+		})
+
+		AfterEach(func() {
+			By("Cleanup the discovered ClusterTemplates and the chain")
+			Expect(k8sClient.DeleteAllOf(ctx, &kcmv1.ClusterTemplate{},
+				crclient.InNamespace(utils.DefaultSystemNamespace),
+				crclient.MatchingLabels{kcmv1.TemplateSourceLabelKey: templateSourceName},
+			)).To(Succeed())
+			Expect(crclient.IgnoreNotFound(k8sClient.Delete(ctx, &kcmv1.ClusterTemplateChain{
+				ObjectMeta: metav1.ObjectMeta{Name: chainName, Namespace: utils.DefaultSystemNamespace},
+			}))).To(Succeed())
+
+			By("Cleanup the TemplateSource and the HelmRepository")
+			Expect(crclient.IgnoreNotFound(k8sClient.Delete(ctx, &kcmv1.TemplateSource{
+				ObjectMeta: metav1.ObjectMeta{Name: templateSourceName},
+			}))).To(Succeed())
+			Expect(crclient.IgnoreNotFound(k8sClient.Delete(ctx, &sourcev1.HelmRepository{
+				ObjectMeta: metav1.ObjectMeta{Name: repositoryName, Namespace: utils.DefaultSystemNamespace},
+			}))).To(Succeed())
+		})
+
+		It("should create the discovered templates and append them to the chain", func() {
+			reconciler := &TemplateSourceReconciler{
+				Client:                mgrClient,
+				SystemNamespace:       utils.DefaultSystemNamespace,
+				listChartVersionsFunc: listChartVersions,
+			}
+
+			By("Reconciling the TemplateSource with two chart versions available")
+			versions = []string{"0.1.0", "0.2.0"}
+			_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: templateSourceKey})
+			Expect(err).NotTo(HaveOccurred())
+
+			for _, name := range []string{"test-discovered-cp-0-1-0", "test-discovered-cp-0-2-0"} {
+				template := &kcmv1.ClusterTemplate{}
+				Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: utils.DefaultSystemNamespace, Name: name}, template)).To(Succeed())
+				Expect(template.Labels).To(HaveKeyWithValue(kcmv1.TemplateSourceLabelKey, templateSourceName))
+				Expect(template.Spec.Helm.ChartSpec).NotTo(BeNil())
+				Expect(template.Spec.Helm.ChartSpec.Chart).To(Equal(chartName))
+				Expect(template.Spec.Helm.ChartSpec.SourceRef.Name).To(Equal(repositoryName))
+			}
+
+			chain := &kcmv1.ClusterTemplateChain{}
+			Expect(k8sClient.Get(ctx, chainKey, chain)).To(Succeed())
+			Expect(chain.Spec.SupportedTemplates).To(Equal([]kcmv1.SupportedTemplate{
+				{Name: "test-discovered-cp-0-1-0", AvailableUpgrades: []kcmv1.AvailableUpgrade{{Name: "test-discovered-cp-0-2-0"}}},
+				{Name: "test-discovered-cp-0-2-0"},
+			}))
+
+			By("Reconciling the TemplateSource after a new chart version is published")
+			versions = []string{"0.1.0", "0.2.0", "0.3.0"}
+			_, err = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: templateSourceKey})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, chainKey, chain)).To(Succeed())
+			Expect(chain.Spec.SupportedTemplates).To(Equal([]kcmv1.SupportedTemplate{
+				{Name: "test-discovered-cp-0-1-0", AvailableUpgrades: []kcmv1.AvailableUpgrade{{Name: "test-discovered-cp-0-2-0"}}},
+				{Name: "test-discovered-cp-0-2-0", AvailableUpgrades: []kcmv1.AvailableUpgrade{{Name: "test-discovered-cp-0-3-0"}}},
+				{Name: "test-discovered-cp-0-3-0"},
+			}))
+
+			templateSource := &kcmv1.TemplateSource{}
+			Expect(k8sClient.Get(ctx, templateSourceKey, templateSource)).To(Succeed())
+			Expect(templateSource.Status.Templates).To(HaveLen(3))
+			Expect(templateSource.Status.LastSyncTime).NotTo(BeNil())
+			Expect(apimeta.IsStatusConditionTrue(templateSource.Status.Conditions, kcmv1.ReadyCondition)).To(BeTrue())
+		})
+	})
+})
@@ -19,12 +19,14 @@ import (
 	"errors"
 
 	sourcev1 "github.com/fluxcd/source-controller/api/v1"
+	"go.opentelemetry.io/otel/attribute"
 	"helm.sh/helm/v3/pkg/action"
 	"helm.sh/helm/v3/pkg/chart"
 	apimeta "k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/client-go/rest"
 
 	"github.com/K0rdent/kcm/api/v1alpha1"
+	"github.com/K0rdent/kcm/internal/tracing"
 )
 
 type Actor struct {
@@ -64,7 +66,14 @@ func (*Actor) EnsureReleaseWithValues(
 	actionConfig *action.Configuration,
 	hcChart *chart.Chart,
 	clusterDeployment *v1alpha1.ClusterDeployment,
-) error {
+) (err error) {
+	ctx, span := tracing.Start(ctx, "helm.DryRunInstall",
+		attribute.String("k8s.namespace.name", clusterDeployment.Namespace),
+		attribute.String("kcm.release.name", clusterDeployment.Name),
+		attribute.String("kcm.chart.name", hcChart.Name()),
+	)
+	defer func() { tracing.End(span, err) }()
+
 	install := action.NewInstall(actionConfig)
 	install.DryRun = true
 	install.ReleaseName = clusterDeployment.Name
@@ -0,0 +1,129 @@
+// Copyright 2024
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helm
+
+import (
+	"container/list"
+	"path"
+	"sync"
+	"sync/atomic"
+
+	"github.com/K0rdent/kcm/internal/metrics"
+)
+
+// The reasons of the chart cache evictions.
+const (
+	evictionReasonSize     = "size"
+	evictionReasonRevision = "revision"
+)
+
+// chartCache is the chart cache shared by all of the reconcilers, nil disables the caching.
+var chartCache atomic.Pointer[ChartCache]
+
+// SetChartCache sets the chart cache used by the artifact downloads, nil disables the caching.
+func SetChartCache(c *ChartCache) {
+	chartCache.Store(c)
+}
+
+// ChartCache is an in-memory LRU cache of the chart archives keyed by the artifact digest.
+// The archive of the previous revision of a HelmChart artifact is evicted
+// as soon as the archive of the new revision is added.
+type ChartCache struct {
+	mu       sync.Mutex
+	maxBytes int64
+	size     int64
+	lru      *list.List
+	entries  map[string]*list.Element
+	// digests holds the digest of the latest artifact of each source
+	digests map[string]string
+}
+
+type chartCacheEntry struct {
+	digest string
+	source string
+	data   []byte
+}
+
+// NewChartCache returns the chart cache holding up to maxBytes of the chart archives.
+func NewChartCache(maxBytes int64) *ChartCache {
+	return &ChartCache{
+		maxBytes: maxBytes,
+		lru:      list.New(),
+		entries:  make(map[string]*list.Element),
+		digests:  make(map[string]string),
+	}
+}
+
+// Get returns the archive of the chart with the given digest if cached.
+func (c *ChartCache) Get(digest string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[digest]
+	metrics.TrackChartCacheLookup(ok)
+	if !ok {
+		return nil, false
+	}
+	c.lru.MoveToFront(e)
+	return cacheEntry(e).data, true
+}
+
+// Add caches the archive of the artifact with the given URL and digest evicting the least
+// recently used archives above the memory limit and the archive of the previous artifact revision.
+func (c *ChartCache) Add(artifactURL, digest string, data []byte) {
+	if digest == "" || int64(len(data)) > c.maxBytes {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	defer func() { metrics.TrackChartCacheSize(c.size, c.lru.Len()) }()
+
+	// the artifacts of all of the revisions of a HelmChart share the same directory
+	source := path.Dir(artifactURL)
+	if previous, ok := c.digests[source]; ok && previous != digest {
+		if e, ok := c.entries[previous]; ok && cacheEntry(e).source == source {
+			c.remove(e, evictionReasonRevision)
+		}
+	}
+	c.digests[source] = digest
+
+	if e, ok := c.entries[digest]; ok {
+		c.lru.MoveToFront(e)
+		return
+	}
+
+	c.entries[digest] = c.lru.PushFront(&chartCacheEntry{digest: digest, source: source, data: data})
+	c.size += int64(len(data))
+	for c.size > c.maxBytes {
+		c.remove(c.lru.Back(), evictionReasonSize)
+	}
+}
+
+func (c *ChartCache) remove(e *list.Element, reason string) {
+	entry := cacheEntry(e)
+	c.lru.Remove(e)
+	delete(c.entries, entry.digest)
+	if c.digests[entry.source] == entry.digest {
+		delete(c.digests, entry.source)
+	}
+	c.size -= int64(len(entry.data))
+	metrics.TrackChartCacheEviction(reason)
+}
+
+func cacheEntry(e *list.Element) *chartCacheEntry {
+	entry, _ := e.Value.(*chartCacheEntry)
+	return entry
+}
@@ -0,0 +1,91 @@
+// Copyright 2024
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helm
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	godigest "github.com/opencontainers/go-digest"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+)
+
+func TestChartCache(t *testing.T) {
+	g := NewWithT(t)
+
+	cache := NewChartCache(10)
+	cache.Add("http://source-controller/helmchart/default/a/a-0.1.0.tgz", "sha256:a1", []byte("aaaa"))
+	cache.Add("http://source-controller/helmchart/default/b/b-0.1.0.tgz", "sha256:b1", []byte("bbbb"))
+
+	_, ok := cache.Get("sha256:a1")
+	g.Expect(ok).To(BeTrue())
+
+	// evicting the least recently used chart above the memory limit
+	cache.Add("http://source-controller/helmchart/default/c/c-0.1.0.tgz", "sha256:c1", []byte("cccc"))
+	_, ok = cache.Get("sha256:b1")
+	g.Expect(ok).To(BeFalse())
+	_, ok = cache.Get("sha256:a1")
+	g.Expect(ok).To(BeTrue())
+
+	// evicting the previous revision of the chart
+	cache.Add("http://source-controller/helmchart/default/a/a-0.2.0.tgz", "sha256:a2", []byte("aa"))
+	_, ok = cache.Get("sha256:a1")
+	g.Expect(ok).To(BeFalse())
+	data, ok := cache.Get("sha256:a2")
+	g.Expect(ok).To(BeTrue())
+	g.Expect(data).To(BeEquivalentTo("aa"))
+	g.Expect(cache.size).To(BeEquivalentTo(6))
+
+	// skipping the charts above the memory limit
+	cache.Add("http://source-controller/helmchart/default/d/d-0.1.0.tgz", "sha256:d1", []byte("ddddddddddd"))
+	_, ok = cache.Get("sha256:d1")
+	g.Expect(ok).To(BeFalse())
+	g.Expect(cache.size).To(BeEquivalentTo(6))
+}
+
+func TestDownloadChartCached(t *testing.T) {
+	g := NewWithT(t)
+
+	path, err := chartutil.Save(&chart.Chart{
+		Metadata: &chart.Metadata{APIVersion: chart.APIVersionV2, Name: "test", Version: "0.1.0"},
+	}, t.TempDir())
+	g.Expect(err).NotTo(HaveOccurred())
+	archive, err := os.ReadFile(path)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests.Add(1)
+		_, _ = w.Write(archive)
+	}))
+	defer server.Close()
+
+	SetChartCache(NewChartCache(1 << 20))
+	defer SetChartCache(nil)
+
+	digest := godigest.FromBytes(archive).String()
+	for range 3 {
+		ch, err := DownloadChart(context.Background(), server.URL+"/helmchart/default/test/test-0.1.0.tgz", digest)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(ch.Metadata.Name).To(Equal("test"))
+	}
+	g.Expect(requests.Load()).To(BeEquivalentTo(1))
+}
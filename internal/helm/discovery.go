@@ -0,0 +1,169 @@
+// Copyright 2024
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helm
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	sourcev1 "github.com/fluxcd/source-controller/api/v1"
+	"helm.sh/helm/v3/pkg/registry"
+	"helm.sh/helm/v3/pkg/repo"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ChartVersion is a version of a chart available in a repository.
+type ChartVersion struct {
+	Name    string
+	Version *semver.Version
+}
+
+// ListChartVersions returns the versions of the charts available in the given HelmRepository
+// with the names matching the given shell pattern and the versions satisfying the given semver constraint.
+// The versions are sorted by the chart name and version. All of the versions are returned if the constraint is empty.
+func ListChartVersions(ctx context.Context, cl client.Client, repository *sourcev1.HelmRepository, pattern, constraint string) ([]ChartVersion, error) {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, fmt.Errorf("invalid chart pattern %s: %w", pattern, err)
+	}
+
+	var versions []ChartVersion
+	var err error
+	if repository.Spec.Type == sourcev1.HelmRepositoryTypeOCI {
+		versions, err = listOCIChartVersions(ctx, cl, repository, pattern)
+	} else {
+		versions, err = listIndexChartVersions(ctx, repository, pattern)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return FilterChartVersions(versions, constraint)
+}
+
+// FilterChartVersions returns the given versions satisfying the given semver constraint,
+// sorted by the chart name and version. All of the versions are returned if the constraint is empty.
+func FilterChartVersions(versions []ChartVersion, constraint string) ([]ChartVersion, error) {
+	result := slices.Clone(versions)
+	if constraint != "" {
+		c, err := semver.NewConstraint(constraint)
+		if err != nil {
+			return nil, fmt.Errorf("invalid version constraint %s: %w", constraint, err)
+		}
+
+		result = slices.DeleteFunc(result, func(v ChartVersion) bool {
+			return !c.Check(v.Version)
+		})
+	}
+
+	slices.SortFunc(result, func(a, b ChartVersion) int {
+		return cmp.Or(cmp.Compare(a.Name, b.Name), a.Version.Compare(b.Version))
+	})
+	return result, nil
+}
+
+func listIndexChartVersions(ctx context.Context, repository *sourcev1.HelmRepository, pattern string) ([]ChartVersion, error) {
+	if repository.Status.Artifact == nil {
+		return nil, fmt.Errorf("HelmRepository %s/%s index is not ready yet", repository.Namespace, repository.Name)
+	}
+
+	buf, err := fetchArtifact(ctx, repository.Status.Artifact.URL, repository.Status.Artifact.Digest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download HelmRepository %s/%s index: %w", repository.Namespace, repository.Name, err)
+	}
+
+	index := new(repo.IndexFile)
+	if err := yaml.Unmarshal(buf.Bytes(), index); err != nil {
+		return nil, fmt.Errorf("failed to parse HelmRepository %s/%s index: %w", repository.Namespace, repository.Name, err)
+	}
+
+	var versions []ChartVersion
+	for name, entries := range index.Entries {
+		if matched, _ := path.Match(pattern, name); !matched {
+			continue
+		}
+
+		for _, entry := range entries {
+			if entry == nil || entry.Metadata == nil {
+				continue
+			}
+
+			version, err := semver.NewVersion(entry.Version)
+			if err != nil { // skip charts with invalid versions, helm does the same
+				continue
+			}
+
+			versions = append(versions, ChartVersion{Name: name, Version: version})
+		}
+	}
+
+	return versions, nil
+}
+
+func listOCIChartVersions(ctx context.Context, cl client.Client, repository *sourcev1.HelmRepository, chartName string) ([]ChartVersion, error) {
+	if strings.ContainsAny(chartName, `*?[\`) {
+		return nil, errors.New("OCI repositories do not support chart patterns, the exact chart name is expected")
+	}
+
+	opts := []registry.ClientOption{}
+	if repository.Spec.Insecure {
+		opts = append(opts, registry.ClientOptPlainHTTP())
+	}
+	if repository.Spec.SecretRef != nil {
+		secret := new(corev1.Secret)
+		key := client.ObjectKey{Namespace: repository.Namespace, Name: repository.Spec.SecretRef.Name}
+		if err := cl.Get(ctx, key, secret); err != nil {
+			return nil, fmt.Errorf("failed to get HelmRepository %s/%s credentials: %w", repository.Namespace, repository.Name, err)
+		}
+		opts = append(opts, registry.ClientOptBasicAuth(string(secret.Data["username"]), string(secret.Data["password"])))
+	}
+
+	tlsConfig, err := repositoryTLSConfig(ctx, cl, repository)
+	if err != nil {
+		return nil, err
+	}
+	if tlsConfig != nil {
+		opts = append(opts, registry.ClientOptHTTPClient(newHTTPClient(tlsConfig)))
+	}
+
+	registryClient, err := registry.NewClient(opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create registry client: %w", err)
+	}
+
+	ref := strings.TrimSuffix(strings.TrimPrefix(repository.Spec.URL, "oci://"), "/") + "/" + chartName
+	tags, err := registryClient.Tags(ref)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tags of %s: %w", ref, err)
+	}
+
+	versions := make([]ChartVersion, 0, len(tags))
+	for _, tag := range tags {
+		version, err := semver.NewVersion(tag)
+		if err != nil {
+			continue
+		}
+		versions = append(versions, ChartVersion{Name: chartName, Version: version})
+	}
+
+	return versions, nil
+}
@@ -0,0 +1,151 @@
+// Copyright 2024
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helm
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Masterminds/semver/v3"
+	sourcev1 "github.com/fluxcd/source-controller/api/v1"
+	. "github.com/onsi/gomega"
+)
+
+const testIndex = `apiVersion: v1
+entries:
+  aws-standalone-cp:
+  - name: aws-standalone-cp
+    version: 0.1.0
+  - name: aws-standalone-cp
+    version: 0.2.0-nightly.20250101
+  - name: aws-standalone-cp
+    version: 1.0.0
+  - name: aws-standalone-cp
+    version: not-a-version
+  aws-hosted-cp:
+  - name: aws-hosted-cp
+    version: 0.1.1
+  azure-standalone-cp:
+  - name: azure-standalone-cp
+    version: 0.1.0
+generated: "2025-01-01T00:00:00Z"
+`
+
+func TestListChartVersions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(testIndex))
+	}))
+	defer server.Close()
+
+	repository := &sourcev1.HelmRepository{
+		Status: sourcev1.HelmRepositoryStatus{
+			Artifact: &sourcev1.Artifact{URL: server.URL + "/index.yaml"},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		pattern    string
+		constraint string
+		expected   []string
+		err        string
+	}{
+		{
+			name:     "all versions of the matching charts",
+			pattern:  "aws-*",
+			expected: []string{"aws-hosted-cp:0.1.1", "aws-standalone-cp:0.1.0", "aws-standalone-cp:0.2.0-nightly.20250101", "aws-standalone-cp:1.0.0"},
+		},
+		{
+			name:       "versions satisfying the constraint",
+			pattern:    "aws-standalone-cp",
+			constraint: ">= 0.1.0, < 1.0.0",
+			expected:   []string{"aws-standalone-cp:0.1.0"},
+		},
+		{
+			name:       "pre-releases satisfying the constraint",
+			pattern:    "aws-standalone-cp",
+			constraint: "~0.2.0-0",
+			expected:   []string{"aws-standalone-cp:0.2.0-nightly.20250101"},
+		},
+		{
+			name:    "invalid pattern",
+			pattern: "aws-[",
+			err:     "invalid chart pattern aws-[: syntax error in pattern",
+		},
+		{
+			name:       "invalid constraint",
+			pattern:    "aws-*",
+			constraint: "latest",
+			err:        "invalid version constraint latest: improper constraint: latest",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			versions, err := ListChartVersions(context.Background(), nil, repository, tt.pattern, tt.constraint)
+			if tt.err != "" {
+				g.Expect(err).To(MatchError(tt.err))
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+
+			actual := make([]string, 0, len(versions))
+			for _, v := range versions {
+				actual = append(actual, v.Name+":"+v.Version.Original())
+			}
+			g.Expect(actual).To(Equal(tt.expected))
+		})
+	}
+}
+
+func TestListChartVersionsNotReady(t *testing.T) {
+	g := NewWithT(t)
+
+	repository := &sourcev1.HelmRepository{}
+	repository.Namespace, repository.Name = "kcm-system", "repo"
+
+	_, err := ListChartVersions(context.Background(), nil, repository, "*", "")
+	g.Expect(err).To(MatchError("HelmRepository kcm-system/repo index is not ready yet"))
+
+	repository.Spec.Type = sourcev1.HelmRepositoryTypeOCI
+	_, err = ListChartVersions(context.Background(), nil, repository, "*", "")
+	g.Expect(err).To(MatchError("OCI repositories do not support chart patterns, the exact chart name is expected"))
+}
+
+func TestFilterChartVersions(t *testing.T) {
+	g := NewWithT(t)
+
+	versions := []ChartVersion{
+		{Name: "b", Version: semver.MustParse("1.0.0")},
+		{Name: "a", Version: semver.MustParse("1.10.0")},
+		{Name: "a", Version: semver.MustParse("1.9.0")},
+	}
+
+	filtered, err := FilterChartVersions(versions, "")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(filtered).To(Equal([]ChartVersion{versions[2], versions[1], versions[0]}))
+
+	filtered, err = FilterChartVersions(versions, "^1.9")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(filtered).To(Equal([]ChartVersion{versions[2], versions[1]}))
+
+	filtered, err = FilterChartVersions(versions, "~1.9")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(filtered).To(Equal([]ChartVersion{versions[2]}))
+}
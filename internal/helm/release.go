@@ -20,6 +20,7 @@ import (
 
 	hcv2 "github.com/fluxcd/helm-controller/api/v2"
 	"github.com/fluxcd/pkg/apis/meta"
+	"go.opentelemetry.io/otel/attribute"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -27,6 +28,8 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
 	kcm "github.com/K0rdent/kcm/api/v1alpha1"
+	"github.com/K0rdent/kcm/internal/config"
+	"github.com/K0rdent/kcm/internal/tracing"
 )
 
 const (
@@ -40,6 +43,7 @@ type ReconcileHelmReleaseOpts struct {
 	ReconcileInterval *time.Duration
 	TargetNamespace   string
 	DependsOn         []meta.NamespacedObjectReference
+	PostRenderers     []hcv2.PostRenderer
 	CreateNamespace   bool
 	SkipCRDs          bool
 }
@@ -49,7 +53,13 @@ func ReconcileHelmRelease(ctx context.Context,
 	name string,
 	namespace string,
 	opts ReconcileHelmReleaseOpts,
-) (*hcv2.HelmRelease, controllerutil.OperationResult, error) {
+) (_ *hcv2.HelmRelease, _ controllerutil.OperationResult, err error) {
+	ctx, span := tracing.Start(ctx, "helm.ReconcileHelmRelease",
+		attribute.String("k8s.namespace.name", namespace),
+		attribute.String("kcm.helmrelease.name", name),
+	)
+	defer func() { tracing.End(span, err) }()
+
 	hr := &hcv2.HelmRelease{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      name,
@@ -71,12 +81,13 @@ func ReconcileHelmRelease(ctx context.Context,
 				if opts.ReconcileInterval != nil {
 					return *opts.ReconcileInterval
 				}
-				return DefaultReconcileInterval
+				return config.HelmReleaseInterval()
 			}()},
 			ReleaseName:     name,
 			Values:          opts.Values,
 			DependsOn:       opts.DependsOn,
 			TargetNamespace: opts.TargetNamespace,
+			PostRenderers:   opts.PostRenderers,
 			Install: &hcv2.Install{
 				CreateNamespace: opts.CreateNamespace,
 			},
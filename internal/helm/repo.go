@@ -20,12 +20,14 @@ import (
 
 	"github.com/fluxcd/pkg/apis/meta"
 	sourcev1 "github.com/fluxcd/source-controller/api/v1"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
 	kcm "github.com/K0rdent/kcm/api/v1alpha1"
+	"github.com/K0rdent/kcm/internal/utils"
 )
 
 type DefaultRegistryConfig struct {
@@ -36,7 +38,10 @@ type DefaultRegistryConfig struct {
 	RepoType          string
 	URL               string
 	CredentialsSecret string
-	Insecure          bool
+	// CertSecret is the name of the Secret with the CA bundle and
+	// optionally the client certificate and key to connect to the registry.
+	CertSecret string
+	Insecure   bool
 }
 
 func (r *DefaultRegistryConfig) HelmRepositorySpec() sourcev1.HelmRepositorySpec {
@@ -53,11 +58,63 @@ func (r *DefaultRegistryConfig) HelmRepositorySpec() sourcev1.HelmRepositorySpec
 			}
 			return nil
 		}(),
+		CertSecretRef: func() *meta.LocalObjectReference {
+			if r.CertSecret != "" {
+				return &meta.LocalObjectReference{
+					Name: r.CertSecret,
+				}
+			}
+			return nil
+		}(),
 	}
 }
 
+// TemplateRegistryHelmRepositorySpec returns the spec of the HelmRepository
+// serving the charts of the given TemplateRegistry.
+func TemplateRegistryHelmRepositorySpec(registry *kcm.TemplateRegistry) (sourcev1.HelmRepositorySpec, error) {
+	repoType, err := utils.DetermineDefaultRepositoryType(registry.Spec.URL)
+	if err != nil {
+		return sourcev1.HelmRepositorySpec{}, fmt.Errorf("invalid TemplateRegistry %s: %w", registry.Name, err)
+	}
+
+	spec := sourcev1.HelmRepositorySpec{
+		Type:     repoType,
+		URL:      registry.Spec.URL,
+		Interval: metav1.Duration{Duration: DefaultReconcileInterval},
+		Insecure: registry.Spec.Insecure,
+	}
+	if registry.Spec.Interval != nil {
+		spec.Interval = *registry.Spec.Interval
+	}
+	if registry.Spec.CredentialsSecret != "" {
+		spec.SecretRef = &meta.LocalObjectReference{Name: registry.Spec.CredentialsSecret}
+	}
+	if registry.Spec.CertSecret != "" {
+		spec.CertSecretRef = &meta.LocalObjectReference{Name: registry.Spec.CertSecret}
+	}
+
+	return spec, nil
+}
+
+// ReconcileHelmRepository ensures the HelmRepository with the given spec exists.
+// If the certSecretRef is set, the Secret is validated and its version is set as the
+// reconcile request of the HelmRepository, so the rotated certificates are picked up
+// by source-controller without waiting for the interval.
 func ReconcileHelmRepository(ctx context.Context, cl client.Client, name, namespace string, spec sourcev1.HelmRepositorySpec) error {
 	l := ctrl.LoggerFrom(ctx)
+
+	var certSecretVersion string
+	if spec.CertSecretRef != nil {
+		secret := &corev1.Secret{}
+		if err := cl.Get(ctx, client.ObjectKey{Namespace: namespace, Name: spec.CertSecretRef.Name}, secret); err != nil {
+			return fmt.Errorf("failed to get HelmRepository %s/%s TLS Secret: %w", namespace, name, err)
+		}
+		if _, err := TLSConfigFromSecret(secret); err != nil {
+			return err
+		}
+		certSecretVersion = secret.ResourceVersion
+	}
+
 	helmRepo := &sourcev1.HelmRepository{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      name,
@@ -70,6 +127,12 @@ func ReconcileHelmRepository(ctx context.Context, cl client.Client, name, namesp
 		}
 
 		helmRepo.Labels[kcm.KCMManagedLabelKey] = kcm.KCMManagedLabelValue
+		if certSecretVersion != "" {
+			if helmRepo.Annotations == nil {
+				helmRepo.Annotations = make(map[string]string)
+			}
+			helmRepo.Annotations[meta.ReconcileRequestAnnotation] = certSecretVersion
+		}
 		helmRepo.Spec = spec
 		return nil
 	})
@@ -0,0 +1,98 @@
+// Copyright 2024
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helm
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+
+	sourcev1 "github.com/fluxcd/source-controller/api/v1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// The keys of the registry TLS Secret, the same as Flux expects in the certSecretRef of a HelmRepository.
+const (
+	CACertKey     = "ca.crt"
+	ClientCertKey = "tls.crt"
+	ClientKeyKey  = "tls.key"
+)
+
+// TLSConfigFromSecret returns the TLS configuration with the CA bundle and
+// the client certificate and key from the given Secret. All of the keys are optional,
+// though the client certificate and key must be set together.
+func TLSConfigFromSecret(secret *corev1.Secret) (*tls.Config, error) {
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if ca := secret.Data[CACertKey]; len(ca) > 0 {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("failed to parse the CA bundle in the %s key of the Secret %s/%s", CACertKey, secret.Namespace, secret.Name)
+		}
+		cfg.RootCAs = pool
+	}
+
+	certPEM, keyPEM := secret.Data[ClientCertKey], secret.Data[ClientKeyKey]
+	switch {
+	case len(certPEM) > 0 && len(keyPEM) > 0:
+		cert, err := tls.X509KeyPair(certPEM, keyPEM)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse the client certificate of the Secret %s/%s: %w", secret.Namespace, secret.Name, err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	case len(certPEM) > 0 || len(keyPEM) > 0:
+		return nil, fmt.Errorf("both %s and %s keys must be set in the Secret %s/%s", ClientCertKey, ClientKeyKey, secret.Namespace, secret.Name)
+	}
+
+	return cfg, nil
+}
+
+// repositoryTLSConfig returns the TLS configuration from the certSecretRef of
+// the given HelmRepository, it returns nil if the certSecretRef is not set.
+func repositoryTLSConfig(ctx context.Context, cl client.Client, repository *sourcev1.HelmRepository) (*tls.Config, error) {
+	if repository.Spec.CertSecretRef == nil {
+		return nil, nil
+	}
+
+	secret := new(corev1.Secret)
+	key := client.ObjectKey{Namespace: repository.Namespace, Name: repository.Spec.CertSecretRef.Name}
+	if err := cl.Get(ctx, key, secret); err != nil {
+		return nil, fmt.Errorf("failed to get HelmRepository %s/%s TLS Secret: %w", repository.Namespace, repository.Name, err)
+	}
+
+	return TLSConfigFromSecret(secret)
+}
+
+// newHTTPClient returns the HTTP client with the given TLS configuration,
+// the default transport is used if the configuration is nil.
+func newHTTPClient(cfg *tls.Config) *http.Client {
+	if cfg == nil {
+		return http.DefaultClient
+	}
+
+	transport := &http.Transport{Proxy: http.ProxyFromEnvironment}
+	if t, ok := http.DefaultTransport.(*http.Transport); ok {
+		transport = t.Clone()
+	}
+	transport.TLSClientConfig = cfg
+
+	return &http.Client{Transport: transport}
+}
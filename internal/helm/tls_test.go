@@ -0,0 +1,69 @@
+// Copyright 2024
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helm
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestTLSConfigFromSecret(t *testing.T) {
+	g := NewWithT(t)
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(testIndex))
+	}))
+	defer server.Close()
+
+	cert := server.TLS.Certificates[0]
+	key, err := x509.MarshalPKCS8PrivateKey(cert.PrivateKey)
+	g.Expect(err).NotTo(HaveOccurred())
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Certificate[0]})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: key})
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, server.URL, nil)
+	g.Expect(err).NotTo(HaveOccurred())
+	_, err = newHTTPClient(nil).Do(req) //nolint:bodyclose // the request fails
+	g.Expect(err).To(MatchError(ContainSubstring("certificate")), "the server must not be trusted by default")
+
+	cfg, err := TLSConfigFromSecret(&corev1.Secret{Data: map[string][]byte{
+		CACertKey:     certPEM,
+		ClientCertKey: certPEM,
+		ClientKeyKey:  keyPEM,
+	}})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(cfg.Certificates).To(HaveLen(1))
+
+	resp, err := newHTTPClient(cfg).Do(req)
+	g.Expect(err).NotTo(HaveOccurred())
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(string(body)).To(Equal(testIndex))
+
+	_, err = TLSConfigFromSecret(&corev1.Secret{Data: map[string][]byte{CACertKey: []byte("garbage")}})
+	g.Expect(err).To(MatchError(ContainSubstring("failed to parse the CA bundle")))
+
+	_, err = TLSConfigFromSecret(&corev1.Secret{Data: map[string][]byte{ClientCertKey: certPEM}})
+	g.Expect(err).To(MatchError(ContainSubstring("both tls.crt and tls.key keys must be set")))
+}
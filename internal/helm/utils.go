@@ -24,20 +24,55 @@ import (
 	sourcev1 "github.com/fluxcd/source-controller/api/v1"
 	"github.com/hashicorp/go-retryablehttp"
 	godigest "github.com/opencontainers/go-digest"
+	"go.opentelemetry.io/otel/attribute"
 	"helm.sh/helm/v3/pkg/chart"
 	"helm.sh/helm/v3/pkg/chart/loader"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/K0rdent/kcm/internal/tracing"
 )
 
 func DownloadChartFromArtifact(ctx context.Context, artifact *sourcev1.Artifact) (*chart.Chart, error) {
 	return DownloadChart(ctx, artifact.URL, artifact.Digest)
 }
 
-func DownloadChart(ctx context.Context, chartURL, digest string) (*chart.Chart, error) {
-	l := log.FromContext(ctx, "chart", chartURL)
+func DownloadChart(ctx context.Context, chartURL, digest string) (_ *chart.Chart, err error) {
+	ctx, span := tracing.Start(ctx, "helm.DownloadChart", attribute.String("kcm.chart.url", chartURL))
+	defer func() { tracing.End(span, err) }()
+
+	cache := chartCache.Load()
+	if cache != nil && digest != "" {
+		if data, ok := cache.Get(digest); ok {
+			span.SetAttributes(attribute.Bool("kcm.chart.cached", true))
+			return loadChart(chartURL, data)
+		}
+	}
+
+	buf, err := fetchArtifact(ctx, chartURL, digest)
+	if err != nil {
+		return nil, err
+	}
+	if cache != nil {
+		cache.Add(chartURL, digest, buf.Bytes())
+	}
+
+	return loadChart(chartURL, buf.Bytes())
+}
+
+func loadChart(chartURL string, data []byte) (*chart.Chart, error) {
+	helmChart, err := loader.LoadArchive(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load archive for chart %s, %w", chartURL, err)
+	}
+	return helmChart, nil
+}
+
+// fetchArtifact downloads the artifact from the given URL verifying its digest if provided.
+func fetchArtifact(ctx context.Context, artifactURL, digest string) (*bytes.Buffer, error) {
+	l := log.FromContext(ctx, "artifact", artifactURL)
 
 	client := retryablehttp.NewClient()
-	req, err := retryablehttp.NewRequestWithContext(ctx, http.MethodGet, chartURL, nil)
+	req, err := retryablehttp.NewRequestWithContext(ctx, http.MethodGet, artifactURL, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -47,23 +82,18 @@ func DownloadChart(ctx context.Context, chartURL, digest string) (*chart.Chart,
 	}
 	defer func() {
 		if err := resp.Body.Close(); err != nil {
-			l.Error(err, "Error closing response body after chart download")
+			l.Error(err, "Error closing response body after artifact download")
 		}
 	}()
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("chart download request failed: %s", resp.Status)
+		return nil, fmt.Errorf("artifact download request failed: %s", resp.Status)
 	}
 
 	var buf bytes.Buffer
 	if err := copyChart(resp.Body, &buf, digest); err != nil {
 		return nil, err
 	}
-
-	helmChart, err := loader.LoadArchive(&buf)
-	if err != nil {
-		return nil, fmt.Errorf("failed to load archive for chart %s, %w", chartURL, err)
-	}
-	return helmChart, nil
+	return &buf, nil
 }
 
 func copyChart(reader io.Reader, writer io.Writer, digest string) error {
@@ -0,0 +1,129 @@
+// Copyright 2024
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kcmctl
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kcm "github.com/K0rdent/kcm/api/v1alpha1"
+)
+
+func newBackupCommand(o *options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "backup",
+		Aliases: []string{"backups"},
+		Short:   "Manage ManagementBackups",
+	}
+
+	cmd.AddCommand(
+		newBackupCreateCommand(o),
+		newBackupListCommand(o),
+	)
+
+	return cmd
+}
+
+func newBackupCreateCommand(o *options) *cobra.Command {
+	var storageLocation string
+	cmd := &cobra.Command{
+		Use:   "create [NAME]",
+		Short: "Trigger a single ManagementBackup",
+		Long:  "Trigger a single ManagementBackup, the name defaults to kcmctl-<timestamp>.",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cl, err := o.newClient()
+			if err != nil {
+				return err
+			}
+
+			name := "kcmctl-" + time.Now().UTC().Format("20060102150405")
+			if len(args) > 0 {
+				name = args[0]
+			}
+
+			backup := &kcm.ManagementBackup{
+				ObjectMeta: metav1.ObjectMeta{Name: name},
+				Spec:       kcm.ManagementBackupSpec{StorageLocation: storageLocation},
+			}
+			if err := cl.Create(cmd.Context(), backup); err != nil {
+				return fmt.Errorf("failed to create ManagementBackup %s: %w", name, err)
+			}
+
+			_, err = fmt.Fprintf(o.streams.Out, "ManagementBackup %s created\n", name)
+			return err
+		},
+	}
+
+	cmd.Flags().StringVar(&storageLocation, "storage-location", "", "The name of the Velero BackupStorageLocation to store the backup in.")
+
+	return cmd
+}
+
+func newBackupListCommand(o *options) *cobra.Command {
+	return &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List ManagementBackups",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			cl, err := o.newClient()
+			if err != nil {
+				return err
+			}
+
+			backups := &kcm.ManagementBackupList{}
+			if err := cl.List(cmd.Context(), backups); err != nil {
+				return fmt.Errorf("failed to list ManagementBackups: %w", err)
+			}
+			slices.SortFunc(backups.Items, func(a, b kcm.ManagementBackup) int {
+				return strings.Compare(a.Name, b.Name)
+			})
+
+			w := tabwriter.NewWriter(o.streams.Out, 0, 0, 3, ' ', 0)
+			fmt.Fprintln(w, "NAME\tSCHEDULE\tLAST BACKUP\tPHASE\tLAST BACKUP TIME\tNEXT ATTEMPT\tERROR")
+			for _, backup := range backups.Items {
+				phase := "-"
+				if backup.Status.LastBackup != nil {
+					phase = string(backup.Status.LastBackup.Phase)
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+					backup.Name, orDash(backup.Spec.Schedule), orDash(backup.Status.LastBackupName), phase,
+					formatTime(backup.Status.LastBackupTime), formatTime(backup.Status.NextAttempt), orDash(backup.Status.Error))
+			}
+			return w.Flush()
+		},
+	}
+}
+
+func formatTime(t *metav1.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,180 @@
+// Copyright 2024
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kcmctl
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kcm "github.com/K0rdent/kcm/api/v1alpha1"
+	"github.com/K0rdent/kcm/internal/airgap"
+)
+
+func newBundleCommand(o *options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "bundle",
+		Short: "Export and import the air-gapped installation bundles",
+	}
+
+	cmd.AddCommand(
+		newBundleExportCommand(o),
+		newBundleImportCommand(o),
+	)
+
+	return cmd
+}
+
+func addRegistryFlags(flags *pflag.FlagSet, r *airgap.Registry, defaultURL string) {
+	flags.StringVar(&r.URL, "registry", defaultURL, "The URL of the registry, prefix with oci:// for OCI registries.")
+	flags.StringVar(&r.Username, "username", "", "The username to authenticate to the registry with.")
+	flags.StringVar(&r.Password, "password", "", "The password to authenticate to the registry with.")
+	flags.BoolVar(&r.PlainHTTP, "plain-http", false, "Access the OCI registry over HTTP.")
+}
+
+func newBundleExportCommand(o *options) *cobra.Command {
+	var (
+		opts    airgap.ExportOptions
+		release string
+	)
+	cmd := &cobra.Command{
+		Use:   "export -o DIR (--release NAME | --version VERSION)",
+		Short: "Export the charts of a KCM release into a bundle",
+		Long: `Export the kcm-templates chart of a KCM release and the charts of all of the templates it contains
+into an OCI image layout bundle in the given directory.
+
+The container images the charts refer to with the default values are listed in the images.txt file of
+the bundle and are to be mirrored separately, e.g. with skopeo or crane.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if (release == "") == (opts.Version == "") {
+				return errors.New("exactly one of --release and --version must be set")
+			}
+			if release != "" {
+				cl, err := o.newClient()
+				if err != nil {
+					return err
+				}
+				r := &kcm.Release{}
+				if err := cl.Get(cmd.Context(), client.ObjectKey{Name: release}, r); err != nil {
+					return fmt.Errorf("failed to get Release %s: %w", release, err)
+				}
+				opts.Version = r.Spec.Version
+			}
+
+			manifest, err := airgap.Export(cmd.Context(), opts)
+			if err != nil {
+				return err
+			}
+
+			for _, warning := range manifest.Warnings {
+				fmt.Fprintf(o.streams.ErrOut, "warning: %s\n", warning)
+			}
+			_, err = fmt.Fprintf(o.streams.Out, "Exported %d charts referencing %d images of the release %s to %s\n",
+				len(manifest.Charts), len(manifest.Images), manifest.Version, opts.Dir)
+			return err
+		},
+	}
+
+	addRegistryFlags(cmd.Flags(), &opts.Source, "oci://ghcr.io/k0rdent/kcm/charts")
+	cmd.Flags().StringVarP(&opts.Dir, "output", "o", "", "The directory to write the bundle to.")
+	cmd.Flags().StringVar(&release, "release", "", "The name of the Release in the management cluster to export.")
+	cmd.Flags().StringVar(&opts.Version, "version", "", "The version of the KCM release to export.")
+	cmd.Flags().StringVar(&opts.TemplatesChart, "templates-chart", "kcm-templates", "The name of the chart with the KCM templates.")
+	_ = cmd.MarkFlagRequired("output")
+
+	return cmd
+}
+
+func newBundleImportCommand(o *options) *cobra.Command {
+	var (
+		target           airgap.Registry
+		updateManagement bool
+	)
+	cmd := &cobra.Command{
+		Use:   "import DIR --registry URL",
+		Short: "Import the bundle into the local registry",
+		Long: `Push the charts of the bundle into the local OCI registry.
+
+With --update-management the default registry of the Management is switched to the local registry,
+so all of the templates sourced from the default registry are fetched from it.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			manifest, err := airgap.Import(cmd.Context(), args[0], target)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(o.streams.Out, "Imported %d charts of the release %s to %s\n", len(manifest.Charts), manifest.Version, target.URL)
+
+			if !updateManagement {
+				_, err = fmt.Fprintf(o.streams.Out, "Set the controller.defaultRegistryURL value of the kcm chart to %s to use the charts\n", target.URL)
+				return err
+			}
+
+			if err := o.setDefaultRegistry(cmd, target); err != nil {
+				return err
+			}
+			_, err = fmt.Fprintf(o.streams.Out, "Management default registry is set to %s\n", target.URL)
+			return err
+		},
+	}
+
+	addRegistryFlags(cmd.Flags(), &target, "")
+	cmd.Flags().BoolVar(&updateManagement, "update-management", false, "Switch the default registry of the Management to the registry.")
+	_ = cmd.MarkFlagRequired("registry")
+
+	return cmd
+}
+
+// setDefaultRegistry sets the default registry in the configuration of the kcm component of the Management.
+func (o *options) setDefaultRegistry(cmd *cobra.Command, r airgap.Registry) error {
+	cl, err := o.newClient()
+	if err != nil {
+		return err
+	}
+
+	mgmt := &kcm.Management{}
+	if err := cl.Get(cmd.Context(), client.ObjectKey{Name: kcm.ManagementName}, mgmt); err != nil {
+		return fmt.Errorf("failed to get Management: %w", err)
+	}
+	original := mgmt.DeepCopy()
+
+	config := make(map[string]any)
+	if mgmt.Spec.Core != nil && mgmt.Spec.Core.KCM.Config != nil {
+		if err := json.Unmarshal(mgmt.Spec.Core.KCM.Config.Raw, &config); err != nil {
+			return fmt.Errorf("failed to parse the kcm component config: %w", err)
+		}
+	}
+	setValue(config, []string{"controller", "defaultRegistryURL"}, r.URL)
+	setValue(config, []string{"controller", "insecureRegistry"}, r.PlainHTTP)
+	raw, err := json.Marshal(config)
+	if err != nil {
+		return err
+	}
+
+	if mgmt.Spec.Core == nil {
+		mgmt.Spec.Core = &kcm.Core{}
+	}
+	mgmt.Spec.Core.KCM.Config = &apiextensionsv1.JSON{Raw: raw}
+	if err := cl.Patch(cmd.Context(), mgmt, client.MergeFrom(original)); err != nil {
+		return fmt.Errorf("failed to update Management: %w", err)
+	}
+	return nil
+}
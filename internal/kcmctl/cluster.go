@@ -0,0 +1,466 @@
+// Copyright 2024
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kcmctl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	kcm "github.com/K0rdent/kcm/api/v1alpha1"
+	"github.com/K0rdent/kcm/internal/clusteraccess"
+)
+
+func newClusterCommand(o *options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "cluster",
+		Aliases: []string{"clusters", "cd"},
+		Short:   "Manage ClusterDeployments",
+	}
+
+	cmd.AddCommand(
+		newClusterCreateCommand(o),
+		newClusterListCommand(o),
+		newClusterUpgradesCommand(o),
+		newClusterUpgradeCommand(o),
+		newClusterKubeconfigCommand(o),
+	)
+
+	return cmd
+}
+
+type clusterCreateOptions struct {
+	template    string
+	credential  string
+	valuesFile  string
+	interactive bool
+	print       bool
+	dryRun      bool
+}
+
+func newClusterCreateCommand(o *options) *cobra.Command {
+	co := &clusterCreateOptions{}
+	cmd := &cobra.Command{
+		Use:   "create NAME --template TEMPLATE --credential CREDENTIAL",
+		Short: "Create a ClusterDeployment from a ClusterTemplate",
+		Long: `Create a ClusterDeployment from a ClusterTemplate.
+
+With --interactive the values of the ClusterTemplate are prompted for one by one,
+the default values are taken from the ClusterTemplate and the values file if any.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.createCluster(cmd, args[0], co)
+		},
+	}
+
+	cmd.Flags().StringVarP(&co.template, "template", "t", "", "The name of the ClusterTemplate.")
+	cmd.Flags().StringVarP(&co.credential, "credential", "c", "", "The name of the Credential.")
+	cmd.Flags().StringVarP(&co.valuesFile, "values", "f", "", "Path to the YAML file with the values of the ClusterTemplate.")
+	cmd.Flags().BoolVarP(&co.interactive, "interactive", "i", false, "Prompt for the values of the ClusterTemplate.")
+	cmd.Flags().BoolVar(&co.print, "print", false, "Print the ClusterDeployment instead of creating it.")
+	cmd.Flags().BoolVar(&co.dryRun, "dry-run", false, "Create the ClusterDeployment in the dry-run mode, only validating the values.")
+	_ = cmd.MarkFlagRequired("template")
+	_ = cmd.MarkFlagRequired("credential")
+
+	return cmd
+}
+
+func (o *options) createCluster(cmd *cobra.Command, name string, co *clusterCreateOptions) error {
+	ctx := cmd.Context()
+
+	namespace, err := o.currentNamespace()
+	if err != nil {
+		return err
+	}
+	cl, err := o.newClient()
+	if err != nil {
+		return err
+	}
+
+	values := make(map[string]any)
+	if co.valuesFile != "" {
+		data, err := os.ReadFile(co.valuesFile)
+		if err != nil {
+			return fmt.Errorf("failed to read the values file: %w", err)
+		}
+		if err := yaml.Unmarshal(data, &values); err != nil {
+			return fmt.Errorf("failed to parse the values file: %w", err)
+		}
+	}
+
+	if co.interactive {
+		template := &kcm.ClusterTemplate{}
+		if err := cl.Get(ctx, client.ObjectKey{Namespace: namespace, Name: co.template}, template); err != nil {
+			return fmt.Errorf("failed to get ClusterTemplate %s/%s: %w", namespace, co.template, err)
+		}
+		if !template.Status.Valid {
+			return fmt.Errorf("the ClusterTemplate %s/%s is not valid: %s", namespace, co.template, template.Status.ValidationError)
+		}
+
+		defaults := make(map[string]any)
+		if template.Status.Config != nil {
+			if err := json.Unmarshal(template.Status.Config.Raw, &defaults); err != nil {
+				return fmt.Errorf("failed to parse the config of the ClusterTemplate: %w", err)
+			}
+		}
+
+		if values, err = promptValues(o.streams.In, o.streams.Out, defaults, values); err != nil {
+			return err
+		}
+	}
+
+	cd := &kcm.ClusterDeployment{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: kcm.GroupVersion.String(),
+			Kind:       kcm.ClusterDeploymentKind,
+		},
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Spec: kcm.ClusterDeploymentSpec{
+			Template:   co.template,
+			Credential: co.credential,
+			DryRun:     co.dryRun,
+		},
+	}
+	if len(values) > 0 {
+		raw, err := json.Marshal(values)
+		if err != nil {
+			return fmt.Errorf("failed to marshal the values: %w", err)
+		}
+		cd.Spec.Config = &apiextensionsv1.JSON{Raw: raw}
+	}
+
+	if co.print {
+		data, err := yaml.Marshal(cd)
+		if err != nil {
+			return fmt.Errorf("failed to marshal the ClusterDeployment: %w", err)
+		}
+		_, err = o.streams.Out.Write(data)
+		return err
+	}
+
+	if err := cl.Create(ctx, cd); err != nil {
+		return fmt.Errorf("failed to create ClusterDeployment %s/%s: %w", namespace, name, err)
+	}
+	_, err = fmt.Fprintf(o.streams.Out, "ClusterDeployment %s/%s created\n", namespace, name)
+	return err
+}
+
+func newClusterListCommand(o *options) *cobra.Command {
+	var allNamespaces bool
+	cmd := &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List ClusterDeployments with the summary of their conditions",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return o.listClusters(cmd, allNamespaces)
+		},
+	}
+
+	cmd.Flags().BoolVarP(&allNamespaces, "all-namespaces", "A", false, "List the ClusterDeployments across all namespaces.")
+
+	return cmd
+}
+
+func (o *options) listClusters(cmd *cobra.Command, allNamespaces bool) error {
+	cl, err := o.newClient()
+	if err != nil {
+		return err
+	}
+
+	var opts []client.ListOption
+	if !allNamespaces {
+		namespace, err := o.currentNamespace()
+		if err != nil {
+			return err
+		}
+		opts = append(opts, client.InNamespace(namespace))
+	}
+
+	cds := &kcm.ClusterDeploymentList{}
+	if err := cl.List(cmd.Context(), cds, opts...); err != nil {
+		return fmt.Errorf("failed to list ClusterDeployments: %w", err)
+	}
+	slices.SortFunc(cds.Items, func(a, b kcm.ClusterDeployment) int {
+		return strings.Compare(a.Namespace+"/"+a.Name, b.Namespace+"/"+b.Name)
+	})
+
+	w := tabwriter.NewWriter(o.streams.Out, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "NAMESPACE\tNAME\tTEMPLATE\tREADY\tCONDITIONS\tSERVICES\tFAILING")
+	for _, cd := range cds.Items {
+		ready, conditions, failing := summarizeConditions(cd.Status.Conditions)
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			cd.Namespace, cd.Name, cd.Spec.Template, ready, conditions, summarizeServices(&cd), failing)
+	}
+	return w.Flush()
+}
+
+// summarizeConditions returns the status of the Ready condition, the number of the True
+// conditions out of all of them and the comma-separated types of the False conditions.
+func summarizeConditions(conditions []metav1.Condition) (ready, summary, failing string) {
+	ready = string(metav1.ConditionUnknown)
+	if c := apimeta.FindStatusCondition(conditions, kcm.ReadyCondition); c != nil {
+		ready = string(c.Status)
+	}
+
+	var (
+		trueConditions int
+		failed         []string
+	)
+	for _, c := range conditions {
+		switch c.Status {
+		case metav1.ConditionTrue:
+			trueConditions++
+		case metav1.ConditionFalse:
+			if c.Type != kcm.ReadyCondition {
+				failed = append(failed, c.Type)
+			}
+		}
+	}
+
+	failing = "-"
+	if len(failed) > 0 {
+		failing = strings.Join(failed, ",")
+	}
+	return ready, fmt.Sprintf("%d/%d", trueConditions, len(conditions)), failing
+}
+
+func summarizeServices(cd *kcm.ClusterDeployment) string {
+	if len(cd.Spec.ServiceSpec.Services) == 0 {
+		return "-"
+	}
+
+	var deployed int
+	for _, svc := range cd.Status.Services {
+		for _, c := range svc.Conditions {
+			if strings.HasSuffix(c.Type, "/SveltosHelmReleaseReady") && c.Status == metav1.ConditionTrue {
+				deployed++
+			}
+		}
+	}
+	return fmt.Sprintf("%d/%d", deployed, len(cd.Spec.ServiceSpec.Services))
+}
+
+func newClusterUpgradesCommand(o *options) *cobra.Command {
+	return &cobra.Command{
+		Use:   "upgrades NAME",
+		Short: "Show the ClusterTemplates the ClusterDeployment can be upgraded to",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cl, err := o.newClient()
+			if err != nil {
+				return err
+			}
+			cd, err := o.getCluster(cmd, cl, args[0])
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintf(o.streams.Out, "Current template: %s\n", cd.Spec.Template)
+			if len(cd.Status.AvailableUpgrades) == 0 {
+				_, err = fmt.Fprintln(o.streams.Out, "No upgrades available")
+				return err
+			}
+			fmt.Fprintln(o.streams.Out, "Available upgrades:")
+			for _, upgrade := range cd.Status.AvailableUpgrades {
+				fmt.Fprintf(o.streams.Out, "  %s\n", upgrade)
+			}
+			return nil
+		},
+	}
+}
+
+func newClusterUpgradeCommand(o *options) *cobra.Command {
+	var template string
+	cmd := &cobra.Command{
+		Use:   "upgrade NAME --template TEMPLATE",
+		Short: "Upgrade the ClusterDeployment to one of its available ClusterTemplates",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.upgradeCluster(cmd, args[0], template)
+		},
+	}
+
+	cmd.Flags().StringVarP(&template, "template", "t", "", "The name of the ClusterTemplate to upgrade to.")
+	_ = cmd.MarkFlagRequired("template")
+
+	return cmd
+}
+
+func (o *options) upgradeCluster(cmd *cobra.Command, name, template string) error {
+	cl, err := o.newClient()
+	if err != nil {
+		return err
+	}
+	cd, err := o.getCluster(cmd, cl, name)
+	if err != nil {
+		return err
+	}
+
+	if cd.Spec.Template == template {
+		_, err = fmt.Fprintf(o.streams.Out, "ClusterDeployment %s/%s already uses the ClusterTemplate %s\n", cd.Namespace, cd.Name, template)
+		return err
+	}
+	if !slices.Contains(cd.Status.AvailableUpgrades, template) {
+		available := "none"
+		if len(cd.Status.AvailableUpgrades) > 0 {
+			available = strings.Join(cd.Status.AvailableUpgrades, ", ")
+		}
+		return fmt.Errorf("the ClusterDeployment %s/%s cannot be upgraded to %s, available upgrades: %s", cd.Namespace, cd.Name, template, available)
+	}
+
+	patch := client.MergeFrom(cd.DeepCopy())
+	cd.Spec.Template = template
+	if err := cl.Patch(cmd.Context(), cd, patch); err != nil {
+		return fmt.Errorf("failed to upgrade ClusterDeployment %s/%s: %w", cd.Namespace, cd.Name, err)
+	}
+
+	_, err = fmt.Fprintf(o.streams.Out, "ClusterDeployment %s/%s is being upgraded to %s\n", cd.Namespace, cd.Name, template)
+	return err
+}
+
+type clusterKubeconfigOptions struct {
+	output          string
+	admin           bool
+	clusterRole     string
+	accessNamespace string
+	ttl             time.Duration
+	timeout         time.Duration
+}
+
+func newClusterKubeconfigCommand(o *options) *cobra.Command {
+	ko := &clusterKubeconfigOptions{}
+	cmd := &cobra.Command{
+		Use:   "kubeconfig NAME",
+		Short: "Issue a short-lived kubeconfig of the cluster",
+		Long: `Issue a short-lived kubeconfig of the cluster.
+
+The kubeconfig is issued through a ClusterAccessRequest, it is scoped to the given
+ClusterRole and expires after the TTL, the request is subject to the clusterAccess
+limits of the controller manager configuration.
+
+With --admin the admin kubeconfig of the cluster is fetched instead, it never expires
+and requires the access to the <cluster>-kubeconfig Secret in the management cluster.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.clusterKubeconfig(cmd, args[0], ko)
+		},
+	}
+
+	cmd.Flags().StringVarP(&ko.output, "output", "o", "", "Path to the file to write the kubeconfig to instead of the standard output.")
+	cmd.Flags().BoolVar(&ko.admin, "admin", false, "Fetch the admin kubeconfig of the cluster instead of issuing a ClusterAccessRequest.")
+	cmd.Flags().StringVar(&ko.clusterRole, "cluster-role", "view", "The ClusterRole in the cluster to grant.")
+	cmd.Flags().StringVar(&ko.accessNamespace, "access-namespace", "", "The namespace in the cluster to scope the access to, the ClusterRole is granted cluster-wide if empty.")
+	cmd.Flags().DurationVar(&ko.ttl, "ttl", time.Hour, "The lifetime of the access.")
+	cmd.Flags().DurationVar(&ko.timeout, "timeout", 2*time.Minute, "How long to wait for the access to be granted.")
+
+	return cmd
+}
+
+func (o *options) clusterKubeconfig(cmd *cobra.Command, name string, ko *clusterKubeconfigOptions) error {
+	cl, err := o.newClient()
+	if err != nil {
+		return err
+	}
+	cd, err := o.getCluster(cmd, cl, name)
+	if err != nil {
+		return err
+	}
+
+	var kubeconfig []byte
+	if ko.admin {
+		kubeconfig, err = clusteraccess.ClusterKubeconfig(cmd.Context(), cl, cd)
+	} else {
+		kubeconfig, err = o.requestClusterAccess(cmd, cl, cd, ko)
+	}
+	if err != nil {
+		return err
+	}
+
+	if ko.output == "" {
+		_, err = o.streams.Out.Write(kubeconfig)
+		return err
+	}
+	return os.WriteFile(ko.output, kubeconfig, 0o600)
+}
+
+// requestClusterAccess creates a ClusterAccessRequest for the cluster, waits for the
+// access to be granted and returns the issued kubeconfig.
+func (o *options) requestClusterAccess(cmd *cobra.Command, cl client.Client, cd *kcm.ClusterDeployment, ko *clusterKubeconfigOptions) ([]byte, error) {
+	req := &kcm.ClusterAccessRequest{
+		ObjectMeta: metav1.ObjectMeta{GenerateName: cd.Name + "-", Namespace: cd.Namespace},
+		Spec: kcm.ClusterAccessRequestSpec{
+			ClusterDeployment: cd.Name,
+			ClusterRole:       ko.clusterRole,
+			Namespace:         ko.accessNamespace,
+			TTL:               metav1.Duration{Duration: ko.ttl},
+		},
+	}
+	if err := cl.Create(cmd.Context(), req); err != nil {
+		return nil, fmt.Errorf("failed to create ClusterAccessRequest for the ClusterDeployment %s/%s: %w", cd.Namespace, cd.Name, err)
+	}
+	fmt.Fprintf(o.streams.ErrOut, "ClusterAccessRequest %s/%s is created, waiting for the access to be granted\n", req.Namespace, req.Name)
+
+	err := wait.PollUntilContextTimeout(cmd.Context(), time.Second, ko.timeout, true, func(ctx context.Context) (bool, error) {
+		if err := cl.Get(ctx, client.ObjectKeyFromObject(req), req); err != nil {
+			return false, err
+		}
+		return apimeta.IsStatusConditionTrue(req.Status.Conditions, kcm.AccessGrantedCondition) && req.Status.KubeconfigSecretName != "", nil
+	})
+	if err != nil {
+		if cond := apimeta.FindStatusCondition(req.Status.Conditions, kcm.AccessGrantedCondition); cond != nil && cond.Message != "" {
+			err = fmt.Errorf("%w: %s", err, cond.Message)
+		}
+		return nil, fmt.Errorf("access to the ClusterDeployment %s/%s is not granted by the ClusterAccessRequest %s: %w", cd.Namespace, cd.Name, req.Name, err)
+	}
+
+	secret := &corev1.Secret{}
+	if err := cl.Get(cmd.Context(), client.ObjectKey{Namespace: req.Namespace, Name: req.Status.KubeconfigSecretName}, secret); err != nil {
+		return nil, fmt.Errorf("failed to get the kubeconfig Secret of the ClusterAccessRequest %s/%s: %w", req.Namespace, req.Name, err)
+	}
+	kubeconfig, ok := secret.Data["value"]
+	if !ok {
+		return nil, fmt.Errorf("the kubeconfig Secret %s/%s has no value", secret.Namespace, secret.Name)
+	}
+
+	return kubeconfig, nil
+}
+
+func (o *options) getCluster(cmd *cobra.Command, cl client.Client, name string) (*kcm.ClusterDeployment, error) {
+	namespace, err := o.currentNamespace()
+	if err != nil {
+		return nil, err
+	}
+
+	cd := &kcm.ClusterDeployment{}
+	if err := cl.Get(cmd.Context(), client.ObjectKey{Namespace: namespace, Name: name}, cd); err != nil {
+		return nil, fmt.Errorf("failed to get ClusterDeployment %s/%s: %w", namespace, name, err)
+	}
+	return cd, nil
+}
@@ -0,0 +1,303 @@
+// Copyright 2024
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kcmctl
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/yaml"
+
+	kcm "github.com/K0rdent/kcm/api/v1alpha1"
+	"github.com/K0rdent/kcm/internal/airgap"
+)
+
+func run(t *testing.T, cl client.Client, input string, args ...string) (string, error) {
+	t.Helper()
+
+	out := &bytes.Buffer{}
+	o := &options{
+		streams:   Streams{In: strings.NewReader(input), Out: out, ErrOut: out},
+		newClient: func() (client.Client, error) { return cl, nil },
+	}
+	cmd := newRootCommand(o)
+	cmd.SetArgs(append(args, "--namespace", "default"))
+	err := cmd.ExecuteContext(context.Background())
+
+	return out.String(), err
+}
+
+func TestClusterList(t *testing.T) {
+	g := NewWithT(t)
+
+	cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&kcm.ClusterDeployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "dev", Namespace: "default"},
+			Spec: kcm.ClusterDeploymentSpec{
+				Template:    "aws-standalone-cp-0-1-0",
+				ServiceSpec: kcm.ServiceSpec{Services: []kcm.Service{{Name: "ingress", Template: "ingress-nginx-4-11-0"}}},
+			},
+			Status: kcm.ClusterDeploymentStatus{
+				Conditions: []metav1.Condition{
+					{Type: kcm.TemplateReadyCondition, Status: metav1.ConditionTrue},
+					{Type: kcm.HelmReleaseReadyCondition, Status: metav1.ConditionFalse},
+					{Type: kcm.ReadyCondition, Status: metav1.ConditionFalse},
+				},
+			},
+		},
+		&kcm.ClusterDeployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "other"},
+			Spec:       kcm.ClusterDeploymentSpec{Template: "aws-standalone-cp-0-1-0"},
+		},
+	).Build()
+
+	out, err := run(t, cl, "", "cluster", "list")
+	g.Expect(err).NotTo(HaveOccurred())
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	g.Expect(lines).To(HaveLen(2))
+	g.Expect(strings.Fields(lines[1])).To(Equal([]string{"default", "dev", "aws-standalone-cp-0-1-0", "False", "1/3", "0/1", "HelmReleaseReady"}))
+}
+
+func TestClusterUpgrade(t *testing.T) {
+	g := NewWithT(t)
+
+	cd := &kcm.ClusterDeployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "dev", Namespace: "default"},
+		Spec:       kcm.ClusterDeploymentSpec{Template: "aws-standalone-cp-0-1-0"},
+		Status:     kcm.ClusterDeploymentStatus{AvailableUpgrades: []string{"aws-standalone-cp-0-1-1"}},
+	}
+	cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cd).Build()
+
+	out, err := run(t, cl, "", "cluster", "upgrades", "dev")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(out).To(ContainSubstring("aws-standalone-cp-0-1-1"))
+
+	_, err = run(t, cl, "", "cluster", "upgrade", "dev", "--template", "aws-standalone-cp-0-2-0")
+	g.Expect(err).To(MatchError(ContainSubstring("available upgrades: aws-standalone-cp-0-1-1")))
+
+	_, err = run(t, cl, "", "cluster", "upgrade", "dev", "--template", "aws-standalone-cp-0-1-1")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(cl.Get(context.Background(), client.ObjectKeyFromObject(cd), cd)).To(Succeed())
+	g.Expect(cd.Spec.Template).To(Equal("aws-standalone-cp-0-1-1"))
+}
+
+func TestClusterCreateInteractive(t *testing.T) {
+	g := NewWithT(t)
+
+	cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&kcm.ClusterTemplate{
+			ObjectMeta: metav1.ObjectMeta{Name: "aws-standalone-cp-0-1-0", Namespace: "default"},
+			Status: kcm.ClusterTemplateStatus{TemplateStatusCommon: kcm.TemplateStatusCommon{
+				TemplateValidationStatus: kcm.TemplateValidationStatus{Valid: true},
+				Config:                   &apiextensionsv1.JSON{Raw: []byte(`{"region":"us-east-2","controlPlaneNumber":3,"worker":{"instanceType":"t3.small"}}`)},
+			}},
+		},
+	).Build()
+
+	// controlPlaneNumber, region, worker.instanceType
+	out, err := run(t, cl, "1\n\nt3.large\n",
+		"cluster", "create", "dev", "--template", "aws-standalone-cp-0-1-0", "--credential", "aws-cred", "--interactive", "--print")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(out).To(ContainSubstring("controlPlaneNumber [3]: region [us-east-2]: worker.instanceType [t3.small]: "))
+
+	cd := &kcm.ClusterDeployment{}
+	g.Expect(yaml.Unmarshal([]byte(out[strings.Index(out, "apiVersion"):]), cd)).To(Succeed())
+	g.Expect(cd.Spec.Template).To(Equal("aws-standalone-cp-0-1-0"))
+	g.Expect(cd.Spec.Credential).To(Equal("aws-cred"))
+	g.Expect(cd.Spec.Config.Raw).To(MatchJSON(`{"controlPlaneNumber":1,"worker":{"instanceType":"t3.large"}}`))
+}
+
+func TestClusterKubeconfig(t *testing.T) {
+	g := NewWithT(t)
+
+	cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&kcm.ClusterDeployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "dev", Namespace: "default"},
+			Spec:       kcm.ClusterDeploymentSpec{Template: "aws-standalone-cp-0-1-0"},
+		},
+		&kcm.ClusterTemplate{ObjectMeta: metav1.ObjectMeta{Name: "aws-standalone-cp-0-1-0", Namespace: "default"}},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "dev-kubeconfig", Namespace: "default"},
+			Data:       map[string][]byte{"value": []byte("admin")},
+		},
+	).WithInterceptorFuncs(interceptor.Funcs{
+		// grant the access the way the ClusterAccessRequest controller does
+		Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+			if err := c.Create(ctx, obj, opts...); err != nil {
+				return err
+			}
+			req, ok := obj.(*kcm.ClusterAccessRequest)
+			if !ok {
+				return nil
+			}
+			req.Status.KubeconfigSecretName = req.KubeconfigSecretName()
+			req.Status.Conditions = []metav1.Condition{{Type: kcm.AccessGrantedCondition, Status: metav1.ConditionTrue, Reason: kcm.SucceededReason}}
+			if err := c.Update(ctx, req); err != nil {
+				return err
+			}
+			return c.Create(ctx, &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: req.Status.KubeconfigSecretName, Namespace: req.Namespace},
+				Data:       map[string][]byte{"value": []byte("scoped")},
+			})
+		},
+	}).Build()
+
+	dir := t.TempDir()
+	output := filepath.Join(dir, "scoped.kubeconfig")
+	_, err := run(t, cl, "", "cluster", "kubeconfig", "dev", "--access-namespace", "apps", "--ttl", "30m", "-o", output)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(os.ReadFile(output)).To(Equal([]byte("scoped")))
+
+	requests := &kcm.ClusterAccessRequestList{}
+	g.Expect(cl.List(context.Background(), requests)).To(Succeed())
+	g.Expect(requests.Items).To(HaveLen(1))
+	g.Expect(requests.Items[0].Spec).To(Equal(kcm.ClusterAccessRequestSpec{
+		ClusterDeployment: "dev",
+		ClusterRole:       "view",
+		Namespace:         "apps",
+		TTL:               metav1.Duration{Duration: 30 * time.Minute},
+	}))
+
+	output = filepath.Join(dir, "admin.kubeconfig")
+	_, err = run(t, cl, "", "cluster", "kubeconfig", "dev", "--admin", "-o", output)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(os.ReadFile(output)).To(Equal([]byte("admin")))
+}
+
+func TestManagementDescribe(t *testing.T) {
+	g := NewWithT(t)
+
+	cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&kcm.Management{
+			ObjectMeta: metav1.ObjectMeta{Name: kcm.ManagementName},
+			Spec:       kcm.ManagementSpec{Release: "kcm-0-1-0"},
+			Status: kcm.ManagementStatus{
+				Release: "kcm-0-1-0",
+				Components: map[string]kcm.ComponentStatus{
+					"kcm":            {Template: "kcm-0-1-0", Success: true},
+					"cluster-api":    {Template: "cluster-api-0-1-0", Success: true},
+					"projectsveltos": {Template: "projectsveltos-0-1-0", Error: "HelmRelease is not ready"},
+				},
+			},
+		},
+	).Build()
+
+	out, err := run(t, cl, "", "management", "describe")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(out).To(ContainSubstring("kcm-0-1-0"))
+	g.Expect(out).To(MatchRegexp(`projectsveltos\s+projectsveltos-0-1-0\s+false\s+HelmRelease is not ready`))
+}
+
+func TestValidate(t *testing.T) {
+	g := NewWithT(t)
+
+	dir := t.TempDir()
+	manifests := filepath.Join(dir, "manifests.yaml")
+	g.Expect(os.WriteFile(manifests, []byte(`apiVersion: k0rdent.mirantis.com/v1alpha1
+kind: ClusterDeployment
+metadata:
+  name: dev
+  namespace: default
+spec:
+  template: aws-standalone-cp-0-1-0
+  credential: aws-cred
+---
+apiVersion: k0rdent.mirantis.com/v1alpha1
+kind: ClusterDeployment
+metadata:
+  name: missing
+  namespace: default
+spec:
+  template: missing
+  credential: aws-cred
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: ignored
+  namespace: default
+`), 0o600)).To(Succeed())
+
+	references := filepath.Join(dir, "references.yaml")
+	g.Expect(os.WriteFile(references, []byte(`apiVersion: v1
+kind: List
+items:
+- apiVersion: k0rdent.mirantis.com/v1alpha1
+  kind: ClusterTemplate
+  metadata:
+    name: aws-standalone-cp-0-1-0
+    namespace: default
+  status:
+    valid: true
+    providers:
+    - infrastructure-aws
+- apiVersion: k0rdent.mirantis.com/v1alpha1
+  kind: Credential
+  metadata:
+    name: aws-cred
+    namespace: default
+  spec:
+    identityRef:
+      apiVersion: infrastructure.cluster.x-k8s.io/v1beta2
+      kind: AWSClusterStaticIdentity
+      name: aws-identity
+  status:
+    ready: true
+`), 0o600)).To(Succeed())
+
+	out, err := run(t, nil, "", "validate", "-f", manifests, "--with", references)
+	g.Expect(err).To(MatchError("1 of the manifests are invalid"))
+	g.Expect(out).To(ContainSubstring("ClusterDeployment default/dev: valid"))
+	g.Expect(out).To(MatchRegexp(`ClusterDeployment default/missing: invalid: .*"missing" not found`))
+	g.Expect(out).NotTo(ContainSubstring("ConfigMap"))
+}
+
+func TestSetDefaultRegistry(t *testing.T) {
+	g := NewWithT(t)
+
+	mgmt := &kcm.Management{
+		ObjectMeta: metav1.ObjectMeta{Name: kcm.ManagementName},
+		Spec: kcm.ManagementSpec{
+			Release: "kcm-0-1-0",
+			Core: &kcm.Core{KCM: kcm.Component{
+				Config: &apiextensionsv1.JSON{Raw: []byte(`{"controller":{"createTemplates":false},"image":{"tag":"dev"}}`)},
+			}},
+		},
+	}
+	cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(mgmt).Build()
+
+	o := &options{newClient: func() (client.Client, error) { return cl, nil }}
+	cmd := newRootCommand(o)
+	cmd.SetContext(context.Background())
+	g.Expect(o.setDefaultRegistry(cmd, airgap.Registry{URL: "oci://registry.local:5000/charts", PlainHTTP: true})).To(Succeed())
+
+	g.Expect(cl.Get(context.Background(), client.ObjectKeyFromObject(mgmt), mgmt)).To(Succeed())
+	g.Expect(mgmt.Spec.Core.KCM.Config.Raw).To(MatchJSON(`{
+		"controller": {"createTemplates": false, "defaultRegistryURL": "oci://registry.local:5000/charts", "insecureRegistry": true},
+		"image": {"tag": "dev"}
+	}`))
+}
@@ -0,0 +1,82 @@
+// Copyright 2024
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kcmctl
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kcm "github.com/K0rdent/kcm/api/v1alpha1"
+)
+
+func newManagementCommand(o *options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "management",
+		Aliases: []string{"mgmt"},
+		Short:   "Inspect the Management",
+	}
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "describe",
+		Short: "Describe the health of the Management components",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return o.describeManagement(cmd)
+		},
+	})
+
+	return cmd
+}
+
+func (o *options) describeManagement(cmd *cobra.Command) error {
+	cl, err := o.newClient()
+	if err != nil {
+		return err
+	}
+
+	mgmt := &kcm.Management{}
+	if err := cl.Get(cmd.Context(), client.ObjectKey{Name: kcm.ManagementName}, mgmt); err != nil {
+		return fmt.Errorf("failed to get Management: %w", err)
+	}
+
+	w := tabwriter.NewWriter(o.streams.Out, 0, 0, 3, ' ', 0)
+
+	fmt.Fprintf(w, "Name:\t%s\n", mgmt.Name)
+	release := mgmt.Status.Release
+	if mgmt.Spec.Release != mgmt.Status.Release {
+		release = fmt.Sprintf("%s (upgrading to %s)", mgmt.Status.Release, mgmt.Spec.Release)
+	}
+	fmt.Fprintf(w, "Release:\t%s\n", release)
+	if c := apimeta.FindStatusCondition(mgmt.Status.Conditions, kcm.ReadyCondition); c != nil {
+		fmt.Fprintf(w, "Ready:\t%s (%s)\n", c.Status, c.Message)
+	}
+	fmt.Fprintf(w, "Providers:\t%s\n", strings.Join(mgmt.Status.AvailableProviders, ", "))
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "COMPONENT\tTEMPLATE\tHEALTHY\tERROR")
+	for _, name := range slices.Sorted(maps.Keys(mgmt.Status.Components)) {
+		component := mgmt.Status.Components[name]
+		fmt.Fprintf(w, "%s\t%s\t%t\t%s\n", name, component.Template, component.Success, orDash(component.Error))
+	}
+
+	return w.Flush()
+}
@@ -0,0 +1,120 @@
+// Copyright 2024
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kcmctl
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+
+	"helm.sh/helm/v3/pkg/chartutil"
+	"sigs.k8s.io/yaml"
+)
+
+// value is a leaf of the values tree.
+type value struct {
+	value any
+	path  []string
+}
+
+// promptValues prompts for each of the leaf values of the defaults overridden by the given values,
+// the empty answer keeps the default. It returns the given values along with the changed ones.
+// The answers are parsed as YAML so the numbers, booleans and lists keep their types.
+// The prompting stops at the end of the input keeping the defaults of the remaining values.
+func promptValues(in io.Reader, out io.Writer, defaults, values map[string]any) (map[string]any, error) {
+	merged := chartutil.CoalesceTables(copyValues(values), copyValues(defaults))
+	result := copyValues(values)
+
+	scanner := bufio.NewScanner(in)
+	for _, leaf := range leafValues(nil, merged) {
+		if _, err := fmt.Fprintf(out, "%s [%s]: ", strings.Join(leaf.path, "."), formatValue(leaf.value)); err != nil {
+			return nil, err
+		}
+		if !scanner.Scan() {
+			if err := scanner.Err(); err != nil {
+				return nil, fmt.Errorf("failed to read the answer: %w", err)
+			}
+			_, err := fmt.Fprintln(out)
+			return result, err
+		}
+
+		answer := strings.TrimSpace(scanner.Text())
+		if answer == "" {
+			continue
+		}
+
+		var parsed any
+		if err := yaml.Unmarshal([]byte(answer), &parsed); err != nil {
+			parsed = answer
+		}
+		setValue(result, leaf.path, parsed)
+	}
+
+	return result, nil
+}
+
+// leafValues returns the leaves of the values tree sorted by their paths.
+// The empty maps are considered to be the leaves.
+func leafValues(prefix []string, values map[string]any) []value {
+	var leaves []value
+	for _, key := range slices.Sorted(maps.Keys(values)) {
+		path := append(slices.Clone(prefix), key)
+		if nested, ok := values[key].(map[string]any); ok && len(nested) > 0 {
+			leaves = append(leaves, leafValues(path, nested)...)
+			continue
+		}
+		leaves = append(leaves, value{path: path, value: values[key]})
+	}
+	return leaves
+}
+
+func setValue(values map[string]any, path []string, v any) {
+	for _, key := range path[:len(path)-1] {
+		nested, ok := values[key].(map[string]any)
+		if !ok {
+			nested = make(map[string]any)
+			values[key] = nested
+		}
+		values = nested
+	}
+	values[path[len(path)-1]] = v
+}
+
+func formatValue(v any) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+
+// copyValues returns the deep copy of the values so they are not modified by the coalescing.
+func copyValues(values map[string]any) map[string]any {
+	result := make(map[string]any, len(values))
+	for k, v := range values {
+		if nested, ok := v.(map[string]any); ok {
+			v = copyValues(nested)
+		}
+		result[k] = v
+	}
+	return result
+}
@@ -0,0 +1,123 @@
+// Copyright 2024
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package kcmctl implements the kcmctl command-line tool for the day-to-day operations with KCM.
+package kcmctl
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kcm "github.com/K0rdent/kcm/api/v1alpha1"
+	"github.com/K0rdent/kcm/internal/build"
+)
+
+var scheme = runtime.NewScheme()
+
+func init() {
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(kcm.AddToScheme(scheme))
+}
+
+// Streams are the standard streams of the commands.
+type Streams struct {
+	In     io.Reader
+	Out    io.Writer
+	ErrOut io.Writer
+}
+
+type options struct {
+	streams Streams
+
+	// newClient returns the client to the management cluster, it is replaced in the tests.
+	newClient func() (client.Client, error)
+
+	kubeconfig string
+	context    string
+	namespace  string
+}
+
+// NewCommand returns the root kcmctl command.
+func NewCommand(streams Streams) *cobra.Command {
+	o := &options{streams: streams}
+	o.newClient = o.defaultClient
+
+	return newRootCommand(o)
+}
+
+func newRootCommand(o *options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:           "kcmctl",
+		Short:         "kcmctl controls the K0rdent Cluster Manager",
+		Version:       build.Version,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	cmd.SetIn(o.streams.In)
+	cmd.SetOut(o.streams.Out)
+	cmd.SetErr(o.streams.ErrOut)
+
+	cmd.PersistentFlags().StringVar(&o.kubeconfig, "kubeconfig", "", "Path to the kubeconfig file of the management cluster.")
+	cmd.PersistentFlags().StringVar(&o.context, "context", "", "The name of the kubeconfig context to use.")
+	cmd.PersistentFlags().StringVarP(&o.namespace, "namespace", "n", "", "The namespace of the objects, defaults to the namespace of the kubeconfig context.")
+
+	cmd.AddCommand(
+		newClusterCommand(o),
+		newManagementCommand(o),
+		newBackupCommand(o),
+		newValidateCommand(o),
+		newBundleCommand(o),
+	)
+
+	return cmd
+}
+
+func (o *options) clientConfig() clientcmd.ClientConfig {
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	rules.ExplicitPath = o.kubeconfig
+	return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, &clientcmd.ConfigOverrides{
+		CurrentContext: o.context,
+		Context:        clientcmdapi.Context{Namespace: o.namespace},
+	})
+}
+
+func (o *options) defaultClient() (client.Client, error) {
+	cfg, err := o.clientConfig().ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the kubeconfig: %w", err)
+	}
+
+	return client.New(cfg, client.Options{Scheme: scheme})
+}
+
+// currentNamespace returns the namespace set in the flags or in the kubeconfig context.
+func (o *options) currentNamespace() (string, error) {
+	if o.namespace != "" {
+		return o.namespace, nil
+	}
+
+	namespace, _, err := o.clientConfig().Namespace()
+	if err != nil {
+		return "", fmt.Errorf("failed to get the namespace from the kubeconfig: %w", err)
+	}
+	return namespace, nil
+}
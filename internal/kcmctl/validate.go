@@ -0,0 +1,222 @@
+// Copyright 2024
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kcmctl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	kcm "github.com/K0rdent/kcm/api/v1alpha1"
+	kcmwebhook "github.com/K0rdent/kcm/internal/webhook"
+)
+
+type validateOptions struct {
+	systemNamespace string
+	files           []string
+	references      []string
+}
+
+func newValidateCommand(o *options) *cobra.Command {
+	vo := &validateOptions{}
+	cmd := &cobra.Command{
+		Use:   "validate -f FILE [--with FILE]",
+		Short: "Validate the manifests offline against the admission webhooks",
+		Long: `Validate the manifests offline running the same checks as the admission webhooks do on creation.
+
+The objects the manifests refer to, e.g. the ClusterTemplates and the Credentials along with their
+status, are looked up in the manifests themselves and in the files passed with --with, which can be
+produced with "kubectl get clustertemplates,credentials -o yaml". The cluster is not contacted.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return o.validate(cmd.Context(), vo)
+		},
+	}
+
+	cmd.Flags().StringArrayVarP(&vo.files, "filename", "f", nil, "Path to the file with the manifests to validate.")
+	cmd.Flags().StringArrayVar(&vo.references, "with", nil, "Path to the file with the objects referred by the manifests.")
+	cmd.Flags().StringVar(&vo.systemNamespace, "system-namespace", "kcm-system", "The namespace KCM is installed in.")
+	_ = cmd.MarkFlagRequired("filename")
+
+	return cmd
+}
+
+func (o *options) validate(ctx context.Context, vo *validateOptions) error {
+	var manifests, references []client.Object
+	for _, file := range vo.files {
+		objs, err := readObjects(file)
+		if err != nil {
+			return err
+		}
+		manifests = append(manifests, objs...)
+	}
+	for _, file := range vo.references {
+		objs, err := readObjects(file)
+		if err != nil {
+			return err
+		}
+		references = append(references, objs...)
+	}
+
+	cl := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(append(references, manifests...)...).
+		WithIndex(&kcm.ClusterDeployment{}, kcm.ClusterDeploymentTemplateIndexKey, kcm.ExtractTemplateNameFromClusterDeployment).
+		WithIndex(&kcm.ClusterDeployment{}, kcm.ClusterDeploymentServiceTemplatesIndexKey, kcm.ExtractServiceTemplateNamesFromClusterDeployment).
+		WithIndex(&kcm.MultiClusterService{}, kcm.MultiClusterServiceTemplatesIndexKey, kcm.ExtractServiceTemplateNamesFromMultiClusterService).
+		WithIndex(&kcm.ClusterTemplate{}, kcm.ClusterTemplateProvidersIndexKey, kcm.ExtractProvidersFromClusterTemplate).
+		Build()
+
+	var invalid int
+	for _, obj := range manifests {
+		validator, defaulter := webhookFor(cl, vo.systemNamespace, obj)
+		if validator == nil {
+			continue
+		}
+
+		kind := obj.GetObjectKind().GroupVersionKind().Kind
+		name := client.ObjectKeyFromObject(obj).String()
+
+		var (
+			warnings admission.Warnings
+			err      error
+		)
+		if defaulter != nil {
+			err = defaulter.Default(ctx, obj)
+		}
+		if err == nil {
+			warnings, err = validator.ValidateCreate(ctx, obj)
+		}
+
+		for _, warning := range warnings {
+			fmt.Fprintf(o.streams.Out, "%s %s: warning: %s\n", kind, name, warning)
+		}
+		if err != nil {
+			invalid++
+			fmt.Fprintf(o.streams.Out, "%s %s: invalid: %v\n", kind, name, err)
+			continue
+		}
+		fmt.Fprintf(o.streams.Out, "%s %s: valid\n", kind, name)
+	}
+
+	if invalid > 0 {
+		return fmt.Errorf("%d of the manifests are invalid", invalid)
+	}
+	return nil
+}
+
+// webhookFor returns the validator and the defaulter if any of the admission webhook of the object.
+// It returns nil if the webhook does not validate the creation of the objects of such kind.
+func webhookFor(cl client.Client, systemNamespace string, obj client.Object) (admission.CustomValidator, admission.CustomDefaulter) {
+	switch obj.(type) {
+	case *kcm.ClusterDeployment:
+		v := &kcmwebhook.ClusterDeploymentValidator{Client: cl}
+		return v, v
+	case *kcm.MultiClusterService:
+		return &kcmwebhook.MultiClusterServiceValidator{Client: cl, SystemNamespace: systemNamespace}, nil
+	case *kcm.Management:
+		return &kcmwebhook.ManagementValidator{Client: cl}, nil
+	case *kcm.ProviderDefinition:
+		return &kcmwebhook.ProviderDefinitionValidator{Client: cl}, nil
+	case *kcm.ClusterTemplateChain:
+		return &kcmwebhook.ClusterTemplateChainValidator{Client: cl}, nil
+	case *kcm.ServiceTemplateChain:
+		return &kcmwebhook.ServiceTemplateChainValidator{Client: cl}, nil
+	default:
+		return nil, nil
+	}
+}
+
+// readObjects reads the objects from the YAML or JSON file with the given path, "-" stands for the standard input.
+// The objects of the kinds unknown to KCM are skipped.
+func readObjects(path string) ([]client.Object, error) {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open %s: %w", path, err)
+		}
+		defer f.Close()
+		r = f
+	}
+
+	var objs []client.Object
+	decoder := utilyaml.NewYAMLOrJSONDecoder(r, 4096)
+	for {
+		u := &unstructured.Unstructured{}
+		if err := decoder.Decode(&u.Object); err != nil {
+			if errors.Is(err, io.EOF) {
+				return objs, nil
+			}
+			return nil, fmt.Errorf("failed to decode %s: %w", path, err)
+		}
+		if len(u.Object) == 0 {
+			continue
+		}
+
+		if u.IsList() {
+			if err := u.EachListItem(func(item runtime.Object) error {
+				obj, err := typedObject(item.(*unstructured.Unstructured))
+				if obj != nil {
+					objs = append(objs, obj)
+				}
+				return err
+			}); err != nil {
+				return nil, fmt.Errorf("failed to decode %s: %w", path, err)
+			}
+			continue
+		}
+
+		obj, err := typedObject(u)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode %s: %w", path, err)
+		}
+		if obj != nil {
+			objs = append(objs, obj)
+		}
+	}
+}
+
+// typedObject converts the unstructured object to the typed one, it returns nil if the kind is unknown.
+func typedObject(u *unstructured.Unstructured) (client.Object, error) {
+	gvk := u.GroupVersionKind()
+	typed, err := scheme.New(gvk)
+	if err != nil {
+		if runtime.IsNotRegisteredError(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, typed); err != nil {
+		return nil, fmt.Errorf("failed to convert %s %s: %w", gvk.Kind, u.GetName(), err)
+	}
+	obj, ok := typed.(client.Object)
+	if !ok {
+		return nil, nil
+	}
+	obj.GetObjectKind().SetGroupVersionKind(gvk)
+	return obj, nil
+}
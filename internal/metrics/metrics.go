@@ -0,0 +1,259 @@
+// Copyright 2024
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package metrics exposes the metrics of the KCM objects along with
+// the controller-runtime ones on the manager's metrics endpoint.
+package metrics
+
+import (
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	kcm "github.com/K0rdent/kcm/api/v1alpha1"
+	providersloader "github.com/K0rdent/kcm/internal/providers"
+)
+
+const namespace = "kcm"
+
+var (
+	clusterDeploymentStatus = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "cluster_deployment_status",
+		Help:      "The ClusterDeployment with its template, infrastructure providers and the status of the Ready condition, always 1.",
+	}, []string{"namespace", "name", "template", "provider", "ready"})
+
+	clusterDeploymentProvisioningDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "cluster_deployment_provisioning_duration_seconds",
+		Help:      "The time from the creation of the ClusterDeployment to it becoming Ready for the first time.",
+		Buckets:   []float64{60, 120, 300, 600, 900, 1200, 1800, 2700, 3600, 5400, 7200},
+	}, []string{"template", "provider"})
+
+	templateValid = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "template_valid",
+		Help:      "Whether the template is valid, 1 if valid and 0 otherwise.",
+	}, []string{"kind", "namespace", "name"})
+
+	managementComponentHealthy = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "management_component_healthy",
+		Help:      "Whether the Management component is installed successfully, 1 if healthy and 0 otherwise.",
+	}, []string{"component", "template"})
+
+	credentialReady = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "credential_ready",
+		Help:      "Whether the Credential is ready, 1 if ready and 0 otherwise.",
+	}, []string{"namespace", "name"})
+
+	backupLastSuccessTimestamp = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "management_backup_last_success_timestamp_seconds",
+		Help:      "The completion time of the last successful backup of the ManagementBackup as a Unix timestamp.",
+	}, []string{"name"})
+
+	backupLastDuration = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "management_backup_last_duration_seconds",
+		Help:      "The duration of the last successful backup of the ManagementBackup.",
+	}, []string{"name"})
+
+	services = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "services",
+		Help:      "The number of the services deployed by the Profile or ClusterProfile on the cluster by the status of their readiness.",
+	}, []string{"profile_namespace", "profile_name", "cluster_namespace", "cluster_name", "ready"})
+
+	chartCacheRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "chart_cache_requests_total",
+		Help:      "The number of the chart cache lookups by their result, either hit or miss.",
+	}, []string{"result"})
+
+	chartCacheEvictions = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "chart_cache_evictions_total",
+		Help:      "The number of the charts evicted from the chart cache by the reason, either size or revision.",
+	}, []string{"reason"})
+
+	chartCacheSize = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "chart_cache_size_bytes",
+		Help:      "The total size of the chart archives in the chart cache.",
+	})
+
+	chartCacheEntries = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "chart_cache_entries",
+		Help:      "The number of the charts in the chart cache.",
+	})
+)
+
+func init() {
+	ctrlmetrics.Registry.MustRegister(
+		clusterDeploymentStatus,
+		clusterDeploymentProvisioningDuration,
+		templateValid,
+		managementComponentHealthy,
+		credentialReady,
+		backupLastSuccessTimestamp,
+		backupLastDuration,
+		services,
+		chartCacheRequests,
+		chartCacheEvictions,
+		chartCacheSize,
+		chartCacheEntries,
+	)
+}
+
+// TrackClusterDeployment records the status of the given ClusterDeployment deployed from the template
+// with the given providers. The provisioning duration is observed up to the first Ready time of the
+// ClusterDeployment if firstReady reports it has just become Ready for the first time.
+func TrackClusterDeployment(cd *kcm.ClusterDeployment, providers []string, firstReady bool) {
+	provider := infrastructureProviders(providers)
+
+	ready := string(metav1.ConditionUnknown)
+	if c := apimeta.FindStatusCondition(cd.Status.Conditions, kcm.ReadyCondition); c != nil {
+		ready = string(c.Status)
+	}
+
+	clusterDeploymentStatus.DeletePartialMatch(prometheus.Labels{"namespace": cd.Namespace, "name": cd.Name})
+	clusterDeploymentStatus.WithLabelValues(cd.Namespace, cd.Name, cd.Spec.Template, provider, ready).Set(1)
+
+	if !firstReady || cd.Status.FirstReadyTime == nil {
+		return
+	}
+	clusterDeploymentProvisioningDuration.WithLabelValues(cd.Spec.Template, provider).
+		Observe(cd.Status.FirstReadyTime.Sub(cd.CreationTimestamp.Time).Seconds())
+}
+
+// DeleteClusterDeployment removes the metrics of the deleted ClusterDeployment.
+func DeleteClusterDeployment(namespace, name string) {
+	clusterDeploymentStatus.DeletePartialMatch(prometheus.Labels{"namespace": namespace, "name": name})
+}
+
+// TrackTemplate records the validity of the template of the given kind.
+func TrackTemplate(kind, namespace, name string, valid bool) {
+	templateValid.WithLabelValues(kind, namespace, name).Set(boolToFloat(valid))
+}
+
+// DeleteTemplate removes the metrics of the deleted template of the given kind.
+func DeleteTemplate(kind, namespace, name string) {
+	templateValid.DeleteLabelValues(kind, namespace, name)
+}
+
+// TrackManagementComponents records the health of the given Management components,
+// the metrics of the removed components are dropped.
+func TrackManagementComponents(components map[string]kcm.ComponentStatus) {
+	managementComponentHealthy.Reset()
+	for name, component := range components {
+		managementComponentHealthy.WithLabelValues(name, component.Template).Set(boolToFloat(component.Success))
+	}
+}
+
+// TrackCredential records the readiness of the Credential.
+func TrackCredential(namespace, name string, ready bool) {
+	credentialReady.WithLabelValues(namespace, name).Set(boolToFloat(ready))
+}
+
+// DeleteCredential removes the metrics of the deleted Credential.
+func DeleteCredential(namespace, name string) {
+	credentialReady.DeleteLabelValues(namespace, name)
+}
+
+// TrackBackup records the completion time and the duration of the given backup
+// of the ManagementBackup if it has completed successfully.
+func TrackBackup(name string, status *velerov1.BackupStatus) {
+	if status == nil || status.Phase != velerov1.BackupPhaseCompleted || status.CompletionTimestamp.IsZero() {
+		return
+	}
+
+	backupLastSuccessTimestamp.WithLabelValues(name).Set(float64(status.CompletionTimestamp.Unix()))
+	if !status.StartTimestamp.IsZero() {
+		backupLastDuration.WithLabelValues(name).Set(status.CompletionTimestamp.Sub(status.StartTimestamp.Time).Seconds())
+	}
+}
+
+// DeleteBackup removes the metrics of the deleted ManagementBackup.
+func DeleteBackup(name string) {
+	backupLastSuccessTimestamp.DeleteLabelValues(name)
+	backupLastDuration.DeleteLabelValues(name)
+}
+
+// TrackServices records the number of the ready and not ready services deployed by the given
+// Profile, or ClusterProfile if the namespace is empty, per each of the matching clusters.
+func TrackServices(profileNamespace, profileName string, statuses []kcm.ServiceStatus) {
+	services.DeletePartialMatch(prometheus.Labels{"profile_namespace": profileNamespace, "profile_name": profileName})
+
+	for _, status := range statuses {
+		counts := make(map[metav1.ConditionStatus]float64)
+		for _, c := range status.Conditions {
+			if strings.HasSuffix(c.Type, "/"+kcm.SveltosHelmReleaseReadyCondition) {
+				counts[c.Status]++
+			}
+		}
+
+		for _, s := range []metav1.ConditionStatus{metav1.ConditionTrue, metav1.ConditionFalse} {
+			services.WithLabelValues(profileNamespace, profileName, status.ClusterNamespace, status.ClusterName, string(s)).Set(counts[s])
+		}
+	}
+}
+
+// DeleteServices removes the metrics of the services of the deleted Profile or ClusterProfile.
+func DeleteServices(profileNamespace, profileName string) {
+	services.DeletePartialMatch(prometheus.Labels{"profile_namespace": profileNamespace, "profile_name": profileName})
+}
+
+// TrackChartCacheLookup records the result of the chart cache lookup.
+func TrackChartCacheLookup(hit bool) {
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	chartCacheRequests.WithLabelValues(result).Inc()
+}
+
+// TrackChartCacheEviction records the eviction of a chart from the chart cache for the given reason.
+func TrackChartCacheEviction(reason string) {
+	chartCacheEvictions.WithLabelValues(reason).Inc()
+}
+
+// TrackChartCacheSize records the total size and the number of the charts in the chart cache.
+func TrackChartCacheSize(bytes int64, entries int) {
+	chartCacheSize.Set(float64(bytes))
+	chartCacheEntries.Set(float64(entries))
+}
+
+func infrastructureProviders(providers []string) string {
+	var infra []string
+	for _, p := range providers {
+		if name, ok := strings.CutPrefix(p, providersloader.InfraPrefix); ok {
+			infra = append(infra, name)
+		}
+	}
+	return strings.Join(infra, ",")
+}
+
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
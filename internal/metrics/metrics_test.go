@@ -0,0 +1,126 @@
+// Copyright 2024
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kcm "github.com/K0rdent/kcm/api/v1alpha1"
+)
+
+func TestTrackClusterDeployment(t *testing.T) {
+	g := NewWithT(t)
+
+	cd := &kcm.ClusterDeployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "dev",
+			Namespace:         "kcm-system",
+			UID:               "uid",
+			CreationTimestamp: metav1.NewTime(time.Now().Add(-10 * time.Minute)),
+		},
+		Spec: kcm.ClusterDeploymentSpec{Template: "aws-standalone-cp-0-1-0"},
+	}
+	providers := []string{"infrastructure-aws", "control-plane-k0sproject-k0smotron", "bootstrap-k0sproject-k0smotron"}
+
+	setReady := func(status metav1.ConditionStatus) {
+		cd.Status.Conditions = []metav1.Condition{{Type: kcm.ReadyCondition, Status: status}}
+	}
+
+	setReady(metav1.ConditionFalse)
+	TrackClusterDeployment(cd, providers, false)
+	g.Expect(testutil.ToFloat64(clusterDeploymentStatus.WithLabelValues("kcm-system", "dev", "aws-standalone-cp-0-1-0", "aws", "False"))).To(Equal(1.0))
+	g.Expect(testutil.CollectAndCount(clusterDeploymentProvisioningDuration)).To(Equal(0))
+
+	setReady(metav1.ConditionTrue)
+	cd.Status.FirstReadyTime = &metav1.Time{Time: cd.CreationTimestamp.Add(5 * time.Minute)}
+	TrackClusterDeployment(cd, providers, true)
+	g.Expect(testutil.CollectAndCount(clusterDeploymentStatus)).To(Equal(1))
+	g.Expect(testutil.ToFloat64(clusterDeploymentStatus.WithLabelValues("kcm-system", "dev", "aws-standalone-cp-0-1-0", "aws", "True"))).To(Equal(1.0))
+	m := provisioningHistogram(g, "aws-standalone-cp-0-1-0", "aws")
+	g.Expect(m.GetSampleCount()).To(Equal(uint64(1)))
+	g.Expect(m.GetSampleSum()).To(Equal((5 * time.Minute).Seconds()), "observed up to the first Ready time")
+
+	// becoming Ready again does not count as the provisioning
+	setReady(metav1.ConditionFalse)
+	TrackClusterDeployment(cd, providers, false)
+	setReady(metav1.ConditionTrue)
+	TrackClusterDeployment(cd, providers, false)
+	g.Expect(provisioningHistogram(g, "aws-standalone-cp-0-1-0", "aws").GetSampleCount()).To(Equal(uint64(1)))
+
+	DeleteClusterDeployment(cd.Namespace, cd.Name)
+	g.Expect(testutil.CollectAndCount(clusterDeploymentStatus)).To(Equal(0))
+}
+
+func provisioningHistogram(g Gomega, template, provider string) *dto.Histogram {
+	m := &dto.Metric{}
+	g.Expect(clusterDeploymentProvisioningDuration.WithLabelValues(template, provider).(prometheus.Histogram).Write(m)).To(Succeed())
+	return m.GetHistogram()
+}
+
+func TestTrackServices(t *testing.T) {
+	g := NewWithT(t)
+
+	condition := func(release string, status metav1.ConditionStatus) metav1.Condition {
+		return metav1.Condition{Type: release + "/" + kcm.SveltosHelmReleaseReadyCondition, Status: status}
+	}
+
+	TrackServices("kcm-system", "dev", []kcm.ServiceStatus{
+		{
+			ClusterName:      "dev",
+			ClusterNamespace: "kcm-system",
+			Conditions: []metav1.Condition{
+				{Type: "Helm", Status: metav1.ConditionTrue},
+				condition("ingress-nginx.ingress-nginx", metav1.ConditionTrue),
+				condition("cert-manager.cert-manager", metav1.ConditionTrue),
+				condition("kyverno.kyverno", metav1.ConditionFalse),
+			},
+		},
+	})
+	g.Expect(testutil.ToFloat64(services.WithLabelValues("kcm-system", "dev", "kcm-system", "dev", "True"))).To(Equal(2.0))
+	g.Expect(testutil.ToFloat64(services.WithLabelValues("kcm-system", "dev", "kcm-system", "dev", "False"))).To(Equal(1.0))
+
+	DeleteServices("kcm-system", "dev")
+	g.Expect(testutil.CollectAndCount(services)).To(Equal(0))
+}
+
+func TestTrackBackup(t *testing.T) {
+	g := NewWithT(t)
+
+	start := time.Date(2024, 12, 1, 10, 0, 0, 0, time.UTC)
+	status := &velerov1.BackupStatus{
+		Phase:          velerov1.BackupPhaseInProgress,
+		StartTimestamp: &metav1.Time{Time: start},
+	}
+
+	TrackBackup("daily", status)
+	g.Expect(testutil.CollectAndCount(backupLastSuccessTimestamp)).To(Equal(0))
+
+	status.Phase = velerov1.BackupPhaseCompleted
+	status.CompletionTimestamp = &metav1.Time{Time: start.Add(90 * time.Second)}
+	TrackBackup("daily", status)
+	g.Expect(testutil.ToFloat64(backupLastSuccessTimestamp.WithLabelValues("daily"))).To(Equal(float64(start.Add(90 * time.Second).Unix())))
+	g.Expect(testutil.ToFloat64(backupLastDuration.WithLabelValues("daily"))).To(Equal(90.0))
+
+	DeleteBackup("daily")
+	g.Expect(testutil.CollectAndCount(backupLastSuccessTimestamp)).To(Equal(0))
+}
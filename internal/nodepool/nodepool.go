@@ -0,0 +1,142 @@
+// Copyright 2024
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nodepool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	clusterapiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	expv1 "sigs.k8s.io/cluster-api/exp/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kcm "github.com/K0rdent/kcm/api/v1alpha1"
+	"github.com/K0rdent/kcm/internal/adoption"
+)
+
+// K0sWorkerConfigTemplateKind and K0sWorkerConfigKind are the kinds of the k0smotron bootstrap template
+// of the MachineDeployments and of the bootstrap config of the MachinePools, the labels and taints
+// of the nodes are passed as the k0s worker arguments.
+const (
+	K0sWorkerConfigTemplateKind = "K0sWorkerConfigTemplate"
+	K0sWorkerConfigKind         = "K0sWorkerConfig"
+)
+
+var (
+	// ErrNoDefaultWorkerPool is returned while neither the worker MachineDeployment
+	// nor the worker MachinePool of the cluster is rendered yet.
+	ErrNoDefaultWorkerPool = errors.New("default worker MachineDeployment or MachinePool of the cluster is not found")
+	// ErrUnsupportedCluster is returned if the NodePool cannot be rendered for the cluster.
+	ErrUnsupportedCluster = errors.New("the NodePool is not supported for the cluster")
+)
+
+// DefaultMachineDeployment returns the worker MachineDeployment rendered by the ClusterTemplate chart
+// of the given ClusterDeployment, its templates are the base of the ones of the NodePools.
+func DefaultMachineDeployment(ctx context.Context, c client.Client, clusterDeployment *kcm.ClusterDeployment) (*clusterapiv1beta1.MachineDeployment, error) {
+	mds := &clusterapiv1beta1.MachineDeploymentList{}
+	if err := c.List(ctx, mds,
+		client.InNamespace(clusterDeployment.Namespace),
+		client.MatchingLabels{kcm.FluxHelmChartNameKey: clusterDeployment.Name},
+	); err != nil {
+		return nil, fmt.Errorf("failed to list MachineDeployments of ClusterDeployment %s/%s: %w", clusterDeployment.Namespace, clusterDeployment.Name, err)
+	}
+
+	mds.Items = slices.DeleteFunc(mds.Items, func(md clusterapiv1beta1.MachineDeployment) bool {
+		_, ok := md.Labels[kcm.NodePoolLabelKey]
+		return ok
+	})
+	if len(mds.Items) == 0 {
+		return nil, ErrNoDefaultWorkerPool
+	}
+
+	slices.SortFunc(mds.Items, func(a, b clusterapiv1beta1.MachineDeployment) int { return strings.Compare(a.Name, b.Name) })
+	return &mds.Items[0], nil
+}
+
+// DefaultMachinePool returns the worker MachinePool rendered by the ClusterTemplate chart of the given
+// ClusterDeployment, e.g. the one of GKE or AKS clusters, its objects are the base of the ones of the NodePools.
+func DefaultMachinePool(ctx context.Context, c client.Client, clusterDeployment *kcm.ClusterDeployment) (*expv1.MachinePool, error) {
+	mps := &expv1.MachinePoolList{}
+	err := c.List(ctx, mps,
+		client.InNamespace(clusterDeployment.Namespace),
+		client.MatchingLabels{kcm.FluxHelmChartNameKey: clusterDeployment.Name},
+	)
+	// the MachinePools may be disabled in Cluster API
+	if apimeta.IsNoMatchError(err) {
+		return nil, ErrNoDefaultWorkerPool
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list MachinePools of ClusterDeployment %s/%s: %w", clusterDeployment.Namespace, clusterDeployment.Name, err)
+	}
+
+	mps.Items = slices.DeleteFunc(mps.Items, func(mp expv1.MachinePool) bool {
+		_, ok := mp.Labels[kcm.NodePoolLabelKey]
+		return ok
+	})
+	if len(mps.Items) == 0 {
+		return nil, ErrNoDefaultWorkerPool
+	}
+
+	slices.SortFunc(mps.Items, func(a, b expv1.MachinePool) int { return strings.Compare(a.Name, b.Name) })
+	return &mps.Items[0], nil
+}
+
+// CheckSupported returns an error wrapping ErrUnsupportedCluster if the NodePool cannot be rendered for the cluster
+// of the given ClusterDeployment: the nodes of the adopted clusters are not managed by Cluster API, and the node
+// labels and taints are only supported with the k0smotron bootstrap configs. The latter is only checked once
+// the cluster is rendered.
+func CheckSupported(ctx context.Context, c client.Client, nodePool *kcm.NodePool, clusterDeployment *kcm.ClusterDeployment) error {
+	template := &kcm.ClusterTemplate{}
+	if err := c.Get(ctx, client.ObjectKey{Namespace: clusterDeployment.Namespace, Name: clusterDeployment.Spec.Template}, template); err != nil {
+		return fmt.Errorf("failed to get ClusterTemplate %s/%s: %w", clusterDeployment.Namespace, clusterDeployment.Spec.Template, err)
+	}
+	if adoption.IsAdoptedClusterTemplate(template) {
+		return fmt.Errorf("%w: the nodes of the adopted cluster %s are not managed by Cluster API", ErrUnsupportedCluster, clusterDeployment.Name)
+	}
+
+	if len(nodePool.Spec.Labels) == 0 && len(nodePool.Spec.Taints) == 0 {
+		return nil
+	}
+
+	mp, err := DefaultMachinePool(ctx, c, clusterDeployment)
+	if err == nil {
+		if ref := mp.Spec.Template.Spec.Bootstrap.ConfigRef; ref == nil || ref.Kind != K0sWorkerConfigKind {
+			return fmt.Errorf("%w: node labels and taints of the MachinePools are only supported with the %s bootstrap configs, "+
+				"set them in the machineTemplate instead", ErrUnsupportedCluster, K0sWorkerConfigKind)
+		}
+		return nil
+	}
+	if !errors.Is(err, ErrNoDefaultWorkerPool) {
+		return err
+	}
+
+	md, err := DefaultMachineDeployment(ctx, c, clusterDeployment)
+	if errors.Is(err, ErrNoDefaultWorkerPool) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if ref := md.Spec.Template.Spec.Bootstrap.ConfigRef; ref == nil || ref.Kind != K0sWorkerConfigTemplateKind {
+		return fmt.Errorf("%w: node labels and taints are only supported with the %s bootstrap templates", ErrUnsupportedCluster, K0sWorkerConfigTemplateKind)
+	}
+
+	return nil
+}
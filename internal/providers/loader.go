@@ -15,30 +15,25 @@
 package providers
 
 import (
-	"cmp"
 	"fmt"
 	"os"
-	"path/filepath"
-	"testing"
 
-	"github.com/K0rdent/kcm/internal/projectroot"
+	providerfiles "github.com/K0rdent/kcm/providers"
 )
 
 const EnvProvidersPathGlob = "PROVIDERS_PATH_GLOB"
 
-func init() {
-	var baseDir string
+// providersGlob is the pattern of the embedded definitions of the built-in providers.
+const providersGlob = "*.yml"
 
-	if testing.Testing() {
-		baseDir = projectroot.Path
+func init() {
+	var err error
+	if pattern := os.Getenv(EnvProvidersPathGlob); pattern != "" {
+		err = RegisterProvidersFromGlob(pattern)
+	} else {
+		err = RegisterProvidersFromFS(providerfiles.FS, providersGlob)
 	}
-
-	providersGlob := cmp.Or(
-		os.Getenv(EnvProvidersPathGlob),
-		filepath.Join(baseDir, "providers", "*.yml"),
-	)
-
-	if err := RegisterProvidersFromGlob(providersGlob); err != nil {
+	if err != nil {
 		panic(fmt.Sprintf("failed to register providers: %v", err))
 	}
 }
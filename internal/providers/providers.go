@@ -15,6 +15,7 @@
 package providers
 
 import (
+	"errors"
 	"fmt"
 	"slices"
 	"strings"
@@ -45,8 +46,14 @@ var (
 	}
 
 	registry map[string]ProviderModule
+	// builtin holds the short names of the providers registered with the Register,
+	// those cannot be replaced nor unregistered at runtime.
+	builtin = make(map[string]struct{})
 )
 
+// ErrBuiltinProvider is returned on attempts to replace a built-in provider at runtime.
+var ErrBuiltinProvider = errors.New("provider is built-in")
+
 type ProviderModule interface {
 	// GetName returns the short name of the provider
 	GetName() string
@@ -56,7 +63,9 @@ type ProviderModule interface {
 	GetClusterIdentityKinds() []string
 }
 
-// Register adds a new provider module to the registry
+// Register adds a new built-in provider module to the registry,
+// it panics if the provider is already registered. The opt-in
+// providers are not listed among the providers of the default Management.
 func Register(p ProviderModule) {
 	mu.Lock()
 	defer mu.Unlock()
@@ -71,20 +80,100 @@ func Register(p ProviderModule) {
 		panic(fmt.Sprintf("provider %q already registered", shortName))
 	}
 
-	providers = append(providers,
-		kcm.Provider{
-			Name: ProviderPrefix + p.GetName(),
-		},
-	)
+	if optIn, ok := p.(interface{ IsOptIn() bool }); !ok || !optIn.IsOptIn() {
+		providers = append(providers,
+			kcm.Provider{
+				Name: ProviderPrefix + p.GetName(),
+			},
+		)
+	}
 
 	registry[shortName] = p
+	builtin[shortName] = struct{}{}
 }
 
-// List returns a copy of all registered providers
+// RegisterOrUpdate adds the provider module to the registry at runtime or replaces
+// the one registered previously with the same name. It returns the ErrBuiltinProvider
+// if the provider with the same name is built-in.
+func RegisterOrUpdate(p ProviderModule) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if registry == nil {
+		registry = make(map[string]ProviderModule)
+	}
+
+	shortName := p.GetName()
+
+	if _, ok := builtin[shortName]; ok {
+		return fmt.Errorf("failed to register provider %q: %w", shortName, ErrBuiltinProvider)
+	}
+
+	if _, exists := registry[shortName]; !exists {
+		providers = append(providers,
+			kcm.Provider{
+				Name: ProviderPrefix + shortName,
+			},
+		)
+	}
+
+	registry[shortName] = p
+
+	return nil
+}
+
+// Unregister removes the provider module registered at runtime with the RegisterOrUpdate.
+// Built-in and unknown providers are left intact.
+func Unregister(shortName string) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if _, ok := builtin[shortName]; ok {
+		return
+	}
+
+	if _, exists := registry[shortName]; !exists {
+		return
+	}
+
+	delete(registry, shortName)
+	providers = slices.DeleteFunc(providers, func(p kcm.Provider) bool {
+		return p.Name == ProviderPrefix+shortName
+	})
+}
+
+// IsBuiltin reports whether the provider with the given short name is built-in.
+func IsBuiltin(shortName string) bool {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	_, ok := builtin[shortName]
+	return ok
+}
+
+// List returns a copy of all registered providers except the built-in opt-in ones
 func List() []kcm.Provider {
+	mu.RLock()
+	defer mu.RUnlock()
+
 	return slices.Clone(providers)
 }
 
+// ListBuiltin returns a copy of the built-in providers
+func ListBuiltin() []kcm.Provider {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	return slices.DeleteFunc(slices.Clone(providers), func(p kcm.Provider) bool {
+		shortName, ok := strings.CutPrefix(p.Name, ProviderPrefix)
+		if !ok {
+			return false
+		}
+		_, isBuiltin := builtin[shortName]
+		return !isBuiltin
+	})
+}
+
 // GetClusterGVKs returns the GroupVersionKind for a provider's cluster resource
 func GetClusterGVKs(shortName string) []schema.GroupVersionKind {
 	mu.RLock()
@@ -98,6 +187,21 @@ func GetClusterGVKs(shortName string) []schema.GroupVersionKind {
 	return module.GetClusterGVKs()
 }
 
+// BuiltinClusterGVKOwner returns the short name of the built-in provider
+// the given cluster GVK belongs to.
+func BuiltinClusterGVKOwner(gvk schema.GroupVersionKind) (string, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	for shortName := range builtin {
+		if module, ok := registry[shortName]; ok && slices.Contains(module.GetClusterGVKs(), gvk) {
+			return shortName, true
+		}
+	}
+
+	return "", false
+}
+
 // GetClusterIdentityKinds returns the supported identity kinds for a given infrastructure provider
 func GetClusterIdentityKinds(infraName string) ([]string, bool) {
 	mu.RLock()
@@ -0,0 +1,94 @@
+// Copyright 2024
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package providers
+
+import (
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	kcm "github.com/K0rdent/kcm/api/v1alpha1"
+)
+
+func TestRegisterOrUpdate(t *testing.T) {
+	g := NewWithT(t)
+
+	gvk := schema.GroupVersionKind{Group: "infrastructure.cluster.x-k8s.io", Version: "v1beta1", Kind: "InHouseCluster"}
+	inHouse := &YAMLProviderDefinition{
+		Name:                 "inhouse",
+		ClusterGVKs:          []schema.GroupVersionKind{gvk},
+		ClusterIdentityKinds: []string{"Secret"},
+	}
+
+	g.Expect(IsBuiltin("aws")).To(BeTrue())
+	g.Expect(IsBuiltin("gcp")).To(BeTrue())
+	g.Expect(GetClusterGVKs("gcp")).NotTo(BeEmpty())
+	g.Expect(List()).NotTo(ContainElement(kcm.Provider{Name: ProviderPrefix + "gcp"}), "the opt-in providers are not listed")
+	g.Expect(List()).NotTo(ContainElement(kcm.Provider{Name: ProviderPrefix + "docker"}), "the providers for the development only are opt-in")
+	g.Expect(IsBuiltin(inHouse.Name)).To(BeFalse())
+	owner, found := BuiltinClusterGVKOwner(schema.GroupVersionKind{Group: "infrastructure.cluster.x-k8s.io", Version: "v1beta2", Kind: "AWSCluster"})
+	g.Expect(found).To(BeTrue())
+	g.Expect(owner).To(Equal("aws"))
+	_, found = BuiltinClusterGVKOwner(gvk)
+	g.Expect(found).To(BeFalse())
+
+	g.Expect(RegisterOrUpdate(&YAMLProviderDefinition{Name: "aws"})).To(MatchError(ErrBuiltinProvider))
+	kinds, found := GetClusterIdentityKinds(InfraPrefix + "aws")
+	g.Expect(found).To(BeTrue())
+	g.Expect(kinds).To(ContainElement("AWSClusterStaticIdentity"))
+
+	g.Expect(RegisterOrUpdate(inHouse)).To(Succeed())
+	g.Expect(GetClusterGVKs(inHouse.Name)).To(Equal([]schema.GroupVersionKind{gvk}))
+	g.Expect(List()).To(ContainElement(kcm.Provider{Name: ProviderPrefix + inHouse.Name}))
+	g.Expect(ListBuiltin()).NotTo(ContainElement(kcm.Provider{Name: ProviderPrefix + inHouse.Name}))
+	g.Expect(ListBuiltin()).To(ContainElements(kcm.Provider{Name: kcm.ProviderSveltosName}, kcm.Provider{Name: ProviderPrefix + "aws"}))
+
+	inHouse = &YAMLProviderDefinition{
+		Name:                 inHouse.Name,
+		ClusterGVKs:          inHouse.ClusterGVKs,
+		ClusterIdentityKinds: []string{"InHouseClusterIdentity"},
+	}
+	g.Expect(RegisterOrUpdate(inHouse)).To(Succeed())
+	kinds, found = GetClusterIdentityKinds(InfraPrefix + inHouse.Name)
+	g.Expect(found).To(BeTrue())
+	g.Expect(kinds).To(Equal([]string{"InHouseClusterIdentity"}))
+	g.Expect(countProvider(List(), ProviderPrefix+inHouse.Name)).To(Equal(1))
+
+	Unregister(inHouse.Name)
+	g.Expect(GetClusterGVKs(inHouse.Name)).To(BeEmpty())
+	g.Expect(List()).NotTo(ContainElement(kcm.Provider{Name: ProviderPrefix + inHouse.Name}))
+
+	Unregister("aws")
+	g.Expect(GetClusterGVKs("aws")).NotTo(BeEmpty())
+	g.Expect(List()).To(ContainElement(kcm.Provider{Name: ProviderPrefix + "aws"}))
+}
+
+func countProvider(list []kcm.Provider, name string) (n int) {
+	for _, p := range list {
+		if p.Name == name {
+			n++
+		}
+	}
+	return n
+}
+
+func TestRegisterProvidersFromGlob(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(RegisterProvidersFromGlob(filepath.Join(t.TempDir(), "*.yml"))).
+		To(MatchError(ContainSubstring("no provider YAML files match the pattern")), "a misconfigured pattern must not leave the registry empty")
+}
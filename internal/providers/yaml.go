@@ -16,7 +16,9 @@ package providers
 
 import (
 	"fmt"
+	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
 	"slices"
 
@@ -29,6 +31,9 @@ type YAMLProviderDefinition struct {
 	Name                 string                    `yaml:"name"`
 	ClusterGVKs          []schema.GroupVersionKind `yaml:"clusterGVKs"`
 	ClusterIdentityKinds []string                  `yaml:"clusterIdentityKinds"`
+	// OptIn excludes the provider from the providers of the default Management,
+	// the provider is installed once it is added to the Management explicitly.
+	OptIn bool `yaml:"optIn"`
 }
 
 var _ ProviderModule = (*YAMLProviderDefinition)(nil)
@@ -57,6 +62,10 @@ func (p *YAMLProviderDefinition) GetClusterIdentityKinds() []string {
 	return slices.Clone(p.ClusterIdentityKinds)
 }
 
+func (p *YAMLProviderDefinition) IsOptIn() bool {
+	return p.OptIn
+}
+
 // RegisterFromYAML registers a provider from a YAML file.
 func RegisterFromYAML(yamlFile string) error {
 	data, err := os.ReadFile(yamlFile)
@@ -64,6 +73,10 @@ func RegisterFromYAML(yamlFile string) error {
 		return fmt.Errorf("failed to read YAML file: %w", err)
 	}
 
+	return registerFromYAMLData(data)
+}
+
+func registerFromYAMLData(data []byte) error {
 	var ypd YAMLProviderDefinition
 
 	if err := yaml.Unmarshal(data, &ypd); err != nil {
@@ -76,11 +89,15 @@ func RegisterFromYAML(yamlFile string) error {
 }
 
 // RegisterProvidersFromGlob loads and registers provider YAML files matching the glob pattern.
+// It returns an error if no files match the pattern.
 func RegisterProvidersFromGlob(pattern string) error {
 	matches, err := filepath.Glob(pattern)
 	if err != nil {
 		return fmt.Errorf("failed to glob pattern %q: %w", pattern, err)
 	}
+	if len(matches) == 0 {
+		return fmt.Errorf("no provider YAML files match the pattern %q", pattern)
+	}
 
 	for _, file := range matches {
 		if err := RegisterFromYAML(file); err != nil {
@@ -90,3 +107,27 @@ func RegisterProvidersFromGlob(pattern string) error {
 
 	return nil
 }
+
+// RegisterProvidersFromFS loads and registers provider YAML files of the given
+// file system matching the glob pattern. It returns an error if no files match the pattern.
+func RegisterProvidersFromFS(fsys fs.FS, pattern string) error {
+	matches, err := fs.Glob(fsys, pattern)
+	if err != nil {
+		return fmt.Errorf("failed to glob pattern %q: %w", pattern, err)
+	}
+	if len(matches) == 0 {
+		return fmt.Errorf("no provider YAML files match the pattern %q", pattern)
+	}
+
+	for _, file := range matches {
+		data, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return fmt.Errorf("provider %s: failed to read YAML file: %w", path.Base(file), err)
+		}
+		if err := registerFromYAMLData(data); err != nil {
+			return fmt.Errorf("provider %s: %w", path.Base(file), err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,110 @@
+// Copyright 2024
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package scope restricts kcm to a set of namespaces given by a list
+// and a label selector, the system namespace is always in the scope.
+package scope
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"sync/atomic"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Scope is the set of namespaces kcm watches.
+type Scope struct {
+	namespaces map[string]struct{}
+	selector   labels.Selector
+}
+
+// Resolve returns the Scope of the given namespaces, the system namespace
+// and the namespaces matching the selector at the moment.
+func Resolve(ctx context.Context, reader client.Reader, namespaces []string, selector labels.Selector, systemNamespace string) (*Scope, error) {
+	s := &Scope{
+		namespaces: map[string]struct{}{systemNamespace: {}},
+		selector:   selector,
+	}
+	for _, ns := range namespaces {
+		s.namespaces[ns] = struct{}{}
+	}
+
+	if selector != nil && !selector.Empty() {
+		matching, err := listMatching(ctx, reader, selector)
+		if err != nil {
+			return nil, err
+		}
+		for _, ns := range matching {
+			s.namespaces[ns] = struct{}{}
+		}
+	}
+
+	return s, nil
+}
+
+func listMatching(ctx context.Context, reader client.Reader, selector labels.Selector) ([]string, error) {
+	namespaces := new(corev1.NamespaceList)
+	if err := reader.List(ctx, namespaces, client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return nil, fmt.Errorf("failed to list namespaces matching %s: %w", selector, err)
+	}
+
+	result := make([]string, len(namespaces.Items))
+	for i, ns := range namespaces.Items {
+		result[i] = ns.Name
+	}
+	return result, nil
+}
+
+// Namespaces returns the sorted namespaces of the Scope.
+func (s *Scope) Namespaces() []string {
+	return slices.Sorted(maps.Keys(s.namespaces))
+}
+
+// Contains returns true if the namespace is in the Scope. The cluster-scoped
+// objects with the empty namespace are always in the Scope, as well as all
+// of the namespaces if the Scope is nil.
+func (s *Scope) Contains(namespace string) bool {
+	if s == nil || namespace == "" {
+		return true
+	}
+	_, ok := s.namespaces[namespace]
+	return ok
+}
+
+// CacheNamespaces returns the namespaces the manager cache is restricted to.
+func (s *Scope) CacheNamespaces() map[string]cache.Config {
+	result := make(map[string]cache.Config, len(s.namespaces))
+	for ns := range s.namespaces {
+		result[ns] = cache.Config{}
+	}
+	return result
+}
+
+var current atomic.Pointer[Scope]
+
+// Set sets the current Scope, nil means all of the namespaces.
+func Set(s *Scope) {
+	current.Store(s)
+}
+
+// Contains returns true if the namespace is in the current Scope.
+func Contains(namespace string) bool {
+	return current.Load().Contains(namespace)
+}
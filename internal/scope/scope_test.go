@@ -0,0 +1,122 @@
+// Copyright 2024
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scope
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	kcm "github.com/K0rdent/kcm/api/v1alpha1"
+)
+
+func namespace(name string, labels map[string]string) *corev1.Namespace {
+	return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}}
+}
+
+func TestResolve(t *testing.T) {
+	g := NewWithT(t)
+
+	cl := fake.NewClientBuilder().WithObjects(
+		namespace("team-a", map[string]string{"kcm": "enabled"}),
+		namespace("team-b", nil),
+	).Build()
+
+	s, err := Resolve(context.Background(), cl, []string{"explicit"}, labels.SelectorFromSet(labels.Set{"kcm": "enabled"}), "kcm-system")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(s.Namespaces()).To(Equal([]string{"explicit", "kcm-system", "team-a"}))
+	g.Expect(s.Contains("team-a")).To(BeTrue())
+	g.Expect(s.Contains("team-b")).To(BeFalse())
+	g.Expect(s.Contains("")).To(BeTrue())
+	g.Expect(s.CacheNamespaces()).To(HaveLen(3))
+
+	var unrestricted *Scope
+	g.Expect(unrestricted.Contains("team-b")).To(BeTrue())
+}
+
+type fakeValidator struct {
+	validated bool
+}
+
+func (f *fakeValidator) ValidateCreate(context.Context, runtime.Object) (admission.Warnings, error) {
+	f.validated = true
+	return nil, nil
+}
+
+func (f *fakeValidator) ValidateUpdate(context.Context, runtime.Object, runtime.Object) (admission.Warnings, error) {
+	f.validated = true
+	return nil, nil
+}
+
+func (f *fakeValidator) ValidateDelete(context.Context, runtime.Object) (admission.Warnings, error) {
+	f.validated = true
+	return nil, nil
+}
+
+func TestValidator(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+
+	s, err := Resolve(ctx, nil, []string{"team-a"}, nil, "kcm-system")
+	g.Expect(err).NotTo(HaveOccurred())
+	Set(s)
+	t.Cleanup(func() { Set(nil) })
+
+	inScope := &kcm.ClusterDeployment{ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "cluster"}}
+	outOfScope := &kcm.ClusterDeployment{ObjectMeta: metav1.ObjectMeta{Namespace: "team-b", Name: "cluster"}}
+
+	inner := &fakeValidator{}
+	v := Validator(inner)
+
+	_, err = v.ValidateCreate(ctx, inScope)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(inner.validated).To(BeTrue())
+
+	inner.validated = false
+	_, err = v.ValidateCreate(ctx, outOfScope)
+	g.Expect(err).To(MatchError("namespace team-b is not watched by kcm"))
+	_, err = v.ValidateUpdate(ctx, outOfScope, outOfScope)
+	g.Expect(err).To(MatchError("namespace team-b is not watched by kcm"))
+	_, err = v.ValidateDelete(ctx, outOfScope)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(inner.validated).To(BeFalse())
+}
+
+func TestWatcher(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+
+	selector := labels.SelectorFromSet(labels.Set{"kcm": "enabled"})
+	cl := fake.NewClientBuilder().WithObjects(namespace("team-a", map[string]string{"kcm": "enabled"})).Build()
+	s, err := Resolve(ctx, cl, nil, selector, "kcm-system")
+	g.Expect(err).NotTo(HaveOccurred())
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- (&Watcher{Reader: cl, Scope: s, SystemNamespace: "kcm-system", Interval: 10 * time.Millisecond}).Start(ctx)
+	}()
+	g.Consistently(errCh).WithTimeout(50 * time.Millisecond).ShouldNot(Receive())
+
+	g.Expect(cl.Create(ctx, namespace("team-b", map[string]string{"kcm": "enabled"}))).To(Succeed())
+	g.Eventually(errCh).Should(Receive(MatchError(ErrScopeChanged)))
+}
@@ -0,0 +1,82 @@
+// Copyright 2024
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scope
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const defaultWatchInterval = 30 * time.Second
+
+// ErrScopeChanged is returned by the Watcher when the namespaces matching
+// the selector change, the manager cache cannot be extended at runtime,
+// hence the controller manager has to be restarted.
+var ErrScopeChanged = errors.New("the namespaces matching the watch selector have changed")
+
+// Watcher checks the namespaces matching the selector of the Scope
+// and stops the manager when they change.
+type Watcher struct {
+	Reader client.Reader
+	Scope  *Scope
+	// Namespaces are the namespaces given explicitly.
+	Namespaces []string
+	// SystemNamespace is the system namespace.
+	SystemNamespace string
+	// Interval is the interval the namespaces are checked at.
+	Interval time.Duration
+}
+
+// NeedLeaderElection implements the manager.LeaderElectionRunnable interface.
+func (*Watcher) NeedLeaderElection() bool {
+	return false
+}
+
+// Start implements the manager.Runnable interface.
+func (w *Watcher) Start(ctx context.Context) error {
+	l := log.FromContext(ctx).WithName("scope-watcher")
+
+	interval := w.Interval
+	if interval == 0 {
+		interval = defaultWatchInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		resolved, err := Resolve(ctx, w.Reader, w.Namespaces, w.Scope.selector, w.SystemNamespace)
+		if err != nil {
+			l.Error(err, "failed to resolve the watched namespaces")
+			continue
+		}
+
+		if current, actual := w.Scope.Namespaces(), resolved.Namespaces(); !slices.Equal(current, actual) {
+			l.Info("The watched namespaces have changed, restarting", "current", current, "actual", actual)
+			return fmt.Errorf("%w: restart to watch %v", ErrScopeChanged, actual)
+		}
+	}
+}
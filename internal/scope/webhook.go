@@ -0,0 +1,91 @@
+// Copyright 2024
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scope
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+type validator struct {
+	admission.CustomValidator
+}
+
+// Validator wraps the admission.CustomValidator rejecting the creation and the
+// update of the objects in the namespaces out of the current Scope, kcm does
+// not reconcile them and cannot read the objects they refer to. The deletion
+// is allowed without the validation.
+func Validator(v admission.CustomValidator) admission.CustomValidator {
+	return &validator{CustomValidator: v}
+}
+
+func (v *validator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	if err := checkScope(obj); err != nil {
+		return nil, err
+	}
+	return v.CustomValidator.ValidateCreate(ctx, obj)
+}
+
+func (v *validator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	if err := checkScope(newObj); err != nil {
+		return nil, err
+	}
+	return v.CustomValidator.ValidateUpdate(ctx, oldObj, newObj)
+}
+
+func (v *validator) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	if !inScope(obj) {
+		return nil, nil
+	}
+	return v.CustomValidator.ValidateDelete(ctx, obj)
+}
+
+type defaulter struct {
+	admission.CustomDefaulter
+}
+
+// Defaulter wraps the admission.CustomDefaulter skipping the objects
+// in the namespaces out of the current Scope.
+func Defaulter(d admission.CustomDefaulter) admission.CustomDefaulter {
+	return &defaulter{CustomDefaulter: d}
+}
+
+func (d *defaulter) Default(ctx context.Context, obj runtime.Object) error {
+	if !inScope(obj) {
+		return nil
+	}
+	return d.CustomDefaulter.Default(ctx, obj)
+}
+
+func inScope(obj runtime.Object) bool {
+	o, ok := obj.(client.Object)
+	return !ok || Contains(o.GetNamespace())
+}
+
+func checkScope(obj runtime.Object) error {
+	if inScope(obj) {
+		return nil
+	}
+
+	o, ok := obj.(client.Object)
+	if !ok {
+		return nil
+	}
+	return fmt.Errorf("namespace %s is not watched by kcm", o.GetNamespace())
+}
@@ -0,0 +1,418 @@
+// Copyright 2024
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sharding distributes the ClusterDeployments among the replicas
+// of the controller manager. The ClusterDeployments are split into a fixed
+// number of shards, either by namespace or by name, and every replica owns
+// a fair share of the shards by holding the corresponding Leases.
+package sharding
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sync"
+	"time"
+
+	coordinationv1 "k8s.io/api/coordination/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	kcm "github.com/K0rdent/kcm/api/v1alpha1"
+)
+
+// Mode defines how the ClusterDeployments are assigned to the shards.
+type Mode string
+
+const (
+	// ModeNamespace assigns all of the ClusterDeployments in a namespace to the same shard.
+	ModeNamespace Mode = "namespace"
+	// ModeClusterDeployment assigns every ClusterDeployment to a shard individually.
+	ModeClusterDeployment Mode = "clusterdeployment"
+)
+
+const (
+	// ShardKeyLabel is the label of a ClusterDeployment overriding the key
+	// its shard is computed from, the ClusterDeployments with the same
+	// value of the label are always reconciled by the same replica.
+	ShardKeyLabel = "k0rdent.mirantis.com/shard-key"
+
+	shardLeasePrefix  = "kcm-shard-"
+	memberLeasePrefix = "kcm-shard-member-"
+	memberLabel       = "k0rdent.mirantis.com/shard-member"
+
+	defaultLeaseDuration = 15 * time.Second
+	defaultRenewInterval = 5 * time.Second
+)
+
+// Config is the configuration of the sharding.
+type Config struct {
+	// Mode defines how the ClusterDeployments are assigned to the shards.
+	Mode Mode
+	// Namespace is the namespace of the shard Leases.
+	Namespace string
+	// Identity is the unique identity of the replica.
+	Identity string
+	// Shards is the number of the shards.
+	Shards int
+	// LeaseDuration is the duration after which a Lease of a lost replica
+	// is taken over by the other replicas.
+	LeaseDuration time.Duration
+	// RenewInterval is the interval the Leases are renewed at.
+	RenewInterval time.Duration
+}
+
+// Manager maintains the Leases of the shards owned by the replica.
+type Manager struct {
+	client client.Client
+	reader client.Reader
+	events chan event.GenericEvent
+	// owned maps the owned shards to their renew deadlines, a shard is not owned
+	// anymore once its deadline passes without a successful renewal
+	owned  map[int]time.Time
+	config Config
+	mu     sync.RWMutex
+}
+
+// NewManager returns a new Manager, the reader is used to read the Leases
+// to not populate the cache of the client with them.
+func NewManager(c client.Client, reader client.Reader, config Config) (*Manager, error) {
+	if config.Shards < 1 {
+		return nil, fmt.Errorf("the number of shards must be positive, got %d", config.Shards)
+	}
+	if config.Mode != ModeNamespace && config.Mode != ModeClusterDeployment {
+		return nil, fmt.Errorf("unknown sharding mode %q", config.Mode)
+	}
+	if config.Namespace == "" || config.Identity == "" {
+		return nil, errors.New("namespace and identity must be set")
+	}
+	if config.LeaseDuration == 0 {
+		config.LeaseDuration = defaultLeaseDuration
+	}
+	if config.RenewInterval == 0 {
+		config.RenewInterval = defaultRenewInterval
+	}
+
+	return &Manager{
+		client: c,
+		reader: reader,
+		config: config,
+		owned:  make(map[int]time.Time),
+		events: make(chan event.GenericEvent, 1024),
+	}, nil
+}
+
+// Shard returns the shard the ClusterDeployment belongs to.
+func Shard(obj client.Object, mode Mode, shards int) int {
+	key := obj.GetNamespace()
+	switch {
+	case obj.GetLabels()[ShardKeyLabel] != "":
+		key = obj.GetLabels()[ShardKeyLabel]
+	case mode == ModeClusterDeployment:
+		key += "/" + obj.GetName()
+	}
+
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+	return int(h.Sum32() % uint32(shards)) //nolint:gosec // the number of shards is always positive
+}
+
+// Owns returns true if the ClusterDeployment belongs to a shard owned by the replica.
+// The shards whose Leases have not been renewed in time may be taken over by
+// the other replicas and hence are not owned even though the sync keeps failing.
+func (m *Manager) Owns(obj client.Object) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	deadline, ok := m.owned[Shard(obj, m.config.Mode, m.config.Shards)]
+	return ok && time.Now().Before(deadline)
+}
+
+// Events returns the channel of the ClusterDeployments of the newly
+// acquired shards, which must be reconciled by the replica.
+func (m *Manager) Events() <-chan event.GenericEvent {
+	return m.events
+}
+
+// NeedLeaderElection implements the manager.LeaderElectionRunnable interface,
+// the Manager runs on every replica.
+func (*Manager) NeedLeaderElection() bool {
+	return false
+}
+
+// Start implements the manager.Runnable interface.
+func (m *Manager) Start(ctx context.Context) error {
+	l := log.FromContext(ctx).WithName("sharding").WithValues("identity", m.config.Identity)
+	ctx = log.IntoContext(ctx, l)
+
+	ticker := time.NewTicker(m.config.RenewInterval)
+	defer ticker.Stop()
+	for {
+		if err := m.sync(ctx); err != nil {
+			l.Error(err, "failed to sync shard Leases")
+		}
+
+		select {
+		case <-ctx.Done():
+			releaseCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), m.config.RenewInterval)
+			m.release(releaseCtx)
+			cancel()
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// sync renews the Leases of the owned shards, releases the shards over the
+// fair share of the replica and acquires the free shards up to it.
+func (m *Manager) sync(ctx context.Context) error {
+	l := log.FromContext(ctx)
+	now := time.Now()
+
+	members, err := m.syncMembers(ctx, now)
+	if err != nil {
+		return err
+	}
+	fairShare := (m.config.Shards + members - 1) / members
+
+	leases := make([]*coordinationv1.Lease, m.config.Shards)
+	for i := range leases {
+		lease := new(coordinationv1.Lease)
+		err := m.reader.Get(ctx, client.ObjectKey{Namespace: m.config.Namespace, Name: shardLeaseName(i)}, lease)
+		switch {
+		case apierrors.IsNotFound(err):
+			continue
+		case err != nil:
+			return fmt.Errorf("failed to get Lease of shard %d: %w", i, err)
+		}
+		leases[i] = lease
+	}
+
+	// the Leases are held since the start of the sync, hence they expire no earlier than the deadline
+	deadline := now.Add(m.config.LeaseDuration)
+	owned := make(map[int]time.Time)
+	for i, lease := range leases {
+		if holder(lease) != m.config.Identity {
+			continue
+		}
+
+		if len(owned) >= fairShare {
+			lease.Spec.HolderIdentity = nil
+			if err := m.client.Update(ctx, lease); err != nil {
+				l.Error(err, "failed to release shard", "shard", i)
+				continue
+			}
+			l.Info("Released shard", "shard", i)
+			continue
+		}
+
+		m.hold(lease, now)
+		if err := m.client.Update(ctx, lease); err != nil {
+			l.Error(err, "failed to renew shard Lease", "shard", i)
+			continue
+		}
+		owned[i] = deadline
+	}
+
+	var acquired []int
+	for i, lease := range leases {
+		if len(owned) >= fairShare {
+			break
+		}
+		if _, ok := owned[i]; ok || holder(lease) == m.config.Identity {
+			continue
+		}
+		if lease != nil && holder(lease) != "" && !m.expired(lease, now) {
+			continue
+		}
+
+		if lease == nil {
+			lease = &coordinationv1.Lease{
+				ObjectMeta: metav1.ObjectMeta{Namespace: m.config.Namespace, Name: shardLeaseName(i)},
+			}
+			m.hold(lease, now)
+			err = m.client.Create(ctx, lease)
+		} else {
+			m.hold(lease, now)
+			err = m.client.Update(ctx, lease)
+		}
+		if err != nil {
+			if !apierrors.IsConflict(err) && !apierrors.IsAlreadyExists(err) {
+				l.Error(err, "failed to acquire shard", "shard", i)
+			}
+			continue
+		}
+
+		l.Info("Acquired shard", "shard", i)
+		owned[i] = deadline
+		acquired = append(acquired, i)
+	}
+
+	m.mu.Lock()
+	m.owned = owned
+	m.mu.Unlock()
+
+	if len(acquired) > 0 {
+		return m.enqueue(ctx, acquired)
+	}
+	return nil
+}
+
+// syncMembers renews the membership Lease of the replica, removes
+// the expired ones and returns the number of the live replicas.
+func (m *Manager) syncMembers(ctx context.Context, now time.Time) (int, error) {
+	leases := new(coordinationv1.LeaseList)
+	if err := m.reader.List(ctx, leases, client.InNamespace(m.config.Namespace), client.HasLabels{memberLabel}); err != nil {
+		return 0, fmt.Errorf("failed to list shard member Leases: %w", err)
+	}
+
+	members := 0
+	var own *coordinationv1.Lease
+	for i := range leases.Items {
+		lease := &leases.Items[i]
+		switch {
+		case holder(lease) == m.config.Identity:
+			own = lease
+		case m.expired(lease, now):
+			if err := m.client.Delete(ctx, lease); client.IgnoreNotFound(err) != nil {
+				log.FromContext(ctx).Error(err, "failed to delete expired shard member Lease", "lease", lease.Name)
+			}
+		default:
+			members++
+		}
+	}
+
+	if own == nil {
+		own = &coordinationv1.Lease{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: m.config.Namespace,
+				Name:      memberLeasePrefix + m.config.Identity,
+				Labels:    map[string]string{memberLabel: "true", kcm.KCMManagedLabelKey: kcm.KCMManagedLabelValue},
+			},
+		}
+		m.hold(own, now)
+		if err := m.client.Create(ctx, own); err != nil {
+			return 0, fmt.Errorf("failed to create shard member Lease: %w", err)
+		}
+	} else {
+		m.hold(own, now)
+		if err := m.client.Update(ctx, own); err != nil {
+			return 0, fmt.Errorf("failed to renew shard member Lease: %w", err)
+		}
+	}
+
+	return members + 1, nil
+}
+
+// enqueue sends the ClusterDeployments of the given shards to the events channel.
+func (m *Manager) enqueue(ctx context.Context, shards []int) error {
+	clusterDeployments := new(kcm.ClusterDeploymentList)
+	if err := m.client.List(ctx, clusterDeployments); err != nil {
+		return fmt.Errorf("failed to list ClusterDeployments: %w", err)
+	}
+
+	var events []event.GenericEvent
+	for i := range clusterDeployments.Items {
+		cd := &clusterDeployments.Items[i]
+		shard := Shard(cd, m.config.Mode, m.config.Shards)
+		for _, s := range shards {
+			if s == shard {
+				events = append(events, event.GenericEvent{Object: cd})
+				break
+			}
+		}
+	}
+
+	// do not block the renewal of the Leases while the controller is not started yet
+	go func() {
+		for _, e := range events {
+			select {
+			case m.events <- e:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return nil
+}
+
+// release releases the Leases held by the replica on shutdown so that
+// the other replicas take the shards over without waiting for the expiration.
+func (m *Manager) release(ctx context.Context) {
+	l := log.FromContext(ctx)
+
+	m.mu.Lock()
+	owned := m.owned
+	m.owned = make(map[int]time.Time)
+	m.mu.Unlock()
+
+	for i := range owned {
+		lease := new(coordinationv1.Lease)
+		if err := m.reader.Get(ctx, client.ObjectKey{Namespace: m.config.Namespace, Name: shardLeaseName(i)}, lease); err != nil {
+			l.Error(err, "failed to get shard Lease", "shard", i)
+			continue
+		}
+		if holder(lease) != m.config.Identity {
+			continue
+		}
+		lease.Spec.HolderIdentity = nil
+		if err := m.client.Update(ctx, lease); err != nil {
+			l.Error(err, "failed to release shard", "shard", i)
+		}
+	}
+
+	member := &coordinationv1.Lease{
+		ObjectMeta: metav1.ObjectMeta{Namespace: m.config.Namespace, Name: memberLeasePrefix + m.config.Identity},
+	}
+	if err := m.client.Delete(ctx, member); client.IgnoreNotFound(err) != nil {
+		l.Error(err, "failed to delete shard member Lease")
+	}
+}
+
+func (m *Manager) hold(lease *coordinationv1.Lease, now time.Time) {
+	if holder(lease) != m.config.Identity {
+		lease.Spec.AcquireTime = &metav1.MicroTime{Time: now}
+	}
+	lease.Spec.HolderIdentity = ptr.To(m.config.Identity)
+	lease.Spec.LeaseDurationSeconds = ptr.To(int32(m.config.LeaseDuration.Seconds()))
+	lease.Spec.RenewTime = &metav1.MicroTime{Time: now}
+}
+
+func (m *Manager) expired(lease *coordinationv1.Lease, now time.Time) bool {
+	if lease.Spec.RenewTime == nil {
+		return true
+	}
+
+	duration := m.config.LeaseDuration
+	if lease.Spec.LeaseDurationSeconds != nil {
+		duration = time.Duration(*lease.Spec.LeaseDurationSeconds) * time.Second
+	}
+	return lease.Spec.RenewTime.Add(duration).Before(now)
+}
+
+func holder(lease *coordinationv1.Lease) string {
+	if lease == nil || lease.Spec.HolderIdentity == nil {
+		return ""
+	}
+	return *lease.Spec.HolderIdentity
+}
+
+func shardLeaseName(shard int) string {
+	return fmt.Sprintf("%s%d", shardLeasePrefix, shard)
+}
@@ -0,0 +1,159 @@
+// Copyright 2024
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sharding
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	kcm "github.com/K0rdent/kcm/api/v1alpha1"
+)
+
+func TestShard(t *testing.T) {
+	g := NewWithT(t)
+
+	cd := func(namespace, name string, labels map[string]string) *kcm.ClusterDeployment {
+		return &kcm.ClusterDeployment{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name, Labels: labels}}
+	}
+
+	// all of the ClusterDeployments of a namespace belong to the same shard
+	g.Expect(Shard(cd("ns", "a", nil), ModeNamespace, 16)).To(Equal(Shard(cd("ns", "b", nil), ModeNamespace, 16)))
+
+	// the ClusterDeployments of a namespace are spread among the shards
+	shards := make(map[int]struct{})
+	for i := range 32 {
+		shards[Shard(cd("ns", fmt.Sprintf("cluster-%d", i), nil), ModeClusterDeployment, 4)] = struct{}{}
+	}
+	g.Expect(shards).To(HaveLen(4))
+
+	// the shard key label overrides the key in both modes
+	labels := map[string]string{ShardKeyLabel: "team-a"}
+	for _, mode := range []Mode{ModeNamespace, ModeClusterDeployment} {
+		g.Expect(Shard(cd("ns1", "a", labels), mode, 16)).To(Equal(Shard(cd("ns2", "b", labels), mode, 16)))
+	}
+}
+
+func TestManagerSync(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+
+	scheme := runtime.NewScheme()
+	g.Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+	g.Expect(kcm.AddToScheme(scheme)).To(Succeed())
+
+	var clusterDeployments []runtime.Object
+	for i := range 8 {
+		clusterDeployments = append(clusterDeployments, &kcm.ClusterDeployment{
+			ObjectMeta: metav1.ObjectMeta{Namespace: fmt.Sprintf("ns-%d", i), Name: "cluster"},
+		})
+	}
+	cl := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(clusterDeployments...).Build()
+
+	newManager := func(identity string) *Manager {
+		m, err := NewManager(cl, cl, Config{Mode: ModeNamespace, Namespace: "kcm-system", Identity: identity, Shards: 4})
+		g.Expect(err).NotTo(HaveOccurred())
+		return m
+	}
+	owned := func(m *Manager) int {
+		m.mu.RLock()
+		defer m.mu.RUnlock()
+		return len(m.owned)
+	}
+
+	a := newManager("replica-a")
+	g.Expect(a.sync(ctx)).To(Succeed())
+	g.Expect(owned(a)).To(Equal(4))
+	for _, obj := range clusterDeployments {
+		cd, ok := obj.(*kcm.ClusterDeployment)
+		g.Expect(ok).To(BeTrue())
+		g.Expect(a.Owns(cd)).To(BeTrue())
+	}
+	g.Eventually(a.Events()).Should(HaveLen(len(clusterDeployments)))
+
+	// the second replica waits for the first one to release the shards over its fair share
+	b := newManager("replica-b")
+	g.Expect(b.sync(ctx)).To(Succeed())
+	g.Expect(owned(b)).To(BeZero())
+
+	g.Expect(a.sync(ctx)).To(Succeed())
+	g.Expect(owned(a)).To(Equal(2))
+
+	g.Expect(b.sync(ctx)).To(Succeed())
+	g.Expect(owned(b)).To(Equal(2))
+	for _, obj := range clusterDeployments {
+		cd, ok := obj.(*kcm.ClusterDeployment)
+		g.Expect(ok).To(BeTrue())
+		g.Expect(a.Owns(cd)).NotTo(Equal(b.Owns(cd)))
+	}
+
+	// the shards of the stopped replica are taken over
+	a.release(ctx)
+	g.Expect(owned(a)).To(BeZero())
+	g.Expect(b.sync(ctx)).To(Succeed())
+	g.Expect(owned(b)).To(Equal(4))
+}
+
+func TestManagerOwnsAfterFailedRenewal(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+
+	scheme := runtime.NewScheme()
+	g.Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+	g.Expect(kcm.AddToScheme(scheme)).To(Succeed())
+
+	var failing atomic.Bool
+	cl := fake.NewClientBuilder().WithScheme(scheme).WithInterceptorFuncs(interceptor.Funcs{
+		List: func(ctx context.Context, c client.WithWatch, list client.ObjectList, opts ...client.ListOption) error {
+			if failing.Load() {
+				return errors.New("unavailable")
+			}
+			return c.List(ctx, list, opts...)
+		},
+	}).Build()
+
+	m, err := NewManager(cl, cl, Config{Mode: ModeNamespace, Namespace: "kcm-system", Identity: "replica", Shards: 1, LeaseDuration: time.Second})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	cd := &kcm.ClusterDeployment{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "cluster"}}
+	g.Expect(m.sync(ctx)).To(Succeed())
+	g.Expect(m.Owns(cd)).To(BeTrue())
+
+	// the shard is not owned anymore once its Lease may be taken over by the other replicas
+	failing.Store(true)
+	g.Expect(m.sync(ctx)).To(MatchError(ContainSubstring("unavailable")))
+	g.Eventually(func() bool { return m.Owns(cd) }, 2*time.Second, 100*time.Millisecond).Should(BeFalse())
+}
+
+func TestNewManager(t *testing.T) {
+	g := NewWithT(t)
+
+	_, err := NewManager(nil, nil, Config{Mode: ModeNamespace, Namespace: "kcm-system", Identity: "replica"})
+	g.Expect(err).To(MatchError(ContainSubstring("the number of shards must be positive")))
+
+	_, err = NewManager(nil, nil, Config{Mode: "unknown", Namespace: "kcm-system", Identity: "replica", Shards: 2})
+	g.Expect(err).To(MatchError(ContainSubstring(`unknown sharding mode "unknown"`)))
+}
@@ -22,6 +22,7 @@ import (
 	sourcev1 "github.com/fluxcd/source-controller/api/v1"
 	sveltosv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
 	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+	"go.opentelemetry.io/otel/attribute"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -29,6 +30,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
 	kcm "github.com/K0rdent/kcm/api/v1alpha1"
+	"github.com/K0rdent/kcm/internal/tracing"
 	"github.com/K0rdent/kcm/internal/utils"
 )
 
@@ -52,6 +54,7 @@ type ReconcileProfileOpts struct {
 
 type HelmChartOpts struct {
 	CredentialsSecretRef  *corev1.SecretReference
+	CASecretRef           *corev1.SecretReference
 	Values                string
 	RepositoryURL         string
 	RepositoryName        string
@@ -70,7 +73,10 @@ func ReconcileClusterProfile(
 	cl client.Client,
 	name string,
 	opts ReconcileProfileOpts,
-) (*sveltosv1beta1.ClusterProfile, error) {
+) (_ *sveltosv1beta1.ClusterProfile, err error) {
+	ctx, span := tracing.Start(ctx, "sveltos.ReconcileClusterProfile", attribute.String("k8s.object.name", name))
+	defer func() { tracing.End(span, err) }()
+
 	l := ctrl.LoggerFrom(ctx)
 	obj := objectMeta(opts.OwnerReference)
 	obj.SetName(name)
@@ -106,7 +112,13 @@ func ReconcileProfile(
 	namespace string,
 	name string,
 	opts ReconcileProfileOpts,
-) (*sveltosv1beta1.Profile, error) {
+) (_ *sveltosv1beta1.Profile, err error) {
+	ctx, span := tracing.Start(ctx, "sveltos.ReconcileProfile",
+		attribute.String("k8s.namespace.name", namespace),
+		attribute.String("k8s.object.name", name),
+	)
+	defer func() { tracing.End(span, err) }()
+
 	l := ctrl.LoggerFrom(ctx)
 	obj := objectMeta(opts.OwnerReference)
 	obj.SetNamespace(namespace)
@@ -224,6 +236,15 @@ func GetHelmChartOpts(ctx context.Context, c client.Client, namespace string, se
 			}
 		}
 
+		// Sveltos takes only the CA bundle in the ca.crt key of the Secret,
+		// the client certificate is not supported by the RegistryCredentialsConfig.
+		if repo.Spec.CertSecretRef != nil {
+			opt.CASecretRef = &corev1.SecretReference{
+				Name:      repo.Spec.CertSecretRef.Name,
+				Namespace: namespace,
+			}
+		}
+
 		opts = append(opts, opt)
 	}
 
@@ -272,6 +293,7 @@ func GetSpec(opts *ReconcileProfileOpts) (*sveltosv1beta1.Spec, error) {
 				PlainHTTP:             hc.PlainHTTP,
 				InsecureSkipTLSVerify: hc.InsecureSkipTLSVerify,
 				CredentialsSecretRef:  hc.CredentialsSecretRef,
+				CASecretRef:           hc.CASecretRef,
 			},
 		}
 
@@ -15,14 +15,19 @@
 package telemetry
 
 import (
-	"github.com/segmentio/analytics-go"
+	"errors"
+	"time"
 
 	"github.com/K0rdent/kcm/internal/build"
 )
 
 const (
-	clusterDeploymentCreateEvent    = "cluster-deployment-create"
-	clusterDeploymentHeartbeatEvent = "cluster-deployment-heartbeat"
+	clusterDeploymentCreateEvent      = "cluster-deployment-create"
+	clusterDeploymentDeleteEvent      = "cluster-deployment-delete"
+	clusterDeploymentUpgradeEvent     = "cluster-deployment-upgrade"
+	clusterDeploymentHeartbeatEvent   = "cluster-deployment-heartbeat"
+	multiClusterServiceHeartbeatEvent = "multi-cluster-service-heartbeat"
+	templateUsageHeartbeatEvent       = "template-usage-heartbeat"
 )
 
 func TrackClusterDeploymentCreate(id, clusterDeploymentID, template string, dryRun bool) error {
@@ -35,7 +40,26 @@ func TrackClusterDeploymentCreate(id, clusterDeploymentID, template string, dryR
 	return TrackEvent(clusterDeploymentCreateEvent, id, props)
 }
 
-func TrackClusterDeploymentHeartbeat(id, clusterDeploymentID, clusterID, template, templateHelmChartVersion string, providers []string) error {
+func TrackClusterDeploymentDelete(id, clusterDeploymentID, template string) error {
+	props := map[string]any{
+		"kcmVersion":          build.Version,
+		"clusterDeploymentID": clusterDeploymentID,
+		"template":            template,
+	}
+	return TrackEvent(clusterDeploymentDeleteEvent, id, props)
+}
+
+func TrackClusterDeploymentUpgrade(id, clusterDeploymentID, fromChart, template string) error {
+	props := map[string]any{
+		"kcmVersion":          build.Version,
+		"clusterDeploymentID": clusterDeploymentID,
+		"fromChart":           fromChart,
+		"template":            template,
+	}
+	return TrackEvent(clusterDeploymentUpgradeEvent, id, props)
+}
+
+func TrackClusterDeploymentHeartbeat(id, clusterDeploymentID, clusterID, template, templateHelmChartVersion string, providers []string, services, readyServices int) error {
 	props := map[string]any{
 		"kcmVersion":               build.Version,
 		"clusterDeploymentID":      clusterDeploymentID,
@@ -43,17 +67,45 @@ func TrackClusterDeploymentHeartbeat(id, clusterDeploymentID, clusterID, templat
 		"template":                 template,
 		"templateHelmChartVersion": templateHelmChartVersion,
 		"providers":                providers,
+		"services":                 services,
+		"readyServices":            readyServices,
 	}
 	return TrackEvent(clusterDeploymentHeartbeatEvent, id, props)
 }
 
-func TrackEvent(name, id string, properties map[string]any) error {
-	if analyticsClient == nil {
-		return nil
+func TrackMultiClusterServiceHeartbeat(id, multiClusterServiceID string, services, clusters int) error {
+	props := map[string]any{
+		"kcmVersion":            build.Version,
+		"multiClusterServiceID": multiClusterServiceID,
+		"services":              services,
+		"clusters":              clusters,
+	}
+	return TrackEvent(multiClusterServiceHeartbeatEvent, id, props)
+}
+
+func TrackTemplateUsageHeartbeat(id, kind, template, templateHelmChartVersion string, usages int) error {
+	props := map[string]any{
+		"kcmVersion":               build.Version,
+		"kind":                     kind,
+		"template":                 template,
+		"templateHelmChartVersion": templateHelmChartVersion,
+		"usages":                   usages,
 	}
-	return analyticsClient.Enqueue(analytics.Track{
-		AnonymousId: id,
-		Event:       name,
+	return TrackEvent(templateUsageHeartbeatEvent, id, props)
+}
+
+// TrackEvent delivers the event to all of the configured sinks.
+func TrackEvent(name, id string, properties map[string]any) error {
+	event := Event{
+		Timestamp:   time.Now().UTC(),
 		Properties:  properties,
-	})
+		Name:        name,
+		AnonymousID: id,
+	}
+
+	var errs error
+	for _, sink := range sinks {
+		errs = errors.Join(errs, sink.Send(event))
+	}
+	return errs
 }
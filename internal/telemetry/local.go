@@ -0,0 +1,241 @@
+// Copyright 2024
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telemetry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	kcm "github.com/K0rdent/kcm/api/v1alpha1"
+)
+
+const (
+	// ConfigMapName is the name of the ConfigMap the configmap sink stores the events in.
+	ConfigMapName = "kcm-telemetry"
+	// ConfigMapEventsKey is the key of the ConfigMap data holding the events as JSON lines.
+	ConfigMapEventsKey = "events.jsonl"
+	// ConfigMapEventsLimit is the maximum number of the newest events kept in the ConfigMap.
+	ConfigMapEventsLimit = 1000
+
+	configMapTimeout       = 10 * time.Second
+	configMapFlushInterval = 10 * time.Second
+)
+
+type fileSink struct {
+	file *os.File
+	mu   sync.Mutex
+}
+
+// NewFileSink returns the sink appending the events as JSON lines to the file with the given path.
+func NewFileSink(path string) (Sink, error) {
+	if path == "" {
+		return nil, errors.New("the file path of the file telemetry sink is not set")
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create the directory of the telemetry file: %w", err)
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the telemetry file: %w", err)
+	}
+
+	return &fileSink{file: f}, nil
+}
+
+func (s *fileSink) Send(event Event) error {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal the event: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write the event to the telemetry file: %w", err)
+	}
+	return nil
+}
+
+func (s *fileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.file.Close()
+}
+
+type configMapSink struct {
+	client  client.Client
+	key     client.ObjectKey
+	pending []string
+	stop    chan struct{}
+	done    chan struct{}
+	mu      sync.Mutex // guards pending
+	flushMu sync.Mutex // serializes the flushes
+}
+
+// NewConfigMapSink returns the sink storing the newest events as JSON lines
+// in the kcm-telemetry ConfigMap in the given namespace. The events are buffered
+// and written to the ConfigMap in the background, so that Send does not reach the API server.
+func NewConfigMapSink(cl client.Client, namespace string) Sink {
+	s := &configMapSink{
+		client: cl,
+		key:    client.ObjectKey{Namespace: namespace, Name: ConfigMapName},
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	go s.run()
+	return s
+}
+
+func (s *configMapSink) Send(event Event) error {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal the event: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.pending = append(s.pending, string(line))
+	if len(s.pending) > ConfigMapEventsLimit {
+		s.pending = s.pending[len(s.pending)-ConfigMapEventsLimit:]
+	}
+	return nil
+}
+
+// Close stops the background flushes and writes the remaining events.
+func (s *configMapSink) Close() error {
+	close(s.stop)
+	<-s.done
+	return s.flush()
+}
+
+func (s *configMapSink) run() {
+	defer close(s.done)
+
+	l := log.Log.WithName("telemetry-configmap-sink")
+	ticker := time.NewTicker(configMapFlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			if err := s.flush(); err != nil {
+				l.Error(err, "failed to flush the telemetry events, retrying on the next flush")
+			}
+		}
+	}
+}
+
+// flush appends the buffered events to the ConfigMap, the events are kept buffered on failures.
+func (s *configMapSink) flush() error {
+	s.flushMu.Lock()
+	defer s.flushMu.Unlock()
+
+	s.mu.Lock()
+	lines := s.pending
+	s.pending = nil
+	s.mu.Unlock()
+
+	if len(lines) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), configMapTimeout)
+	defer cancel()
+
+	conflicting := func(err error) bool { return apierrors.IsConflict(err) || apierrors.IsAlreadyExists(err) }
+	err := retry.OnError(retry.DefaultRetry, conflicting, func() error {
+		cm := &corev1.ConfigMap{}
+		if err := s.client.Get(ctx, s.key, cm); err != nil {
+			if !apierrors.IsNotFound(err) {
+				return fmt.Errorf("failed to get the telemetry ConfigMap: %w", err)
+			}
+
+			cm = &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      s.key.Name,
+					Namespace: s.key.Namespace,
+					Labels:    configMapLabels(),
+				},
+				Data: map[string]string{ConfigMapEventsKey: appendEventLines("", lines, ConfigMapEventsLimit)},
+			}
+			err := s.client.Create(ctx, cm)
+			if apierrors.IsAlreadyExists(err) {
+				// the ConfigMap created before it was labeled as managed by KCM is not cached, label it and retry
+				labeled := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: s.key.Name, Namespace: s.key.Namespace, Labels: configMapLabels()}}
+				if patchErr := s.client.Patch(ctx, labeled, client.Merge); patchErr != nil {
+					return fmt.Errorf("failed to label the telemetry ConfigMap: %w", patchErr)
+				}
+			}
+			return err
+		}
+
+		if cm.Data == nil {
+			cm.Data = make(map[string]string)
+		}
+		cm.Data[ConfigMapEventsKey] = appendEventLines(cm.Data[ConfigMapEventsKey], lines, ConfigMapEventsLimit)
+		return s.client.Update(ctx, cm)
+	})
+	if err != nil {
+		s.mu.Lock()
+		s.pending = append(lines, s.pending...)
+		if len(s.pending) > ConfigMapEventsLimit {
+			s.pending = s.pending[len(s.pending)-ConfigMapEventsLimit:]
+		}
+		s.mu.Unlock()
+	}
+	return err
+}
+
+// configMapLabels returns the labels of the telemetry ConfigMap, only the ConfigMaps
+// labeled as managed by KCM are read through the cache.
+func configMapLabels() map[string]string {
+	return map[string]string{
+		kcm.GenericComponentNameLabel: kcm.GenericComponentLabelValueKCM,
+		kcm.KCMManagedLabelKey:        kcm.KCMManagedLabelValue,
+	}
+}
+
+// appendEventLines appends the new lines to the given JSON lines keeping at most limit of the newest lines.
+func appendEventLines(lines string, newLines []string, limit int) string {
+	all := append(strings.Split(strings.TrimSuffix(lines, "\n"), "\n"), newLines...)
+	if all[0] == "" {
+		all = all[1:]
+	}
+	if len(all) > limit {
+		all = all[len(all)-limit:]
+	}
+	return strings.Join(all, "\n") + "\n"
+}
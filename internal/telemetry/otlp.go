@@ -0,0 +1,103 @@
+// Copyright 2024
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploghttp"
+	"go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	"go.opentelemetry.io/otel/sdk/resource"
+
+	"github.com/K0rdent/kcm/internal/build"
+)
+
+const instrumentationName = "github.com/K0rdent/kcm/internal/telemetry"
+
+type otlpSink struct {
+	provider *sdklog.LoggerProvider
+	logger   log.Logger
+}
+
+// NewOTLPSink returns the sink exporting the events as OpenTelemetry
+// log records to the OTLP/HTTP endpoint with the given URL.
+func NewOTLPSink(ctx context.Context, endpoint string) (Sink, error) {
+	if endpoint == "" {
+		return nil, errors.New("the OTLP endpoint of the otlp telemetry sink is not set")
+	}
+
+	exporter, err := otlploghttp.New(ctx, otlploghttp.WithEndpointURL(endpoint))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the OTLP exporter: %w", err)
+	}
+
+	provider := sdklog.NewLoggerProvider(
+		sdklog.WithProcessor(sdklog.NewBatchProcessor(exporter)),
+		sdklog.WithResource(resource.NewSchemaless(
+			attribute.String("service.name", "kcm"),
+			attribute.String("service.version", build.Version),
+		)),
+	)
+
+	return &otlpSink{provider: provider, logger: provider.Logger(instrumentationName)}, nil
+}
+
+func (s *otlpSink) Send(event Event) error {
+	var record log.Record
+	record.SetTimestamp(event.Timestamp)
+	record.SetSeverity(log.SeverityInfo)
+	record.SetBody(log.StringValue(event.Name))
+	record.AddAttributes(
+		log.String("event.name", event.Name),
+		log.String("kcm.anonymous_id", event.AnonymousID),
+	)
+	for k, v := range event.Properties {
+		record.AddAttributes(log.KeyValue{Key: "kcm." + k, Value: logValue(v)})
+	}
+
+	s.logger.Emit(context.Background(), record)
+	return nil
+}
+
+func (s *otlpSink) Close() error {
+	return s.provider.Shutdown(context.Background())
+}
+
+func logValue(v any) log.Value {
+	switch v := v.(type) {
+	case string:
+		return log.StringValue(v)
+	case bool:
+		return log.BoolValue(v)
+	case int:
+		return log.IntValue(v)
+	case int64:
+		return log.Int64Value(v)
+	case float64:
+		return log.Float64Value(v)
+	case []string:
+		values := make([]log.Value, 0, len(v))
+		for _, s := range v {
+			values = append(values, log.StringValue(s))
+		}
+		return log.SliceValue(values...)
+	default:
+		return log.StringValue(fmt.Sprint(v))
+	}
+}
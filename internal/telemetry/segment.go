@@ -0,0 +1,44 @@
+// Copyright 2024
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telemetry
+
+import (
+	"github.com/segmentio/analytics-go"
+)
+
+// segmentToken is set at build time.
+var segmentToken = ""
+
+type segmentSink struct {
+	client analytics.Client
+}
+
+// NewSegmentSink returns the sink delivering the events to Segment with the given write key.
+func NewSegmentSink(token string) Sink {
+	return &segmentSink{client: analytics.New(token)}
+}
+
+func (s *segmentSink) Send(event Event) error {
+	return s.client.Enqueue(analytics.Track{
+		AnonymousId: event.AnonymousID,
+		Event:       event.Name,
+		Timestamp:   event.Timestamp,
+		Properties:  event.Properties,
+	})
+}
+
+func (s *segmentSink) Close() error {
+	return s.client.Close()
+}
@@ -0,0 +1,121 @@
+// Copyright 2024
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Names of the supported sinks.
+const (
+	SinkSegment   = "segment"
+	SinkOTLP      = "otlp"
+	SinkFile      = "file"
+	SinkConfigMap = "configmap"
+)
+
+// Event is a telemetry event.
+type Event struct {
+	// Timestamp is the time the event has been tracked at.
+	Timestamp time.Time `json:"timestamp"`
+	// Properties are the properties of the event.
+	Properties map[string]any `json:"properties,omitempty"`
+	// Name is the name of the event.
+	Name string `json:"event"`
+	// AnonymousID is the anonymous ID of the KCM installation the event has been tracked by.
+	AnonymousID string `json:"anonymousId"`
+}
+
+// Sink delivers the telemetry events to a backend.
+type Sink interface {
+	// Send delivers the event to the backend, it may be done asynchronously.
+	Send(event Event) error
+	// Close flushes the pending events and releases the resources of the sink.
+	Close() error
+}
+
+// SinksConfig configures the telemetry sinks.
+type SinksConfig struct {
+	// OTLPEndpoint is the URL of the OTLP/HTTP endpoint of the otlp sink.
+	OTLPEndpoint string
+	// FilePath is the path of the file the file sink appends the events to.
+	FilePath string
+	// SystemNamespace is the namespace of the ConfigMap of the configmap sink.
+	SystemNamespace string
+	// Sinks is the list of the names of the enabled sinks.
+	Sinks []string
+}
+
+var sinks []Sink
+
+// NewSinks creates the sinks enabled in the given config. The segment sink is skipped
+// if the build has no Segment token. The client is used by the configmap sink.
+func NewSinks(ctx context.Context, cfg SinksConfig, cl client.Client) ([]Sink, error) {
+	var (
+		result []Sink
+		err    error
+	)
+	for _, name := range cfg.Sinks {
+		var sink Sink
+		switch strings.TrimSpace(name) {
+		case SinkSegment:
+			if segmentToken == "" {
+				continue
+			}
+			sink = NewSegmentSink(segmentToken)
+		case SinkOTLP:
+			sink, err = NewOTLPSink(ctx, cfg.OTLPEndpoint)
+		case SinkFile:
+			sink, err = NewFileSink(cfg.FilePath)
+		case SinkConfigMap:
+			sink = NewConfigMapSink(cl, cfg.SystemNamespace)
+		case "":
+			continue
+		default:
+			err = fmt.Errorf("unknown telemetry sink %q", name)
+		}
+		if err != nil {
+			return nil, errors.Join(err, closeSinks(result))
+		}
+		result = append(result, sink)
+	}
+
+	return result, nil
+}
+
+// SetSinks sets the sinks the events are delivered to.
+// It is expected to be called once before the events are tracked.
+func SetSinks(s ...Sink) {
+	sinks = s
+}
+
+// Close closes all of the sinks set with SetSinks.
+func Close() error {
+	return closeSinks(sinks)
+}
+
+func closeSinks(s []Sink) error {
+	var errs error
+	for _, sink := range s {
+		errs = errors.Join(errs, sink.Close())
+	}
+	return errs
+}
@@ -0,0 +1,117 @@
+// Copyright 2024
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telemetry
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestNewSinks(t *testing.T) {
+	g := NewWithT(t)
+
+	path := filepath.Join(t.TempDir(), "kcm", "telemetry.jsonl")
+	result, err := NewSinks(context.Background(), SinksConfig{
+		Sinks:    []string{SinkSegment, SinkFile, " " + SinkConfigMap, ""},
+		FilePath: path,
+	}, fake.NewClientBuilder().Build())
+	g.Expect(err).NotTo(HaveOccurred())
+	// the segment sink is skipped without the token
+	g.Expect(result).To(HaveLen(2))
+	g.Expect(closeSinks(result)).To(Succeed())
+
+	_, err = NewSinks(context.Background(), SinksConfig{Sinks: []string{"unknown"}}, nil)
+	g.Expect(err).To(MatchError(ContainSubstring(`unknown telemetry sink "unknown"`)))
+
+	_, err = NewSinks(context.Background(), SinksConfig{Sinks: []string{SinkOTLP}}, nil)
+	g.Expect(err).To(HaveOccurred())
+}
+
+func TestTrackEvent(t *testing.T) {
+	g := NewWithT(t)
+
+	path := filepath.Join(t.TempDir(), "telemetry.jsonl")
+	fileSink, err := NewFileSink(path)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	cl := fake.NewClientBuilder().Build()
+	SetSinks(fileSink, NewConfigMapSink(cl, "kcm-system"))
+	t.Cleanup(func() { SetSinks() })
+
+	g.Expect(TrackClusterDeploymentDelete("mgmt-uid", "cd-uid", "aws-standalone-cp-0-1-0")).To(Succeed())
+	g.Expect(TrackMultiClusterServiceHeartbeat("mgmt-uid", "mcs-uid", 2, 3)).To(Succeed())
+	g.Expect(Close()).To(Succeed())
+
+	data, err := os.ReadFile(path)
+	g.Expect(err).NotTo(HaveOccurred())
+	events := decodeEvents(g, string(data))
+	g.Expect(events).To(HaveLen(2))
+	g.Expect(events[0].Name).To(Equal(clusterDeploymentDeleteEvent))
+	g.Expect(events[0].AnonymousID).To(Equal("mgmt-uid"))
+	g.Expect(events[0].Properties).To(HaveKeyWithValue("template", "aws-standalone-cp-0-1-0"))
+	g.Expect(events[1].Name).To(Equal(multiClusterServiceHeartbeatEvent))
+
+	cm := &corev1.ConfigMap{}
+	g.Expect(cl.Get(context.Background(), client.ObjectKey{Namespace: "kcm-system", Name: ConfigMapName}, cm)).To(Succeed())
+	g.Expect(decodeEvents(g, cm.Data[ConfigMapEventsKey])).To(Equal(events))
+}
+
+func TestConfigMapSinkBuffersEvents(t *testing.T) {
+	g := NewWithT(t)
+
+	cl := fake.NewClientBuilder().Build()
+	sink := NewConfigMapSink(cl, "kcm-system")
+	g.Expect(sink.Send(Event{Name: clusterDeploymentCreateEvent})).To(Succeed())
+
+	key := client.ObjectKey{Namespace: "kcm-system", Name: ConfigMapName}
+	g.Expect(apierrors.IsNotFound(cl.Get(context.Background(), key, &corev1.ConfigMap{}))).To(BeTrue(),
+		"the events should not be written on Send")
+
+	g.Expect(sink.Close()).To(Succeed())
+	cm := &corev1.ConfigMap{}
+	g.Expect(cl.Get(context.Background(), key, cm)).To(Succeed())
+	g.Expect(decodeEvents(g, cm.Data[ConfigMapEventsKey])).To(ConsistOf(HaveField("Name", clusterDeploymentCreateEvent)))
+}
+
+func TestAppendEventLines(t *testing.T) {
+	g := NewWithT(t)
+
+	lines := ""
+	for _, line := range []string{"1", "2", "3"} {
+		lines = appendEventLines(lines, []string{line}, 3)
+	}
+	lines = appendEventLines(lines, []string{"4", "5"}, 3)
+	g.Expect(lines).To(Equal("3\n4\n5\n"))
+}
+
+func decodeEvents(g Gomega, data string) []Event {
+	var events []Event
+	for _, line := range strings.Split(strings.TrimSpace(data), "\n") {
+		var event Event
+		g.Expect(json.Unmarshal([]byte(line), &event)).To(Succeed())
+		events = append(events, event)
+	}
+	return events
+}
@@ -18,8 +18,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
@@ -50,12 +52,20 @@ func (t *Tracker) Start(ctx context.Context) error {
 func (t *Tracker) Tick(ctx context.Context) {
 	l := log.FromContext(ctx).WithName("telemetry tracker")
 
-	logger := l.WithValues("event", clusterDeploymentHeartbeatEvent)
-	err := t.trackClusterDeploymentHeartbeat(ctx)
-	if err != nil {
-		logger.Error(err, "failed to track an event")
-	} else {
-		logger.Info("successfully tracked an event")
+	for _, heartbeat := range []struct {
+		track func(context.Context) error
+		event string
+	}{
+		{t.trackClusterDeploymentHeartbeat, clusterDeploymentHeartbeatEvent},
+		{t.trackMultiClusterServiceHeartbeat, multiClusterServiceHeartbeatEvent},
+		{t.trackTemplateUsageHeartbeat, templateUsageHeartbeatEvent},
+	} {
+		logger := l.WithValues("event", heartbeat.event)
+		if err := heartbeat.track(ctx); err != nil {
+			logger.Error(err, "failed to track an event")
+		} else {
+			logger.Info("successfully tracked an event")
+		}
 	}
 }
 
@@ -66,13 +76,13 @@ func (t *Tracker) trackClusterDeploymentHeartbeat(ctx context.Context) error {
 	}
 
 	templatesList := &v1alpha1.ClusterTemplateList{}
-	if err := t.List(ctx, templatesList, client.InNamespace(t.SystemNamespace)); err != nil {
+	if err := t.List(ctx, templatesList); err != nil {
 		return err
 	}
 
-	templates := make(map[string]v1alpha1.ClusterTemplate)
+	templates := make(map[client.ObjectKey]v1alpha1.ClusterTemplate)
 	for _, template := range templatesList.Items {
-		templates[template.Name] = template
+		templates[client.ObjectKeyFromObject(&template)] = template
 	}
 
 	var errs error
@@ -82,7 +92,7 @@ func (t *Tracker) trackClusterDeploymentHeartbeat(ctx context.Context) error {
 	}
 
 	for _, clusterDeployment := range clusterDeployments.Items {
-		template := templates[clusterDeployment.Spec.Template]
+		template := templates[client.ObjectKey{Namespace: clusterDeployment.Namespace, Name: clusterDeployment.Spec.Template}]
 		// TODO: get k0s cluster ID once it's exposed in k0smotron API
 		clusterID := ""
 
@@ -93,6 +103,8 @@ func (t *Tracker) trackClusterDeploymentHeartbeat(ctx context.Context) error {
 			clusterDeployment.Spec.Template,
 			template.Status.ChartVersion,
 			template.Status.Providers,
+			len(clusterDeployment.Spec.ServiceSpec.Services),
+			readyServices(clusterDeployment.Status.Services),
 		)
 		if err != nil {
 			errs = errors.Join(errs, fmt.Errorf("failed to track the heartbeat of the clusterDeployment %s/%s", clusterDeployment.Namespace, clusterDeployment.Name))
@@ -101,3 +113,102 @@ func (t *Tracker) trackClusterDeploymentHeartbeat(ctx context.Context) error {
 	}
 	return errs
 }
+
+func (t *Tracker) trackMultiClusterServiceHeartbeat(ctx context.Context) error {
+	mgmt := &v1alpha1.Management{}
+	if err := t.Get(ctx, client.ObjectKey{Name: v1alpha1.ManagementName}, mgmt); err != nil {
+		return err
+	}
+
+	multiClusterServices := &v1alpha1.MultiClusterServiceList{}
+	if err := t.List(ctx, multiClusterServices); err != nil {
+		return err
+	}
+
+	var errs error
+	for _, mcs := range multiClusterServices.Items {
+		if err := TrackMultiClusterServiceHeartbeat(
+			string(mgmt.UID),
+			string(mcs.UID),
+			len(mcs.Spec.ServiceSpec.Services),
+			len(mcs.Status.Services),
+		); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("failed to track the heartbeat of the MultiClusterService %s", mcs.Name))
+		}
+	}
+	return errs
+}
+
+// trackTemplateUsageHeartbeat tracks the number of the ClusterDeployments and MultiClusterServices
+// using each of the ClusterTemplates and ServiceTemplates, the unused templates are not tracked.
+func (t *Tracker) trackTemplateUsageHeartbeat(ctx context.Context) error {
+	mgmt := &v1alpha1.Management{}
+	if err := t.Get(ctx, client.ObjectKey{Name: v1alpha1.ManagementName}, mgmt); err != nil {
+		return err
+	}
+
+	type templateRef struct {
+		kind string
+		key  client.ObjectKey
+	}
+	usages := make(map[templateRef]int)
+	useServices := func(namespace string, services []v1alpha1.Service) {
+		for _, svc := range services {
+			usages[templateRef{kind: v1alpha1.ServiceTemplateKind, key: client.ObjectKey{Namespace: namespace, Name: svc.Template}}]++
+		}
+	}
+
+	clusterDeployments := &v1alpha1.ClusterDeploymentList{}
+	if err := t.List(ctx, clusterDeployments); err != nil {
+		return err
+	}
+	for _, cd := range clusterDeployments.Items {
+		usages[templateRef{kind: v1alpha1.ClusterTemplateKind, key: client.ObjectKey{Namespace: cd.Namespace, Name: cd.Spec.Template}}]++
+		useServices(cd.Namespace, cd.Spec.ServiceSpec.Services)
+	}
+
+	multiClusterServices := &v1alpha1.MultiClusterServiceList{}
+	if err := t.List(ctx, multiClusterServices); err != nil {
+		return err
+	}
+	for _, mcs := range multiClusterServices.Items {
+		useServices(t.SystemNamespace, mcs.Spec.ServiceSpec.Services)
+	}
+
+	chartVersions := make(map[templateRef]string)
+	clusterTemplates := &v1alpha1.ClusterTemplateList{}
+	if err := t.List(ctx, clusterTemplates); err != nil {
+		return err
+	}
+	for _, template := range clusterTemplates.Items {
+		chartVersions[templateRef{kind: v1alpha1.ClusterTemplateKind, key: client.ObjectKeyFromObject(&template)}] = template.Status.ChartVersion
+	}
+	serviceTemplates := &v1alpha1.ServiceTemplateList{}
+	if err := t.List(ctx, serviceTemplates); err != nil {
+		return err
+	}
+	for _, template := range serviceTemplates.Items {
+		chartVersions[templateRef{kind: v1alpha1.ServiceTemplateKind, key: client.ObjectKeyFromObject(&template)}] = template.Status.ChartVersion
+	}
+
+	var errs error
+	for ref, count := range usages {
+		if err := TrackTemplateUsageHeartbeat(string(mgmt.UID), ref.kind, ref.key.Name, chartVersions[ref], count); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("failed to track the usage of the %s %s", ref.kind, ref.key))
+		}
+	}
+	return errs
+}
+
+// readyServices returns the number of the ready services among the given services statuses.
+func readyServices(statuses []v1alpha1.ServiceStatus) int {
+	ready := 0
+	for _, status := range statuses {
+		for _, c := range status.Conditions {
+			if strings.HasSuffix(c.Type, "/"+v1alpha1.SveltosHelmReleaseReadyCondition) && c.Status == metav1.ConditionTrue {
+				ready++
+			}
+		}
+	}
+	return ready
+}
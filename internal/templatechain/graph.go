@@ -0,0 +1,202 @@
+// Copyright 2024
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package templatechain
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+
+	kcm "github.com/K0rdent/kcm/api/v1alpha1"
+)
+
+// TemplateInfo holds the attributes of a Template from the chain namespace
+// the upgrade graph is validated against.
+type TemplateInfo struct {
+	ProviderContracts kcm.CompatibilityContracts
+	Name              string
+	KubernetesVersion string
+	ValidationError   string
+	Providers         kcm.Providers
+	Valid             bool
+}
+
+// Result is the computed and validated upgrade graph of a chain.
+type Result struct {
+	Graph                []kcm.UpgradeGraphNode
+	UnreachableTemplates []string
+	Warnings             []string
+}
+
+// Valid reports whether the upgrade graph passed validation.
+func (r *Result) Valid() bool {
+	return len(r.Warnings) == 0
+}
+
+// Compute computes the upgrade graph from the explicit and the resolved upgrades of the chain
+// and validates it against the given Templates from the chain namespace and the given management status.
+// The management status is used to verify the provider contracts of the upgrade targets,
+// the verification is skipped if the management status is nil.
+func Compute(spec *kcm.TemplateChainSpec, status *kcm.TemplateChainStatus, templates map[string]TemplateInfo, management *kcm.ManagementStatus) Result {
+	var res Result
+
+	names := make([]string, 0, len(spec.SupportedTemplates))
+	for _, supportedTemplate := range spec.SupportedTemplates {
+		if !slices.Contains(names, supportedTemplate.Name) {
+			names = append(names, supportedTemplate.Name)
+		}
+	}
+	slices.Sort(names)
+
+	upgrades := make(map[string][]string, len(names))
+	incoming := make(map[string]bool, len(names))
+	for _, name := range names {
+		var targets []string
+		for _, upgrade := range kcm.AvailableUpgradesFor(spec, status, name) {
+			targets = append(targets, upgrade.Name)
+			incoming[upgrade.Name] = true
+		}
+		slices.Sort(targets)
+		upgrades[name] = slices.Compact(targets)
+
+		res.Graph = append(res.Graph, kcm.UpgradeGraphNode{
+			Name:              name,
+			KubernetesVersion: templates[name].KubernetesVersion,
+			Upgrades:          upgrades[name],
+		})
+	}
+
+	if len(incoming) > 0 {
+		for _, name := range names {
+			if len(upgrades[name]) == 0 && !incoming[name] {
+				res.UnreachableTemplates = append(res.UnreachableTemplates, name)
+			}
+		}
+	}
+
+	for _, cycle := range findCycles(names, upgrades) {
+		res.Warnings = append(res.Warnings, "upgrade cycle detected: "+strings.Join(cycle, " -> "))
+	}
+
+	for _, name := range names {
+		for _, target := range upgrades[name] {
+			res.Warnings = append(res.Warnings, validateUpgrade(templates, management, name, target)...)
+		}
+	}
+
+	return res
+}
+
+// validateUpgrade returns the problems with the upgrade from the source to the target Template.
+func validateUpgrade(templates map[string]TemplateInfo, management *kcm.ManagementStatus, source, target string) []string {
+	targetTemplate, ok := templates[target]
+	if !ok {
+		return []string{fmt.Sprintf("upgrade target %s of %s does not exist", target, source)}
+	}
+	if !targetTemplate.Valid {
+		msg := fmt.Sprintf("upgrade target %s of %s is not valid", target, source)
+		if targetTemplate.ValidationError != "" {
+			msg += ": " + targetTemplate.ValidationError
+		}
+		return []string{msg}
+	}
+
+	var warnings []string
+	if sourceTemplate, ok := templates[source]; ok && sourceTemplate.KubernetesVersion != "" && targetTemplate.KubernetesVersion != "" {
+		sourceVersion, sourceErr := semver.NewVersion(sourceTemplate.KubernetesVersion)
+		targetVersion, targetErr := semver.NewVersion(targetTemplate.KubernetesVersion)
+		if sourceErr == nil && targetErr == nil && targetVersion.LessThan(sourceVersion) {
+			warnings = append(warnings, fmt.Sprintf("upgrade from %s to %s decreases the Kubernetes version from %s to %s",
+				source, target, sourceTemplate.KubernetesVersion, targetTemplate.KubernetesVersion))
+		}
+	}
+
+	if management == nil {
+		return warnings
+	}
+
+	for _, provider := range targetTemplate.Providers {
+		if !slices.Contains(management.AvailableProviders, provider) {
+			warnings = append(warnings, fmt.Sprintf("upgrade target %s of %s requires the %s provider which is not available", target, source, provider))
+		}
+	}
+
+	providerNames := make([]string, 0, len(targetTemplate.ProviderContracts))
+	for providerName := range targetTemplate.ProviderContracts {
+		providerNames = append(providerNames, providerName)
+	}
+	slices.Sort(providerNames)
+	for _, providerName := range providerNames {
+		requiredContract := targetTemplate.ProviderContracts[providerName]
+		providerCAPIContracts, ok := management.CAPIContracts[providerName]
+		if !ok {
+			continue // both the provider and cluster templates contract versions must be set for the validation
+		}
+
+		var exposedProviderContracts []string
+		for _, supportedVersions := range providerCAPIContracts {
+			exposedProviderContracts = append(exposedProviderContracts, strings.Split(supportedVersions, "_")...)
+		}
+		if !slices.Contains(exposedProviderContracts, requiredContract) {
+			warnings = append(warnings, fmt.Sprintf("upgrade target %s of %s requires the %s contract of the %s provider which is not supported",
+				target, source, requiredContract, providerName))
+		}
+	}
+
+	return warnings
+}
+
+// findCycles returns the cycles found by the depth-first traversal of the graph
+// defined by the given adjacency lists.
+func findCycles(names []string, upgrades map[string][]string) [][]string {
+	const (
+		unvisited = iota
+		inProgress
+		done
+	)
+
+	var (
+		cycles [][]string
+		state  = make(map[string]int, len(names))
+		path   []string
+		visit  func(name string)
+	)
+	visit = func(name string) {
+		state[name] = inProgress
+		path = append(path, name)
+		for _, target := range upgrades[name] {
+			switch state[target] {
+			case unvisited:
+				visit(target)
+			case inProgress:
+				start := slices.Index(path, target)
+				cycle := append(slices.Clone(path[start:]), target)
+				cycles = append(cycles, cycle)
+			}
+		}
+		path = path[:len(path)-1]
+		state[name] = done
+	}
+
+	for _, name := range names {
+		if state[name] == unvisited {
+			visit(name)
+		}
+	}
+
+	return cycles
+}
@@ -0,0 +1,169 @@
+// Copyright 2024
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package templatechain
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	kcmv1 "github.com/K0rdent/kcm/api/v1alpha1"
+)
+
+func TestCompute(t *testing.T) {
+	validTemplate := func(name, k8sVersion string) TemplateInfo {
+		return TemplateInfo{Name: name, KubernetesVersion: k8sVersion, Valid: true}
+	}
+
+	upgrades := func(names ...string) []kcmv1.AvailableUpgrade {
+		res := make([]kcmv1.AvailableUpgrade, 0, len(names))
+		for _, name := range names {
+			res = append(res, kcmv1.AvailableUpgrade{Name: name})
+		}
+		return res
+	}
+
+	tests := []struct {
+		name                string
+		spec                kcmv1.TemplateChainSpec
+		status              kcmv1.TemplateChainStatus
+		templates           map[string]TemplateInfo
+		management          *kcmv1.ManagementStatus
+		expectedGraph       []kcmv1.UpgradeGraphNode
+		expectedUnreachable []string
+		expectedWarnings    []string
+		expectedValid       bool
+	}{
+		{
+			name: "valid linear chain with the resolved upgrades",
+			spec: kcmv1.TemplateChainSpec{SupportedTemplates: []kcmv1.SupportedTemplate{
+				{Name: "a-1-0-0", AvailableUpgrades: upgrades("a-1-1-0")},
+				{Name: "a-1-1-0"},
+				{Name: "a-1-2-0"},
+			}},
+			status: kcmv1.TemplateChainStatus{ResolvedUpgrades: []kcmv1.ResolvedUpgrade{
+				{Name: "a-1-1-0", AvailableUpgrades: upgrades("a-1-2-0")},
+			}},
+			templates: map[string]TemplateInfo{
+				"a-1-0-0": validTemplate("a-1-0-0", "v1.30.0"),
+				"a-1-1-0": validTemplate("a-1-1-0", "v1.31.0"),
+				"a-1-2-0": validTemplate("a-1-2-0", "v1.31.2"),
+			},
+			expectedGraph: []kcmv1.UpgradeGraphNode{
+				{Name: "a-1-0-0", KubernetesVersion: "v1.30.0", Upgrades: []string{"a-1-1-0"}},
+				{Name: "a-1-1-0", KubernetesVersion: "v1.31.0", Upgrades: []string{"a-1-2-0"}},
+				{Name: "a-1-2-0", KubernetesVersion: "v1.31.2"},
+			},
+			expectedValid: true,
+		},
+		{
+			name: "chain without upgrades has no unreachable templates",
+			spec: kcmv1.TemplateChainSpec{SupportedTemplates: []kcmv1.SupportedTemplate{{Name: "a"}, {Name: "b"}}},
+			templates: map[string]TemplateInfo{
+				"a": validTemplate("a", ""),
+				"b": validTemplate("b", ""),
+			},
+			expectedGraph: []kcmv1.UpgradeGraphNode{{Name: "a"}, {Name: "b"}},
+			expectedValid: true,
+		},
+		{
+			name: "cycle, unreachable and missing templates",
+			spec: kcmv1.TemplateChainSpec{SupportedTemplates: []kcmv1.SupportedTemplate{
+				{Name: "a", AvailableUpgrades: upgrades("b")},
+				{Name: "b", AvailableUpgrades: upgrades("a", "missing")},
+				{Name: "c"},
+			}},
+			templates: map[string]TemplateInfo{
+				"a": validTemplate("a", ""),
+				"b": validTemplate("b", ""),
+				"c": validTemplate("c", ""),
+			},
+			expectedGraph: []kcmv1.UpgradeGraphNode{
+				{Name: "a", Upgrades: []string{"b"}},
+				{Name: "b", Upgrades: []string{"a", "missing"}},
+				{Name: "c"},
+			},
+			expectedUnreachable: []string{"c"},
+			expectedWarnings: []string{
+				"upgrade cycle detected: a -> b -> a",
+				"upgrade target missing of b does not exist",
+			},
+		},
+		{
+			name: "invalid target and kubernetes version decrease",
+			spec: kcmv1.TemplateChainSpec{SupportedTemplates: []kcmv1.SupportedTemplate{
+				{Name: "a", AvailableUpgrades: upgrades("b", "c")},
+				{Name: "b"},
+				{Name: "c"},
+			}},
+			templates: map[string]TemplateInfo{
+				"a": validTemplate("a", "v1.31.0"),
+				"b": {Name: "b", ValidationError: "chart is not found"},
+				"c": validTemplate("c", "v1.30.5"),
+			},
+			expectedGraph: []kcmv1.UpgradeGraphNode{
+				{Name: "a", KubernetesVersion: "v1.31.0", Upgrades: []string{"b", "c"}},
+				{Name: "b"},
+				{Name: "c", KubernetesVersion: "v1.30.5"},
+			},
+			expectedWarnings: []string{
+				"upgrade target b of a is not valid: chart is not found",
+				"upgrade from a to c decreases the Kubernetes version from v1.31.0 to v1.30.5",
+			},
+		},
+		{
+			name: "unsatisfied providers and contracts",
+			spec: kcmv1.TemplateChainSpec{SupportedTemplates: []kcmv1.SupportedTemplate{
+				{Name: "a", AvailableUpgrades: upgrades("b")},
+				{Name: "b"},
+			}},
+			templates: map[string]TemplateInfo{
+				"a": validTemplate("a", ""),
+				"b": {
+					Name:              "b",
+					Valid:             true,
+					Providers:         kcmv1.Providers{"infrastructure-aws", "infrastructure-azure"},
+					ProviderContracts: kcmv1.CompatibilityContracts{"infrastructure-aws": "v1beta2"},
+				},
+			},
+			management: &kcmv1.ManagementStatus{
+				AvailableProviders: kcmv1.Providers{"infrastructure-aws"},
+				CAPIContracts: map[string]kcmv1.CompatibilityContracts{
+					"infrastructure-aws": {"v1beta1": "v1alpha3_v1beta1"},
+				},
+			},
+			expectedGraph: []kcmv1.UpgradeGraphNode{
+				{Name: "a", Upgrades: []string{"b"}},
+				{Name: "b"},
+			},
+			expectedWarnings: []string{
+				"upgrade target b of a requires the infrastructure-azure provider which is not available",
+				"upgrade target b of a requires the v1beta2 contract of the infrastructure-aws provider which is not supported",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			res := Compute(&tt.spec, &tt.status, tt.templates, tt.management)
+			g.Expect(res.Graph).To(Equal(tt.expectedGraph))
+			g.Expect(res.UnreachableTemplates).To(Equal(tt.expectedUnreachable))
+			g.Expect(res.Warnings).To(Equal(tt.expectedWarnings))
+			g.Expect(res.Valid()).To(Equal(tt.expectedValid))
+		})
+	}
+}
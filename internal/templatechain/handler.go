@@ -0,0 +1,146 @@
+// Copyright 2024
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package templatechain
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	kcm "github.com/K0rdent/kcm/api/v1alpha1"
+)
+
+// GraphHandlerPath is the path the GraphHandler is served on.
+const GraphHandlerPath = "/templatechains/"
+
+// GraphView is the JSON representation of the upgrade graph of a chain.
+type GraphView struct {
+	Kind                 string                 `json:"kind"`
+	Namespace            string                 `json:"namespace"`
+	Name                 string                 `json:"name"`
+	Graph                []kcm.UpgradeGraphNode `json:"graph"`
+	UnreachableTemplates []string               `json:"unreachableTemplates,omitempty"`
+	Warnings             []string               `json:"warnings,omitempty"`
+	Valid                bool                   `json:"valid"`
+}
+
+// GraphHandler renders the upgrade graphs of the chains as DOT or JSON.
+// The chain is addressed as /templatechains/{clustertemplatechains|servicetemplatechains}/{namespace}/{name},
+// the format is set with the "format" query parameter, either "json" (default) or "dot".
+type GraphHandler struct {
+	Client client.Client
+}
+
+func (h *GraphHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, GraphHandlerPath), "/"), "/")
+	if len(parts) != 3 {
+		http.Error(w, "expected path "+GraphHandlerPath+"{clustertemplatechains|servicetemplatechains}/{namespace}/{name}", http.StatusBadRequest)
+		return
+	}
+
+	var chain interface {
+		client.Object
+		GetStatus() *kcm.TemplateChainStatus
+	}
+	var kind string
+	switch parts[0] {
+	case "clustertemplatechains":
+		chain, kind = new(kcm.ClusterTemplateChain), kcm.ClusterTemplateChainKind
+	case "servicetemplatechains":
+		chain, kind = new(kcm.ServiceTemplateChain), kcm.ServiceTemplateChainKind
+	default:
+		http.Error(w, "unsupported resource "+parts[0], http.StatusBadRequest)
+		return
+	}
+
+	if err := h.Client.Get(r.Context(), client.ObjectKey{Namespace: parts[1], Name: parts[2]}, chain); err != nil {
+		if apierrors.IsNotFound(err) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	status := chain.GetStatus()
+	view := GraphView{
+		Kind:                 kind,
+		Namespace:            chain.GetNamespace(),
+		Name:                 chain.GetName(),
+		Graph:                status.Graph,
+		UnreachableTemplates: status.UnreachableTemplates,
+		Warnings:             status.Warnings,
+		Valid:                status.Valid,
+	}
+
+	var (
+		body        []byte
+		contentType string
+		err         error
+	)
+	switch format := r.URL.Query().Get("format"); format {
+	case "", "json":
+		body, err = json.Marshal(view)
+		contentType = "application/json"
+	case "dot":
+		body, contentType = []byte(RenderDOT(view)), "text/vnd.graphviz"
+	default:
+		http.Error(w, "unsupported format "+format, http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	if _, err := w.Write(body); err != nil {
+		log.FromContext(r.Context()).Error(err, "failed to write the template chain graph")
+	}
+}
+
+// RenderDOT renders the given upgrade graph in the DOT language.
+// The unreachable Templates are drawn dashed.
+func RenderDOT(view GraphView) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "digraph %q {\n", view.Namespace+"/"+view.Name)
+	b.WriteString("  rankdir=LR;\n")
+	for _, node := range view.Graph {
+		label := node.Name
+		if node.KubernetesVersion != "" {
+			label += `\nk8s ` + node.KubernetesVersion
+		}
+
+		attrs := fmt.Sprintf("label=%q", label)
+		for _, unreachable := range view.UnreachableTemplates {
+			if unreachable == node.Name {
+				attrs += ", style=dashed"
+			}
+		}
+		fmt.Fprintf(&b, "  %q [%s];\n", node.Name, attrs)
+	}
+	for _, node := range view.Graph {
+		for _, upgrade := range node.Upgrades {
+			fmt.Fprintf(&b, "  %q -> %q;\n", node.Name, upgrade)
+		}
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
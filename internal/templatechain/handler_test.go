@@ -0,0 +1,115 @@
+// Copyright 2024
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package templatechain
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kcmv1 "github.com/K0rdent/kcm/api/v1alpha1"
+	"github.com/K0rdent/kcm/test/scheme"
+)
+
+func TestGraphHandler(t *testing.T) {
+	chain := &kcmv1.ClusterTemplateChain{
+		ObjectMeta: metav1.ObjectMeta{Name: "aws", Namespace: "kcm-system"},
+		Status: kcmv1.TemplateChainStatus{
+			Graph: []kcmv1.UpgradeGraphNode{
+				{Name: "aws-1-0-0", KubernetesVersion: "v1.30.0", Upgrades: []string{"aws-1-1-0"}},
+				{Name: "aws-1-1-0", KubernetesVersion: "v1.31.0"},
+				{Name: "aws-legacy"},
+			},
+			UnreachableTemplates: []string{"aws-legacy"},
+			Valid:                true,
+		},
+	}
+
+	h := &GraphHandler{Client: fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(chain).Build()}
+
+	tests := []struct {
+		name         string
+		path         string
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:         "dot",
+			path:         "/templatechains/clustertemplatechains/kcm-system/aws?format=dot",
+			expectedCode: http.StatusOK,
+			expectedBody: `digraph "kcm-system/aws" {
+  rankdir=LR;
+  "aws-1-0-0" [label="aws-1-0-0\\nk8s v1.30.0"];
+  "aws-1-1-0" [label="aws-1-1-0\\nk8s v1.31.0"];
+  "aws-legacy" [label="aws-legacy", style=dashed];
+  "aws-1-0-0" -> "aws-1-1-0";
+}
+`,
+		},
+		{
+			name:         "not found",
+			path:         "/templatechains/servicetemplatechains/kcm-system/aws",
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "unsupported resource",
+			path:         "/templatechains/clustertemplates/kcm-system/aws",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "unsupported format",
+			path:         "/templatechains/clustertemplatechains/kcm-system/aws?format=svg",
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			g.Expect(rec.Code).To(Equal(tt.expectedCode))
+			if tt.expectedBody != "" {
+				g.Expect(rec.Body.String()).To(Equal(tt.expectedBody))
+			}
+		})
+	}
+
+	t.Run("json", func(t *testing.T) {
+		g := NewWithT(t)
+
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/templatechains/clustertemplatechains/kcm-system/aws", nil))
+		g.Expect(rec.Code).To(Equal(http.StatusOK))
+		g.Expect(rec.Header().Get("Content-Type")).To(Equal("application/json"))
+
+		view := GraphView{}
+		g.Expect(json.Unmarshal(rec.Body.Bytes(), &view)).To(Succeed())
+		g.Expect(view).To(Equal(GraphView{
+			Kind:                 kcmv1.ClusterTemplateChainKind,
+			Namespace:            "kcm-system",
+			Name:                 "aws",
+			Graph:                chain.Status.Graph,
+			UnreachableTemplates: chain.Status.UnreachableTemplates,
+			Valid:                true,
+		}))
+	})
+}
@@ -0,0 +1,91 @@
+// Copyright 2024
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package tracing instruments the controllers, the webhooks and the Helm operations
+// with the OpenTelemetry spans exported to an OTLP endpoint.
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/K0rdent/kcm/internal/build"
+)
+
+const instrumentationName = "github.com/K0rdent/kcm"
+
+// Config configures the export of the spans.
+type Config struct {
+	// Endpoint is the URL of the OTLP/HTTP endpoint the spans are exported to.
+	// The tracing is disabled if it is empty.
+	Endpoint string
+	// SamplingRatio is the ratio of the sampled traces in the range [0, 1].
+	SamplingRatio float64
+}
+
+// Setup installs the global tracer provider exporting the spans to the endpoint
+// set in the config. The returned function flushes the pending spans and must be
+// called before the process exits. If the endpoint is not set the spans are not recorded.
+func Setup(ctx context.Context, cfg Config) (shutdown func(context.Context) error, _ error) {
+	if cfg.Endpoint == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporter, err := otlptracehttp.New(ctx, otlptracehttp.WithEndpointURL(cfg.Endpoint))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the OTLP trace exporter: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SamplingRatio))),
+		sdktrace.WithResource(resource.NewSchemaless(
+			attribute.String("service.name", "kcm"),
+			attribute.String("service.version", build.Version),
+		)),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	return provider.Shutdown, nil
+}
+
+// Start starts the span with the given name and attributes as a child of the span in the context if any.
+// The trace and span IDs of a recorded span are added to the logger in the returned context.
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	ctx, span := otel.Tracer(instrumentationName).Start(ctx, name, trace.WithAttributes(attrs...))
+	if sc := span.SpanContext(); sc.IsValid() {
+		ctx = log.IntoContext(ctx, log.FromContext(ctx).WithValues("traceID", sc.TraceID().String(), "spanID", sc.SpanID().String()))
+	}
+	return ctx, span
+}
+
+// End records the error if any and ends the span.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,88 @@
+// Copyright 2024
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-logr/logr/funcr"
+	. "github.com/onsi/gomega"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func setupRecorder(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	return recorder
+}
+
+func TestStart(t *testing.T) {
+	g := NewWithT(t)
+	recorder := setupRecorder(t)
+
+	var logged string
+	ctx := log.IntoContext(context.Background(), funcr.New(func(_, args string) { logged = args }, funcr.Options{}))
+
+	ctx, parent := Start(ctx, "parent")
+	_, child := Start(ctx, "child")
+	End(child, errors.New("failed"))
+	End(parent, nil)
+
+	log.FromContext(ctx).Info("message")
+	g.Expect(logged).To(ContainSubstring(`"traceID"="` + parent.SpanContext().TraceID().String() + `"`))
+	g.Expect(logged).To(ContainSubstring(`"spanID"="` + parent.SpanContext().SpanID().String() + `"`))
+
+	spans := recorder.Ended()
+	g.Expect(spans).To(HaveLen(2))
+	g.Expect(spans[0].Name()).To(Equal("child"))
+	g.Expect(spans[0].Parent().SpanID()).To(Equal(parent.SpanContext().SpanID()))
+	g.Expect(spans[0].Status().Code).To(Equal(codes.Error))
+	g.Expect(spans[0].Events()).To(HaveLen(1))
+	g.Expect(spans[1].Name()).To(Equal("parent"))
+	g.Expect(spans[1].Status().Code).To(Equal(codes.Unset))
+}
+
+func TestReconciler(t *testing.T) {
+	g := NewWithT(t)
+	recorder := setupRecorder(t)
+
+	r := Reconciler("ClusterDeployment", reconcile.Func(func(context.Context, reconcile.Request) (reconcile.Result, error) {
+		return reconcile.Result{}, errors.New("failed")
+	}))
+	_, err := r.Reconcile(context.Background(), reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "kcm-system", Name: "dev"}})
+	g.Expect(err).To(MatchError("failed"))
+
+	spans := recorder.Ended()
+	g.Expect(spans).To(HaveLen(1))
+	g.Expect(spans[0].Name()).To(Equal("ClusterDeployment.Reconcile"))
+	g.Expect(spans[0].Status().Code).To(Equal(codes.Error))
+	g.Expect(spans[0].Attributes()).To(ContainElements(
+		HaveField("Value.AsString()", "kcm-system"),
+		HaveField("Value.AsString()", "dev"),
+	))
+}
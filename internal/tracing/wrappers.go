@@ -0,0 +1,90 @@
+// Copyright 2024
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+type reconciler struct {
+	reconcile.Reconciler
+	name string
+}
+
+// Reconciler wraps the reconciler so each of the reconciliations of the object
+// of the given kind is recorded as the root span of the following steps.
+func Reconciler(kind string, r reconcile.Reconciler) reconcile.Reconciler {
+	return &reconciler{Reconciler: r, name: kind + ".Reconcile"}
+}
+
+func (r *reconciler) Reconcile(ctx context.Context, req reconcile.Request) (_ reconcile.Result, err error) {
+	ctx, span := Start(ctx, r.name,
+		attribute.String("k8s.namespace.name", req.Namespace),
+		attribute.String("k8s.object.name", req.Name),
+	)
+	defer func() { End(span, err) }()
+
+	return r.Reconciler.Reconcile(ctx, req)
+}
+
+type validator struct {
+	admission.CustomValidator
+	kind string
+}
+
+// Validator wraps the validator so each of the validations of the object
+// of the given kind is recorded as a span.
+func Validator(kind string, v admission.CustomValidator) admission.CustomValidator {
+	return &validator{CustomValidator: v, kind: kind}
+}
+
+func (v *validator) ValidateCreate(ctx context.Context, obj runtime.Object) (_ admission.Warnings, err error) {
+	ctx, span := v.start(ctx, "ValidateCreate", obj)
+	defer func() { End(span, err) }()
+
+	return v.CustomValidator.ValidateCreate(ctx, obj)
+}
+
+func (v *validator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (_ admission.Warnings, err error) {
+	ctx, span := v.start(ctx, "ValidateUpdate", newObj)
+	defer func() { End(span, err) }()
+
+	return v.CustomValidator.ValidateUpdate(ctx, oldObj, newObj)
+}
+
+func (v *validator) ValidateDelete(ctx context.Context, obj runtime.Object) (_ admission.Warnings, err error) {
+	ctx, span := v.start(ctx, "ValidateDelete", obj)
+	defer func() { End(span, err) }()
+
+	return v.CustomValidator.ValidateDelete(ctx, obj)
+}
+
+func (v *validator) start(ctx context.Context, operation string, obj runtime.Object) (context.Context, trace.Span) {
+	var attrs []attribute.KeyValue
+	if o, ok := obj.(client.Object); ok {
+		attrs = append(attrs,
+			attribute.String("k8s.namespace.name", o.GetNamespace()),
+			attribute.String("k8s.object.name", o.GetName()),
+		)
+	}
+	return Start(ctx, v.kind+"Webhook."+operation, attrs...)
+}
@@ -0,0 +1,94 @@
+// Copyright 2024
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kcm "github.com/K0rdent/kcm/api/v1alpha1"
+)
+
+// GetTemplateLifecycle returns the effective lifecycle of the given ClusterTemplate or ServiceTemplate.
+// The lifecycle defined in the Template is merged with the lifecycles defined for it
+// in the template chains from the same namespace, the latter take precedence.
+// Returns nil if the lifecycle is defined neither in the Template nor in the chains.
+func GetTemplateLifecycle(ctx context.Context, cl client.Client, template client.Object) (*kcm.TemplateLifecycle, error) {
+	var (
+		lifecycles []*kcm.TemplateLifecycle
+		chains     []kcm.TemplateChainSpec
+	)
+
+	switch t := template.(type) {
+	case *kcm.ClusterTemplate:
+		lifecycles = append(lifecycles, t.Spec.Lifecycle)
+
+		chainList := new(kcm.ClusterTemplateChainList)
+		if err := cl.List(ctx, chainList, client.InNamespace(t.Namespace)); err != nil {
+			return nil, fmt.Errorf("failed to list ClusterTemplateChains in namespace %s: %w", t.Namespace, err)
+		}
+		for _, chain := range chainList.Items {
+			chains = append(chains, chain.Spec)
+		}
+	case *kcm.ServiceTemplate:
+		lifecycles = append(lifecycles, t.Spec.Lifecycle)
+
+		chainList := new(kcm.ServiceTemplateChainList)
+		if err := cl.List(ctx, chainList, client.InNamespace(t.Namespace)); err != nil {
+			return nil, fmt.Errorf("failed to list ServiceTemplateChains in namespace %s: %w", t.Namespace, err)
+		}
+		for _, chain := range chainList.Items {
+			chains = append(chains, chain.Spec)
+		}
+	default:
+		return nil, fmt.Errorf("unexpected template type %T, expected ClusterTemplate or ServiceTemplate", template)
+	}
+
+	for _, chain := range chains {
+		for _, supportedTemplate := range chain.SupportedTemplates {
+			if supportedTemplate.Name == template.GetName() {
+				lifecycles = append(lifecycles, supportedTemplate.Lifecycle)
+			}
+		}
+	}
+
+	return kcm.MergeLifecycles(lifecycles...), nil
+}
+
+// TemplateLifecycleMessage returns a human-readable description of the given lifecycle
+// of the Template with the given kind and name.
+func TemplateLifecycleMessage(kind, name string, lifecycle *kcm.TemplateLifecycle, now time.Time) string {
+	msg := fmt.Sprintf("%s %s is deprecated", kind, name)
+	if lifecycle.EndOfLife != nil {
+		if lifecycle.IsEndOfLife(now) {
+			msg = fmt.Sprintf("%s %s has reached its end-of-life on %s", kind, name, lifecycle.EndOfLife.UTC().Format(time.DateOnly))
+		} else {
+			msg += fmt.Sprintf(" and will reach its end-of-life on %s", lifecycle.EndOfLife.UTC().Format(time.DateOnly))
+		}
+	}
+
+	if lifecycle.DeprecationMessage != "" {
+		msg += ": " + lifecycle.DeprecationMessage
+	}
+
+	if lifecycle.Replacement != "" {
+		msg += fmt.Sprintf(". Consider using %s instead", lifecycle.Replacement)
+	}
+
+	return msg
+}
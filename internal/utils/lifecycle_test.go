@@ -0,0 +1,152 @@
+// Copyright 2024
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kcmv1 "github.com/K0rdent/kcm/api/v1alpha1"
+	"github.com/K0rdent/kcm/internal/utils"
+	"github.com/K0rdent/kcm/test/objects/template"
+	"github.com/K0rdent/kcm/test/objects/templatechain"
+	"github.com/K0rdent/kcm/test/scheme"
+)
+
+func TestGetTemplateLifecycle(t *testing.T) {
+	const (
+		templateName    = "tpl"
+		replacementName = "tpl-next"
+	)
+
+	var (
+		earlyEOL = metav1.NewTime(time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC))
+		lateEOL  = metav1.NewTime(time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC))
+	)
+
+	tests := []struct {
+		name            string
+		template        *kcmv1.ClusterTemplate
+		existingObjects []runtime.Object
+		expected        *kcmv1.TemplateLifecycle
+	}{
+		{
+			name:     "no lifecycle, expect nil",
+			template: template.NewClusterTemplate(template.WithName(templateName)),
+		},
+		{
+			name: "not deprecated lifecycle",
+			template: template.NewClusterTemplate(
+				template.WithName(templateName),
+				template.WithLifecycle(&kcmv1.TemplateLifecycle{Replacement: replacementName}),
+			),
+			expected: &kcmv1.TemplateLifecycle{Replacement: replacementName},
+		},
+		{
+			name: "lifecycle from the template only",
+			template: template.NewClusterTemplate(
+				template.WithName(templateName),
+				template.WithLifecycle(&kcmv1.TemplateLifecycle{Deprecated: true, Replacement: replacementName}),
+			),
+			expected: &kcmv1.TemplateLifecycle{Deprecated: true, Replacement: replacementName},
+		},
+		{
+			name: "lifecycle from the chain takes precedence, the earliest end-of-life wins",
+			template: template.NewClusterTemplate(
+				template.WithName(templateName),
+				template.WithLifecycle(&kcmv1.TemplateLifecycle{EndOfLife: &lateEOL, DeprecationMessage: "old"}),
+			),
+			existingObjects: []runtime.Object{
+				templatechain.NewClusterTemplateChain(
+					templatechain.WithNamespace(template.DefaultNamespace),
+					templatechain.WithSupportedTemplates([]kcmv1.SupportedTemplate{
+						{Name: "other", Lifecycle: &kcmv1.TemplateLifecycle{Deprecated: true}},
+						{Name: templateName, Lifecycle: &kcmv1.TemplateLifecycle{EndOfLife: &earlyEOL, DeprecationMessage: "new"}},
+					}),
+				),
+				templatechain.NewClusterTemplateChain(
+					templatechain.WithName("another-namespace"),
+					templatechain.WithNamespace("another"),
+					templatechain.WithSupportedTemplates([]kcmv1.SupportedTemplate{
+						{Name: templateName, Lifecycle: &kcmv1.TemplateLifecycle{Replacement: replacementName}},
+					}),
+				),
+			},
+			expected: &kcmv1.TemplateLifecycle{EndOfLife: &earlyEOL, DeprecationMessage: "new"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithRuntimeObjects(tt.existingObjects...).Build()
+			lifecycle, err := utils.GetTemplateLifecycle(context.Background(), c, tt.template)
+			g.Expect(err).NotTo(HaveOccurred())
+			if lifecycle != nil && lifecycle.EndOfLife != nil {
+				// the fake client returns the time in the local location
+				lifecycle.EndOfLife.Time = lifecycle.EndOfLife.UTC()
+			}
+			g.Expect(lifecycle).To(Equal(tt.expected))
+		})
+	}
+}
+
+func TestTemplateLifecycleMessage(t *testing.T) {
+	var (
+		now = time.Date(2025, time.June, 1, 0, 0, 0, 0, time.UTC)
+		eol = metav1.NewTime(time.Date(2025, time.July, 1, 0, 0, 0, 0, time.UTC))
+	)
+
+	tests := []struct {
+		name      string
+		lifecycle *kcmv1.TemplateLifecycle
+		now       time.Time
+		expected  string
+	}{
+		{
+			name:      "deprecated",
+			lifecycle: &kcmv1.TemplateLifecycle{Deprecated: true},
+			now:       now,
+			expected:  "ClusterTemplate tpl is deprecated",
+		},
+		{
+			name:      "deprecated with end-of-life in the future",
+			lifecycle: &kcmv1.TemplateLifecycle{EndOfLife: &eol, DeprecationMessage: "unsupported k8s version", Replacement: "tpl-next"},
+			now:       now,
+			expected:  "ClusterTemplate tpl is deprecated and will reach its end-of-life on 2025-07-01: unsupported k8s version. Consider using tpl-next instead",
+		},
+		{
+			name:      "end-of-life reached",
+			lifecycle: &kcmv1.TemplateLifecycle{EndOfLife: &eol},
+			now:       eol.Time,
+			expected:  "ClusterTemplate tpl has reached its end-of-life on 2025-07-01",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := utils.TemplateLifecycleMessage(kcmv1.ClusterTemplateKind, "tpl", tt.lifecycle, tt.now); got != tt.expected {
+				t.Errorf("TemplateLifecycleMessage() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+// Copyright 2024
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package status
+
+import (
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kcm "github.com/K0rdent/kcm/api/v1alpha1"
+)
+
+// ConditionTransitions returns the transitions of the current conditions which either
+// do not exist among the previous ones or have a different status or reason than
+// the previous condition of the same type. The time of a transition is the LastTransitionTime
+// of the condition if its status has changed and the given time otherwise.
+func ConditionTransitions(previous, current []metav1.Condition, now metav1.Time) []kcm.ConditionTransition {
+	var transitions []kcm.ConditionTransition
+	for _, c := range current {
+		t := now
+		prev := apimeta.FindStatusCondition(previous, c.Type)
+		switch {
+		case prev != nil && prev.Status == c.Status && prev.Reason == c.Reason:
+			continue
+		case (prev == nil || prev.Status != c.Status) && !c.LastTransitionTime.IsZero():
+			t = c.LastTransitionTime
+		}
+
+		transitions = append(transitions, kcm.ConditionTransition{
+			Time:    t,
+			Type:    c.Type,
+			Status:  c.Status,
+			Reason:  c.Reason,
+			Message: c.Message,
+		})
+	}
+
+	return transitions
+}
+
+// AppendConditionHistory appends the transitions to the history keeping
+// at most limit of the newest transitions of each condition type.
+func AppendConditionHistory(history, transitions []kcm.ConditionTransition, limit int) []kcm.ConditionTransition {
+	if len(transitions) == 0 {
+		return history
+	}
+
+	history = append(history, transitions...)
+
+	counts := make(map[string]int)
+	for _, h := range history {
+		counts[h.Type]++
+	}
+
+	trimmed := make([]kcm.ConditionTransition, 0, len(history))
+	for _, h := range history {
+		if counts[h.Type] > limit {
+			counts[h.Type]--
+			continue
+		}
+		trimmed = append(trimmed, h)
+	}
+
+	return trimmed
+}
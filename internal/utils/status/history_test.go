@@ -0,0 +1,83 @@
+// Copyright 2024
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package status
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kcm "github.com/K0rdent/kcm/api/v1alpha1"
+)
+
+func TestConditionTransitions(t *testing.T) {
+	g := NewWithT(t)
+
+	transitionTime := metav1.NewTime(time.Date(2024, 12, 1, 10, 0, 0, 0, time.UTC))
+	now := metav1.NewTime(transitionTime.Add(time.Minute))
+
+	previous := []metav1.Condition{
+		{Type: kcm.TemplateReadyCondition, Status: metav1.ConditionTrue, Reason: kcm.SucceededReason},
+		{Type: kcm.HelmReleaseReadyCondition, Status: metav1.ConditionFalse, Reason: kcm.ProgressingReason},
+		{Type: kcm.CredentialReadyCondition, Status: metav1.ConditionTrue, Reason: kcm.SucceededReason},
+	}
+	current := []metav1.Condition{
+		{Type: kcm.TemplateReadyCondition, Status: metav1.ConditionTrue, Reason: kcm.SucceededReason, LastTransitionTime: transitionTime},
+		{Type: kcm.HelmReleaseReadyCondition, Status: metav1.ConditionFalse, Reason: "InstallFailed", Message: "install failed", LastTransitionTime: transitionTime},
+		{Type: kcm.CredentialReadyCondition, Status: metav1.ConditionFalse, Reason: kcm.FailedReason, LastTransitionTime: transitionTime},
+		{Type: kcm.ReadyCondition, Status: metav1.ConditionFalse, Reason: kcm.FailedReason, LastTransitionTime: transitionTime},
+	}
+
+	g.Expect(ConditionTransitions(previous, current, now)).To(Equal([]kcm.ConditionTransition{
+		// the status has not changed, hence the time of the reconciliation
+		{Time: now, Type: kcm.HelmReleaseReadyCondition, Status: metav1.ConditionFalse, Reason: "InstallFailed", Message: "install failed"},
+		{Time: transitionTime, Type: kcm.CredentialReadyCondition, Status: metav1.ConditionFalse, Reason: kcm.FailedReason},
+		{Time: transitionTime, Type: kcm.ReadyCondition, Status: metav1.ConditionFalse, Reason: kcm.FailedReason},
+	}))
+	g.Expect(ConditionTransitions(current, current, now)).To(BeEmpty())
+}
+
+func TestAppendConditionHistory(t *testing.T) {
+	g := NewWithT(t)
+
+	transition := func(conditionType string, minute int) kcm.ConditionTransition {
+		return kcm.ConditionTransition{
+			Time:   metav1.NewTime(time.Date(2024, 12, 1, 10, minute, 0, 0, time.UTC)),
+			Type:   conditionType,
+			Status: metav1.ConditionTrue,
+		}
+	}
+
+	history := AppendConditionHistory(nil, []kcm.ConditionTransition{
+		transition(kcm.ReadyCondition, 0),
+		transition(kcm.TemplateReadyCondition, 0),
+	}, 2)
+	history = AppendConditionHistory(history, []kcm.ConditionTransition{transition(kcm.ReadyCondition, 1)}, 2)
+	history = AppendConditionHistory(history, nil, 2)
+	g.Expect(history).To(Equal([]kcm.ConditionTransition{
+		transition(kcm.ReadyCondition, 0),
+		transition(kcm.TemplateReadyCondition, 0),
+		transition(kcm.ReadyCondition, 1),
+	}))
+
+	history = AppendConditionHistory(history, []kcm.ConditionTransition{transition(kcm.ReadyCondition, 2)}, 2)
+	g.Expect(history).To(Equal([]kcm.ConditionTransition{
+		transition(kcm.TemplateReadyCondition, 0),
+		transition(kcm.ReadyCondition, 1),
+		transition(kcm.ReadyCondition, 2),
+	}))
+}
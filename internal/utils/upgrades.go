@@ -0,0 +1,104 @@
+// Copyright 2024
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+
+	"github.com/Masterminds/semver/v3"
+
+	kcm "github.com/K0rdent/kcm/api/v1alpha1"
+)
+
+// TemplateChartVersion is the Template name along with the name and version of its Helm chart.
+type TemplateChartVersion struct {
+	Version *semver.Version
+	Name    string
+	Chart   string
+}
+
+// ResolveUpgradeRules resolves the given upgrade rules against the given Templates.
+// The result is sorted by the Template name, the upgrades of each Template are sorted by the chart version.
+// The Templates without any available upgrades are omitted.
+func ResolveUpgradeRules(rules []kcm.UpgradeRule, templates []TemplateChartVersion) ([]kcm.ResolvedUpgrade, error) {
+	type constraints struct {
+		from, to *semver.Constraints
+	}
+
+	parsed := make([]constraints, len(rules))
+	for i, rule := range rules {
+		from, err := semver.NewConstraint(rule.From)
+		if err != nil {
+			return nil, fmt.Errorf("invalid from constraint %s of the %s upgrade rule: %w", rule.From, rule.Chart, err)
+		}
+		to, err := semver.NewConstraint(rule.To)
+		if err != nil {
+			return nil, fmt.Errorf("invalid to constraint %s of the %s upgrade rule: %w", rule.To, rule.Chart, err)
+		}
+		parsed[i] = constraints{from: from, to: to}
+	}
+
+	sorted := slices.Clone(templates)
+	slices.SortFunc(sorted, func(a, b TemplateChartVersion) int {
+		return cmp.Or(cmp.Compare(a.Chart, b.Chart), a.Version.Compare(b.Version), cmp.Compare(a.Name, b.Name))
+	})
+
+	var resolved []kcm.ResolvedUpgrade
+	for _, template := range sorted {
+		var upgrades []TemplateChartVersion
+		for i, rule := range rules {
+			if rule.Chart != template.Chart || !parsed[i].from.Check(template.Version) {
+				continue
+			}
+
+			var candidates []TemplateChartVersion
+			for _, candidate := range sorted {
+				if candidate.Chart == template.Chart && candidate.Version.GreaterThan(template.Version) && parsed[i].to.Check(candidate.Version) {
+					candidates = append(candidates, candidate)
+				}
+			}
+			if len(candidates) == 0 {
+				continue
+			}
+
+			if rule.Target == kcm.UpgradeRuleTargetAll {
+				upgrades = append(upgrades, candidates...)
+			} else {
+				upgrades = append(upgrades, candidates[len(candidates)-1])
+			}
+		}
+		if len(upgrades) == 0 {
+			continue
+		}
+
+		slices.SortFunc(upgrades, func(a, b TemplateChartVersion) int {
+			return cmp.Or(a.Version.Compare(b.Version), cmp.Compare(a.Name, b.Name))
+		})
+		upgrades = slices.CompactFunc(upgrades, func(a, b TemplateChartVersion) bool { return a.Name == b.Name })
+
+		result := kcm.ResolvedUpgrade{Name: template.Name}
+		for _, upgrade := range upgrades {
+			result.AvailableUpgrades = append(result.AvailableUpgrades, kcm.AvailableUpgrade{Name: upgrade.Name})
+		}
+		resolved = append(resolved, result)
+	}
+
+	slices.SortFunc(resolved, func(a, b kcm.ResolvedUpgrade) int {
+		return cmp.Compare(a.Name, b.Name)
+	})
+	return resolved, nil
+}
@@ -0,0 +1,105 @@
+// Copyright 2024
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils_test
+
+import (
+	"testing"
+
+	"github.com/Masterminds/semver/v3"
+	. "github.com/onsi/gomega"
+
+	kcmv1 "github.com/K0rdent/kcm/api/v1alpha1"
+	"github.com/K0rdent/kcm/internal/utils"
+)
+
+func TestResolveUpgradeRules(t *testing.T) {
+	templateVersion := func(chart, version string) utils.TemplateChartVersion {
+		return utils.TemplateChartVersion{
+			Name:    chart + "-" + version,
+			Chart:   chart,
+			Version: semver.MustParse(version),
+		}
+	}
+
+	templates := []utils.TemplateChartVersion{
+		templateVersion("aws", "1.2.0"),
+		templateVersion("aws", "1.2.1"),
+		templateVersion("aws", "1.3.0"),
+		templateVersion("aws", "1.4.0"),
+		templateVersion("azure", "1.2.0"),
+		templateVersion("azure", "1.3.0"),
+	}
+
+	tests := []struct {
+		name     string
+		rules    []kcmv1.UpgradeRule
+		expected []kcmv1.ResolvedUpgrade
+		err      string
+	}{
+		{
+			name:  "no rules",
+			rules: nil,
+		},
+		{
+			name:  "upgrade to the highest version in the range",
+			rules: []kcmv1.UpgradeRule{{Chart: "aws", From: "~1.2", To: ">=1.2 <1.4"}},
+			expected: []kcmv1.ResolvedUpgrade{
+				{Name: "aws-1.2.0", AvailableUpgrades: []kcmv1.AvailableUpgrade{{Name: "aws-1.3.0"}}},
+				{Name: "aws-1.2.1", AvailableUpgrades: []kcmv1.AvailableUpgrade{{Name: "aws-1.3.0"}}},
+			},
+		},
+		{
+			name:  "upgrade to all of the higher versions in the range",
+			rules: []kcmv1.UpgradeRule{{Chart: "aws", From: "~1.2", To: ">=1.2 <1.4", Target: kcmv1.UpgradeRuleTargetAll}},
+			expected: []kcmv1.ResolvedUpgrade{
+				{Name: "aws-1.2.0", AvailableUpgrades: []kcmv1.AvailableUpgrade{{Name: "aws-1.2.1"}, {Name: "aws-1.3.0"}}},
+				{Name: "aws-1.2.1", AvailableUpgrades: []kcmv1.AvailableUpgrade{{Name: "aws-1.3.0"}}},
+			},
+		},
+		{
+			name: "multiple rules are merged",
+			rules: []kcmv1.UpgradeRule{
+				{Chart: "aws", From: "1.2.0", To: "~1.2"},
+				{Chart: "aws", From: "<1.4", To: "^1"},
+				{Chart: "azure", From: "*", To: "*"},
+			},
+			expected: []kcmv1.ResolvedUpgrade{
+				{Name: "aws-1.2.0", AvailableUpgrades: []kcmv1.AvailableUpgrade{{Name: "aws-1.2.1"}, {Name: "aws-1.4.0"}}},
+				{Name: "aws-1.2.1", AvailableUpgrades: []kcmv1.AvailableUpgrade{{Name: "aws-1.4.0"}}},
+				{Name: "aws-1.3.0", AvailableUpgrades: []kcmv1.AvailableUpgrade{{Name: "aws-1.4.0"}}},
+				{Name: "azure-1.2.0", AvailableUpgrades: []kcmv1.AvailableUpgrade{{Name: "azure-1.3.0"}}},
+			},
+		},
+		{
+			name:  "invalid constraint",
+			rules: []kcmv1.UpgradeRule{{Chart: "aws", From: "~1.2", To: "latest"}},
+			err:   "invalid to constraint latest of the aws upgrade rule: improper constraint: latest",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			resolved, err := utils.ResolveUpgradeRules(tt.rules, templates)
+			if tt.err != "" {
+				g.Expect(err).To(MatchError(tt.err))
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(resolved).To(Equal(tt.expected))
+		})
+	}
+}
@@ -0,0 +1,66 @@
+// Copyright 2024
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	"maps"
+	"strings"
+)
+
+// ValuesLayer is a set of Helm values along with the name of their source.
+type ValuesLayer struct {
+	Values map[string]any
+	Source string
+}
+
+// MergeValues deep-merges the given layers of Helm values, the values of the later layers win.
+// Nested maps are merged key by key, any other value (including lists) replaces the value of the earlier layer.
+// Along with the merged values it returns the source of each leaf value keyed by its dot-separated path.
+func MergeValues(layers ...ValuesLayer) (merged map[string]any, sources map[string]string) {
+	merged, sources = make(map[string]any), make(map[string]string)
+	for _, layer := range layers {
+		mergeValuesInto(merged, layer.Values, "", layer.Source, sources)
+	}
+	return merged, sources
+}
+
+func mergeValuesInto(dst, src map[string]any, prefix, source string, sources map[string]string) {
+	for key, value := range src {
+		path := prefix + key
+
+		srcMap, srcIsMap := value.(map[string]any)
+		dstMap, dstIsMap := dst[key].(map[string]any)
+		switch {
+		case srcIsMap && dstIsMap:
+			mergeValuesInto(dstMap, srcMap, path+".", source, sources)
+		case srcIsMap:
+			deleteSources(sources, path)
+			dstMap = make(map[string]any, len(srcMap))
+			dst[key] = dstMap
+			mergeValuesInto(dstMap, srcMap, path+".", source, sources)
+		default:
+			deleteSources(sources, path)
+			dst[key] = value
+			sources[path] = source
+		}
+	}
+}
+
+// deleteSources deletes the sources of the value with the given path and of all of its nested values.
+func deleteSources(sources map[string]string, path string) {
+	maps.DeleteFunc(sources, func(k, _ string) bool {
+		return k == path || strings.HasPrefix(k, path+".")
+	})
+}
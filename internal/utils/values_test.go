@@ -0,0 +1,58 @@
+// Copyright 2024
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/K0rdent/kcm/internal/utils"
+)
+
+func TestMergeValues(t *testing.T) {
+	g := NewWithT(t)
+
+	merged, sources := utils.MergeValues(
+		utils.ValuesLayer{Source: "team", Values: map[string]any{
+			"region": "us-east-2",
+			"worker": map[string]any{"instanceType": "t3.small", "rootVolumeSize": 32},
+			"tags":   map[string]any{"team": "platform"},
+			"sshKey": "team-key",
+		}},
+		utils.ValuesLayer{Source: "prod", Values: map[string]any{
+			"worker": map[string]any{"instanceType": "t3.large"},
+			"tags":   "none",
+		}},
+		utils.ValuesLayer{Source: "spec.config", Values: map[string]any{
+			"region": "eu-west-1",
+			"tags":   map[string]any{"env": "prod"},
+		}},
+	)
+
+	g.Expect(merged).To(Equal(map[string]any{
+		"region": "eu-west-1",
+		"worker": map[string]any{"instanceType": "t3.large", "rootVolumeSize": 32},
+		"tags":   map[string]any{"env": "prod"},
+		"sshKey": "team-key",
+	}))
+	g.Expect(sources).To(Equal(map[string]string{
+		"region":                "spec.config",
+		"worker.instanceType":   "prod",
+		"worker.rootVolumeSize": "team",
+		"tags.env":              "spec.config",
+		"sshKey":                "team",
+	}))
+}
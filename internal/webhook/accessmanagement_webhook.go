@@ -27,6 +27,8 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 
 	"github.com/K0rdent/kcm/api/v1alpha1"
+	"github.com/K0rdent/kcm/internal/scope"
+	"github.com/K0rdent/kcm/internal/tracing"
 )
 
 var errAccessManagementDeletionForbidden = errors.New("AccessManagement deletion is forbidden")
@@ -40,7 +42,7 @@ func (v *AccessManagementValidator) SetupWebhookWithManager(mgr ctrl.Manager) er
 	v.Client = mgr.GetClient()
 	return ctrl.NewWebhookManagedBy(mgr).
 		For(&v1alpha1.AccessManagement{}).
-		WithValidator(v).
+		WithValidator(tracing.Validator("AccessManagement", v)).
 		WithDefaulter(v).
 		Complete()
 }
@@ -51,7 +53,7 @@ var (
 )
 
 // ValidateCreate implements webhook.Validator so a webhook will be registered for the type.
-func (v *AccessManagementValidator) ValidateCreate(ctx context.Context, _ runtime.Object) (admission.Warnings, error) {
+func (v *AccessManagementValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
 	itemsList := &metav1.PartialObjectMetadataList{}
 	itemsList.SetGroupVersionKind(v1alpha1.GroupVersion.WithKind(v1alpha1.AccessManagementKind))
 
@@ -63,12 +65,31 @@ func (v *AccessManagementValidator) ValidateCreate(ctx context.Context, _ runtim
 		return nil, errors.New("AccessManagement object already exists")
 	}
 
-	return nil, nil
+	return outOfScopeWarnings(obj), nil
 }
 
 // ValidateUpdate implements webhook.Validator so a webhook will be registered for the type.
-func (*AccessManagementValidator) ValidateUpdate(_ context.Context, _, _ runtime.Object) (admission.Warnings, error) {
-	return nil, nil
+func (*AccessManagementValidator) ValidateUpdate(_ context.Context, _, newObj runtime.Object) (admission.Warnings, error) {
+	return outOfScopeWarnings(newObj), nil
+}
+
+// outOfScopeWarnings warns about the explicitly listed target namespaces
+// which are not watched by kcm and hence are skipped.
+func outOfScopeWarnings(obj runtime.Object) admission.Warnings {
+	accessManagement, ok := obj.(*v1alpha1.AccessManagement)
+	if !ok {
+		return nil
+	}
+
+	var warnings admission.Warnings
+	for _, rule := range accessManagement.Spec.AccessRules {
+		for _, ns := range rule.TargetNamespaces.List {
+			if !scope.Contains(ns) {
+				warnings = append(warnings, fmt.Sprintf("target namespace %s is not watched by kcm and is skipped", ns))
+			}
+		}
+	}
+	return warnings
 }
 
 // ValidateDelete implements webhook.Validator so a webhook will be registered for the type.
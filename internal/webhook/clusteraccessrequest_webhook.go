@@ -0,0 +1,77 @@
+// Copyright 2024
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	kcmv1 "github.com/K0rdent/kcm/api/v1alpha1"
+	"github.com/K0rdent/kcm/internal/clusteraccess"
+	"github.com/K0rdent/kcm/internal/config"
+	"github.com/K0rdent/kcm/internal/scope"
+	"github.com/K0rdent/kcm/internal/tracing"
+)
+
+const invalidClusterAccessRequestMsg = "the ClusterAccessRequest is invalid"
+
+type ClusterAccessRequestValidator struct{}
+
+// SetupWebhookWithManager will setup the manager to manage the webhooks
+func (v *ClusterAccessRequestValidator) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&kcmv1.ClusterAccessRequest{}).
+		WithValidator(tracing.Validator("ClusterAccessRequest", scope.Validator(v))).
+		Complete()
+}
+
+var _ webhook.CustomValidator = &ClusterAccessRequestValidator{}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type.
+// The requested access is checked against the limits of the controller manager configuration.
+func (*ClusterAccessRequestValidator) ValidateCreate(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+	accessReq, ok := obj.(*kcmv1.ClusterAccessRequest)
+	if !ok {
+		return nil, apierrors.NewBadRequest(fmt.Sprintf("expected ClusterAccessRequest but got a %T", obj))
+	}
+
+	access := clusteraccess.Access{
+		ClusterRole: accessReq.Spec.ClusterRole,
+		Namespace:   accessReq.Spec.Namespace,
+		TTL:         accessReq.Spec.TTL.Duration,
+	}
+	if err := clusteraccess.CheckLimits(access, config.ClusterAccessLimits()); err != nil {
+		return nil, fmt.Errorf("%s: %w", invalidClusterAccessRequestMsg, err)
+	}
+
+	return nil, nil
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type.
+// The spec is immutable, so the requests created before the limits were changed may still be updated.
+func (*ClusterAccessRequestValidator) ValidateUpdate(context.Context, runtime.Object, runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type.
+func (*ClusterAccessRequestValidator) ValidateDelete(context.Context, runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
@@ -0,0 +1,87 @@
+// Copyright 2024
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/K0rdent/kcm/api/v1alpha1"
+)
+
+func newClusterAccessRequest(clusterRole, namespace string, ttl time.Duration) *v1alpha1.ClusterAccessRequest {
+	return &v1alpha1.ClusterAccessRequest{
+		ObjectMeta: metav1.ObjectMeta{Name: "alice", Namespace: "kcm-system"},
+		Spec: v1alpha1.ClusterAccessRequestSpec{
+			ClusterDeployment: "dev",
+			ClusterRole:       clusterRole,
+			Namespace:         namespace,
+			TTL:               metav1.Duration{Duration: ttl},
+		},
+	}
+}
+
+func TestClusterAccessRequestValidateCreate(t *testing.T) {
+	g := NewWithT(t)
+
+	ctx := admission.NewContextWithRequest(context.Background(), admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{Operation: admissionv1.Create}})
+
+	tests := []struct {
+		name      string
+		accessReq *v1alpha1.ClusterAccessRequest
+		err       string
+	}{
+		{
+			name:      "should fail if the ClusterRole is not allowed",
+			accessReq: newClusterAccessRequest("cluster-admin", "apps", time.Hour),
+			err:       "the ClusterAccessRequest is invalid: the ClusterRole cluster-admin is not allowed, the allowed ClusterRoles are: view, edit",
+		},
+		{
+			name:      "should fail if the access is cluster-wide and the TTL exceeds the maximum",
+			accessReq: newClusterAccessRequest("view", "", 48*time.Hour),
+			err: "the ClusterAccessRequest is invalid: " +
+				"the namespace must be set, the ClusterRoles are not allowed to be granted cluster-wide\n" +
+				"the TTL 48h0m0s exceeds the maximum of 8h0m0s",
+		},
+		{
+			name:      "should fail if the TTL is less than the minimum",
+			accessReq: newClusterAccessRequest("edit", "apps", time.Minute),
+			err:       "the ClusterAccessRequest is invalid: the TTL 1m0s is less than the minimum of 10m0s",
+		},
+		{
+			name:      "should succeed",
+			accessReq: newClusterAccessRequest("edit", "apps", time.Hour),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(_ *testing.T) {
+			validator := &ClusterAccessRequestValidator{}
+			warn, err := validator.ValidateCreate(ctx, tt.accessReq)
+			if tt.err != "" {
+				g.Expect(err).To(MatchError(tt.err))
+			} else {
+				g.Expect(err).To(Succeed())
+			}
+			g.Expect(warn).To(BeEmpty())
+		})
+	}
+}
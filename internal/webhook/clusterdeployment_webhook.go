@@ -15,11 +15,13 @@
 package webhook
 
 import (
+	"cmp"
 	"context"
 	"errors"
 	"fmt"
 	"slices"
 	"strings"
+	"time"
 
 	"github.com/Masterminds/semver/v3"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
@@ -32,6 +34,9 @@ import (
 
 	kcmv1 "github.com/K0rdent/kcm/api/v1alpha1"
 	providersloader "github.com/K0rdent/kcm/internal/providers"
+	"github.com/K0rdent/kcm/internal/scope"
+	"github.com/K0rdent/kcm/internal/tracing"
+	"github.com/K0rdent/kcm/internal/utils"
 )
 
 type ClusterDeploymentValidator struct {
@@ -46,8 +51,8 @@ func (v *ClusterDeploymentValidator) SetupWebhookWithManager(mgr ctrl.Manager) e
 	v.Client = mgr.GetClient()
 	return ctrl.NewWebhookManagedBy(mgr).
 		For(&kcmv1.ClusterDeployment{}).
-		WithValidator(v).
-		WithDefaulter(v).
+		WithValidator(tracing.Validator("ClusterDeployment", scope.Validator(v))).
+		WithDefaulter(scope.Defaulter(v)).
 		Complete()
 }
 
@@ -84,7 +89,16 @@ func (v *ClusterDeploymentValidator) ValidateCreate(ctx context.Context, obj run
 		return nil, fmt.Errorf("%s: %w", invalidClusterDeploymentMsg, err)
 	}
 
-	return nil, nil
+	if err := validateAutoscaler(ctx, v.Client, clusterDeployment); err != nil {
+		return nil, fmt.Errorf("%s: %w", invalidClusterDeploymentMsg, err)
+	}
+
+	warnings, err := v.validateLifecycle(ctx, nil, clusterDeployment, template)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", invalidClusterDeploymentMsg, err)
+	}
+
+	return warnings, nil
 }
 
 // ValidateUpdate implements webhook.Validator so a webhook will be registered for the type.
@@ -128,7 +142,92 @@ func (v *ClusterDeploymentValidator) ValidateUpdate(ctx context.Context, oldObj,
 		return nil, fmt.Errorf("%s: %w", invalidClusterDeploymentMsg, err)
 	}
 
-	return nil, nil
+	if err := validateAutoscaler(ctx, v.Client, newClusterDeployment); err != nil {
+		return nil, fmt.Errorf("%s: %w", invalidClusterDeploymentMsg, err)
+	}
+
+	warnings, err := v.validateLifecycle(ctx, oldClusterDeployment, newClusterDeployment, template)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", invalidClusterDeploymentMsg, err)
+	}
+
+	return warnings, nil
+}
+
+// validateAutoscaler checks the ServiceTemplate of the cluster-autoscaler is valid
+// and the cluster-autoscaler release does not collide with the services of the ClusterDeployment.
+func validateAutoscaler(ctx context.Context, c client.Client, clusterDeployment *kcmv1.ClusterDeployment) error {
+	if clusterDeployment.Spec.Autoscaling == nil || clusterDeployment.Spec.Autoscaling.Autoscaler == nil {
+		return nil
+	}
+	autoscaler := clusterDeployment.Spec.Autoscaling.Autoscaler
+
+	for _, svc := range clusterDeployment.Spec.ServiceSpec.Services {
+		if svc.Name == kcmv1.ClusterAutoscalerServiceName && cmp.Or(svc.Namespace, svc.Name) == cmp.Or(autoscaler.Namespace, kcmv1.ClusterAutoscalerServiceName) {
+			return fmt.Errorf("service %s/%s collides with the cluster-autoscaler release", cmp.Or(svc.Namespace, svc.Name), svc.Name)
+		}
+	}
+
+	return validateServices(ctx, c, clusterDeployment.Namespace, []kcmv1.Service{{
+		Name:     kcmv1.ClusterAutoscalerServiceName,
+		Template: autoscaler.Template,
+	}})
+}
+
+// validateLifecycle checks the lifecycle of the ClusterTemplate and the ServiceTemplates referenced
+// by the ClusterDeployment. Deprecated templates produce warnings; templates that have reached
+// their end-of-life are rejected unless they were already referenced by the old object.
+func (v *ClusterDeploymentValidator) validateLifecycle(ctx context.Context, oldClusterDeployment, clusterDeployment *kcmv1.ClusterDeployment, template *kcmv1.ClusterTemplate) (admission.Warnings, error) {
+	var (
+		now                 = time.Now()
+		warnings            admission.Warnings
+		oldServiceTemplates []string
+	)
+
+	lifecycle, err := utils.GetTemplateLifecycle(ctx, v.Client, template)
+	if err != nil {
+		return nil, err
+	}
+	if lifecycle.IsDeprecated() {
+		msg := utils.TemplateLifecycleMessage(kcmv1.ClusterTemplateKind, template.Name, lifecycle, now)
+		if lifecycle.IsEndOfLife(now) && (oldClusterDeployment == nil || oldClusterDeployment.Spec.Template != template.Name) {
+			return nil, errors.New(msg)
+		}
+		warnings = append(warnings, msg)
+	}
+
+	if oldClusterDeployment != nil {
+		for _, svc := range oldClusterDeployment.Spec.ServiceSpec.Services {
+			oldServiceTemplates = append(oldServiceTemplates, svc.Template)
+		}
+	}
+
+	for _, svc := range clusterDeployment.Spec.ServiceSpec.Services {
+		if svc.Disable {
+			continue
+		}
+
+		svcTpl := new(kcmv1.ServiceTemplate)
+		if err := v.Get(ctx, client.ObjectKey{Namespace: clusterDeployment.Namespace, Name: svc.Template}, svcTpl); err != nil {
+			return nil, fmt.Errorf("failed to get ServiceTemplate %s/%s: %w", clusterDeployment.Namespace, svc.Template, err)
+		}
+
+		lifecycle, err := utils.GetTemplateLifecycle(ctx, v.Client, svcTpl)
+		if err != nil {
+			return nil, err
+		}
+		if !lifecycle.IsDeprecated() {
+			continue
+		}
+
+		msg := utils.TemplateLifecycleMessage(kcmv1.ServiceTemplateKind, svcTpl.Name, lifecycle, now)
+		if lifecycle.IsEndOfLife(now) && !slices.Contains(oldServiceTemplates, svcTpl.Name) {
+			return nil, errors.New(msg)
+		}
+		warnings = append(warnings, msg)
+	}
+
+	return warnings, nil
 }
 
 func validateK8sCompatibility(ctx context.Context, cl client.Client, template *kcmv1.ClusterTemplate, mc *kcmv1.ClusterDeployment) error {
@@ -202,12 +301,41 @@ func (v *ClusterDeploymentValidator) Default(ctx context.Context, obj runtime.Ob
 		return nil
 	}
 
+	// the template defaults would override the ClusterDeploymentDefaults layered under the spec.config
+	hasDefaults, err := v.hasClusterDeploymentDefaults(ctx, clusterDeployment)
+	if err != nil {
+		return fmt.Errorf("failed to check ClusterDeploymentDefaults for the clusterDeployment: %w", err)
+	}
+	if hasDefaults {
+		return nil
+	}
+
 	clusterDeployment.Spec.DryRun = true
 	clusterDeployment.Spec.Config = &apiextensionsv1.JSON{Raw: template.Status.Config.Raw}
 
 	return nil
 }
 
+// hasClusterDeploymentDefaults reports whether any of the ClusterDeploymentDefaults selects the given ClusterDeployment.
+func (v *ClusterDeploymentValidator) hasClusterDeploymentDefaults(ctx context.Context, clusterDeployment *kcmv1.ClusterDeployment) (bool, error) {
+	defaultsList := &kcmv1.ClusterDeploymentDefaultsList{}
+	if err := v.List(ctx, defaultsList, client.InNamespace(clusterDeployment.Namespace)); err != nil {
+		return false, err
+	}
+
+	for _, defaults := range defaultsList.Items {
+		matches, err := defaults.Matches(clusterDeployment.Labels)
+		if err != nil {
+			return false, err
+		}
+		if matches {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
 func (v *ClusterDeploymentValidator) getClusterDeploymentTemplate(ctx context.Context, templateNamespace, templateName string) (tpl *kcmv1.ClusterTemplate, err error) {
 	tpl = new(kcmv1.ClusterTemplate)
 	return tpl, v.Get(ctx, client.ObjectKey{Namespace: templateNamespace, Name: templateName}, tpl)
@@ -18,10 +18,13 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	. "github.com/onsi/gomega"
 	admissionv1 "k8s.io/api/admission/v1"
 	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
@@ -31,6 +34,7 @@ import (
 	"github.com/K0rdent/kcm/test/objects/credential"
 	"github.com/K0rdent/kcm/test/objects/management"
 	"github.com/K0rdent/kcm/test/objects/template"
+	"github.com/K0rdent/kcm/test/objects/templatechain"
 	"github.com/K0rdent/kcm/test/scheme"
 )
 
@@ -41,6 +45,9 @@ var (
 
 	testNamespace = "test"
 
+	pastEOL   = metav1.NewTime(time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC))
+	futureEOL = metav1.NewTime(time.Date(2999, time.January, 1, 0, 0, 0, 0, time.UTC))
+
 	mgmt = management.NewManagement(
 		management.WithAvailableProviders(v1alpha1.Providers{
 			"infrastructure-aws",
@@ -196,6 +203,87 @@ func TestClusterDeploymentValidateCreate(t *testing.T) {
 				),
 			},
 		},
+		{
+			name: "should fail if the ServiceTemplate of the cluster-autoscaler is not found",
+			ClusterDeployment: clusterdeployment.NewClusterDeployment(
+				clusterdeployment.WithClusterTemplate(testTemplateName),
+				clusterdeployment.WithCredential(testCredentialName),
+				clusterdeployment.WithAutoscaler(testSvcTemplate1Name),
+			),
+			existingObjects: []runtime.Object{
+				mgmt,
+				cred,
+				template.NewClusterTemplate(
+					template.WithName(testTemplateName),
+					template.WithProvidersStatus(
+						"infrastructure-aws",
+						"control-plane-k0smotron",
+						"bootstrap-k0smotron",
+					),
+					template.WithValidationStatus(v1alpha1.TemplateValidationStatus{Valid: true}),
+				),
+			},
+			err: fmt.Sprintf("the ClusterDeployment is invalid: servicetemplates.k0rdent.mirantis.com \"%s\" not found", testSvcTemplate1Name),
+		},
+		{
+			name: "should fail if a service collides with the cluster-autoscaler release",
+			ClusterDeployment: clusterdeployment.NewClusterDeployment(
+				clusterdeployment.WithClusterTemplate(testTemplateName),
+				clusterdeployment.WithCredential(testCredentialName),
+				clusterdeployment.WithAutoscaler(testSvcTemplate1Name),
+				func(cd *v1alpha1.ClusterDeployment) {
+					cd.Spec.Autoscaling.Autoscaler.Namespace = "kube-system"
+					cd.Spec.ServiceSpec.Services = []v1alpha1.Service{{
+						Name:      v1alpha1.ClusterAutoscalerServiceName,
+						Namespace: "kube-system",
+						Template:  testSvcTemplate1Name,
+					}}
+				},
+			),
+			existingObjects: []runtime.Object{
+				mgmt,
+				cred,
+				template.NewClusterTemplate(
+					template.WithName(testTemplateName),
+					template.WithProvidersStatus(
+						"infrastructure-aws",
+						"control-plane-k0smotron",
+						"bootstrap-k0smotron",
+					),
+					template.WithValidationStatus(v1alpha1.TemplateValidationStatus{Valid: true}),
+				),
+				template.NewServiceTemplate(
+					template.WithName(testSvcTemplate1Name),
+					template.WithValidationStatus(v1alpha1.TemplateValidationStatus{Valid: true}),
+				),
+			},
+			err: "the ClusterDeployment is invalid: service kube-system/cluster-autoscaler collides with the cluster-autoscaler release",
+		},
+		{
+			name: "should succeed with the cluster-autoscaler",
+			ClusterDeployment: clusterdeployment.NewClusterDeployment(
+				clusterdeployment.WithClusterTemplate(testTemplateName),
+				clusterdeployment.WithCredential(testCredentialName),
+				clusterdeployment.WithAutoscaler(testSvcTemplate1Name),
+			),
+			existingObjects: []runtime.Object{
+				mgmt,
+				cred,
+				template.NewClusterTemplate(
+					template.WithName(testTemplateName),
+					template.WithProvidersStatus(
+						"infrastructure-aws",
+						"control-plane-k0smotron",
+						"bootstrap-k0smotron",
+					),
+					template.WithValidationStatus(v1alpha1.TemplateValidationStatus{Valid: true}),
+				),
+				template.NewServiceTemplate(
+					template.WithName(testSvcTemplate1Name),
+					template.WithValidationStatus(v1alpha1.TemplateValidationStatus{Valid: true}),
+				),
+			},
+		},
 		{
 			name: "cluster template k8s version does not satisfy service template constraints",
 			ClusterDeployment: clusterdeployment.NewClusterDeployment(
@@ -304,6 +392,167 @@ func TestClusterDeploymentValidateCreate(t *testing.T) {
 			},
 			err: "the ClusterDeployment is invalid: wrong kind of the ClusterIdentity \"SomeOtherDummyClusterStaticIdentity\" for provider \"infrastructure-aws\"",
 		},
+		{
+			name: "should succeed if the Secret credential matches the gcp template providers",
+			ClusterDeployment: clusterdeployment.NewClusterDeployment(
+				clusterdeployment.WithClusterTemplate(testTemplateName),
+				clusterdeployment.WithCredential(testCredentialName),
+			),
+			existingObjects: []runtime.Object{
+				credential.NewCredential(
+					credential.WithName(testCredentialName),
+					credential.WithReady(true),
+					credential.WithIdentityRef(
+						&corev1.ObjectReference{
+							Kind: "Secret",
+							Name: "gcp-cloud-sa",
+						}),
+				),
+				management.NewManagement(
+					management.WithAvailableProviders(v1alpha1.Providers{
+						"infrastructure-gcp",
+						"control-plane-k0smotron",
+						"bootstrap-k0smotron",
+					}),
+				),
+				template.NewClusterTemplate(
+					template.WithName(testTemplateName),
+					template.WithProvidersStatus(
+						"infrastructure-gcp",
+						"control-plane-k0smotron",
+						"bootstrap-k0smotron",
+					),
+					template.WithValidationStatus(v1alpha1.TemplateValidationStatus{Valid: true}),
+				),
+			},
+		},
+		{
+			name: "should fail if credential and metal3 template providers doesn't match",
+			ClusterDeployment: clusterdeployment.NewClusterDeployment(
+				clusterdeployment.WithClusterTemplate(testTemplateName),
+				clusterdeployment.WithCredential(testCredentialName),
+			),
+			existingObjects: []runtime.Object{
+				cred,
+				management.NewManagement(
+					management.WithAvailableProviders(v1alpha1.Providers{
+						"infrastructure-metal3",
+						"control-plane-k0smotron",
+						"bootstrap-k0smotron",
+					}),
+				),
+				template.NewClusterTemplate(
+					template.WithName(testTemplateName),
+					template.WithProvidersStatus(
+						"infrastructure-metal3",
+						"control-plane-k0smotron",
+						"bootstrap-k0smotron",
+					),
+					template.WithValidationStatus(v1alpha1.TemplateValidationStatus{Valid: true}),
+				),
+			},
+			err: "the ClusterDeployment is invalid: wrong kind of the ClusterIdentity \"AWSClusterStaticIdentity\" for provider \"infrastructure-metal3\"",
+		},
+		{
+			name: "should succeed with warnings if the templates are deprecated",
+			ClusterDeployment: clusterdeployment.NewClusterDeployment(
+				clusterdeployment.WithClusterTemplate(testTemplateName),
+				clusterdeployment.WithCredential(testCredentialName),
+				clusterdeployment.WithServiceTemplate(testSvcTemplate1Name),
+			),
+			existingObjects: []runtime.Object{
+				mgmt,
+				cred,
+				template.NewClusterTemplate(
+					template.WithName(testTemplateName),
+					template.WithProvidersStatus(
+						"infrastructure-aws",
+						"control-plane-k0smotron",
+						"bootstrap-k0smotron",
+					),
+					template.WithValidationStatus(v1alpha1.TemplateValidationStatus{Valid: true}),
+					template.WithLifecycle(&v1alpha1.TemplateLifecycle{
+						Deprecated:         true,
+						DeprecationMessage: "use the new one",
+						Replacement:        newTemplateName,
+					}),
+				),
+				template.NewServiceTemplate(
+					template.WithName(testSvcTemplate1Name),
+					template.WithValidationStatus(v1alpha1.TemplateValidationStatus{Valid: true}),
+				),
+				templatechain.NewServiceTemplateChain(
+					templatechain.WithNamespace(metav1.NamespaceDefault),
+					templatechain.WithSupportedTemplates([]v1alpha1.SupportedTemplate{
+						{
+							Name:      testSvcTemplate1Name,
+							Lifecycle: &v1alpha1.TemplateLifecycle{EndOfLife: &futureEOL},
+						},
+					}),
+				),
+			},
+			warnings: admission.Warnings{
+				fmt.Sprintf("ClusterTemplate %s is deprecated: use the new one. Consider using %s instead", testTemplateName, newTemplateName),
+				fmt.Sprintf("ServiceTemplate %s is deprecated and will reach its end-of-life on 2999-01-01", testSvcTemplate1Name),
+			},
+		},
+		{
+			name: "should fail if the cluster template has reached its end-of-life",
+			ClusterDeployment: clusterdeployment.NewClusterDeployment(
+				clusterdeployment.WithClusterTemplate(testTemplateName),
+				clusterdeployment.WithCredential(testCredentialName),
+			),
+			existingObjects: []runtime.Object{
+				mgmt,
+				cred,
+				template.NewClusterTemplate(
+					template.WithName(testTemplateName),
+					template.WithProvidersStatus(
+						"infrastructure-aws",
+						"control-plane-k0smotron",
+						"bootstrap-k0smotron",
+					),
+					template.WithValidationStatus(v1alpha1.TemplateValidationStatus{Valid: true}),
+					template.WithLifecycle(&v1alpha1.TemplateLifecycle{EndOfLife: &pastEOL}),
+				),
+			},
+			err: fmt.Sprintf("the ClusterDeployment is invalid: ClusterTemplate %s has reached its end-of-life on 2020-01-01", testTemplateName),
+		},
+		{
+			name: "should fail if the service template has reached its end-of-life in the chain",
+			ClusterDeployment: clusterdeployment.NewClusterDeployment(
+				clusterdeployment.WithClusterTemplate(testTemplateName),
+				clusterdeployment.WithCredential(testCredentialName),
+				clusterdeployment.WithServiceTemplate(testSvcTemplate1Name),
+			),
+			existingObjects: []runtime.Object{
+				mgmt,
+				cred,
+				template.NewClusterTemplate(
+					template.WithName(testTemplateName),
+					template.WithProvidersStatus(
+						"infrastructure-aws",
+						"control-plane-k0smotron",
+						"bootstrap-k0smotron",
+					),
+					template.WithValidationStatus(v1alpha1.TemplateValidationStatus{Valid: true}),
+				),
+				template.NewServiceTemplate(
+					template.WithName(testSvcTemplate1Name),
+					template.WithValidationStatus(v1alpha1.TemplateValidationStatus{Valid: true}),
+				),
+				templatechain.NewServiceTemplateChain(
+					templatechain.WithNamespace(metav1.NamespaceDefault),
+					templatechain.WithSupportedTemplates([]v1alpha1.SupportedTemplate{
+						{
+							Name:      testSvcTemplate1Name,
+							Lifecycle: &v1alpha1.TemplateLifecycle{EndOfLife: &pastEOL},
+						},
+					}),
+				),
+			},
+			err: fmt.Sprintf("the ClusterDeployment is invalid: ServiceTemplate %s has reached its end-of-life on 2020-01-01", testSvcTemplate1Name),
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -604,6 +853,59 @@ func TestClusterDeploymentValidateUpdate(t *testing.T) {
 			},
 			err: "the ClusterDeployment is invalid: the template is not valid: validation error example",
 		},
+		{
+			name: "update spec.template: should fail if the new cluster template has reached its end-of-life",
+			oldClusterDeployment: clusterdeployment.NewClusterDeployment(
+				clusterdeployment.WithClusterTemplate(testTemplateName),
+				clusterdeployment.WithCredential(testCredentialName),
+				clusterdeployment.WithAvailableUpgrades([]string{newTemplateName}),
+			),
+			newClusterDeployment: clusterdeployment.NewClusterDeployment(
+				clusterdeployment.WithClusterTemplate(newTemplateName),
+				clusterdeployment.WithCredential(testCredentialName),
+			),
+			existingObjects: []runtime.Object{
+				mgmt, cred,
+				template.NewClusterTemplate(
+					template.WithName(newTemplateName),
+					template.WithProvidersStatus(
+						"infrastructure-aws",
+						"control-plane-k0smotron",
+						"bootstrap-k0smotron",
+					),
+					template.WithValidationStatus(v1alpha1.TemplateValidationStatus{Valid: true}),
+					template.WithLifecycle(&v1alpha1.TemplateLifecycle{EndOfLife: &pastEOL}),
+				),
+			},
+			err: fmt.Sprintf("the ClusterDeployment is invalid: ClusterTemplate %s has reached its end-of-life on 2020-01-01", newTemplateName),
+		},
+		{
+			name: "should succeed with warnings if the unchanged cluster template has reached its end-of-life",
+			oldClusterDeployment: clusterdeployment.NewClusterDeployment(
+				clusterdeployment.WithClusterTemplate(testTemplateName),
+				clusterdeployment.WithConfig(`{"foo":"bar"}`),
+				clusterdeployment.WithCredential(testCredentialName),
+			),
+			newClusterDeployment: clusterdeployment.NewClusterDeployment(
+				clusterdeployment.WithClusterTemplate(testTemplateName),
+				clusterdeployment.WithConfig(`{"a":"b"}`),
+				clusterdeployment.WithCredential(testCredentialName),
+			),
+			existingObjects: []runtime.Object{
+				mgmt, cred,
+				template.NewClusterTemplate(
+					template.WithName(testTemplateName),
+					template.WithProvidersStatus(
+						"infrastructure-aws",
+						"control-plane-k0smotron",
+						"bootstrap-k0smotron",
+					),
+					template.WithValidationStatus(v1alpha1.TemplateValidationStatus{Valid: true}),
+					template.WithLifecycle(&v1alpha1.TemplateLifecycle{EndOfLife: &pastEOL}),
+				),
+			},
+			warnings: admission.Warnings{fmt.Sprintf("ClusterTemplate %s has reached its end-of-life on 2020-01-01", testTemplateName)},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -688,6 +990,25 @@ func TestClusterDeploymentDefault(t *testing.T) {
 				),
 			},
 		},
+		{
+			name:   "should not set defaults: ClusterDeploymentDefaults select the clusterDeployment",
+			input:  clusterdeployment.NewClusterDeployment(clusterdeployment.WithClusterTemplate(testTemplateName)),
+			output: clusterdeployment.NewClusterDeployment(clusterdeployment.WithClusterTemplate(testTemplateName)),
+			existingObjects: []runtime.Object{
+				mgmt,
+				template.NewClusterTemplate(
+					template.WithName(testTemplateName),
+					template.WithValidationStatus(v1alpha1.TemplateValidationStatus{Valid: true}),
+					template.WithConfigStatus(clusterDeploymentConfig),
+				),
+				&v1alpha1.ClusterDeploymentDefaults{
+					ObjectMeta: metav1.ObjectMeta{Name: "defaults", Namespace: clusterdeployment.DefaultNamespace},
+					Spec: v1alpha1.ClusterDeploymentDefaultsSpec{
+						Config: &apiextensionsv1.JSON{Raw: []byte(`{"region":"us-east-2"}`)},
+					},
+				},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -31,6 +31,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 
 	kcmv1 "github.com/K0rdent/kcm/api/v1alpha1"
+	"github.com/K0rdent/kcm/internal/tracing"
 )
 
 type ManagementValidator struct {
@@ -43,7 +44,7 @@ func (v *ManagementValidator) SetupWebhookWithManager(mgr ctrl.Manager) error {
 	v.Client = mgr.GetClient()
 	return ctrl.NewWebhookManagedBy(mgr).
 		For(&kcmv1.Management{}).
-		WithValidator(v).
+		WithValidator(tracing.Validator("Management", v)).
 		WithDefaulter(v).
 		Complete()
 }
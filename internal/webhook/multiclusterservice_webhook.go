@@ -27,6 +27,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 
 	"github.com/K0rdent/kcm/api/v1alpha1"
+	"github.com/K0rdent/kcm/internal/tracing"
 )
 
 type MultiClusterServiceValidator struct {
@@ -41,7 +42,7 @@ func (v *MultiClusterServiceValidator) SetupWebhookWithManager(mgr ctrl.Manager)
 	v.Client = mgr.GetClient()
 	return ctrl.NewWebhookManagedBy(mgr).
 		For(&v1alpha1.MultiClusterService{}).
-		WithValidator(v).
+		WithValidator(tracing.Validator("MultiClusterService", v)).
 		WithDefaulter(v).
 		Complete()
 }
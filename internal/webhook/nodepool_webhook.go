@@ -0,0 +1,106 @@
+// Copyright 2024
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	kcmv1 "github.com/K0rdent/kcm/api/v1alpha1"
+	"github.com/K0rdent/kcm/internal/nodepool"
+	"github.com/K0rdent/kcm/internal/scope"
+	"github.com/K0rdent/kcm/internal/tracing"
+)
+
+const invalidNodePoolMsg = "the NodePool is invalid"
+
+type NodePoolValidator struct {
+	client.Client
+}
+
+// SetupWebhookWithManager will setup the manager to manage the webhooks
+func (v *NodePoolValidator) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	v.Client = mgr.GetClient()
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&kcmv1.NodePool{}).
+		WithValidator(tracing.Validator("NodePool", scope.Validator(v))).
+		Complete()
+}
+
+var _ webhook.CustomValidator = &NodePoolValidator{}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type.
+func (v *NodePoolValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	nodePool, ok := obj.(*kcmv1.NodePool)
+	if !ok {
+		return nil, apierrors.NewBadRequest(fmt.Sprintf("expected NodePool but got a %T", obj))
+	}
+
+	return v.validate(ctx, nodePool)
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type.
+// Only the changes of the spec are validated, so the metadata of the existing NodePools may still be updated.
+func (v *NodePoolValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	oldNodePool, ok := oldObj.(*kcmv1.NodePool)
+	if !ok {
+		return nil, apierrors.NewBadRequest(fmt.Sprintf("expected NodePool but got a %T", oldObj))
+	}
+	newNodePool, ok := newObj.(*kcmv1.NodePool)
+	if !ok {
+		return nil, apierrors.NewBadRequest(fmt.Sprintf("expected NodePool but got a %T", newObj))
+	}
+
+	if equality.Semantic.DeepEqual(oldNodePool.Spec, newNodePool.Spec) {
+		return nil, nil
+	}
+
+	return v.validate(ctx, newNodePool)
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type.
+func (*NodePoolValidator) ValidateDelete(context.Context, runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// validate rejects the NodePools which cannot be rendered for the cluster of their ClusterDeployment,
+// the NodePools of the ClusterDeployments which do not exist yet are checked by the controller.
+func (v *NodePoolValidator) validate(ctx context.Context, nodePool *kcmv1.NodePool) (admission.Warnings, error) {
+	clusterDeployment := &kcmv1.ClusterDeployment{}
+	if err := v.Get(ctx, client.ObjectKey{Namespace: nodePool.Namespace, Name: nodePool.Spec.ClusterDeployment}, clusterDeployment); err != nil {
+		if apierrors.IsNotFound(err) {
+			return admission.Warnings{fmt.Sprintf("ClusterDeployment %s is not found", nodePool.Spec.ClusterDeployment)}, nil
+		}
+		return nil, fmt.Errorf("failed to get ClusterDeployment %s/%s: %w", nodePool.Namespace, nodePool.Spec.ClusterDeployment, err)
+	}
+
+	if err := nodepool.CheckSupported(ctx, v.Client, nodePool, clusterDeployment); err != nil {
+		if errors.Is(err, nodepool.ErrUnsupportedCluster) {
+			return nil, fmt.Errorf("%s: %w", invalidNodePoolMsg, err)
+		}
+		return nil, err
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,186 @@
+// Copyright 2024
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+	clusterapiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	expv1 "sigs.k8s.io/cluster-api/exp/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/K0rdent/kcm/api/v1alpha1"
+)
+
+func TestNodePoolValidate(t *testing.T) {
+	g := NewWithT(t)
+
+	ctx := admission.NewContextWithRequest(context.Background(), admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{Operation: admissionv1.Create}})
+
+	const namespace = "kcm-system"
+
+	testScheme := runtime.NewScheme()
+	g.Expect(clientgoscheme.AddToScheme(testScheme)).To(Succeed())
+	g.Expect(v1alpha1.AddToScheme(testScheme)).To(Succeed())
+	g.Expect(clusterapiv1beta1.AddToScheme(testScheme)).To(Succeed())
+	g.Expect(expv1.AddToScheme(testScheme)).To(Succeed())
+
+	newClusterDeployment := func(name, template string) *v1alpha1.ClusterDeployment {
+		return &v1alpha1.ClusterDeployment{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Spec:       v1alpha1.ClusterDeploymentSpec{Template: template},
+		}
+	}
+	newClusterTemplate := func(name string, providers ...string) *v1alpha1.ClusterTemplate {
+		return &v1alpha1.ClusterTemplate{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Status:     v1alpha1.ClusterTemplateStatus{Providers: providers},
+		}
+	}
+	newMachineDeployment := func(clusterDeployment, bootstrapKind string) *clusterapiv1beta1.MachineDeployment {
+		return &clusterapiv1beta1.MachineDeployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      clusterDeployment + "-md",
+				Namespace: namespace,
+				Labels:    map[string]string{v1alpha1.FluxHelmChartNameKey: clusterDeployment},
+			},
+			Spec: clusterapiv1beta1.MachineDeploymentSpec{
+				Template: clusterapiv1beta1.MachineTemplateSpec{Spec: clusterapiv1beta1.MachineSpec{
+					Bootstrap: clusterapiv1beta1.Bootstrap{ConfigRef: &corev1.ObjectReference{Kind: bootstrapKind, Name: clusterDeployment + "-config"}},
+				}},
+			},
+		}
+	}
+	newNodePool := func(clusterDeployment string, labels map[string]string) *v1alpha1.NodePool {
+		return &v1alpha1.NodePool{
+			ObjectMeta: metav1.ObjectMeta{Name: "gpu", Namespace: namespace},
+			Spec:       v1alpha1.NodePoolSpec{ClusterDeployment: clusterDeployment, Labels: labels},
+		}
+	}
+
+	newMachinePool := func(clusterDeployment string, configRef *corev1.ObjectReference) *expv1.MachinePool {
+		return &expv1.MachinePool{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      clusterDeployment + "-mp",
+				Namespace: namespace,
+				Labels:    map[string]string{v1alpha1.FluxHelmChartNameKey: clusterDeployment},
+			},
+			Spec: expv1.MachinePoolSpec{
+				Template: clusterapiv1beta1.MachineTemplateSpec{Spec: clusterapiv1beta1.MachineSpec{
+					Bootstrap: clusterapiv1beta1.Bootstrap{ConfigRef: configRef},
+				}},
+			},
+		}
+	}
+
+	existingObjects := []runtime.Object{
+		newClusterTemplate("aws-standalone-cp", "infrastructure-aws", "bootstrap-k0sproject-k0smotron"),
+		newClusterTemplate("aws-eks", "infrastructure-aws"),
+		newClusterTemplate("gcp-gke", "infrastructure-gcp"),
+		newClusterTemplate("adopted-cluster", "infrastructure-internal"),
+		newClusterDeployment("aws", "aws-standalone-cp"),
+		newClusterDeployment("eks", "aws-eks"),
+		newClusterDeployment("gke", "gcp-gke"),
+		newClusterDeployment("adopted", "adopted-cluster"),
+		newClusterDeployment("k0s-mp", "aws-standalone-cp"),
+		newMachineDeployment("aws", "K0sWorkerConfigTemplate"),
+		newMachineDeployment("eks", "EKSConfigTemplate"),
+		newMachinePool("gke", nil),
+		newMachinePool("k0s-mp", &corev1.ObjectReference{Kind: "K0sWorkerConfig", Name: "k0s-mp-config"}),
+	}
+
+	tests := []struct {
+		name     string
+		nodePool *v1alpha1.NodePool
+		err      string
+		warnings admission.Warnings
+	}{
+		{
+			name:     "should succeed with a warning if the ClusterDeployment does not exist",
+			nodePool: newNodePool("missing", nil),
+			warnings: admission.Warnings{"ClusterDeployment missing is not found"},
+		},
+		{
+			name:     "should fail if the cluster is adopted",
+			nodePool: newNodePool("adopted", nil),
+			err:      "the NodePool is invalid: the NodePool is not supported for the cluster: the nodes of the adopted cluster adopted are not managed by Cluster API",
+		},
+		{
+			name:     "should succeed if the workers of the cluster are MachinePools",
+			nodePool: newNodePool("gke", nil),
+		},
+		{
+			name:     "should fail if the node labels are set for MachinePools without a k0s bootstrap config",
+			nodePool: newNodePool("gke", map[string]string{"gpu": "true"}),
+			err: "the NodePool is invalid: the NodePool is not supported for the cluster: node labels and taints of the MachinePools " +
+				"are only supported with the K0sWorkerConfig bootstrap configs, set them in the machineTemplate instead",
+		},
+		{
+			name:     "should succeed with the node labels for MachinePools with a k0s bootstrap config",
+			nodePool: newNodePool("k0s-mp", map[string]string{"gpu": "true"}),
+		},
+		{
+			name:     "should fail if the node labels are set for a non-k0s bootstrap template",
+			nodePool: newNodePool("eks", map[string]string{"gpu": "true"}),
+			err:      "the NodePool is invalid: the NodePool is not supported for the cluster: node labels and taints are only supported with the K0sWorkerConfigTemplate bootstrap templates",
+		},
+		{
+			name:     "should succeed without the node labels for a non-k0s bootstrap template",
+			nodePool: newNodePool("eks", nil),
+		},
+		{
+			name:     "should succeed with the node labels for a k0s bootstrap template",
+			nodePool: newNodePool("aws", map[string]string{"gpu": "true"}),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(_ *testing.T) {
+			c := fake.NewClientBuilder().WithScheme(testScheme).WithRuntimeObjects(existingObjects...).Build()
+			validator := &NodePoolValidator{Client: c}
+			warn, err := validator.ValidateCreate(ctx, tt.nodePool)
+			if tt.err != "" {
+				g.Expect(err).To(MatchError(tt.err))
+			} else {
+				g.Expect(err).To(Succeed())
+			}
+			g.Expect(warn).To(Equal(tt.warnings))
+		})
+	}
+
+	t.Run("should allow the updates of the metadata of an unsupported NodePool", func(_ *testing.T) {
+		c := fake.NewClientBuilder().WithScheme(testScheme).WithRuntimeObjects(existingObjects...).Build()
+		validator := &NodePoolValidator{Client: c}
+
+		oldNodePool := newNodePool("adopted", nil)
+		newNodePool := oldNodePool.DeepCopy()
+		newNodePool.OwnerReferences = []metav1.OwnerReference{{Kind: v1alpha1.ClusterDeploymentKind, Name: "adopted"}}
+		_, err := validator.ValidateUpdate(ctx, oldNodePool, newNodePool)
+		g.Expect(err).To(Succeed())
+
+		newNodePool.Spec.Replicas = ptr.To[int32](3)
+		_, err = validator.ValidateUpdate(ctx, oldNodePool, newNodePool)
+		g.Expect(err).To(HaveOccurred())
+	})
+}
@@ -0,0 +1,180 @@
+// Copyright 2024
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	kcmv1 "github.com/K0rdent/kcm/api/v1alpha1"
+	providersloader "github.com/K0rdent/kcm/internal/providers"
+	"github.com/K0rdent/kcm/internal/tracing"
+)
+
+var errInvalidProviderDefinition = errors.New("the ProviderDefinition is invalid")
+
+type ProviderDefinitionValidator struct {
+	client.Client
+}
+
+// SetupWebhookWithManager will setup the manager to manage the webhooks
+func (v *ProviderDefinitionValidator) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	v.Client = mgr.GetClient()
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&kcmv1.ProviderDefinition{}).
+		WithValidator(tracing.Validator("ProviderDefinition", v)).
+		Complete()
+}
+
+var _ webhook.CustomValidator = &ProviderDefinitionValidator{}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (v *ProviderDefinitionValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	definition, ok := obj.(*kcmv1.ProviderDefinition)
+	if !ok {
+		return admission.Warnings{"Wrong object"}, apierrors.NewBadRequest(fmt.Sprintf("expected ProviderDefinition but got a %T", obj))
+	}
+
+	if err := validateProviderDefinition(definition); err != nil {
+		return nil, fmt.Errorf("%w: %w", errInvalidProviderDefinition, err)
+	}
+
+	if err := v.validateClusterGVKsNotClaimed(ctx, definition); err != nil {
+		return nil, fmt.Errorf("%w: %w", errInvalidProviderDefinition, err)
+	}
+
+	return nil, nil
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+func (v *ProviderDefinitionValidator) ValidateUpdate(ctx context.Context, _, newObj runtime.Object) (admission.Warnings, error) {
+	definition, ok := newObj.(*kcmv1.ProviderDefinition)
+	if !ok {
+		return admission.Warnings{"Wrong object"}, apierrors.NewBadRequest(fmt.Sprintf("expected ProviderDefinition but got a %T", newObj))
+	}
+
+	if err := validateProviderDefinition(definition); err != nil {
+		return nil, fmt.Errorf("%w: %w", errInvalidProviderDefinition, err)
+	}
+
+	if err := v.validateClusterGVKsNotClaimed(ctx, definition); err != nil {
+		return nil, fmt.Errorf("%w: %w", errInvalidProviderDefinition, err)
+	}
+
+	return nil, nil
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (v *ProviderDefinitionValidator) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	definition, ok := obj.(*kcmv1.ProviderDefinition)
+	if !ok {
+		return admission.Warnings{"Wrong object"}, apierrors.NewBadRequest(fmt.Sprintf("expected ProviderDefinition but got a %T", obj))
+	}
+
+	templates := &kcmv1.ClusterTemplateList{}
+	if err := v.List(ctx, templates,
+		client.MatchingFields{kcmv1.ClusterTemplateProvidersIndexKey: providersloader.InfraPrefix + definition.Name},
+		client.Limit(1),
+	); err != nil {
+		return nil, err
+	}
+
+	if len(templates.Items) > 0 {
+		return nil, fmt.Errorf("the provider %s is still in use by the ClusterTemplate %s/%s",
+			definition.Name, templates.Items[0].Namespace, templates.Items[0].Name)
+	}
+
+	return nil, nil
+}
+
+func validateProviderDefinition(definition *kcmv1.ProviderDefinition) error {
+	if providersloader.IsBuiltin(definition.Name) {
+		return fmt.Errorf("the provider %s is built-in and cannot be redefined", definition.Name)
+	}
+
+	for _, prefix := range []string{providersloader.InfraPrefix, providersloader.ProviderPrefix} {
+		if strings.HasPrefix(definition.Name, prefix) {
+			return fmt.Errorf("the name must be the short name of the provider without the %q prefix", prefix)
+		}
+	}
+
+	var errs error
+
+	if len(definition.Spec.ClusterGVKs) == 0 {
+		errs = errors.Join(errs, errors.New("at least one cluster GVK must be set"))
+	}
+
+	seen := make(map[schema.GroupVersionKind]struct{}, len(definition.Spec.ClusterGVKs))
+	for _, gvk := range definition.GetClusterGVKs() {
+		if gvk.Group == "" || gvk.Version == "" || gvk.Kind == "" {
+			errs = errors.Join(errs, fmt.Errorf("the cluster GVK %q must have the group, version and kind set", gvk))
+			continue
+		}
+		if _, ok := seen[gvk]; ok {
+			errs = errors.Join(errs, fmt.Errorf("the cluster GVK %q is duplicated", gvk))
+		}
+		seen[gvk] = struct{}{}
+	}
+
+	if len(definition.Spec.ClusterIdentityKinds) == 0 {
+		errs = errors.Join(errs, errors.New("at least one cluster identity kind must be set"))
+	}
+
+	for _, kind := range definition.Spec.ClusterIdentityKinds {
+		if kind == "" {
+			errs = errors.Join(errs, errors.New("the cluster identity kind must not be empty"))
+		}
+	}
+
+	return errs
+}
+
+// validateClusterGVKsNotClaimed ensures none of the cluster GVKs of the given definition
+// belongs to a built-in provider or to another ProviderDefinition, otherwise
+// the clusters of such kind would be attributed to the arbitrary one of the providers.
+func (v *ProviderDefinitionValidator) validateClusterGVKsNotClaimed(ctx context.Context, definition *kcmv1.ProviderDefinition) error {
+	definitions := &kcmv1.ProviderDefinitionList{}
+	if err := v.List(ctx, definitions); err != nil {
+		return fmt.Errorf("failed to list ProviderDefinitions: %w", err)
+	}
+
+	var errs error
+	for _, gvk := range definition.GetClusterGVKs() {
+		if owner, ok := providersloader.BuiltinClusterGVKOwner(gvk); ok {
+			errs = errors.Join(errs, fmt.Errorf("the cluster GVK %q belongs to the built-in provider %s", gvk, owner))
+			continue
+		}
+
+		for _, other := range definitions.Items {
+			if other.Name != definition.Name && slices.Contains(other.GetClusterGVKs(), gvk) {
+				errs = errors.Join(errs, fmt.Errorf("the cluster GVK %q belongs to the ProviderDefinition %s", gvk, other.Name))
+				break
+			}
+		}
+	}
+
+	return errs
+}
@@ -0,0 +1,165 @@
+// Copyright 2024
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/K0rdent/kcm/api/v1alpha1"
+	"github.com/K0rdent/kcm/test/objects/template"
+	"github.com/K0rdent/kcm/test/scheme"
+)
+
+func newProviderDefinition(name string, gvks []v1alpha1.ClusterGVK, identityKinds ...string) *v1alpha1.ProviderDefinition {
+	return &v1alpha1.ProviderDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: v1alpha1.ProviderDefinitionSpec{
+			ClusterGVKs:          gvks,
+			ClusterIdentityKinds: identityKinds,
+		},
+	}
+}
+
+func TestProviderDefinitionValidateCreate(t *testing.T) {
+	g := NewWithT(t)
+
+	ctx := admission.NewContextWithRequest(context.Background(), admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{Operation: admissionv1.Create}})
+
+	inHouseCluster := v1alpha1.ClusterGVK{Group: "infrastructure.cluster.x-k8s.io", Version: "v1beta1", Kind: "InHouseCluster"}
+
+	tests := []struct {
+		name            string
+		definition      *v1alpha1.ProviderDefinition
+		existingObjects []runtime.Object
+		err             string
+	}{
+		{
+			name:       "should fail if the provider is built-in",
+			definition: newProviderDefinition("aws", []v1alpha1.ClusterGVK{inHouseCluster}, "Secret"),
+			err:        "the ProviderDefinition is invalid: the provider aws is built-in and cannot be redefined",
+		},
+		{
+			name:       "should fail if the name is prefixed",
+			definition: newProviderDefinition("infrastructure-inhouse", []v1alpha1.ClusterGVK{inHouseCluster}, "Secret"),
+			err:        `the ProviderDefinition is invalid: the name must be the short name of the provider without the "infrastructure-" prefix`,
+		},
+		{
+			name:       "should fail if the cluster GVKs are duplicated and identity kinds are not set",
+			definition: newProviderDefinition("inhouse", []v1alpha1.ClusterGVK{inHouseCluster, inHouseCluster}),
+			err: "the ProviderDefinition is invalid: " +
+				`the cluster GVK "infrastructure.cluster.x-k8s.io/v1beta1, Kind=InHouseCluster" is duplicated` + "\n" +
+				"at least one cluster identity kind must be set",
+		},
+		{
+			name: "should fail if the cluster GVK belongs to the built-in provider",
+			definition: newProviderDefinition("inhouse", []v1alpha1.ClusterGVK{
+				{Group: "infrastructure.cluster.x-k8s.io", Version: "v1beta2", Kind: "AWSCluster"},
+			}, "Secret"),
+			err: "the ProviderDefinition is invalid: " +
+				`the cluster GVK "infrastructure.cluster.x-k8s.io/v1beta2, Kind=AWSCluster" belongs to the built-in provider aws`,
+		},
+		{
+			name:       "should fail if the cluster GVK belongs to another definition",
+			definition: newProviderDefinition("inhouse", []v1alpha1.ClusterGVK{inHouseCluster}, "Secret"),
+			existingObjects: []runtime.Object{
+				newProviderDefinition("outhouse", []v1alpha1.ClusterGVK{inHouseCluster}, "Secret"),
+			},
+			err: "the ProviderDefinition is invalid: " +
+				`the cluster GVK "infrastructure.cluster.x-k8s.io/v1beta1, Kind=InHouseCluster" belongs to the ProviderDefinition outhouse`,
+		},
+		{
+			name:       "should succeed",
+			definition: newProviderDefinition("inhouse", []v1alpha1.ClusterGVK{inHouseCluster}, "Secret", "InHouseClusterIdentity"),
+			existingObjects: []runtime.Object{
+				newProviderDefinition("inhouse", []v1alpha1.ClusterGVK{inHouseCluster}, "Secret"),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(_ *testing.T) {
+			c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithRuntimeObjects(tt.existingObjects...).Build()
+			validator := &ProviderDefinitionValidator{Client: c}
+
+			_, err := validator.ValidateCreate(ctx, tt.definition)
+			if tt.err != "" {
+				g.Expect(err).To(MatchError(tt.err))
+			} else {
+				g.Expect(err).To(Succeed())
+			}
+		})
+	}
+}
+
+func TestProviderDefinitionValidateDelete(t *testing.T) {
+	g := NewWithT(t)
+
+	ctx := admission.NewContextWithRequest(context.Background(), admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{Operation: admissionv1.Delete}})
+
+	definition := newProviderDefinition("inhouse", []v1alpha1.ClusterGVK{{Group: "infrastructure.cluster.x-k8s.io", Version: "v1beta1", Kind: "InHouseCluster"}}, "Secret")
+
+	tests := []struct {
+		name            string
+		existingObjects []runtime.Object
+		err             string
+	}{
+		{
+			name: "should fail if the provider is in use",
+			existingObjects: []runtime.Object{
+				template.NewClusterTemplate(
+					template.WithName("inhouse-standalone-cp"),
+					template.WithNamespace("kcm-system"),
+					template.WithProvidersStatus("infrastructure-inhouse", "control-plane-k0smotron"),
+				),
+			},
+			err: "the provider inhouse is still in use by the ClusterTemplate kcm-system/inhouse-standalone-cp",
+		},
+		{
+			name: "should succeed",
+			existingObjects: []runtime.Object{
+				template.NewClusterTemplate(
+					template.WithName("aws-standalone-cp"),
+					template.WithProvidersStatus("infrastructure-aws", "control-plane-k0smotron"),
+				),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(_ *testing.T) {
+			c := fake.NewClientBuilder().
+				WithScheme(scheme.Scheme).
+				WithRuntimeObjects(tt.existingObjects...).
+				WithIndex(&v1alpha1.ClusterTemplate{}, v1alpha1.ClusterTemplateProvidersIndexKey, v1alpha1.ExtractProvidersFromClusterTemplate).
+				Build()
+			validator := &ProviderDefinitionValidator{Client: c}
+
+			_, err := validator.ValidateDelete(ctx, definition)
+			if tt.err != "" {
+				g.Expect(err).To(MatchError(tt.err))
+			} else {
+				g.Expect(err).To(Succeed())
+			}
+		})
+	}
+}
@@ -29,6 +29,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 
 	kcmv1 "github.com/K0rdent/kcm/api/v1alpha1"
+	"github.com/K0rdent/kcm/internal/tracing"
 )
 
 var errManagementIsNotFound = errors.New("no Management object found")
@@ -42,7 +43,7 @@ func (v *ReleaseValidator) SetupWebhookWithManager(mgr ctrl.Manager) error {
 	v.Client = mgr.GetClient()
 	return ctrl.NewWebhookManagedBy(mgr).
 		For(&kcmv1.Release{}).
-		WithValidator(v).
+		WithValidator(tracing.Validator("Release", v)).
 		Complete()
 }
 
@@ -21,6 +21,7 @@ import (
 	"slices"
 	"strings"
 
+	sourcev1 "github.com/fluxcd/source-controller/api/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -32,6 +33,8 @@ import (
 
 	"github.com/K0rdent/kcm/api/v1alpha1"
 	"github.com/K0rdent/kcm/internal/helm"
+	"github.com/K0rdent/kcm/internal/scope"
+	"github.com/K0rdent/kcm/internal/tracing"
 )
 
 var errTemplateDeletionForbidden = errors.New("template deletion is forbidden")
@@ -53,8 +56,8 @@ func (v *ClusterTemplateValidator) SetupWebhookWithManager(mgr ctrl.Manager) err
 	v.templateChainKind = v1alpha1.ClusterTemplateChainKind
 	return ctrl.NewWebhookManagedBy(mgr).
 		For(&v1alpha1.ClusterTemplate{}).
-		WithValidator(v).
-		WithDefaulter(v).
+		WithValidator(tracing.Validator("ClusterTemplate", scope.Validator(v))).
+		WithDefaulter(scope.Defaulter(v)).
 		Complete()
 }
 
@@ -120,8 +123,8 @@ func (v *ServiceTemplateValidator) SetupWebhookWithManager(mgr ctrl.Manager) err
 	v.templateChainKind = v1alpha1.ServiceTemplateChainKind
 	return ctrl.NewWebhookManagedBy(mgr).
 		For(&v1alpha1.ServiceTemplate{}).
-		WithValidator(v).
-		WithDefaulter(v).
+		WithValidator(tracing.Validator("ServiceTemplate", scope.Validator(v))).
+		WithDefaulter(scope.Defaulter(v)).
 		Complete()
 }
 
@@ -200,7 +203,7 @@ func (v *ProviderTemplateValidator) SetupWebhookWithManager(mgr ctrl.Manager) er
 	v.templateKind = v1alpha1.ProviderTemplateKind
 	return ctrl.NewWebhookManagedBy(mgr).
 		For(&v1alpha1.ProviderTemplate{}).
-		WithValidator(v).
+		WithValidator(tracing.Validator("ProviderTemplate", v)).
 		WithDefaulter(v).
 		Complete()
 }
@@ -323,7 +326,13 @@ func setHelmChartDefaults(helmSpec *v1alpha1.HelmSpec) {
 		return
 	}
 	chartSpec := helmSpec.ChartSpec
-	if chartSpec.SourceRef.Name == "" && chartSpec.SourceRef.Kind == "" {
+	switch {
+	case helmSpec.Registry != "":
+		chartSpec.SourceRef = sourcev1.LocalHelmChartSourceReference{
+			Kind: sourcev1.HelmRepositoryKind,
+			Name: v1alpha1.TemplateRegistryRepoName(helmSpec.Registry),
+		}
+	case chartSpec.SourceRef.Name == "" && chartSpec.SourceRef.Kind == "":
 		chartSpec.SourceRef = v1alpha1.DefaultSourceRef
 	}
 	if chartSpec.Interval.Duration == 0 {
@@ -18,7 +18,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
 
+	"github.com/Masterminds/semver/v3"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -27,6 +29,8 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 
 	"github.com/K0rdent/kcm/api/v1alpha1"
+	"github.com/K0rdent/kcm/internal/scope"
+	"github.com/K0rdent/kcm/internal/tracing"
 )
 
 var errInvalidTemplateChainSpec = errors.New("the template chain spec is invalid")
@@ -39,8 +43,8 @@ func (in *ClusterTemplateChainValidator) SetupWebhookWithManager(mgr ctrl.Manage
 	in.Client = mgr.GetClient()
 	return ctrl.NewWebhookManagedBy(mgr).
 		For(&v1alpha1.ClusterTemplateChain{}).
-		WithValidator(in).
-		WithDefaulter(in).
+		WithValidator(tracing.Validator("ClusterTemplateChain", scope.Validator(in))).
+		WithDefaulter(scope.Defaulter(in)).
 		Complete()
 }
 
@@ -64,8 +68,17 @@ func (*ClusterTemplateChainValidator) ValidateCreate(_ context.Context, obj runt
 }
 
 // ValidateUpdate implements webhook.Validator so a webhook will be registered for the type.
-func (*ClusterTemplateChainValidator) ValidateUpdate(_ context.Context, _, _ runtime.Object) (admission.Warnings, error) {
-	return nil, nil
+func (*ClusterTemplateChainValidator) ValidateUpdate(_ context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	oldChain, ok := oldObj.(*v1alpha1.ClusterTemplateChain)
+	if !ok {
+		return admission.Warnings{"Wrong object"}, apierrors.NewBadRequest(fmt.Sprintf("expected ClusterTemplateChain but got a %T", oldObj))
+	}
+	newChain, ok := newObj.(*v1alpha1.ClusterTemplateChain)
+	if !ok {
+		return admission.Warnings{"Wrong object"}, apierrors.NewBadRequest(fmt.Sprintf("expected ClusterTemplateChain but got a %T", newObj))
+	}
+
+	return validateTemplateChainUpdate(oldChain.Spec, newChain.Spec)
 }
 
 // ValidateDelete implements webhook.Validator so a webhook will be registered for the type.
@@ -86,8 +99,8 @@ func (in *ServiceTemplateChainValidator) SetupWebhookWithManager(mgr ctrl.Manage
 	in.Client = mgr.GetClient()
 	return ctrl.NewWebhookManagedBy(mgr).
 		For(&v1alpha1.ServiceTemplateChain{}).
-		WithValidator(in).
-		WithDefaulter(in).
+		WithValidator(tracing.Validator("ServiceTemplateChain", scope.Validator(in))).
+		WithDefaulter(scope.Defaulter(in)).
 		Complete()
 }
 
@@ -110,8 +123,17 @@ func (*ServiceTemplateChainValidator) ValidateCreate(_ context.Context, obj runt
 }
 
 // ValidateUpdate implements webhook.Validator so a webhook will be registered for the type.
-func (*ServiceTemplateChainValidator) ValidateUpdate(_ context.Context, _, _ runtime.Object) (admission.Warnings, error) {
-	return nil, nil
+func (*ServiceTemplateChainValidator) ValidateUpdate(_ context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	oldChain, ok := oldObj.(*v1alpha1.ServiceTemplateChain)
+	if !ok {
+		return admission.Warnings{"Wrong object"}, apierrors.NewBadRequest(fmt.Sprintf("expected ServiceTemplateChain but got a %T", oldObj))
+	}
+	newChain, ok := newObj.(*v1alpha1.ServiceTemplateChain)
+	if !ok {
+		return admission.Warnings{"Wrong object"}, apierrors.NewBadRequest(fmt.Sprintf("expected ServiceTemplateChain but got a %T", newObj))
+	}
+
+	return validateTemplateChainUpdate(oldChain.Spec, newChain.Spec)
 }
 
 // ValidateDelete implements webhook.Validator so a webhook will be registered for the type.
@@ -124,6 +146,38 @@ func (*ServiceTemplateChainValidator) Default(_ context.Context, _ runtime.Objec
 	return nil
 }
 
+// validateTemplateChainUpdate validates the updated chain spec and ensures
+// that neither the supported templates nor their available upgrades have been removed.
+func validateTemplateChainUpdate(oldSpec, newSpec v1alpha1.TemplateChainSpec) (admission.Warnings, error) {
+	if warnings := isTemplateChainValid(newSpec); len(warnings) > 0 {
+		return warnings, errInvalidTemplateChainSpec
+	}
+
+	newTemplates := make(map[string]v1alpha1.SupportedTemplate, len(newSpec.SupportedTemplates))
+	for _, supportedTemplate := range newSpec.SupportedTemplates {
+		newTemplates[supportedTemplate.Name] = supportedTemplate
+	}
+
+	warnings := admission.Warnings{}
+	for _, oldTemplate := range oldSpec.SupportedTemplates {
+		newTemplate, ok := newTemplates[oldTemplate.Name]
+		if !ok {
+			warnings = append(warnings, fmt.Sprintf("supported template %s cannot be removed", oldTemplate.Name))
+			continue
+		}
+		for _, upgrade := range oldTemplate.AvailableUpgrades {
+			if !slices.Contains(newTemplate.AvailableUpgrades, upgrade) {
+				warnings = append(warnings, fmt.Sprintf("available upgrade from %s to %s cannot be removed", oldTemplate.Name, upgrade.Name))
+			}
+		}
+	}
+	if len(warnings) > 0 {
+		return warnings, errInvalidTemplateChainSpec
+	}
+
+	return nil, nil
+}
+
 func isTemplateChainValid(spec v1alpha1.TemplateChainSpec) admission.Warnings {
 	supportedTemplates := make(map[string]bool, len(spec.SupportedTemplates))
 	availableForUpgrade := make(map[string]bool, len(spec.SupportedTemplates))
@@ -139,5 +193,13 @@ func isTemplateChainValid(spec v1alpha1.TemplateChainSpec) admission.Warnings {
 			warnings = append(warnings, fmt.Sprintf("template %s is allowed for upgrade but is not present in the list of spec.SupportedTemplates", template))
 		}
 	}
+	for _, rule := range spec.UpgradeRules {
+		if _, err := semver.NewConstraint(rule.From); err != nil {
+			warnings = append(warnings, fmt.Sprintf("upgrade rule for the %s chart has invalid from constraint %s: %v", rule.Chart, rule.From, err))
+		}
+		if _, err := semver.NewConstraint(rule.To); err != nil {
+			warnings = append(warnings, fmt.Sprintf("upgrade rule for the %s chart has invalid to constraint %s: %v", rule.Chart, rule.To, err))
+		}
+	}
 	return warnings
 }
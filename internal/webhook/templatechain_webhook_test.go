@@ -63,6 +63,18 @@ func TestClusterTemplateChainValidateCreate(t *testing.T) {
 			name:  "should succeed",
 			chain: tc.NewClusterTemplateChain(tc.WithName("test"), tc.WithSupportedTemplates(append(supportedTemplates, v1alpha1.SupportedTemplate{Name: upgradeToTemplateName}))),
 		},
+		{
+			name: "should fail if spec is invalid: incorrect upgrade rules",
+			chain: func() *v1alpha1.ClusterTemplateChain {
+				chain := tc.NewClusterTemplateChain(tc.WithName("test"), tc.WithSupportedTemplates(append(supportedTemplates, v1alpha1.SupportedTemplate{Name: upgradeToTemplateName})))
+				chain.Spec.UpgradeRules = []v1alpha1.UpgradeRule{{Chart: "template", From: "~1.0", To: "latest"}}
+				return chain
+			}(),
+			warnings: admission.Warnings{
+				"upgrade rule for the template chart has invalid to constraint latest: improper constraint: latest",
+			},
+			err: "the template chain spec is invalid",
+		},
 	}
 
 	for _, tt := range tests {
@@ -183,3 +195,82 @@ func TestServiceTemplateChainValidateCreate(t *testing.T) {
 		})
 	}
 }
+
+func TestClusterTemplateChainValidateUpdate(t *testing.T) {
+	ctx := context.Background()
+
+	oldChain := tc.NewClusterTemplateChain(tc.WithName("test"), tc.WithSupportedTemplates([]v1alpha1.SupportedTemplate{
+		{Name: "template-1-0-1", AvailableUpgrades: []v1alpha1.AvailableUpgrade{{Name: "template-1-0-2"}}},
+		{Name: "template-1-0-2"},
+	}))
+
+	tests := []struct {
+		name     string
+		newChain *v1alpha1.ClusterTemplateChain
+		err      string
+		warnings admission.Warnings
+	}{
+		{
+			name: "should succeed if the chain is extended",
+			newChain: tc.NewClusterTemplateChain(tc.WithName("test"), tc.WithSupportedTemplates([]v1alpha1.SupportedTemplate{
+				{Name: "template-1-0-1", AvailableUpgrades: []v1alpha1.AvailableUpgrade{{Name: "template-1-0-2"}}},
+				{Name: "template-1-0-2", AvailableUpgrades: []v1alpha1.AvailableUpgrade{{Name: "template-1-0-3"}}},
+				{Name: "template-1-0-3"},
+			})),
+		},
+		{
+			name: "should succeed if the lifecycle of a supported template is changed",
+			newChain: tc.NewClusterTemplateChain(tc.WithName("test"), tc.WithSupportedTemplates([]v1alpha1.SupportedTemplate{
+				{
+					Name:              "template-1-0-1",
+					Lifecycle:         &v1alpha1.TemplateLifecycle{Deprecated: true},
+					AvailableUpgrades: []v1alpha1.AvailableUpgrade{{Name: "template-1-0-2"}},
+				},
+				{Name: "template-1-0-2"},
+			})),
+		},
+		{
+			name: "should fail if the new spec is invalid",
+			newChain: tc.NewClusterTemplateChain(tc.WithName("test"), tc.WithSupportedTemplates([]v1alpha1.SupportedTemplate{
+				{Name: "template-1-0-1", AvailableUpgrades: []v1alpha1.AvailableUpgrade{{Name: "template-1-0-2"}}},
+				{Name: "template-1-0-2", AvailableUpgrades: []v1alpha1.AvailableUpgrade{{Name: "template-1-0-3"}}},
+			})),
+			warnings: admission.Warnings{
+				"template template-1-0-3 is allowed for upgrade but is not present in the list of spec.SupportedTemplates",
+			},
+			err: "the template chain spec is invalid",
+		},
+		{
+			name: "should fail if a supported template or an available upgrade is removed",
+			newChain: tc.NewClusterTemplateChain(tc.WithName("test"), tc.WithSupportedTemplates([]v1alpha1.SupportedTemplate{
+				{Name: "template-1-0-1"},
+			})),
+			warnings: admission.Warnings{
+				"available upgrade from template-1-0-1 to template-1-0-2 cannot be removed",
+				"supported template template-1-0-2 cannot be removed",
+			},
+			err: "the template chain spec is invalid",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			c := fake.NewClientBuilder().WithScheme(scheme.Scheme).Build()
+			validator := &ClusterTemplateChainValidator{Client: c}
+			warn, err := validator.ValidateUpdate(ctx, oldChain, tt.newChain)
+			if tt.err != "" {
+				g.Expect(err).To(MatchError(tt.err))
+			} else {
+				g.Expect(err).To(Succeed())
+			}
+
+			if len(tt.warnings) > 0 {
+				g.Expect(warn).To(Equal(tt.warnings))
+			} else {
+				g.Expect(warn).To(BeEmpty())
+			}
+		})
+	}
+}
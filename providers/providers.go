@@ -12,21 +12,12 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-package telemetry
+// Package providers embeds the definitions of the built-in providers.
+package providers
 
-import (
-	"github.com/segmentio/analytics-go"
-)
+import "embed"
 
-var (
-	segmentToken    = ""
-	analyticsClient analytics.Client
-)
-
-func init() {
-	if segmentToken == "" {
-		return
-	}
-
-	analyticsClient = analytics.New(segmentToken)
-}
+// FS holds the YAML definitions of the built-in providers.
+//
+//go:embed *.yml
+var FS embed.FS
@@ -0,0 +1,11 @@
+{{- define "cluster.name" -}}
+    {{- .Release.Name | trunc 63 | trimSuffix "-" }}
+{{- end }}
+
+{{- define "gcpmanagedcontrolplane.name" -}}
+    {{- include "cluster.name" . }}-cp
+{{- end }}
+
+{{- define "machinepool.worker.name" -}}
+    {{- include "cluster.name" . }}-worker
+{{- end }}
@@ -40,6 +40,7 @@ const (
 	ProviderAzure   ProviderType = "infrastructure-azure"
 	ProviderVSphere ProviderType = "infrastructure-vsphere"
 	ProviderAdopted ProviderType = "infrastructure-internal"
+	ProviderDocker  ProviderType = "infrastructure-docker"
 	providerLabel                = "cluster.x-k8s.io/provider"
 )
 
@@ -54,6 +55,8 @@ const (
 	TemplateVSphereStandaloneCP Template = "vsphere-standalone-cp"
 	TemplateVSphereHostedCP     Template = "vsphere-hosted-cp"
 	TemplateAdoptedCluster      Template = "adopted-cluster"
+	TemplateDockerStandaloneCP  Template = "docker-standalone-cp"
+	TemplateDockerHostedCP      Template = "docker-hosted-cp"
 )
 
 //go:embed resources/aws-standalone-cp.yaml.tpl
@@ -80,6 +83,12 @@ var vsphereHostedCPClusterDeploymentTemplateBytes []byte
 //go:embed resources/adopted-cluster.yaml.tpl
 var adoptedClusterDeploymentTemplateBytes []byte
 
+//go:embed resources/docker-standalone-cp.yaml.tpl
+var dockerStandaloneCPClusterDeploymentTemplateBytes []byte
+
+//go:embed resources/docker-hosted-cp.yaml.tpl
+var dockerHostedCPClusterDeploymentTemplateBytes []byte
+
 func FilterAllProviders() []string {
 	return []string{
 		utils.KCMControllerLabel,
@@ -151,6 +160,10 @@ func GetUnstructured(templateName Template) *unstructured.Unstructured {
 		clusterDeploymentTemplateBytes = azureStandaloneCPClusterDeploymentTemplateBytes
 	case TemplateAdoptedCluster:
 		clusterDeploymentTemplateBytes = adoptedClusterDeploymentTemplateBytes
+	case TemplateDockerStandaloneCP:
+		clusterDeploymentTemplateBytes = dockerStandaloneCPClusterDeploymentTemplateBytes
+	case TemplateDockerHostedCP:
+		clusterDeploymentTemplateBytes = dockerHostedCPClusterDeploymentTemplateBytes
 	default:
 		Fail(fmt.Sprintf("Unsupported template: %s", templateName))
 	}
@@ -51,4 +51,7 @@ const (
 	// Adopted
 	EnvVarAdoptedKubeconfigPath = "KUBECONFIG_DATA_PATH"
 	EnvVarAdoptedCredential     = "ADOPTED_CREDENTIAL"
+
+	// Docker
+	EnvVarDockerCredential = "DOCKER_CREDENTIAL"
 )
@@ -74,7 +74,8 @@ func NewProviderValidator(template Template, clusterName string, action Validati
 				"ccm":                        validateCCM,
 			}
 			resourceOrder = []string{"clusters", "machines", "aws-managed-control-planes", "csi-driver", "ccm"}
-		case TemplateAzureStandaloneCP, TemplateAzureHostedCP, TemplateVSphereStandaloneCP:
+		case TemplateAzureStandaloneCP, TemplateAzureHostedCP, TemplateVSphereStandaloneCP,
+			TemplateDockerStandaloneCP, TemplateDockerHostedCP:
 			delete(resourcesToValidate, "csi-driver")
 		case TemplateAdoptedCluster:
 			resourcesToValidate = map[string]resourceValidationFunc{
@@ -342,6 +342,40 @@ func (kc *KubeClient) ListClusterTemplates(ctx context.Context) ([]unstructured.
 	return resources.Items, nil
 }
 
+// AddManagementProvider adds the provider with the given name to the providers
+// of the Management unless it is already listed, the opt-in providers are not installed otherwise.
+func (kc *KubeClient) AddManagementProvider(ctx context.Context, name string) error {
+	client := kc.GetDynamicClient(schema.GroupVersionResource{
+		Group:    v1alpha1.GroupVersion.Group,
+		Version:  v1alpha1.GroupVersion.Version,
+		Resource: "managements",
+	}, false)
+	management, err := client.Get(ctx, v1alpha1.ManagementName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get management %s: %w", v1alpha1.ManagementName, err)
+	}
+
+	providers, _, err := unstructured.NestedSlice(management.Object, "spec", "providers")
+	if err != nil {
+		return fmt.Errorf("failed to get the providers of management %s: %w", v1alpha1.ManagementName, err)
+	}
+	for _, provider := range providers {
+		if p, ok := provider.(map[string]any); ok && p["name"] == name {
+			return nil
+		}
+	}
+
+	providers = append(providers, map[string]any{"name": name})
+	if err := unstructured.SetNestedSlice(management.Object, providers, "spec", "providers"); err != nil {
+		return fmt.Errorf("failed to set the providers of management %s: %w", v1alpha1.ManagementName, err)
+	}
+	if _, err := client.Update(ctx, management, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("failed to add provider %s to management %s: %w", name, v1alpha1.ManagementName, err)
+	}
+
+	return nil
+}
+
 func (kc *KubeClient) GetCredential(ctx context.Context, name string) (*unstructured.Unstructured, error) {
 	client := kc.GetDynamicClient(schema.GroupVersionResource{
 		Group:    v1alpha1.GroupVersion.Group,
@@ -0,0 +1,100 @@
+// Copyright 2024
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package e2e
+
+import (
+	"context"
+	"os"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	internalutils "github.com/K0rdent/kcm/internal/utils"
+	"github.com/K0rdent/kcm/test/e2e/clusterdeployment"
+	"github.com/K0rdent/kcm/test/e2e/clusterdeployment/clusteridentity"
+	"github.com/K0rdent/kcm/test/e2e/kubeclient"
+)
+
+var _ = Context("Docker Templates", Label("provider:local", "provider:docker"), Ordered, func() {
+	var (
+		kc          *kubeclient.KubeClient
+		deleteFunc  func() error
+		clusterName string
+		template    clusterdeployment.Template
+	)
+
+	BeforeAll(func() {
+		By("creating kube client")
+		kc = kubeclient.NewFromLocal(internalutils.DefaultSystemNamespace)
+		By("installing the opt-in Docker provider")
+		Expect(kc.AddManagementProvider(context.Background(), "cluster-api-provider-docker")).To(Succeed())
+		Eventually(func() error {
+			return validateController(kc, clusterdeployment.GetProviderLabel(clusterdeployment.ProviderDocker), string(clusterdeployment.ProviderDocker))
+		}).WithTimeout(10 * time.Minute).WithPolling(10 * time.Second).Should(Succeed())
+		By("providing cluster identity")
+		ci := clusteridentity.New(kc, clusterdeployment.ProviderDocker)
+		ci.WaitForValidCredential(kc)
+		Expect(os.Setenv(clusterdeployment.EnvVarDockerCredential, ci.CredentialName)).Should(Succeed())
+	})
+
+	AfterEach(func() {
+		// If we failed collect logs from each of the affiliated controllers
+		// as well as the output of clusterctl to store as artifacts.
+		if CurrentSpecReport().Failed() {
+			By("collecting failure logs from controllers")
+			collectLogArtifacts(kc, clusterName, clusterdeployment.ProviderDocker, clusterdeployment.ProviderCAPI)
+		}
+
+		if deleteFunc != nil && cleanup() {
+			deletionValidator := clusterdeployment.NewProviderValidator(
+				template,
+				clusterName,
+				clusterdeployment.ValidationActionDelete,
+			)
+
+			Expect(deleteFunc()).NotTo(HaveOccurred())
+			deleteFunc = nil
+			Eventually(func() error {
+				return deletionValidator.Validate(context.Background(), kc)
+			}).WithTimeout(10 * time.Minute).WithPolling(10 * time.Second).Should(Succeed())
+		}
+	})
+
+	for _, t := range []clusterdeployment.Template{
+		clusterdeployment.TemplateDockerStandaloneCP,
+		clusterdeployment.TemplateDockerHostedCP,
+	} {
+		It("should deploy "+string(t)+" managed cluster", func() {
+			template = t
+
+			templateBy(template, "creating a ClusterDeployment")
+			d := clusterdeployment.GetUnstructured(template)
+			clusterName = d.GetName()
+
+			deleteFunc = kc.CreateClusterDeployment(context.Background(), d)
+
+			templateBy(template, "waiting for infrastructure to deploy successfully")
+			deploymentValidator := clusterdeployment.NewProviderValidator(
+				template,
+				clusterName,
+				clusterdeployment.ValidationActionDeploy,
+			)
+			Eventually(func() error {
+				return deploymentValidator.Validate(context.Background(), kc)
+			}).WithTimeout(15 * time.Minute).WithPolling(10 * time.Second).Should(Succeed())
+		})
+	}
+})
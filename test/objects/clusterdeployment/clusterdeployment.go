@@ -82,6 +82,14 @@ func WithServiceTemplate(templateName string) Opt {
 	}
 }
 
+func WithAutoscaler(templateName string) Opt {
+	return func(p *v1alpha1.ClusterDeployment) {
+		p.Spec.Autoscaling = &v1alpha1.AutoscalingSpec{
+			Autoscaler: &v1alpha1.AutoscalerService{Template: templateName},
+		}
+	}
+}
+
 func WithCredential(credName string) Opt {
 	return func(p *v1alpha1.ClusterDeployment) {
 		p.Spec.Credential = credName
@@ -226,3 +226,16 @@ func WithClusterStatusProviderContracts(providerContracts map[string]string) Opt
 		ct.Status.ProviderContracts = providerContracts
 	}
 }
+
+func WithLifecycle(lifecycle *v1alpha1.TemplateLifecycle) Opt {
+	return func(template Template) {
+		switch tt := template.(type) {
+		case *v1alpha1.ClusterTemplate:
+			tt.Spec.Lifecycle = lifecycle
+		case *v1alpha1.ServiceTemplate:
+			tt.Spec.Lifecycle = lifecycle
+		default:
+			panic(fmt.Sprintf("unexpected obj typed %T, expected *ClusterTemplate or *ServiceTemplate", tt))
+		}
+	}
+}